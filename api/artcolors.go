@@ -0,0 +1,256 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"  // register decoders for image.Decode
+	_ "image/jpeg" // register decoders for image.Decode
+	_ "image/png"  // register decoders for image.Decode
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/b0bbywan/go-odio-api/backend/mpris"
+	"github.com/b0bbywan/go-odio-api/cache"
+)
+
+const (
+	// artColorsTTL bounds how long the colors of a cover are kept once
+	// computed; see artColorsKey for covers rewritten under the same URL.
+	artColorsTTL = time.Hour
+	// maxArtBytes caps the artwork read to compute its colors.
+	maxArtBytes = 8 << 20
+	// maxArtPixels caps the decoded size: a small file may declare huge
+	// dimensions and allocate gigabytes once decoded.
+	maxArtPixels = 4096 * 4096
+	// artSampleSize is the side of the grid sampled from the cover.
+	artSampleSize = 64
+	// minColorDistance keeps the secondary color visibly apart from the
+	// primary one (squared RGB distance).
+	minColorDistance = 48 * 48
+)
+
+// ArtColors is the theming palette of a cover, as CSS hex colors.
+type ArtColors struct {
+	Primary   string `json:"primary"`
+	Secondary string `json:"secondary"`
+}
+
+// artClient fetches remote covers; it is bounded since a stalled image host
+// must not hold the request.
+var artClient = &http.Client{Timeout: 5 * time.Second}
+
+// ArtColorsHandler serves the dominant colors of the current track's
+// artwork, so a UI can theme its now-playing card. The cover is read the way
+// CoverHandler serves it (prefetched cache, then disk) and the result is
+// cached per cover version (artColorsKey). http(s) covers are fetched by the server itself, so only
+// with remote (api.remote_artwork): any local MPRIS client picks the URL.
+func ArtColorsHandler(
+	getPlayer func(string) (*mpris.Player, error),
+	artwork func(string) ([]byte, bool),
+	remote bool,
+) http.HandlerFunc {
+	colors := cache.New[ArtColors](artColorsTTL)
+	return withPlayer(func(w http.ResponseWriter, r *http.Request, busName string) {
+		player, err := getPlayer(busName)
+		if err != nil {
+			handleMPRISError(w, err)
+			return
+		}
+
+		artUrl := player.Metadata["mpris:artUrl"]
+		if artUrl == "" {
+			writeError(w, "no artwork for the current track", http.StatusNotFound)
+			return
+		}
+
+		key := artColorsKey(artUrl)
+		palette, ok := colors.Get(key)
+		if !ok {
+			data, err := readArtwork(artUrl, artwork, remote)
+			if errors.Is(err, errRemoteArtwork) {
+				writeError(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			if err != nil {
+				writeError(w, err.Error(), http.StatusBadGateway)
+				return
+			}
+			img, err := decodeArtwork(data)
+			if err != nil {
+				writeError(w, err.Error(), http.StatusUnsupportedMediaType)
+				return
+			}
+			primary, secondary := dominantColors(img)
+			palette = ArtColors{Primary: hexColor(primary), Secondary: hexColor(secondary)}
+			colors.CleanExpired()
+			colors.Set(key, palette)
+		}
+
+		JSONHandler(func(w http.ResponseWriter, r *http.Request) (any, error) {
+			return palette, nil
+		})(w, r)
+	})
+}
+
+// artColorsKey identifies one version of a cover: a file:// cover also keys
+// on the file's mtime and size, as players rewrite a fixed path (e.g.
+// /tmp/cover.jpg) on every track, like the MPRIS artwork cache does. A file
+// that cannot be stat'ed falls back to the artUrl.
+func artColorsKey(artUrl string) string {
+	path, ok := mpris.ArtworkPath(artUrl)
+	if !ok {
+		return artUrl
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return artUrl
+	}
+	return fmt.Sprintf("%s\x00%d\x00%d", artUrl, info.ModTime().UnixNano(), info.Size())
+}
+
+var errRemoteArtwork = errors.New("remote artwork disabled (api.remote_artwork)")
+
+// readArtwork returns the bytes of a cover: file:// from the prefetched
+// cache or disk, http(s) from the network when remote is set.
+func readArtwork(artUrl string, artwork func(string) ([]byte, bool), remote bool) ([]byte, error) {
+	var body io.ReadCloser
+	switch {
+	case strings.HasPrefix(artUrl, "file://"):
+		path, data, ok := localArtwork(artUrl, artwork)
+		if !ok {
+			return nil, fmt.Errorf("invalid artwork URL %q", artUrl)
+		}
+		if data != nil {
+			return data, nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		body = f
+	case strings.HasPrefix(artUrl, "http://"), strings.HasPrefix(artUrl, "https://"):
+		if !remote {
+			return nil, errRemoteArtwork
+		}
+		resp, err := artClient.Get(artUrl)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			_ = resp.Body.Close()
+			return nil, fmt.Errorf("artwork fetch: %s", resp.Status)
+		}
+		body = resp.Body
+	default:
+		return nil, fmt.Errorf("unsupported artwork URL %q", artUrl)
+	}
+	defer func() { _ = body.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(body, maxArtBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxArtBytes {
+		return nil, errors.New("artwork too large")
+	}
+	return data, nil
+}
+
+// decodeArtwork decodes a cover after checking its declared dimensions
+// against maxArtPixels.
+func decodeArtwork(data []byte) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("unsupported artwork format: %w", err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width > maxArtPixels/cfg.Height {
+		return nil, fmt.Errorf("artwork too large: %dx%d", cfg.Width, cfg.Height)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("unsupported artwork format: %w", err)
+	}
+	return img, nil
+}
+
+// colorBucket accumulates the pixels quantized to the same bucket.
+type colorBucket struct {
+	count   int
+	r, g, b int
+}
+
+func (b colorBucket) average() color.RGBA {
+	return color.RGBA{
+		R: uint8(b.r / b.count),
+		G: uint8(b.g / b.count),
+		B: uint8(b.b / b.count),
+		A: 0xff,
+	}
+}
+
+// dominantColors samples img on an artSampleSize grid and buckets the pixels
+// by their 4 high bits per channel. The primary color is the average of the
+// fullest bucket, the secondary one that of the fullest bucket far enough
+// from it, or the primary again for a flat cover. Transparent pixels are
+// ignored.
+func dominantColors(img image.Image) (color.RGBA, color.RGBA) {
+	bounds := img.Bounds()
+	buckets := make(map[uint16]*colorBucket)
+	for y := range artSampleSize {
+		py := bounds.Min.Y + (2*y+1)*bounds.Dy()/(2*artSampleSize)
+		for x := range artSampleSize {
+			px := bounds.Min.X + (2*x+1)*bounds.Dx()/(2*artSampleSize)
+			c := color.NRGBAModel.Convert(img.At(px, py)).(color.NRGBA)
+			if c.A < 0x80 {
+				continue
+			}
+			key := uint16(c.R>>4)<<8 | uint16(c.G>>4)<<4 | uint16(c.B>>4)
+			b := buckets[key]
+			if b == nil {
+				b = &colorBucket{}
+				buckets[key] = b
+			}
+			b.count++
+			b.r += int(c.R)
+			b.g += int(c.G)
+			b.b += int(c.B)
+		}
+	}
+
+	ranked := make([]*colorBucket, 0, len(buckets))
+	for _, b := range buckets {
+		ranked = append(ranked, b)
+	}
+	if len(ranked) == 0 {
+		black := color.RGBA{A: 0xff}
+		return black, black
+	}
+	sort.Slice(ranked, func(i, j int) bool { return ranked[i].count > ranked[j].count })
+
+	primary := ranked[0].average()
+	for _, b := range ranked[1:] {
+		if c := b.average(); colorDistance(primary, c) >= minColorDistance {
+			return primary, c
+		}
+	}
+	return primary, primary
+}
+
+// colorDistance is the squared RGB distance between a and b.
+func colorDistance(a, b color.RGBA) int {
+	dr := int(a.R) - int(b.R)
+	dg := int(a.G) - int(b.G)
+	db := int(a.B) - int(b.B)
+	return dr*dr + dg*dg + db*db
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
@@ -0,0 +1,196 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/b0bbywan/go-odio-api/backend/mpris"
+)
+
+// coverPNG draws a cover with the top three quarters in top and the rest in
+// bottom.
+func coverPNG(t *testing.T, top, bottom color.RGBA) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	for y := range 100 {
+		for x := range 100 {
+			if y < 75 {
+				img.Set(x, y, top)
+			} else {
+				img.Set(x, y, bottom)
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDominantColors(t *testing.T) {
+	red := color.RGBA{R: 200, G: 20, B: 30, A: 0xff}
+	blue := color.RGBA{R: 10, G: 40, B: 220, A: 0xff}
+
+	img, _, err := image.Decode(bytes.NewReader(coverPNG(t, red, blue)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	primary, secondary := dominantColors(img)
+	if primary != red || secondary != blue {
+		t.Errorf("dominantColors() = %s, %s; want %s, %s", hexColor(primary), hexColor(secondary), hexColor(red), hexColor(blue))
+	}
+
+	flat, _, _ := image.Decode(bytes.NewReader(coverPNG(t, red, red)))
+	if primary, secondary := dominantColors(flat); primary != red || secondary != red {
+		t.Errorf("flat cover = %s, %s; want the primary twice", hexColor(primary), hexColor(secondary))
+	}
+
+	transparent := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	if primary, _ := dominantColors(transparent); hexColor(primary) != "#000000" {
+		t.Errorf("transparent cover primary = %s, want #000000", hexColor(primary))
+	}
+}
+
+func TestArtColorsHandler(t *testing.T) {
+	const artURL = "file:///tmp/odio-cover.png"
+	cover := coverPNG(t, color.RGBA{R: 255, A: 0xff}, color.RGBA{B: 255, A: 0xff})
+	players := map[string]*mpris.Player{
+		"org.mpris.MediaPlayer2.spotify": {Metadata: map[string]string{"mpris:artUrl": artURL}},
+		"org.mpris.MediaPlayer2.vlc":     {Metadata: map[string]string{}},
+	}
+	getPlayer := func(busName string) (*mpris.Player, error) {
+		if p, ok := players[busName]; ok {
+			return p, nil
+		}
+		return nil, &mpris.PlayerNotFoundError{BusName: busName}
+	}
+	reads := 0
+	artwork := func(url string) ([]byte, bool) {
+		reads++
+		return cover, url == artURL
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /players/{player}/art-colors", ArtColorsHandler(getPlayer, artwork, false))
+
+	for range 2 {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/players/org.mpris.MediaPlayer2.spotify/art-colors", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200 (%s)", w.Code, w.Body)
+		}
+		var got ArtColors
+		if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if want := (ArtColors{Primary: "#ff0000", Secondary: "#0000ff"}); got != want {
+			t.Errorf("colors = %+v, want %+v", got, want)
+		}
+	}
+	if reads != 1 {
+		t.Errorf("artwork read %d times, want 1 (cached per artUrl)", reads)
+	}
+
+	for path, want := range map[string]int{
+		"/players/org.mpris.MediaPlayer2.vlc/art-colors":  http.StatusNotFound,
+		"/players/org.mpris.MediaPlayer2.nope/art-colors": http.StatusNotFound,
+	} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != want {
+			t.Errorf("GET %s: status = %d, want %d", path, w.Code, want)
+		}
+	}
+}
+
+// TestArtColorsFollowRewrittenCover: a player rewriting the same cover file
+// gets the new palette, not the one cached for the previous track.
+func TestArtColorsFollowRewrittenCover(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cover.png")
+	artURL := "file://" + path
+	player := &mpris.Player{Metadata: map[string]string{"mpris:artUrl": artURL}}
+	getPlayer := func(string) (*mpris.Player, error) { return player, nil }
+	none := func(string) ([]byte, bool) { return nil, false }
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /players/{player}/art-colors", ArtColorsHandler(getPlayer, none, false))
+
+	tracks := []struct {
+		primary, secondary color.RGBA
+		modTime            time.Time
+		want               ArtColors
+	}{
+		{color.RGBA{R: 255, A: 0xff}, color.RGBA{B: 255, A: 0xff}, time.Unix(1000, 0), ArtColors{Primary: "#ff0000", Secondary: "#0000ff"}},
+		{color.RGBA{G: 255, A: 0xff}, color.RGBA{B: 255, A: 0xff}, time.Unix(2000, 0), ArtColors{Primary: "#00ff00", Secondary: "#0000ff"}},
+	}
+	for _, track := range tracks {
+		if err := os.WriteFile(path, coverPNG(t, track.primary, track.secondary), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, track.modTime, track.modTime); err != nil {
+			t.Fatal(err)
+		}
+
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/players/org.mpris.MediaPlayer2.mpd/art-colors", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200 (%s)", w.Code, w.Body)
+		}
+		var got ArtColors
+		if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if got != track.want {
+			t.Errorf("colors = %+v, want %+v", got, track.want)
+		}
+	}
+}
+
+func TestDecodeArtworkRejectsHugeDimensions(t *testing.T) {
+	var buf bytes.Buffer
+	if err := gif.Encode(&buf, image.NewPaletted(image.Rect(0, 0, 1, 1), color.Palette{color.Black}), nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := decodeArtwork(buf.Bytes()); err != nil {
+		t.Fatalf("decodeArtwork(1x1) error: %v", err)
+	}
+
+	// the logical screen size follows the 6-byte header, little-endian
+	bomb := buf.Bytes()
+	copy(bomb[6:10], []byte{0xff, 0xff, 0xff, 0xff})
+	if _, err := decodeArtwork(bomb); err == nil || !strings.Contains(err.Error(), "too large") {
+		t.Errorf("decodeArtwork(65535x65535) error = %v, want too large", err)
+	}
+}
+
+func TestReadArtworkRemoteOptIn(t *testing.T) {
+	fetched := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched = true
+		_, _ = w.Write([]byte("cover"))
+	}))
+	defer srv.Close()
+	none := func(string) ([]byte, bool) { return nil, false }
+
+	if _, err := readArtwork(srv.URL+"/cover.png", none, false); err != errRemoteArtwork {
+		t.Errorf("readArtwork(remote off) error = %v, want errRemoteArtwork", err)
+	}
+	if fetched {
+		t.Error("remote artwork fetched although api.remote_artwork is off")
+	}
+
+	data, err := readArtwork(srv.URL+"/cover.png", none, true)
+	if err != nil || string(data) != "cover" {
+		t.Errorf("readArtwork(remote on) = %q, %v; want cover", data, err)
+	}
+}
@@ -2,13 +2,45 @@ package api
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/b0bbywan/go-odio-api/backend/pulseaudio"
 	"github.com/b0bbywan/go-odio-api/logger"
 )
 
-func AudioHandler(pa *pulseaudio.PulseAudioBackend) http.HandlerFunc {
+// AudioController is the slice of the PulseAudio backend the /audio routes
+// depend on. *pulseaudio.PulseAudioBackend satisfies it; tests supply
+// in-memory fakes.
+type AudioController interface {
+	Kind() pulseaudio.AudioServerKind
+	ServerInfo() (*pulseaudio.ServerInfo, error)
+	ListClients() ([]pulseaudio.AudioClient, error)
+	FilterClients(f pulseaudio.AudioClientFilter) ([]pulseaudio.AudioClient, error)
+	GetClient(name string) (*pulseaudio.AudioClient, bool)
+	RefreshClient(name string) (*pulseaudio.AudioClient, error)
+	CacheUpdatedAt() time.Time
+	ListOutputs() ([]pulseaudio.AudioOutput, error)
+	OutputCacheUpdatedAt() time.Time
+	Cookie() ([]byte, error)
+
+	ToggleMuteMaster() error
+	SetVolumeMaster(volume float32) error
+	ToggleMute(name string) error
+	SetVolume(name string, vol float32) error
+	NormalizeClientVolume(name string, target float32) error
+	SetDefaultOutput(name string) error
+	ToggleMuteOutput(name string) error
+	SetVolumeOutput(name string, vol float32) error
+	CreateLoopback(source, sink string) (uint32, error)
+	RemoveLoopback(moduleIndex uint32) error
+}
+
+func AudioHandler(pa AudioController) http.HandlerFunc {
 	return JSONHandler(func(w http.ResponseWriter, r *http.Request) (any, error) {
 		clients, err := pa.ListClients()
 		if err != nil {
@@ -33,46 +65,178 @@ func AudioHandler(pa *pulseaudio.PulseAudioBackend) http.HandlerFunc {
 	})
 }
 
+// audioState is the GET /audio/state body: the server info and the clients,
+// as served separately by /audio/server and /audio/clients.
+type audioState struct {
+	Server  *pulseaudio.ServerInfo   `json:"server"`
+	Clients []pulseaudio.AudioClient `json:"clients"`
+}
+
+// AudioStateHandler serves the server info and the client list in one
+// response, saving dashboards a round trip.
+func AudioStateHandler(
+	serverInfo func() (*pulseaudio.ServerInfo, error),
+	listClients func() ([]pulseaudio.AudioClient, error),
+) http.HandlerFunc {
+	return JSONHandler(func(w http.ResponseWriter, r *http.Request) (any, error) {
+		server, err := serverInfo()
+		if err != nil {
+			return nil, err
+		}
+		clients, err := listClients()
+		if err != nil {
+			return nil, err
+		}
+		return audioState{Server: server, Clients: clients}, nil
+	})
+}
+
+// ListClientsHandler serves /audio/clients, optionally narrowed by
+// ?corked=true|false and ?muted=true|false from the client cache.
+func ListClientsHandler(pa AudioController) http.HandlerFunc {
+	return JSONHandler(func(w http.ResponseWriter, r *http.Request) (any, error) {
+		filter, err := parseClientFilter(r.URL.Query())
+		if err != nil {
+			return nil, httpError(http.StatusBadRequest, err)
+		}
+
+		var clients []pulseaudio.AudioClient
+		if filter == (pulseaudio.AudioClientFilter{}) {
+			clients, err = pa.ListClients()
+		} else {
+			clients, err = pa.FilterClients(filter)
+		}
+		var notReadyErr *pulseaudio.NotReadyError
+		if errors.As(err, &notReadyErr) {
+			return nil, httpError(http.StatusServiceUnavailable, err)
+		}
+		if err != nil {
+			return nil, err
+		}
+		setCacheHeader(w, pa.CacheUpdatedAt())
+		return paginate(w, r, clients)
+	})
+}
+
+// ClientHandler serves one client from the cache, for widgets polling a single
+// stream; ?refresh=true reloads it from the audio server first.
+func ClientHandler(
+	get func(string) (*pulseaudio.AudioClient, bool),
+	refresh func(string) (*pulseaudio.AudioClient, error),
+) http.HandlerFunc {
+	return JSONHandler(func(w http.ResponseWriter, r *http.Request) (any, error) {
+		name := r.PathValue("sink")
+		doRefresh := false
+		if raw := r.URL.Query().Get("refresh"); raw != "" {
+			var err error
+			if doRefresh, err = strconv.ParseBool(raw); err != nil {
+				return nil, httpError(http.StatusBadRequest, fmt.Errorf("refresh must be true or false"))
+			}
+		}
+
+		if doRefresh {
+			client, err := refresh(name)
+			if err != nil {
+				status := audioErrorStatus(err)
+				if status == http.StatusNotFound {
+					return nil, jsonError(status, err)
+				}
+				return nil, httpError(status, err)
+			}
+			return client, nil
+		}
+
+		client, ok := get(name)
+		if !ok {
+			return nil, jsonError(http.StatusNotFound, &pulseaudio.NotFoundError{Resource: "client", Name: name})
+		}
+		return client, nil
+	})
+}
+
+type clientVolumeResponse struct {
+	Volume float32 `json:"volume"`
+	Muted  bool    `json:"muted"`
+}
+
+// ClientVolumeHandler serves only a cached client's volume and mute state,
+// for widgets polling a single slider.
+func ClientVolumeHandler(get func(string) (*pulseaudio.AudioClient, bool)) http.HandlerFunc {
+	return JSONHandler(func(w http.ResponseWriter, r *http.Request) (any, error) {
+		name := r.PathValue("sink")
+		client, ok := get(name)
+		if !ok {
+			return nil, jsonError(http.StatusNotFound, &pulseaudio.NotFoundError{Resource: "client", Name: name})
+		}
+		return clientVolumeResponse{Volume: client.Volume, Muted: client.Muted}, nil
+	})
+}
+
+func parseClientFilter(q url.Values) (pulseaudio.AudioClientFilter, error) {
+	var f pulseaudio.AudioClientFilter
+	for _, p := range []struct {
+		name string
+		dst  **bool
+	}{{"corked", &f.Corked}, {"muted", &f.Muted}} {
+		raw := q.Get(p.name)
+		if raw == "" {
+			continue
+		}
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return f, fmt.Errorf("%s must be true or false", p.name)
+		}
+		*p.dst = &v
+	}
+	return f, nil
+}
+
 func handleAudioError(w http.ResponseWriter, err error) {
 	if err == nil {
 		w.WriteHeader(http.StatusAccepted)
 		return
 	}
+	writeError(w, err.Error(), audioErrorStatus(err))
+}
 
+// audioErrorStatus maps an audio backend error to its HTTP status.
+func audioErrorStatus(err error) int {
 	var disabledErr *pulseaudio.DisabledError
 	if errors.As(err, &disabledErr) {
-		http.Error(w, err.Error(), http.StatusForbidden)
-		return
+		return http.StatusForbidden
 	}
 
 	var notFoundErr *pulseaudio.NotFoundError
 	if errors.As(err, &notFoundErr) {
-		http.Error(w, err.Error(), http.StatusNotFound)
-		return
+		return http.StatusNotFound
 	}
 
 	var notReadyErr *pulseaudio.NotReadyError
 	if errors.As(err, &notReadyErr) {
-		http.Error(w, err.Error(), http.StatusServiceUnavailable)
-		return
+		return http.StatusServiceUnavailable
+	}
+
+	var unavailableErr *pulseaudio.BackendUnavailableError
+	if errors.As(err, &unavailableErr) {
+		return http.StatusServiceUnavailable
 	}
 
-	http.Error(w, err.Error(), http.StatusInternalServerError)
+	return http.StatusInternalServerError
 }
 
-func MuteClientHandler(pa *pulseaudio.PulseAudioBackend) http.HandlerFunc {
+func MuteClientHandler(pa AudioController) http.HandlerFunc {
 	return withSink(pa, func(w http.ResponseWriter, r *http.Request, sink string) {
 		handleAudioError(w, pa.ToggleMute(sink))
 	})
 }
 
-func MuteMasterHandler(pa *pulseaudio.PulseAudioBackend) http.HandlerFunc {
+func MuteMasterHandler(pa AudioController) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		handleAudioError(w, pa.ToggleMuteMaster())
 	}
 }
 
-func SetVolumeClientHandler(pa *pulseaudio.PulseAudioBackend) http.HandlerFunc {
+func SetVolumeClientHandler(pa AudioController) http.HandlerFunc {
 	return withSink(pa, func(w http.ResponseWriter, r *http.Request, sink string) {
 		withBody(validateVolume, func(w http.ResponseWriter, r *http.Request, req *setVolumeRequest) {
 			handleAudioError(w, pa.SetVolume(sink, req.Volume))
@@ -80,25 +244,35 @@ func SetVolumeClientHandler(pa *pulseaudio.PulseAudioBackend) http.HandlerFunc {
 	})
 }
 
-func SetVolumeMasterHandler(pa *pulseaudio.PulseAudioBackend) http.HandlerFunc {
+// NormalizeClientHandler sets a client's volume so its level relative to its
+// sink matches the requested target.
+func NormalizeClientHandler(pa AudioController) http.HandlerFunc {
+	return withSink(pa, func(w http.ResponseWriter, r *http.Request, sink string) {
+		withBody(validateNormalize, func(w http.ResponseWriter, r *http.Request, req *normalizeRequest) {
+			handleAudioError(w, pa.NormalizeClientVolume(sink, req.Target))
+		})(w, r)
+	})
+}
+
+func SetVolumeMasterHandler(pa AudioController) http.HandlerFunc {
 	return withBody(validateVolume, func(w http.ResponseWriter, r *http.Request, req *setVolumeRequest) {
 		handleAudioError(w, pa.SetVolumeMaster(req.Volume))
 	})
 }
 
-func SetDefaultOutputHandler(pa *pulseaudio.PulseAudioBackend) http.HandlerFunc {
+func SetDefaultOutputHandler(pa AudioController) http.HandlerFunc {
 	return withOutput(pa, func(w http.ResponseWriter, r *http.Request, output string) {
 		handleAudioError(w, pa.SetDefaultOutput(output))
 	})
 }
 
-func MuteOutputHandler(pa *pulseaudio.PulseAudioBackend) http.HandlerFunc {
+func MuteOutputHandler(pa AudioController) http.HandlerFunc {
 	return withOutput(pa, func(w http.ResponseWriter, r *http.Request, output string) {
 		handleAudioError(w, pa.ToggleMuteOutput(output))
 	})
 }
 
-func SetVolumeOutputHandler(pa *pulseaudio.PulseAudioBackend) http.HandlerFunc {
+func SetVolumeOutputHandler(pa AudioController) http.HandlerFunc {
 	return withOutput(pa, func(w http.ResponseWriter, r *http.Request, output string) {
 		withBody(validateVolume, func(w http.ResponseWriter, r *http.Request, req *setVolumeRequest) {
 			handleAudioError(w, pa.SetVolumeOutput(output, req.Volume))
@@ -107,20 +281,67 @@ func SetVolumeOutputHandler(pa *pulseaudio.PulseAudioBackend) http.HandlerFunc {
 }
 
 func withOutput(
-	pa *pulseaudio.PulseAudioBackend,
+	pa AudioController,
 	fn func(w http.ResponseWriter, r *http.Request, output string),
 ) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		output := r.PathValue("output")
 		if output == "" {
-			http.Error(w, "missing output", http.StatusNotFound)
+			writeError(w, "missing output", http.StatusNotFound)
 			return
 		}
 		fn(w, r, output)
 	}
 }
 
-func CookieHandler(pa *pulseaudio.PulseAudioBackend) http.HandlerFunc {
+type loopbackRequest struct {
+	Source string `json:"source"`
+	Sink   string `json:"sink"`
+}
+
+type loopbackResponse struct {
+	ModuleIndex uint32 `json:"module_index"`
+}
+
+// validateLoopback requires both names and rejects whitespace and quotes,
+// which would break out of the module argument string.
+func validateLoopback(req *loopbackRequest) error {
+	for _, f := range []struct{ field, name string }{{"source", req.Source}, {"sink", req.Sink}} {
+		if f.name == "" {
+			return errors.New(f.field + " is required")
+		}
+		if strings.ContainsAny(f.name, " \t\n\"'") {
+			return errors.New("invalid " + f.field + " name")
+		}
+	}
+	return nil
+}
+
+// CreateLoopbackHandler loads a loopback and answers 201 with its module index,
+// the handle DELETE /audio/loopbacks/{module_index} takes.
+func CreateLoopbackHandler(create func(source, sink string) (uint32, error)) http.HandlerFunc {
+	return withBody(validateLoopback, func(w http.ResponseWriter, r *http.Request, req *loopbackRequest) {
+		index, err := create(req.Source, req.Sink)
+		if err != nil {
+			handleAudioError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, loopbackResponse{ModuleIndex: index})
+	})
+}
+
+func RemoveLoopbackHandler(remove func(moduleIndex uint32) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		index, err := strconv.ParseUint(r.PathValue("module_index"), 10, 32)
+		if err != nil {
+			writeError(w, "invalid module index", http.StatusBadRequest)
+			return
+		}
+		handleAudioError(w, remove(uint32(index)))
+	}
+}
+
+func CookieHandler(pa AudioController) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		data, err := pa.Cookie()
 		if err != nil {
@@ -136,13 +357,13 @@ func CookieHandler(pa *pulseaudio.PulseAudioBackend) http.HandlerFunc {
 }
 
 func withSink(
-	pa *pulseaudio.PulseAudioBackend,
+	pa AudioController,
 	fn func(w http.ResponseWriter, r *http.Request, sink string),
 ) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		sink := r.PathValue("sink")
 		if sink == "" {
-			http.Error(w, "missing sink", http.StatusNotFound)
+			writeError(w, "missing sink", http.StatusNotFound)
 			return
 		}
 
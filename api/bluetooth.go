@@ -1,8 +1,12 @@
 package api
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/b0bbywan/go-odio-api/backend/bluetooth"
 )
@@ -11,17 +15,56 @@ type bluetoothAddressRequest struct {
 	Address string `json:"address"`
 }
 
+type bluetoothRenameRequest struct {
+	Alias string `json:"alias"`
+}
+
+type bluetoothProfileRequest struct {
+	Profile string `json:"profile"`
+}
+
+type bluetoothConfirmRequest struct {
+	Accept *bool `json:"accept"`
+}
+
+type bluetoothDiscoverableRequest struct {
+	Duration string `json:"duration"`
+}
+
+// pairingResponse tells the client when the pairing window closes, so it can
+// show a countdown without polling GET /bluetooth.
+type pairingResponse struct {
+	PairingUntil time.Time `json:"pairing_until"`
+	SecondsLeft  int       `json:"seconds_left"`
+}
+
+const (
+	defaultBluetoothWait = 30 * time.Second
+	maxBluetoothWait     = 5 * time.Minute
+)
+
 func handleBluetoothError(w http.ResponseWriter, err error) {
 	if err == nil {
 		w.WriteHeader(http.StatusAccepted)
 		return
 	}
-	if errors.Is(err, bluetooth.ErrInvalidAddress) {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if errors.Is(err, bluetooth.ErrInvalidAddress) ||
+		errors.Is(err, bluetooth.ErrInvalidProfile) ||
+		errors.Is(err, bluetooth.ErrInvalidAlias) ||
+		errors.Is(err, bluetooth.ErrInvalidDuration) {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, bluetooth.ErrDeviceNotAllowed) {
+		writeError(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if errors.Is(err, bluetooth.ErrNoPendingConfirmation) {
+		writeError(w, err.Error(), http.StatusNotFound)
 		return
 	}
 	// Everything else here is a BlueZ/device operation failure upstream of us.
-	http.Error(w, err.Error(), http.StatusBadGateway)
+	writeError(w, err.Error(), http.StatusBadGateway)
 }
 
 func withBluetoothAction(action func() error) http.HandlerFunc {
@@ -37,3 +80,103 @@ func withBluetoothAddress(action func(string) error) http.HandlerFunc {
 		handleBluetoothError(w, action(req.Address))
 	})
 }
+
+// withBluetoothRename decodes a {"alias": "..."} body and renames the device
+// addressed in the path.
+func withBluetoothRename(action func(address, alias string) error) http.HandlerFunc {
+	return withBody(nil, func(w http.ResponseWriter, r *http.Request, req *bluetoothRenameRequest) {
+		handleBluetoothError(w, action(r.PathValue("address"), req.Alias))
+	})
+}
+
+// withBluetoothProfile decodes a {"profile": "..."} body and runs it against
+// the device addressed in the path; both are validated by the backend.
+func withBluetoothProfile(action func(address, profile string) error) http.HandlerFunc {
+	return withBody(nil, func(w http.ResponseWriter, r *http.Request, req *bluetoothProfileRequest) {
+		handleBluetoothError(w, action(r.PathValue("address"), req.Profile))
+	})
+}
+
+// withBluetoothConfirm decodes a {"accept": true|false} body and answers the
+// pending numeric-comparison pairing.
+func withBluetoothConfirm(confirm func(bool) error) http.HandlerFunc {
+	validate := func(req *bluetoothConfirmRequest) error {
+		if req.Accept == nil {
+			return errors.New("accept is required")
+		}
+		return nil
+	}
+	return withBody(validate, func(w http.ResponseWriter, r *http.Request, req *bluetoothConfirmRequest) {
+		handleBluetoothError(w, confirm(*req.Accept))
+	})
+}
+
+// withBluetoothDiscoverable decodes a {"duration": "60s"} body and makes the
+// adapter discoverable, without pairing, for that long.
+func withBluetoothDiscoverable(action func(time.Duration) error) http.HandlerFunc {
+	return withBody(nil, func(w http.ResponseWriter, r *http.Request, req *bluetoothDiscoverableRequest) {
+		d, err := time.ParseDuration(req.Duration)
+		if err != nil {
+			writeError(w, fmt.Sprintf("invalid duration %q", req.Duration), http.StatusBadRequest)
+			return
+		}
+		handleBluetoothError(w, action(d))
+	})
+}
+
+// PairingHandler powers the adapter up and makes it pairable, then answers
+// with the window's deadline. Calling it during an active window keeps the
+// existing deadline rather than restarting it.
+func PairingHandler(pair func() error, status func() bluetooth.BluetoothStatus) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := pair(); err != nil {
+			handleBluetoothError(w, err)
+			return
+		}
+		st := status()
+		if !st.PairingActive || st.PairingUntil == nil {
+			// Pairing was accepted but its status has not landed yet.
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		writeJSON(w, http.StatusOK, pairingResponse{
+			PairingUntil: *st.PairingUntil,
+			SecondsLeft:  max(0, int(time.Until(*st.PairingUntil).Seconds())),
+		})
+	}
+}
+
+// BluetoothWaitHandler long-polls until the device in the path connects:
+// 200 with the connection once Connected=true, 408 when ?timeout (default
+// 30s, at most 5m) runs out first. A client hanging up ends the wait.
+func BluetoothWaitHandler(wait func(ctx context.Context, address string) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		timeout := defaultBluetoothWait
+		if raw := r.URL.Query().Get("timeout"); raw != "" {
+			d, err := time.ParseDuration(raw)
+			if err != nil || d <= 0 || d > maxBluetoothWait {
+				writeError(w, fmt.Sprintf("invalid timeout %q (max %s)", raw, maxBluetoothWait), http.StatusBadRequest)
+				return
+			}
+			timeout = d
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		address := r.PathValue("address")
+		err := wait(ctx, address)
+		switch {
+		case err == nil:
+			writeJSON(w, http.StatusOK, bluetooth.DeviceConnection{Address: strings.ToUpper(address), Connected: true})
+		case r.Context().Err() != nil:
+			// the client went away or the server is shutting down (see
+			// drainMiddleware); the former never reads this
+			writeError(w, "wait interrupted", http.StatusServiceUnavailable)
+		case errors.Is(err, context.DeadlineExceeded):
+			writeError(w, "device did not connect within "+timeout.String(), http.StatusRequestTimeout)
+		default:
+			handleBluetoothError(w, err)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/b0bbywan/go-odio-api/backend"
+	"github.com/b0bbywan/go-odio-api/config"
+)
+
+// debugState is the single document GET /debug/state returns, meant to be
+// attached as-is to a bug report.
+type debugState struct {
+	Version    string         `json:"version"`
+	Uptime     string         `json:"uptime"`
+	Goroutines int            `json:"goroutines"`
+	Config     map[string]any `json:"config"`
+	State      backend.State  `json:"state"`
+}
+
+// DebugStateHandler serves the redacted settings, process info and the
+// cached state of every backend.
+func DebugStateHandler(settings map[string]any, started time.Time, state func() backend.State) http.HandlerFunc {
+	return JSONHandler(func(w http.ResponseWriter, r *http.Request) (any, error) {
+		return debugState{
+			Version:    config.AppVersion,
+			Uptime:     time.Since(started).Round(time.Second).String(),
+			Goroutines: runtime.NumGoroutine(),
+			Config:     settings,
+			State:      state(),
+		}, nil
+	})
+}
+
+// CacheImportHandler loads an exported State into the backend caches.
+func CacheImportHandler(importState func(backend.State)) http.HandlerFunc {
+	return withBody(nil, func(w http.ResponseWriter, r *http.Request, st *backend.State) {
+		importState(*st)
+		w.WriteHeader(http.StatusAccepted)
+	})
+}
+
+// zeroconfTxtRequest is the body of POST /zeroconf/txt.
+type zeroconfTxtRequest struct {
+	Records []string `json:"records"`
+}
+
+// ZeroconfTxtHandler replaces the TXT records zeroconf announces, to try
+// runtime metadata changes by hand. Malformed records are a 400.
+func ZeroconfTxtHandler(update func([]string) error) http.HandlerFunc {
+	return withBody(nil, func(w http.ResponseWriter, r *http.Request, req *zeroconfTxtRequest) {
+		if err := update(req.Records); err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// envelopeOptOutHeader lets a client ask for bare payloads whatever
+// api.envelope says, e.g. the built-in UI reading its own API ("off").
+const envelopeOptOutHeader = "X-Odio-Envelope"
+
+// envelope is the api.envelope shape of a successful JSON response.
+type envelope struct {
+	Data any          `json:"data"`
+	Meta envelopeMeta `json:"meta"`
+}
+
+// envelopeMeta repeats the cache and pagination headers in the body.
+type envelopeMeta struct {
+	CacheUpdatedAt string `json:"cache_updated_at,omitempty"`
+	Total          *int   `json:"total,omitempty"`
+}
+
+// envelopeWriter marks a response as enveloped for JSONHandler and
+// writeError. It passes Flush and Hijack through, so event streams and
+// WebSockets work unchanged.
+type envelopeWriter struct {
+	http.ResponseWriter
+}
+
+func (w *envelopeWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+func (w *envelopeWriter) Flush() {
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *envelopeWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// envelopeMiddleware turns on the api.envelope shape for every request but
+// those opting out with envelopeOptOutHeader.
+func envelopeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.EqualFold(r.Header.Get(envelopeOptOutHeader), "off") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(&envelopeWriter{w}, r)
+	})
+}
+
+func enveloped(w http.ResponseWriter) bool {
+	_, ok := w.(*envelopeWriter)
+	return ok
+}
+
+// wrapEnvelope builds {"data": ..., "meta": ...}, meta taken from the headers
+// the handler set.
+func wrapEnvelope(w http.ResponseWriter, data any) envelope {
+	meta := envelopeMeta{CacheUpdatedAt: w.Header().Get("X-Cache-Updated-At")}
+	if total, err := strconv.Atoi(w.Header().Get("X-Total-Count")); err == nil {
+		meta.Total = &total
+	}
+	return envelope{Data: data, Meta: meta}
+}
+
+// writeError is http.Error, answering {"error": {...}} instead of plain
+// text when the response is enveloped.
+func writeError(w http.ResponseWriter, msg string, code int) {
+	if !enveloped(w) {
+		http.Error(w, msg, code)
+		return
+	}
+	slug := strings.ReplaceAll(strings.ToLower(http.StatusText(code)), " ", "_")
+	writeJSONError(w, code, slug, strings.TrimSpace(msg))
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestEnvelopeMiddleware(t *testing.T) {
+	updated := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /items", listHandler(
+		func() ([]string, error) { return []string{"a", "b", "c"}, nil },
+		func() time.Time { return updated },
+	))
+	mux.HandleFunc("GET /fail", JSONHandler(func(w http.ResponseWriter, r *http.Request) (any, error) {
+		return nil, httpError(http.StatusNotFound, errors.New("player not found"))
+	}))
+	handler := envelopeMiddleware(mux)
+
+	t.Run("data and meta", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items?limit=2", nil))
+		var got struct {
+			Data []string     `json:"data"`
+			Meta envelopeMeta `json:"meta"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatalf("decode %s: %v", w.Body, err)
+		}
+		if len(got.Data) != 2 || got.Meta.Total == nil || *got.Meta.Total != 3 {
+			t.Errorf("body = %s, want 2 items of 3", w.Body)
+		}
+		if got.Meta.CacheUpdatedAt != "2026-01-02T03:04:05Z" {
+			t.Errorf("meta.cache_updated_at = %q", got.Meta.CacheUpdatedAt)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fail", nil))
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("status = %d, want 404", w.Code)
+		}
+		var got struct {
+			Error struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatalf("decode %s: %v", w.Body, err)
+		}
+		if got.Error.Code != "not_found" || got.Error.Message != "player not found" {
+			t.Errorf("error = %+v", got.Error)
+		}
+	})
+
+	t.Run("opt out", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/items", nil)
+		req.Header.Set(envelopeOptOutHeader, "off")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		var got []string
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || len(got) != 3 {
+			t.Errorf("body = %s, want the bare list", w.Body)
+		}
+	})
+
+	t.Run("streams still flush", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		envelopeMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := w.(http.Flusher); !ok {
+				t.Error("enveloped writer is not an http.Flusher")
+			}
+			w.(http.Flusher).Flush()
+		})).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events", nil))
+		if !w.Flushed {
+			t.Error("Flush did not reach the underlying writer")
+		}
+	})
+}
+
+func TestWriteJSONEnvelope(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusAccepted, map[string]string{"token": "abc"})
+	})
+
+	w := httptest.NewRecorder()
+	envelopeMiddleware(handler).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/power/reboot", nil))
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202", w.Code)
+	}
+	var wrapped struct {
+		Data map[string]string `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &wrapped); err != nil || wrapped.Data["token"] != "abc" {
+		t.Errorf("enveloped body = %s, want the payload under data", w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/power/reboot", nil))
+	var bare map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &bare); err != nil || bare["token"] != "abc" {
+		t.Errorf("bare body = %s, want the payload alone", w.Body)
+	}
+}
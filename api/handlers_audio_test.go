@@ -1,89 +1,153 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/b0bbywan/go-odio-api/backend/pulseaudio"
+	"github.com/b0bbywan/go-odio-api/config"
 )
 
-// TestAudioHandler tests the GET /audio combined endpoint logic.
-// Since AudioHandler depends on a concrete *PulseAudioBackend, we replicate
-// the handler logic with test data to verify JSON shape and cache header selection.
-func TestAudioHandler(t *testing.T) {
-	clients := []pulseaudio.AudioClient{
-		{ID: 1, Name: "Firefox", App: "firefox", Volume: 0.8},
-		{ID: 2, Name: "Spotify", App: "spotify", Volume: 0.5, Muted: true},
-	}
-	outputs := []pulseaudio.AudioOutput{
-		{Index: 0, Name: "speakers", Description: "Built-in Speakers", Volume: 1.0, Default: true, State: "running"},
-	}
+// fakeAudio is an in-memory AudioController: it serves clients and outputs
+// from slices and records actions instead of reaching the audio server.
+type fakeAudio struct {
+	clients        []pulseaudio.AudioClient
+	outputs        []pulseaudio.AudioOutput
+	clientsUpdated time.Time
+	outputsUpdated time.Time
+	err            error // returned by every action
+	calls          []string
+}
 
-	t.Run("returns clients and outputs as JSON", func(t *testing.T) {
-		handler := JSONHandler(func(w http.ResponseWriter, r *http.Request) (any, error) {
-			return map[string]any{
-				"clients": clients,
-				"outputs": outputs,
-			}, nil
-		})
+func (f *fakeAudio) action(call string) error {
+	f.calls = append(f.calls, call)
+	return f.err
+}
 
-		req := httptest.NewRequest("GET", "/audio", nil)
-		w := httptest.NewRecorder()
-		handler(w, req)
+func (f *fakeAudio) Kind() pulseaudio.AudioServerKind { return pulseaudio.ServerPipeWire }
 
-		if w.Code != http.StatusOK {
-			t.Fatalf("status = %d, want 200", w.Code)
-		}
-		if ct := w.Header().Get("Content-Type"); ct != "application/json" {
-			t.Fatalf("Content-Type = %s, want application/json", ct)
-		}
+func (f *fakeAudio) ServerInfo() (*pulseaudio.ServerInfo, error) {
+	return &pulseaudio.ServerInfo{Kind: pulseaudio.ServerPipeWire}, nil
+}
 
-		var result map[string]json.RawMessage
-		if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
-			t.Fatalf("failed to unmarshal: %v", err)
-		}
-		if _, ok := result["clients"]; !ok {
-			t.Error("response missing 'clients' key")
-		}
-		if _, ok := result["outputs"]; !ok {
-			t.Error("response missing 'outputs' key")
-		}
+func (f *fakeAudio) ListClients() ([]pulseaudio.AudioClient, error) { return f.clients, nil }
 
-		var gotClients []pulseaudio.AudioClient
-		if err := json.Unmarshal(result["clients"], &gotClients); err != nil {
-			t.Fatalf("failed to unmarshal clients: %v", err)
-		}
-		if len(gotClients) != 2 {
-			t.Errorf("clients count = %d, want 2", len(gotClients))
+func (f *fakeAudio) FilterClients(filter pulseaudio.AudioClientFilter) ([]pulseaudio.AudioClient, error) {
+	out := []pulseaudio.AudioClient{}
+	for _, c := range f.clients {
+		if (filter.Corked == nil || c.Corked == *filter.Corked) && (filter.Muted == nil || c.Muted == *filter.Muted) {
+			out = append(out, c)
 		}
+	}
+	return out, nil
+}
 
-		var gotOutputs []pulseaudio.AudioOutput
-		if err := json.Unmarshal(result["outputs"], &gotOutputs); err != nil {
-			t.Fatalf("failed to unmarshal outputs: %v", err)
-		}
-		if len(gotOutputs) != 1 {
-			t.Errorf("outputs count = %d, want 1", len(gotOutputs))
+func (f *fakeAudio) GetClient(name string) (*pulseaudio.AudioClient, bool) {
+	for _, c := range f.clients {
+		if c.Name == name {
+			return &c, true
 		}
-	})
+	}
+	return nil, false
+}
 
-	t.Run("returns error on failure", func(t *testing.T) {
-		handler := JSONHandler(func(w http.ResponseWriter, r *http.Request) (any, error) {
-			return nil, http.ErrServerClosed
-		})
+func (f *fakeAudio) RefreshClient(name string) (*pulseaudio.AudioClient, error) {
+	if c, ok := f.GetClient(name); ok {
+		return c, nil
+	}
+	return nil, &pulseaudio.NotFoundError{Resource: "client", Name: name}
+}
 
-		req := httptest.NewRequest("GET", "/audio", nil)
-		w := httptest.NewRecorder()
-		handler(w, req)
+func (f *fakeAudio) CacheUpdatedAt() time.Time                      { return f.clientsUpdated }
+func (f *fakeAudio) ListOutputs() ([]pulseaudio.AudioOutput, error) { return f.outputs, nil }
+func (f *fakeAudio) OutputCacheUpdatedAt() time.Time                { return f.outputsUpdated }
+func (f *fakeAudio) Cookie() ([]byte, error)                        { return []byte("cookie"), f.err }
 
-		if w.Code != http.StatusInternalServerError {
-			t.Errorf("status = %d, want 500", w.Code)
-		}
-	})
+func (f *fakeAudio) ToggleMuteMaster() error { return f.action("mute master") }
+
+func (f *fakeAudio) SetVolumeMaster(volume float32) error {
+	return f.action(fmt.Sprintf("volume master %v", volume))
+}
+
+func (f *fakeAudio) ToggleMute(name string) error { return f.action("mute " + name) }
+
+func (f *fakeAudio) SetVolume(name string, vol float32) error {
+	return f.action(fmt.Sprintf("volume %s %v", name, vol))
+}
+
+func (f *fakeAudio) NormalizeClientVolume(name string, target float32) error {
+	return f.action(fmt.Sprintf("normalize %s %v", name, target))
+}
+
+func (f *fakeAudio) SetDefaultOutput(name string) error { return f.action("default " + name) }
+func (f *fakeAudio) ToggleMuteOutput(name string) error { return f.action("mute output " + name) }
+
+func (f *fakeAudio) SetVolumeOutput(name string, vol float32) error {
+	return f.action(fmt.Sprintf("volume output %s %v", name, vol))
+}
+
+func (f *fakeAudio) CreateLoopback(source, sink string) (uint32, error) {
+	return 42, f.action(fmt.Sprintf("loopback %s %s", source, sink))
+}
+
+func (f *fakeAudio) RemoveLoopback(moduleIndex uint32) error {
+	return f.action(fmt.Sprintf("unload %d", moduleIndex))
+}
+
+// TestAudioHandler tests the GET /audio combined endpoint.
+func TestAudioHandler(t *testing.T) {
+	fake := &fakeAudio{
+		clients: []pulseaudio.AudioClient{
+			{ID: 1, Name: "Firefox", App: "firefox", Volume: 0.8},
+			{ID: 2, Name: "Spotify", App: "spotify", Volume: 0.5, Muted: true},
+		},
+		outputs: []pulseaudio.AudioOutput{
+			{Index: 0, Name: "speakers", Description: "Built-in Speakers", Volume: 1.0, Default: true, State: "running"},
+		},
+	}
+
+	req := httptest.NewRequest("GET", "/audio", nil)
+	w := httptest.NewRecorder()
+	AudioHandler(fake)(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("Content-Type = %s, want application/json", ct)
+	}
+
+	var result map[string]json.RawMessage
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if string(result["kind"]) != `"pipewire"` {
+		t.Errorf("kind = %s, want \"pipewire\"", result["kind"])
+	}
+
+	var gotClients []pulseaudio.AudioClient
+	if err := json.Unmarshal(result["clients"], &gotClients); err != nil {
+		t.Fatalf("failed to unmarshal clients: %v", err)
+	}
+	if len(gotClients) != 2 {
+		t.Errorf("clients count = %d, want 2", len(gotClients))
+	}
+
+	var gotOutputs []pulseaudio.AudioOutput
+	if err := json.Unmarshal(result["outputs"], &gotOutputs); err != nil {
+		t.Fatalf("failed to unmarshal outputs: %v", err)
+	}
+	if len(gotOutputs) != 1 {
+		t.Errorf("outputs count = %d, want 1", len(gotOutputs))
+	}
 }
 
 // TestAudioHandlerCacheHeader tests that the cache header uses the most recent timestamp.
@@ -119,18 +183,11 @@ func TestAudioHandlerCacheHeader(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			handler := JSONHandler(func(w http.ResponseWriter, r *http.Request) (any, error) {
-				if tt.outputsUpdated.After(tt.clientsUpdated) {
-					setCacheHeader(w, tt.outputsUpdated)
-				} else {
-					setCacheHeader(w, tt.clientsUpdated)
-				}
-				return map[string]any{"clients": []any{}, "outputs": []any{}}, nil
-			})
+			fake := &fakeAudio{clientsUpdated: tt.clientsUpdated, outputsUpdated: tt.outputsUpdated}
 
 			req := httptest.NewRequest("GET", "/audio", nil)
 			w := httptest.NewRecorder()
-			handler(w, req)
+			AudioHandler(fake)(w, req)
 
 			got := w.Header().Get("X-Cache-Updated-At")
 			if got != tt.wantHeader {
@@ -140,6 +197,122 @@ func TestAudioHandlerCacheHeader(t *testing.T) {
 	}
 }
 
+// TestPulseRoutes drives the registered /audio routes end to end against an
+// in-memory backend: path values, body validation and error mapping.
+func TestPulseRoutes(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		body      string
+		err       error
+		wantCode  int
+		wantBody  string
+		wantCalls []string
+	}{
+		{
+			name: "list clients filtered", method: http.MethodGet, path: "/audio/clients?muted=true",
+			wantCode: http.StatusOK, wantBody: `"name":"Spotify"`,
+		},
+		{
+			name: "single client", method: http.MethodGet, path: "/audio/clients/Spotify",
+			wantCode: http.StatusOK, wantBody: `"name":"Spotify"`,
+		},
+		{
+			name: "client volume", method: http.MethodPost, path: "/audio/clients/Spotify/volume", body: `{"volume":0.25}`,
+			wantCode: http.StatusAccepted, wantCalls: []string{"volume Spotify 0.25"},
+		},
+		{
+			name: "invalid volume never reaches the backend", method: http.MethodPost, path: "/audio/server/volume", body: `{"volume":2}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "output mute", method: http.MethodPost, path: "/audio/outputs/speakers/mute",
+			wantCode: http.StatusAccepted, wantCalls: []string{"mute output speakers"},
+		},
+		{
+			name: "backend unavailable", method: http.MethodPost, path: "/audio/server/mute",
+			err:      &pulseaudio.BackendUnavailableError{},
+			wantCode: http.StatusServiceUnavailable, wantCalls: []string{"mute master"},
+		},
+		{
+			name: "loopback created", method: http.MethodPost, path: "/audio/loopbacks", body: `{"source":"mic","sink":"speakers"}`,
+			wantCode: http.StatusCreated, wantBody: `{"module_index":42}`, wantCalls: []string{"loopback mic speakers"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeAudio{
+				clients: []pulseaudio.AudioClient{{Name: "Spotify", Muted: true}, {Name: "Firefox"}},
+				err:     tt.err,
+			}
+			s := &Server{mux: http.NewServeMux()}
+			s.registerPulseRoutes(fake)
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			s.mux.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d (body %q)", w.Code, tt.wantCode, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("body = %q, want to contain %q", w.Body.String(), tt.wantBody)
+			}
+			if !slices.Equal(fake.calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", fake.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// TestAudioStateHandler verifies /audio/state combines the server info and the
+// clients in one body, and fails as a whole when the server info does.
+func TestAudioStateHandler(t *testing.T) {
+	serverInfo := func() (*pulseaudio.ServerInfo, error) {
+		return &pulseaudio.ServerInfo{Kind: "pipewire", DefaultSink: "speakers", Volume: 0.7}, nil
+	}
+	listClients := func() ([]pulseaudio.AudioClient, error) {
+		return []pulseaudio.AudioClient{{ID: 1, Name: "Firefox", App: "firefox"}}, nil
+	}
+
+	t.Run("combines server and clients", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		AudioStateHandler(serverInfo, listClients)(w, httptest.NewRequest("GET", "/audio/state", nil))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", w.Code)
+		}
+		var got struct {
+			Server  pulseaudio.ServerInfo    `json:"server"`
+			Clients []pulseaudio.AudioClient `json:"clients"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatalf("failed to unmarshal: %v", err)
+		}
+		if got.Server.DefaultSink != "speakers" {
+			t.Errorf("server.default_sink = %q, want speakers", got.Server.DefaultSink)
+		}
+		if len(got.Clients) != 1 || got.Clients[0].Name != "Firefox" {
+			t.Errorf("clients = %+v, want [Firefox]", got.Clients)
+		}
+	})
+
+	t.Run("server error fails the request", func(t *testing.T) {
+		failing := func() (*pulseaudio.ServerInfo, error) {
+			return nil, &pulseaudio.NotReadyError{Message: "output cache not ready"}
+		}
+		w := httptest.NewRecorder()
+		AudioStateHandler(failing, listClients)(w, httptest.NewRequest("GET", "/audio/state", nil))
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("status = %d, want 500", w.Code)
+		}
+	})
+}
+
 // TestHandleAudioError tests the centralized audio error handler.
 func TestHandleAudioError(t *testing.T) {
 	tests := []struct {
@@ -171,6 +344,12 @@ func TestHandleAudioError(t *testing.T) {
 			wantCode: http.StatusServiceUnavailable,
 			wantBody: "output cache not ready",
 		},
+		{
+			name:     "BackendUnavailableError returns 503 Service Unavailable",
+			err:      &pulseaudio.BackendUnavailableError{},
+			wantCode: http.StatusServiceUnavailable,
+			wantBody: "audio server unavailable",
+		},
 		{
 			name:     "generic error returns 500 Internal Server Error",
 			err:      errors.New("pulse connection lost"),
@@ -326,3 +505,216 @@ func TestWithSink(t *testing.T) {
 		})
 	}
 }
+
+func TestLoopbackHandlers(t *testing.T) {
+	create := func(source, sink string) (uint32, error) {
+		if source == "bluez_source.gone" {
+			return 0, &pulseaudio.NotFoundError{Resource: "source", Name: source}
+		}
+		return 42, nil
+	}
+	var removed uint32
+	remove := func(index uint32) error {
+		removed = index
+		return nil
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /audio/loopbacks", CreateLoopbackHandler(create))
+	mux.HandleFunc("DELETE /audio/loopbacks/{module_index}", RemoveLoopbackHandler(remove))
+
+	tests := []struct {
+		method, path, body string
+		want               int
+	}{
+		{http.MethodPost, "/audio/loopbacks", `{"source":"bluez_source.AA_BB.a2dp_source","sink":"alsa_output.default"}`, http.StatusCreated},
+		{http.MethodPost, "/audio/loopbacks", `{"source":"bluez_source.gone","sink":"alsa_output.default"}`, http.StatusNotFound},
+		{http.MethodPost, "/audio/loopbacks", `{"source":"bluez_source.AA_BB.a2dp_source"}`, http.StatusBadRequest},
+		{http.MethodPost, "/audio/loopbacks", `{"source":"a sink=other","sink":"alsa_output.default"}`, http.StatusBadRequest},
+		{http.MethodDelete, "/audio/loopbacks/42", "", http.StatusAccepted},
+		{http.MethodDelete, "/audio/loopbacks/-1", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s %s %s: status = %d, want %d", tt.method, tt.path, tt.body, w.Code, tt.want)
+		}
+		if w.Code == http.StatusCreated && strings.TrimSpace(w.Body.String()) != `{"module_index":42}` {
+			t.Errorf("create body = %s", w.Body.String())
+		}
+	}
+	if removed != 42 {
+		t.Errorf("removed module = %d, want 42", removed)
+	}
+}
+
+func TestNormalizeClientHandlerValidatesTarget(t *testing.T) {
+	for _, body := range []string{`{"target":0}`, `{"target":1.2}`, `{}`} {
+		req := httptest.NewRequest(http.MethodPost, "/audio/clients/spotify/normalize", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.SetPathValue("sink", "spotify")
+		w := httptest.NewRecorder()
+		NormalizeClientHandler(nil)(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("body %s: status = %d, want %d", body, w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestListClientsHandlerFilters(t *testing.T) {
+	pa, err := pulseaudio.New(context.Background(), &config.PulseAudioConfig{Enabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pa.ImportCache([]pulseaudio.AudioClient{
+		{Name: "Spotify", Corked: true},
+		{Name: "Firefox", Muted: true},
+	}, nil)
+
+	tests := []struct {
+		query     string
+		wantCode  int
+		wantNames []string
+	}{
+		{"", http.StatusOK, []string{"Spotify", "Firefox"}},
+		{"?corked=true", http.StatusOK, []string{"Spotify"}},
+		{"?muted=true", http.StatusOK, []string{"Firefox"}},
+		{"?corked=false&muted=false", http.StatusOK, []string{}},
+		{"?corked=maybe", http.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		ListClientsHandler(pa)(w, httptest.NewRequest(http.MethodGet, "/audio/clients"+tt.query, nil))
+		if w.Code != tt.wantCode {
+			t.Errorf("%s: status = %d, want %d", tt.query, w.Code, tt.wantCode)
+			continue
+		}
+		if tt.wantCode != http.StatusOK {
+			continue
+		}
+		var got []pulseaudio.AudioClient
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatalf("%s: invalid JSON: %v", tt.query, err)
+		}
+		names := []string{}
+		for _, c := range got {
+			names = append(names, c.Name)
+		}
+		if !slices.Equal(names, tt.wantNames) {
+			t.Errorf("%s: clients = %v, want %v", tt.query, names, tt.wantNames)
+		}
+	}
+}
+
+func TestClientHandler(t *testing.T) {
+	get := func(name string) (*pulseaudio.AudioClient, bool) {
+		if name == "Spotify" {
+			return &pulseaudio.AudioClient{Name: name, Volume: 0.4}, true
+		}
+		return nil, false
+	}
+	refreshed := ""
+	refresh := func(name string) (*pulseaudio.AudioClient, error) {
+		refreshed = name
+		if name != "Spotify" {
+			return nil, &pulseaudio.NotFoundError{Resource: "client", Name: name}
+		}
+		return &pulseaudio.AudioClient{Name: name, Volume: 0.7}, nil
+	}
+	handler := ClientHandler(get, refresh)
+
+	tests := []struct {
+		name          string
+		client        string
+		query         string
+		wantCode      int
+		wantVolume    float32
+		wantRefreshed string
+	}{
+		{"from cache", "Spotify", "", http.StatusOK, 0.4, ""},
+		{"refreshed", "Spotify", "?refresh=true", http.StatusOK, 0.7, "Spotify"},
+		{"missing from cache", "VLC", "", http.StatusNotFound, 0, ""},
+		{"missing on refresh", "VLC", "?refresh=true", http.StatusNotFound, 0, "VLC"},
+		{"bad refresh value", "Spotify", "?refresh=soon", http.StatusBadRequest, 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refreshed = ""
+			req := httptest.NewRequest(http.MethodGet, "/audio/clients/"+tt.client+tt.query, nil)
+			req.SetPathValue("sink", tt.client)
+			w := httptest.NewRecorder()
+			handler(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantCode)
+			}
+			if refreshed != tt.wantRefreshed {
+				t.Errorf("refreshed %q, want %q", refreshed, tt.wantRefreshed)
+			}
+			if tt.wantCode == http.StatusNotFound {
+				assertJSONNotFound(t, w, `{"error":"client not found: VLC"}`)
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			var got pulseaudio.AudioClient
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+			if got.Volume != tt.wantVolume {
+				t.Errorf("volume = %v, want %v", got.Volume, tt.wantVolume)
+			}
+		})
+	}
+}
+
+func TestClientVolumeHandler(t *testing.T) {
+	get := func(name string) (*pulseaudio.AudioClient, bool) {
+		if name == "Spotify" {
+			return &pulseaudio.AudioClient{Name: name, Volume: 0.8, Muted: true}, true
+		}
+		return nil, false
+	}
+	handler := ClientVolumeHandler(get)
+
+	tests := []struct {
+		name     string
+		client   string
+		wantCode int
+		wantBody string
+	}{
+		{"cached client", "Spotify", http.StatusOK, `{"volume":0.8,"muted":true}`},
+		{"missing client", "VLC", http.StatusNotFound, `{"error":"client not found: VLC"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/audio/clients/"+tt.client+"/volume", nil)
+			req.SetPathValue("sink", tt.client)
+			w := httptest.NewRecorder()
+			handler(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantCode)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", ct)
+			}
+			if body := strings.TrimSpace(w.Body.String()); body != tt.wantBody {
+				t.Errorf("body = %s, want %s", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+// assertJSONNotFound checks a 404 answers the exact JSON body, not text/plain.
+func assertJSONNotFound(t *testing.T, w *httptest.ResponseRecorder, want string) {
+	t.Helper()
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	if body := strings.TrimSpace(w.Body.String()); body != want {
+		t.Errorf("body = %s, want %s", body, want)
+	}
+}
@@ -0,0 +1,241 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/b0bbywan/go-odio-api/backend/bluetooth"
+)
+
+func TestWithBluetoothProfile(t *testing.T) {
+	var gotAddress, gotProfile string
+	action := func(address, profile string) error {
+		gotAddress, gotProfile = address, profile
+		if profile == "stereo" {
+			return fmt.Errorf("%w: %q", bluetooth.ErrInvalidProfile, profile)
+		}
+		return nil
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /bluetooth/devices/{address}/profile", withBluetoothProfile(action))
+
+	tests := []struct {
+		body string
+		want int
+	}{
+		{`{"profile":"a2dp_sink"}`, http.StatusAccepted},
+		{`{"profile":"stereo"}`, http.StatusBadRequest},
+		{`not json`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/bluetooth/devices/40:C1:F6:D4:67:88/profile", strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("body %s: status = %d, want %d", tt.body, w.Code, tt.want)
+		}
+	}
+	if gotAddress != "40:C1:F6:D4:67:88" || gotProfile != "stereo" {
+		t.Errorf("action got (%q, %q)", gotAddress, gotProfile)
+	}
+}
+
+func TestWithBluetoothRename(t *testing.T) {
+	var gotAddress, gotAlias string
+	action := func(address, alias string) error {
+		gotAddress, gotAlias = address, alias
+		if len(alias) > 248 {
+			return bluetooth.ErrInvalidAlias
+		}
+		return nil
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("PATCH /bluetooth/devices/{address}", withBluetoothRename(action))
+
+	tests := []struct {
+		body string
+		want int
+	}{
+		{`{"alias":"Living Room Speaker"}`, http.StatusAccepted},
+		{`{"alias":"` + strings.Repeat("a", 249) + `"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPatch, "/bluetooth/devices/40:C1:F6:D4:67:88", strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("alias of %d bytes: status = %d, want %d", len(tt.body), w.Code, tt.want)
+		}
+	}
+	if gotAddress != "40:C1:F6:D4:67:88" {
+		t.Errorf("action address = %q", gotAddress)
+	}
+	if len(gotAlias) != 249 {
+		t.Errorf("action alias length = %d, want 249", len(gotAlias))
+	}
+}
+
+func TestWithBluetoothDiscoverable(t *testing.T) {
+	var got time.Duration
+	action := func(d time.Duration) error {
+		got = d
+		if d <= 0 {
+			return bluetooth.ErrInvalidDuration
+		}
+		return nil
+	}
+	handler := withBluetoothDiscoverable(action)
+
+	tests := []struct {
+		body string
+		want int
+	}{
+		{`{"duration":"60s"}`, http.StatusAccepted},
+		{`{"duration":"0s"}`, http.StatusBadRequest},
+		{`{"duration":"soon"}`, http.StatusBadRequest},
+		{`{}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/bluetooth/discoverable", strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		handler(w, req)
+		if w.Code != tt.want {
+			t.Errorf("body %s: status = %d, want %d", tt.body, w.Code, tt.want)
+		}
+	}
+	if got != 0 {
+		t.Errorf("last duration passed = %v, want 0", got)
+	}
+}
+
+func TestPairingHandler(t *testing.T) {
+	until := time.Now().Add(45 * time.Second)
+	calls := 0
+	pair := func() error {
+		calls++
+		return nil
+	}
+	status := func() bluetooth.BluetoothStatus {
+		return bluetooth.BluetoothStatus{PairingActive: true, PairingUntil: &until}
+	}
+	handler := PairingHandler(pair, status)
+
+	for range 2 {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodPost, "/bluetooth/pairing", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", w.Code)
+		}
+		var resp pairingResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if !resp.PairingUntil.Equal(until) {
+			t.Errorf("pairing_until = %v, want %v", resp.PairingUntil, until)
+		}
+		if resp.SecondsLeft < 40 || resp.SecondsLeft > 45 {
+			t.Errorf("seconds_left = %d, want ~45", resp.SecondsLeft)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("pair called %d times, want 2", calls)
+	}
+
+	t.Run("error maps like other bluetooth actions", func(t *testing.T) {
+		h := PairingHandler(func() error { return fmt.Errorf("bluez down") }, status)
+		w := httptest.NewRecorder()
+		h(w, httptest.NewRequest(http.MethodPost, "/bluetooth/pairing", nil))
+		if w.Code != http.StatusBadGateway {
+			t.Errorf("status = %d, want 502", w.Code)
+		}
+	})
+}
+
+func TestBluetoothWaitHandler(t *testing.T) {
+	wait := func(ctx context.Context, address string) error {
+		switch address {
+		case "40:c1:f6:d4:67:88":
+			return nil
+		case "nope":
+			return bluetooth.ErrInvalidAddress
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /bluetooth/devices/{address}/wait", BluetoothWaitHandler(wait))
+
+	tests := []struct {
+		url  string
+		want int
+	}{
+		{"/bluetooth/devices/40:c1:f6:d4:67:88/wait", http.StatusOK},
+		{"/bluetooth/devices/AA:BB:CC:DD:EE:FF/wait?timeout=10ms", http.StatusRequestTimeout},
+		{"/bluetooth/devices/nope/wait", http.StatusBadRequest},
+		{"/bluetooth/devices/AA:BB:CC:DD:EE:FF/wait?timeout=soon", http.StatusBadRequest},
+		{"/bluetooth/devices/AA:BB:CC:DD:EE:FF/wait?timeout=1h", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+		if w.Code != tt.want {
+			t.Errorf("GET %s: status = %d, want %d", tt.url, w.Code, tt.want)
+		}
+		if w.Code == http.StatusOK {
+			var got bluetooth.DeviceConnection
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			want := bluetooth.DeviceConnection{Address: "40:C1:F6:D4:67:88", Connected: true}
+			if got != want {
+				t.Errorf("body = %+v, want %+v", got, want)
+			}
+		}
+	}
+}
+
+func TestWithBluetoothConfirm(t *testing.T) {
+	var got []bool
+	pending := true
+	confirm := func(accept bool) error {
+		if !pending {
+			return bluetooth.ErrNoPendingConfirmation
+		}
+		got = append(got, accept)
+		return nil
+	}
+	handler := withBluetoothConfirm(confirm)
+
+	tests := []struct {
+		body    string
+		pending bool
+		want    int
+	}{
+		{`{"accept":true}`, true, http.StatusAccepted},
+		{`{"accept":false}`, true, http.StatusAccepted},
+		{`{}`, true, http.StatusBadRequest},
+		{`{"accept":true}`, false, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		pending = tt.pending
+		req := httptest.NewRequest(http.MethodPost, "/bluetooth/pairing/confirm", strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		handler(w, req)
+		if w.Code != tt.want {
+			t.Errorf("body %s (pending=%v): status = %d, want %d", tt.body, tt.pending, w.Code, tt.want)
+		}
+	}
+	if len(got) != 2 || !got[0] || got[1] {
+		t.Errorf("confirm calls = %v, want [true false]", got)
+	}
+}
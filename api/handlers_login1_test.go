@@ -272,3 +272,87 @@ func TestPowerOffHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestWithLogin1Request(t *testing.T) {
+	tests := []struct {
+		name           string
+		fn             func() (*login1.PendingAction, error)
+		wantStatusCode int
+		wantToken      string
+	}{
+		{
+			name:           "immediate action returns 202 without body",
+			fn:             func() (*login1.PendingAction, error) { return nil, nil },
+			wantStatusCode: http.StatusAccepted,
+		},
+		{
+			name: "scheduled action returns 202 with its token",
+			fn: func() (*login1.PendingAction, error) {
+				return &login1.PendingAction{Action: "reboot", Token: "abc"}, nil
+			},
+			wantStatusCode: http.StatusAccepted,
+			wantToken:      "abc",
+		},
+		{
+			name:           "already pending returns 409",
+			fn:             func() (*login1.PendingAction, error) { return nil, login1.ErrActionPending },
+			wantStatusCode: http.StatusConflict,
+		},
+		{
+			name: "capability disabled returns 403",
+			fn: func() (*login1.PendingAction, error) {
+				return nil, &login1.CapabilityError{Required: "reboot capability disabled"}
+			},
+			wantStatusCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			withLogin1Request(tt.fn)(w, httptest.NewRequest("POST", "/power/reboot", nil))
+
+			if w.Code != tt.wantStatusCode {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatusCode)
+			}
+			if tt.wantToken != "" {
+				var got login1.PendingAction
+				if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if got.Token != tt.wantToken {
+					t.Errorf("token = %q, want %q", got.Token, tt.wantToken)
+				}
+			}
+		})
+	}
+}
+
+func TestCancelPendingHandler(t *testing.T) {
+	cancel := func(token string) error {
+		if token != "abc" {
+			return login1.ErrNoPendingAction
+		}
+		return nil
+	}
+	tests := []struct {
+		name           string
+		query          string
+		wantStatusCode int
+	}{
+		{name: "missing token returns 400", query: "", wantStatusCode: http.StatusBadRequest},
+		{name: "unknown token returns 404", query: "?token=nope", wantStatusCode: http.StatusNotFound},
+		{name: "valid token returns 202", query: "?token=abc", wantStatusCode: http.StatusAccepted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			CancelPendingHandler(cancel)(w, httptest.NewRequest("DELETE", "/power/pending"+tt.query, nil))
+
+			if w.Code != tt.wantStatusCode {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatusCode)
+			}
+		})
+	}
+}
@@ -1,16 +1,26 @@
 package api
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
 
 	"github.com/b0bbywan/go-odio-api/backend/mpris"
 )
 
+func noArtwork(string) ([]byte, bool) { return nil, false }
+
 func TestCoverHandler(t *testing.T) {
 	// Create a temp file to serve as local cover art
 	tmpDir := t.TempDir()
@@ -102,7 +112,7 @@ func TestCoverHandler(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			handler := CoverHandler(tt.getPlayer)
+			handler := CoverHandler(tt.getPlayer, noArtwork)
 
 			req := httptest.NewRequest("GET", "/players/"+tt.busName+"/cover", nil)
 			req.SetPathValue("player", tt.busName)
@@ -131,6 +141,38 @@ func TestCoverHandler(t *testing.T) {
 	}
 }
 
+// TestCoverHandlerServesPrefetchedArtwork: prefetched bytes are served
+// without touching the file, which may already be gone.
+func TestCoverHandlerServesPrefetchedArtwork(t *testing.T) {
+	artURL := "file:///nonexistent/cover.png"
+	handler := CoverHandler(
+		func(string) (*mpris.Player, error) {
+			return &mpris.Player{Metadata: map[string]string{"mpris:artUrl": artURL}}, nil
+		},
+		func(url string) ([]byte, bool) {
+			if url != artURL {
+				return nil, false
+			}
+			return []byte("cached-image-data"), true
+		},
+	)
+
+	req := httptest.NewRequest("GET", "/players/org.mpris.MediaPlayer2.mpd/cover", nil)
+	req.SetPathValue("player", "org.mpris.MediaPlayer2.mpd")
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if got := w.Body.String(); got != "cached-image-data" {
+		t.Errorf("body = %q, want cached bytes", got)
+	}
+	if got := w.Header().Get("Content-Type"); got != "image/png" {
+		t.Errorf("Content-Type = %q, want image/png", got)
+	}
+}
+
 // TestHandleMPRISError tests the MPRIS error mapping function
 func TestHandleMPRISError(t *testing.T) {
 	tests := []struct {
@@ -162,6 +204,24 @@ func TestHandleMPRISError(t *testing.T) {
 			wantStatusCode: http.StatusBadRequest,
 			wantBodyMatch:  "volume: must be between 0 and 1",
 		},
+		{
+			name: "joined ValidationErrors return 400 with every field",
+			err: errors.Join(
+				&mpris.ValidationError{Field: "volume", Message: "must be between 0 and 1"},
+				&mpris.ValidationError{Field: "loop", Message: "must be None, Track, or Playlist"},
+			),
+			wantStatusCode: http.StatusBadRequest,
+			wantBodyMatch:  "volume: must be between 0 and 1\nloop: must be None",
+		},
+		{
+			name: "AmbiguousPlayerError returns 409 Conflict",
+			err: &mpris.AmbiguousPlayerError{
+				Name:       "vlc",
+				Candidates: []string{"org.mpris.MediaPlayer2.vlc.instance1", "org.mpris.MediaPlayer2.vlc.instance2"},
+			},
+			wantStatusCode: http.StatusConflict,
+			wantBodyMatch:  "org.mpris.MediaPlayer2.vlc.instance2",
+		},
 		{
 			name: "PlayerNotFoundError returns 404 Not Found",
 			err: &mpris.PlayerNotFoundError{
@@ -277,6 +337,210 @@ func TestTracklistHandler(t *testing.T) {
 	}
 }
 
+func TestPositionHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		getPosition    func(string) (*mpris.PositionInfo, error)
+		wantStatusCode int
+		wantBodyMatch  string
+	}{
+		{
+			name: "success returns 200 with position, length, rate and status",
+			getPosition: func(string) (*mpris.PositionInfo, error) {
+				return &mpris.PositionInfo{Position: 42_000_000, Length: 180_000_000, Rate: 1, Status: mpris.StatusPlaying}, nil
+			},
+			wantStatusCode: http.StatusOK,
+			wantBodyMatch:  `{"position":42000000,"length":180000000,"rate":1,"status":"Playing"}`,
+		},
+		{
+			name: "player not found returns 404",
+			getPosition: func(busName string) (*mpris.PositionInfo, error) {
+				return nil, &mpris.PlayerNotFoundError{BusName: busName}
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantBodyMatch:  "player not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := PositionHandler(tt.getPosition)
+
+			req := httptest.NewRequest("GET", "/players/org.mpris.MediaPlayer2.mpd/position", nil)
+			req.SetPathValue("player", "org.mpris.MediaPlayer2.mpd")
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			if w.Code != tt.wantStatusCode {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatusCode)
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBodyMatch) {
+				t.Errorf("body = %q, want to contain %q", w.Body.String(), tt.wantBodyMatch)
+			}
+		})
+	}
+}
+
+func TestPlayerViewHandlers(t *testing.T) {
+	vol := 0.5
+	player := &mpris.Player{
+		BusName:        "org.mpris.MediaPlayer2.mpd",
+		PlaybackStatus: mpris.StatusPlaying,
+		Metadata:       map[string]string{"xesam:title": "Song"},
+		Capabilities:   mpris.Capabilities{CanPlay: true, CanPause: true},
+	}
+	idle := &mpris.Player{BusName: "org.mpris.MediaPlayer2.idle"}
+	getPlayer := func(busName string) (*mpris.Player, error) {
+		switch busName {
+		case player.BusName:
+			return player, nil
+		case idle.BusName:
+			return idle, nil
+		}
+		return nil, &mpris.PlayerNotFoundError{BusName: busName}
+	}
+	getStatus := func(busName string) (*mpris.PlayerStatus, error) {
+		if _, err := getPlayer(busName); err != nil {
+			return nil, err
+		}
+		return &mpris.PlayerStatus{PlaybackStatus: mpris.StatusPlaying, Volume: &vol, Position: 42, LoopStatus: "None"}, nil
+	}
+
+	tests := []struct {
+		name           string
+		handler        http.HandlerFunc
+		busName        string
+		wantStatusCode int
+		wantBodyMatch  string
+	}{
+		{
+			name:           "capabilities only",
+			handler:        PlayerCapabilitiesHandler(getPlayer),
+			busName:        "org.mpris.MediaPlayer2.mpd",
+			wantStatusCode: http.StatusOK,
+			wantBodyMatch:  `{"can_play":true,"can_pause":true,"can_go_next":false,`,
+		},
+		{
+			name:           "metadata only",
+			handler:        PlayerMetadataHandler(getPlayer),
+			busName:        "org.mpris.MediaPlayer2.mpd",
+			wantStatusCode: http.StatusOK,
+			wantBodyMatch:  `{"xesam:title":"Song"}`,
+		},
+		{
+			name:           "metadata keys",
+			handler:        PlayerMetadataKeysHandler(getPlayer),
+			busName:        "org.mpris.MediaPlayer2.mpd",
+			wantStatusCode: http.StatusOK,
+			wantBodyMatch:  `["xesam:title"]`,
+		},
+		{
+			name:           "metadata keys of an idle player is empty",
+			handler:        PlayerMetadataKeysHandler(getPlayer),
+			busName:        "org.mpris.MediaPlayer2.idle",
+			wantStatusCode: http.StatusOK,
+			wantBodyMatch:  `[]`,
+		},
+		{
+			name:           "status only",
+			handler:        PlayerStatusHandler(getStatus),
+			busName:        "org.mpris.MediaPlayer2.mpd",
+			wantStatusCode: http.StatusOK,
+			wantBodyMatch:  `{"playback_status":"Playing","volume":0.5,"position":42,"loop_status":"None"}`,
+		},
+		{
+			name:           "capabilities of an unknown player returns 404",
+			handler:        PlayerCapabilitiesHandler(getPlayer),
+			busName:        "org.mpris.MediaPlayer2.vlc",
+			wantStatusCode: http.StatusNotFound,
+			wantBodyMatch:  "player not found",
+		},
+		{
+			name:           "status of an unknown player returns 404",
+			handler:        PlayerStatusHandler(getStatus),
+			busName:        "org.mpris.MediaPlayer2.vlc",
+			wantStatusCode: http.StatusNotFound,
+			wantBodyMatch:  "player not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/players/"+tt.busName+"/status", nil)
+			req.SetPathValue("player", tt.busName)
+			w := httptest.NewRecorder()
+
+			tt.handler(w, req)
+
+			if w.Code != tt.wantStatusCode {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatusCode)
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBodyMatch) {
+				t.Errorf("body = %q, want to contain %q", w.Body.String(), tt.wantBodyMatch)
+			}
+		})
+	}
+}
+
+func TestRefreshPlayerHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		refresh        func(string) (*mpris.Player, error)
+		wantStatusCode int
+		wantBodyMatch  string
+		wantRetryAfter string
+	}{
+		{
+			name: "success returns 200 with the fresh player",
+			refresh: func(busName string) (*mpris.Player, error) {
+				return &mpris.Player{BusName: busName}, nil
+			},
+			wantStatusCode: http.StatusOK,
+			wantBodyMatch:  `"bus_name":"org.mpris.MediaPlayer2.mpd"`,
+		},
+		{
+			name: "player not on D-Bus returns 404",
+			refresh: func(busName string) (*mpris.Player, error) {
+				return nil, &mpris.PlayerNotFoundError{BusName: busName}
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantBodyMatch:  "player not found",
+		},
+		{
+			name: "refreshed too recently returns 429 with Retry-After",
+			refresh: func(busName string) (*mpris.Player, error) {
+				return nil, &mpris.RefreshRateLimitedError{BusName: busName, RetryAfter: 2300 * time.Millisecond}
+			},
+			wantStatusCode: http.StatusTooManyRequests,
+			wantBodyMatch:  "refreshed too recently",
+			wantRetryAfter: "3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := RefreshPlayerHandler(tt.refresh)
+
+			req := httptest.NewRequest("POST", "/players/org.mpris.MediaPlayer2.mpd/refresh", nil)
+			req.SetPathValue("player", "org.mpris.MediaPlayer2.mpd")
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			if w.Code != tt.wantStatusCode {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatusCode)
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBodyMatch) {
+				t.Errorf("body = %q, want to contain %q", w.Body.String(), tt.wantBodyMatch)
+			}
+			if got := w.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetryAfter)
+			}
+		})
+	}
+}
+
 func TestWithTrackRoutePattern(t *testing.T) {
 	newMux := func(gotBus, gotTrack *string) *http.ServeMux {
 		mux := http.NewServeMux()
@@ -369,3 +633,449 @@ func TestWithPlayer(t *testing.T) {
 		})
 	}
 }
+
+// TestWithAllowedAction checks that a forbidden action is rejected with 403
+// before the handler runs.
+func TestWithAllowedAction(t *testing.T) {
+	allowed := func(action string) bool { return action == mpris.ActionPlay }
+
+	tests := []struct {
+		action    string
+		wantCode  int
+		wantCalls int
+	}{
+		{mpris.ActionPlay, http.StatusAccepted, 1},
+		{mpris.ActionStop, http.StatusForbidden, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.action, func(t *testing.T) {
+			calls := 0
+			handler := withAllowedAction(allowed, tt.action, func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				w.WriteHeader(http.StatusAccepted)
+			})
+
+			w := httptest.NewRecorder()
+			handler(w, httptest.NewRequest("POST", "/players/p/"+tt.action, nil))
+
+			if w.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantCode)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// TestWithPlayerName checks that a short player name is resolved to its bus
+// name, and that an ambiguous or unknown one is rejected before the handler.
+func TestWithPlayerName(t *testing.T) {
+	resolve := func(name string) (string, error) {
+		switch name {
+		case "spotify":
+			return "org.mpris.MediaPlayer2.spotify", nil
+		case "vlc":
+			return "", &mpris.AmbiguousPlayerError{Name: name, Candidates: []string{"a", "b"}}
+		}
+		return "", &mpris.PlayerNotFoundError{BusName: name}
+	}
+	var got string
+	handler := withPlayerName(resolve, withPlayer(func(w http.ResponseWriter, r *http.Request, busName string) {
+		got = busName
+	}))
+
+	tests := []struct {
+		player   string
+		want     int
+		wantName string
+	}{
+		{"spotify", http.StatusOK, "org.mpris.MediaPlayer2.spotify"},
+		{"vlc", http.StatusConflict, ""},
+		{"nope", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		got = ""
+		req := httptest.NewRequest(http.MethodPost, "/players/"+tt.player+"/play", nil)
+		req.SetPathValue("player", tt.player)
+		w := httptest.NewRecorder()
+		handler(w, req)
+		if w.Code != tt.want || got != tt.wantName {
+			t.Errorf("%s: status = %d, busName = %q; want %d, %q", tt.player, w.Code, got, tt.want, tt.wantName)
+		}
+	}
+}
+
+// TestSetVolumeHandlerBodyTooLarge checks that an oversized body is rejected
+// before the backend is reached.
+func TestSetVolumeHandlerBodyTooLarge(t *testing.T) {
+	handler := maxBodyMiddleware(64 << 10)(SetVolumeHandler(nil))
+
+	body := bytes.NewBufferString(`{"volume": 0.5, "pad": "`)
+	body.WriteString(strings.Repeat("a", 128<<10))
+	body.WriteString(`"}`)
+
+	req := httptest.NewRequest("POST", "/players/org.mpris.MediaPlayer2.spotify/volume", body)
+	req.SetPathValue("player", "org.mpris.MediaPlayer2.spotify")
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status code = %d, want %d", w.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+// fakePlayers is an in-memory PlayerController: it serves players from a
+// slice and records control calls instead of reaching D-Bus.
+type fakePlayers struct {
+	players    []mpris.Player
+	gone       []mpris.Player  // served by GonePlayers
+	denied     map[string]bool // actions ActionAllowed rejects
+	err        error           // returned by every control call
+	calls      []string
+	updates    chan mpris.Player // handed to the next SubscribePlayer
+	subscribed func()            // run by SubscribePlayer, if set
+}
+
+func (f *fakePlayers) control(busName, call string) error {
+	if _, err := f.GetPlayerFromCache(busName); err != nil {
+		return err
+	}
+	f.calls = append(f.calls, call)
+	return f.err
+}
+
+func (f *fakePlayers) ListPlayers() ([]mpris.Player, error) { return f.players, nil }
+func (f *fakePlayers) GonePlayers() []mpris.Player          { return f.gone }
+func (f *fakePlayers) CacheUpdatedAt() time.Time            { return time.Time{} }
+
+func (f *fakePlayers) ResolveBusName(name string) (string, error) {
+	if strings.HasPrefix(name, mpris.MPRIS_PREFIX+".") {
+		return name, nil
+	}
+	return mpris.MPRIS_PREFIX + "." + name, nil
+}
+
+func (f *fakePlayers) GetPlayerFromCache(busName string) (*mpris.Player, error) {
+	for _, p := range f.players {
+		if p.BusName == busName {
+			return &p, nil
+		}
+	}
+	return nil, &mpris.PlayerNotFoundError{BusName: busName}
+}
+
+func (f *fakePlayers) GetPlayerStatus(busName string) (*mpris.PlayerStatus, error) {
+	p, err := f.GetPlayerFromCache(busName)
+	if err != nil {
+		return nil, err
+	}
+	return &mpris.PlayerStatus{PlaybackStatus: p.PlaybackStatus, Volume: p.Volume, Position: p.Position, LoopStatus: p.LoopStatus}, nil
+}
+
+func (f *fakePlayers) GetPosition(busName string) (*mpris.PositionInfo, error) {
+	p, err := f.GetPlayerFromCache(busName)
+	if err != nil {
+		return nil, err
+	}
+	return &mpris.PositionInfo{Position: p.Position, Rate: 1, Status: p.PlaybackStatus}, nil
+}
+
+func (f *fakePlayers) GetTracklist(busName string) (*mpris.TracklistResponse, error) {
+	p, err := f.GetPlayerFromCache(busName)
+	if err != nil {
+		return nil, err
+	}
+	return &mpris.TracklistResponse{CanEditTracks: p.CanEditTracks, Tracks: p.Tracklist}, nil
+}
+
+func (f *fakePlayers) RefreshPlayer(busName string) (*mpris.Player, error) {
+	return f.GetPlayerFromCache(busName)
+}
+
+func (f *fakePlayers) Artwork(string) ([]byte, bool)    { return nil, false }
+func (f *fakePlayers) ActionAllowed(action string) bool { return !f.denied[action] }
+
+func (f *fakePlayers) Play(busName string) error  { return f.control(busName, "play "+busName) }
+func (f *fakePlayers) Pause(busName string) error { return f.control(busName, "pause "+busName) }
+func (f *fakePlayers) PlayPause(busName string) error {
+	return f.control(busName, "play_pause "+busName)
+}
+func (f *fakePlayers) Stop(busName string) error     { return f.control(busName, "stop "+busName) }
+func (f *fakePlayers) Next(busName string) error     { return f.control(busName, "next "+busName) }
+func (f *fakePlayers) Previous(busName string) error { return f.control(busName, "previous "+busName) }
+
+func (f *fakePlayers) Seek(busName string, offset int64) error {
+	return f.control(busName, fmt.Sprintf("seek %s %d", busName, offset))
+}
+
+func (f *fakePlayers) SetPosition(busName, trackID string, position int64) error {
+	return f.control(busName, fmt.Sprintf("position %s %s %d", busName, trackID, position))
+}
+
+func (f *fakePlayers) SetVolume(busName string, volume float64) error {
+	return f.control(busName, fmt.Sprintf("volume %s %v", busName, volume))
+}
+
+func (f *fakePlayers) SetLoopStatus(busName string, status mpris.LoopStatus) error {
+	return f.control(busName, fmt.Sprintf("loop %s %s", busName, status))
+}
+
+func (f *fakePlayers) SetShuffle(busName string, shuffle bool) error {
+	return f.control(busName, fmt.Sprintf("shuffle %s %v", busName, shuffle))
+}
+
+func (f *fakePlayers) SetFullscreen(busName string, on bool) error {
+	return f.control(busName, fmt.Sprintf("fullscreen %s %v", busName, on))
+}
+
+func (f *fakePlayers) SetRating(busName string, rating float64) error {
+	return f.control(busName, fmt.Sprintf("rating %s %v", busName, rating))
+}
+
+func (f *fakePlayers) ApplyState(busName string, req mpris.StateRequest) (*mpris.StateResult, error) {
+	if err := f.control(busName, "state "+busName); err != nil {
+		return nil, err
+	}
+	p, _ := f.GetPlayerFromCache(busName)
+	return &mpris.StateResult{Player: p}, nil
+}
+
+func (f *fakePlayers) SetPriority(busName string, priority int) error {
+	return f.control(busName, fmt.Sprintf("priority %s %d", busName, priority))
+}
+
+func (f *fakePlayers) GoTo(busName, trackRef string) error {
+	return f.control(busName, fmt.Sprintf("goto %s %s", busName, trackRef))
+}
+
+func (f *fakePlayers) AddTrack(busName, uri, afterTrack string, setAsCurrent bool) error {
+	return f.control(busName, fmt.Sprintf("add %s %s", busName, uri))
+}
+
+func (f *fakePlayers) RemoveTrack(busName, trackRef string) error {
+	return f.control(busName, fmt.Sprintf("remove %s %s", busName, trackRef))
+}
+
+func (f *fakePlayers) SubscribePlayer(string) chan mpris.Player {
+	if f.subscribed != nil {
+		f.subscribed()
+	}
+	if f.updates == nil {
+		f.updates = make(chan mpris.Player, 1)
+	}
+	return f.updates
+}
+
+func (f *fakePlayers) UnsubscribePlayer(string, chan mpris.Player) {}
+
+// TestMPRISRoutes drives the registered /players routes end to end against an
+// in-memory backend: short-name resolution, allowed actions, body decoding
+// and error mapping.
+func TestMPRISRoutes(t *testing.T) {
+	const spotify = "org.mpris.MediaPlayer2.spotify"
+
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		body      string
+		denied    map[string]bool
+		err       error
+		wantCode  int
+		wantBody  string
+		wantCalls []string
+	}{
+		{
+			name: "list players", method: http.MethodGet, path: "/players",
+			wantCode: http.StatusOK, wantBody: `"bus_name":"org.mpris.MediaPlayer2.spotify"`,
+		},
+		{
+			name: "play by short name", method: http.MethodPost, path: "/players/spotify/play",
+			wantCode: http.StatusAccepted, wantCalls: []string{"play " + spotify},
+		},
+		{
+			name: "volume decodes the body", method: http.MethodPost, path: "/players/spotify/volume", body: `{"volume":0.4}`,
+			wantCode: http.StatusAccepted, wantCalls: []string{"volume " + spotify + " 0.4"},
+		},
+		{
+			name: "denied action never reaches the backend", method: http.MethodPost, path: "/players/spotify/stop",
+			denied: map[string]bool{mpris.ActionStop: true}, wantCode: http.StatusForbidden,
+		},
+		{
+			name: "unknown player", method: http.MethodPost, path: "/players/vlc/play",
+			wantCode: http.StatusNotFound, wantBody: "player not found",
+		},
+		{
+			name: "capability error", method: http.MethodPost, path: "/players/spotify/next",
+			err: &mpris.CapabilityError{Required: "CanGoNext"}, wantCode: http.StatusForbidden,
+			wantCalls: []string{"next " + spotify},
+		},
+		{
+			name: "status from the cache", method: http.MethodGet, path: "/players/spotify/status",
+			wantCode: http.StatusOK, wantBody: `"playback_status":"Playing"`,
+		},
+		{
+			name: "tracklist goto", method: http.MethodPost, path: "/players/spotify/tracklist/goto/7",
+			wantCode: http.StatusAccepted, wantCalls: []string{"goto " + spotify + " 7"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakePlayers{
+				players: []mpris.Player{{BusName: spotify, PlaybackStatus: mpris.StatusPlaying}},
+				denied:  tt.denied,
+				err:     tt.err,
+			}
+			s := &Server{mux: http.NewServeMux()}
+			s.registerMPRISRoutes(fake)
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			s.mux.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d (body %q)", w.Code, tt.wantCode, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("body = %q, want to contain %q", w.Body.String(), tt.wantBody)
+			}
+			if !slices.Equal(fake.calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", fake.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestListPlayersIncludeGone(t *testing.T) {
+	fake := &fakePlayers{
+		players: []mpris.Player{{BusName: "org.mpris.MediaPlayer2.spotify"}},
+		gone:    []mpris.Player{{BusName: "org.mpris.MediaPlayer2.vlc", Gone: true}},
+	}
+	handler := ListPlayersHandler(fake)
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"/players", []string{"org.mpris.MediaPlayer2.spotify"}},
+		{"/players?include_gone=true", []string{"org.mpris.MediaPlayer2.spotify", "org.mpris.MediaPlayer2.vlc"}},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+		var got []mpris.Player
+		if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+			t.Fatalf("%s: decode: %v", tt.path, err)
+		}
+		var names []string
+		for _, p := range got {
+			names = append(names, p.BusName)
+		}
+		if !slices.Equal(names, tt.want) {
+			t.Errorf("%s: players = %v, want %v", tt.path, names, tt.want)
+		}
+	}
+	if len(fake.players) != 1 {
+		t.Errorf("include_gone modified the cached players: %+v", fake.players)
+	}
+}
+
+// TestPlayerWebSocket subscribes to one player over /ws/players/{player}: the
+// cached player comes first, then each update, and the socket ends when the
+// player goes away.
+func TestPlayerWebSocket(t *testing.T) {
+	const spotify = "org.mpris.MediaPlayer2.spotify"
+	fake := &fakePlayers{
+		players: []mpris.Player{{BusName: spotify, PlaybackStatus: mpris.StatusPaused}},
+		updates: make(chan mpris.Player, 1),
+	}
+	s := &Server{mux: http.NewServeMux()}
+	s.registerMPRISRoutes(fake)
+	srv := httptest.NewServer(s.mux)
+	defer srv.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws/players/spotify"
+	conn, err := websocket.Dial(wsURL, "", srv.URL)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var got mpris.Player
+	if err := websocket.JSON.Receive(conn, &got); err != nil {
+		t.Fatalf("receive initial player: %v", err)
+	}
+	if got.BusName != spotify || got.PlaybackStatus != mpris.StatusPaused {
+		t.Errorf("initial player = %s %s, want %s Paused", got.BusName, got.PlaybackStatus, spotify)
+	}
+
+	fake.updates <- mpris.Player{BusName: spotify, PlaybackStatus: mpris.StatusPlaying}
+	if err := websocket.JSON.Receive(conn, &got); err != nil {
+		t.Fatalf("receive update: %v", err)
+	}
+	if got.PlaybackStatus != mpris.StatusPlaying {
+		t.Errorf("update status = %s, want Playing", got.PlaybackStatus)
+	}
+
+	close(fake.updates)
+	if err := websocket.JSON.Receive(conn, &got); err == nil {
+		t.Error("socket should close once the player is removed")
+	}
+}
+
+// TestPlayerWebSocketSubscribesFirst: a change landing as the subscription is
+// taken is in the first message, not lost between the cache read and the
+// subscription.
+func TestPlayerWebSocketSubscribesFirst(t *testing.T) {
+	const spotify = "org.mpris.MediaPlayer2.spotify"
+	fake := &fakePlayers{
+		players: []mpris.Player{{BusName: spotify, PlaybackStatus: mpris.StatusPaused}},
+		updates: make(chan mpris.Player, 1),
+	}
+	fake.subscribed = func() {
+		fake.players = []mpris.Player{{BusName: spotify, PlaybackStatus: mpris.StatusPlaying}}
+	}
+	s := &Server{mux: http.NewServeMux()}
+	s.registerMPRISRoutes(fake)
+	srv := httptest.NewServer(s.mux)
+	defer srv.Close()
+
+	conn, err := websocket.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws/players/spotify", "", srv.URL)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var got mpris.Player
+	if err := websocket.JSON.Receive(conn, &got); err != nil {
+		t.Fatalf("receive initial player: %v", err)
+	}
+	if got.PlaybackStatus != mpris.StatusPlaying {
+		t.Errorf("initial status = %s, want Playing", got.PlaybackStatus)
+	}
+}
+
+func TestPlayerWebSocketRejects(t *testing.T) {
+	fake := &fakePlayers{players: []mpris.Player{{BusName: "org.mpris.MediaPlayer2.spotify"}}}
+	s := &Server{mux: http.NewServeMux()}
+	s.registerMPRISRoutes(fake)
+	srv := httptest.NewServer(s.mux)
+	defer srv.Close()
+	wsBase := "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	if _, err := websocket.Dial(wsBase+"/ws/players/vlc", "", srv.URL); err == nil {
+		t.Error("unknown player: dial should fail")
+	}
+	if _, err := websocket.Dial(wsBase+"/ws/players/spotify", "", "https://evil.example"); err == nil {
+		t.Error("foreign origin: dial should fail")
+	}
+}
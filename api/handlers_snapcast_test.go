@@ -0,0 +1,64 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/b0bbywan/go-odio-api/backend/snapcast"
+)
+
+func TestHandleSnapcastError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		wantStatusCode int
+		wantBodyMatch  string
+	}{
+		{
+			name:           "no error returns 202 Accepted",
+			err:            nil,
+			wantStatusCode: http.StatusAccepted,
+		},
+		{
+			name:           "ClientNotFoundError returns 404 Not Found",
+			err:            &snapcast.ClientNotFoundError{ID: "kitchen"},
+			wantStatusCode: http.StatusNotFound,
+			wantBodyMatch:  "kitchen",
+		},
+		{
+			name:           "ValidationError returns 400 Bad Request",
+			err:            &snapcast.ValidationError{Field: "volume", Message: "must be between 0 and 100"},
+			wantStatusCode: http.StatusBadRequest,
+			wantBodyMatch:  "volume",
+		},
+		{
+			name:           "RPCError returns 502 Bad Gateway",
+			err:            &snapcast.RPCError{Code: -32603, Message: "Internal error"},
+			wantStatusCode: http.StatusBadGateway,
+			wantBodyMatch:  "Internal error",
+		},
+		{
+			name:           "connection error returns 502 Bad Gateway",
+			err:            errors.New("dial tcp 127.0.0.1:1705: connection refused"),
+			wantStatusCode: http.StatusBadGateway,
+			wantBodyMatch:  "connection refused",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handleSnapcastError(w, tt.err)
+
+			if w.Code != tt.wantStatusCode {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatusCode)
+			}
+			if tt.wantBodyMatch != "" && !strings.Contains(w.Body.String(), tt.wantBodyMatch) {
+				t.Errorf("body = %q, want to contain %q", w.Body.String(), tt.wantBodyMatch)
+			}
+		})
+	}
+}
@@ -1,10 +1,15 @@
 package api
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/b0bbywan/go-odio-api/backend/systemd"
 )
@@ -86,6 +91,18 @@ func TestHandleSystemdError(t *testing.T) {
 			wantStatusCode: http.StatusForbidden,
 			wantBodyMatch:  "cannot act on unmanaged user unit",
 		},
+		{
+			name:           "InvalidUnitNameError returns 400 Bad Request",
+			err:            &systemd.InvalidUnitNameError{Unit: "mympd", Reason: "must end in .service"},
+			wantStatusCode: http.StatusBadRequest,
+			wantBodyMatch:  "invalid unit name",
+		},
+		{
+			name:           "StopTimeoutError returns 504 Gateway Timeout",
+			err:            &systemd.StopTimeoutError{Unit: "mpd.service", State: "deactivating"},
+			wantStatusCode: http.StatusGatewayTimeout,
+			wantBodyMatch:  "still deactivating after stop",
+		},
 		{
 			name:           "generic error returns 500 Internal Server Error",
 			err:            http.ErrServerClosed,
@@ -537,3 +554,299 @@ func TestRestartServiceHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceBatchHandler(t *testing.T) {
+	mock := &mockSystemdBackend{
+		restartFunc: func(name string, scope systemd.UnitScope) error {
+			switch name {
+			case "unmanaged.service":
+				return &systemd.PermissionUserError{Unit: name}
+			case "broken.service":
+				return errors.New("job failed")
+			}
+			return nil
+		},
+	}
+	actions := map[string]func(string, systemd.UnitScope) error{"restart": mock.RestartService}
+
+	tests := []struct {
+		name           string
+		scope          string
+		body           string
+		wantStatusCode int
+		wantResults    []serviceBatchResult
+	}{
+		{
+			name:           "partial success reports each unit",
+			scope:          "user",
+			body:           `{"action":"restart","units":["mpd.service","unmanaged.service","broken.service"]}`,
+			wantStatusCode: http.StatusOK,
+			wantResults: []serviceBatchResult{
+				{Unit: "mpd.service", Status: http.StatusAccepted},
+				{Unit: "unmanaged.service", Status: http.StatusForbidden, Error: "cannot act on unmanaged user unit: unmanaged.service"},
+				{Unit: "broken.service", Status: http.StatusInternalServerError, Error: "job failed"},
+			},
+		},
+		{
+			name:           "unknown action returns 400",
+			scope:          "user",
+			body:           `{"action":"mask","units":["mpd.service"]}`,
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "empty units returns 400",
+			scope:          "user",
+			body:           `{"action":"restart","units":[]}`,
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid scope returns 404",
+			scope:          "nope",
+			body:           `{"action":"restart","units":["mpd.service"]}`,
+			wantStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := ServiceBatchHandler(nil, actions)
+
+			req := httptest.NewRequest("POST", "/services/"+tt.scope+"/batch", strings.NewReader(tt.body))
+			req.SetPathValue("scope", tt.scope)
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			if w.Code != tt.wantStatusCode {
+				t.Fatalf("status = %d, want %d (body %q)", w.Code, tt.wantStatusCode, w.Body.String())
+			}
+			if tt.wantResults == nil {
+				return
+			}
+			var got []serviceBatchResult
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if len(got) != len(tt.wantResults) {
+				t.Fatalf("got %d results, want %d", len(got), len(tt.wantResults))
+			}
+			for i := range got {
+				if got[i] != tt.wantResults[i] {
+					t.Errorf("result[%d] = %+v, want %+v", i, got[i], tt.wantResults[i])
+				}
+			}
+		})
+	}
+}
+
+func TestServiceRestartAllHandler(t *testing.T) {
+	units := func(scope systemd.UnitScope) []string {
+		if scope == systemd.ScopeSystem {
+			return []string{"bluetooth.service"}
+		}
+		return []string{"mpd.service", "snapclient.service"}
+	}
+	var restarted []string
+	restart := func(name string, scope systemd.UnitScope) error {
+		if scope == systemd.ScopeSystem {
+			return &systemd.PermissionSystemError{Unit: name}
+		}
+		if name == "snapclient.service" {
+			return errors.New("job failed")
+		}
+		restarted = append(restarted, name)
+		return nil
+	}
+	handler := ServiceRestartAllHandler(units, restart)
+
+	tests := []struct {
+		name           string
+		scope          string
+		wantStatusCode int
+		wantResults    []serviceBatchResult
+	}{
+		{
+			name:           "user scope restarts every watched unit",
+			scope:          "user",
+			wantStatusCode: http.StatusOK,
+			wantResults: []serviceBatchResult{
+				{Unit: "mpd.service", Status: http.StatusAccepted},
+				{Unit: "snapclient.service", Status: http.StatusInternalServerError, Error: "job failed"},
+			},
+		},
+		{
+			name:           "system scope is forbidden unit by unit",
+			scope:          "system",
+			wantStatusCode: http.StatusOK,
+			wantResults: []serviceBatchResult{
+				{Unit: "bluetooth.service", Status: http.StatusForbidden, Error: (&systemd.PermissionSystemError{Unit: "bluetooth.service"}).Error()},
+			},
+		},
+		{
+			name:           "invalid scope returns 404",
+			scope:          "nope",
+			wantStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/services/"+tt.scope+"/restart-all", nil)
+			req.SetPathValue("scope", tt.scope)
+			w := httptest.NewRecorder()
+			handler(w, req)
+
+			if w.Code != tt.wantStatusCode {
+				t.Fatalf("status = %d, want %d (body %q)", w.Code, tt.wantStatusCode, w.Body.String())
+			}
+			if tt.wantResults == nil {
+				return
+			}
+			var got []serviceBatchResult
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if !slices.Equal(got, tt.wantResults) {
+				t.Errorf("results = %+v, want %+v", got, tt.wantResults)
+			}
+		})
+	}
+	if !slices.Equal(restarted, []string{"mpd.service"}) {
+		t.Errorf("restarted = %v, want [mpd.service]", restarted)
+	}
+}
+
+func TestServiceLogStreamHandler(t *testing.T) {
+	serve := func(follow func(context.Context, string, systemd.UnitScope) (<-chan systemd.JournalEntry, error), max time.Duration, path string) *httptest.ResponseRecorder {
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /services/{scope}/{unit}/logs/stream", ServiceLogStreamHandler(follow, max))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	t.Run("streams entries as data events", func(t *testing.T) {
+		var gotUnit string
+		var gotScope systemd.UnitScope
+		follow := func(_ context.Context, unit string, scope systemd.UnitScope) (<-chan systemd.JournalEntry, error) {
+			gotUnit, gotScope = unit, scope
+			ch := make(chan systemd.JournalEntry, 2)
+			ch <- systemd.JournalEntry{Priority: 6, Message: "started"}
+			ch <- systemd.JournalEntry{Priority: 3, Message: "crashed"}
+			close(ch)
+			return ch, nil
+		}
+
+		w := serve(follow, time.Minute, "/services/user/mpd.service/logs/stream")
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", w.Code)
+		}
+		if gotUnit != "mpd.service" || gotScope != systemd.ScopeUser {
+			t.Errorf("follow(%q, %q), want mpd.service in user scope", gotUnit, gotScope)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		events := strings.Split(strings.TrimSpace(w.Body.String()), "\n\n")
+		if len(events) != 2 {
+			t.Fatalf("got %d events, want 2: %q", len(events), w.Body.String())
+		}
+		var entry systemd.JournalEntry
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(events[1], "data: ")), &entry); err != nil {
+			t.Fatalf("decode event: %v", err)
+		}
+		if entry.Message != "crashed" || entry.Priority != 3 {
+			t.Errorf("second event = %+v", entry)
+		}
+	})
+
+	t.Run("ends after the max duration", func(t *testing.T) {
+		follow := func(ctx context.Context, _ string, _ systemd.UnitScope) (<-chan systemd.JournalEntry, error) {
+			return make(chan systemd.JournalEntry), nil // never sends nor closes
+		}
+		done := make(chan struct{})
+		go func() {
+			serve(follow, 20*time.Millisecond, "/services/system/bluetooth.service/logs/stream")
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("stream did not end after its max duration")
+		}
+	})
+
+	t.Run("unwatched unit is forbidden", func(t *testing.T) {
+		follow := func(_ context.Context, unit string, scope systemd.UnitScope) (<-chan systemd.JournalEntry, error) {
+			return nil, &systemd.NotWatchedError{Unit: unit, Scope: scope}
+		}
+		if w := serve(follow, time.Minute, "/services/system/sshd.service/logs/stream"); w.Code != http.StatusForbidden {
+			t.Errorf("status = %d, want 403", w.Code)
+		}
+	})
+
+	t.Run("invalid scope", func(t *testing.T) {
+		follow := func(context.Context, string, systemd.UnitScope) (<-chan systemd.JournalEntry, error) {
+			t.Error("follow called for an invalid scope")
+			return nil, nil
+		}
+		if w := serve(follow, time.Minute, "/services/root/mpd.service/logs/stream"); w.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", w.Code)
+		}
+	})
+}
+
+func TestScopeServicesHandler(t *testing.T) {
+	list := func(scope systemd.UnitScope) ([]systemd.Service, error) {
+		if scope == systemd.ScopeSystem {
+			return []systemd.Service{{Name: "bluetooth.service", Scope: scope, Running: true}}, nil
+		}
+		return []systemd.Service{
+			{Name: "mpd.service", Scope: scope, Running: true},
+			{Name: "snapclient.service", Scope: scope},
+		}, nil
+	}
+	handler := ScopeServicesHandler(list, func() time.Time { return time.Time{} })
+
+	tests := []struct {
+		name           string
+		path           string
+		scope          string
+		wantStatusCode int
+		wantNames      []string
+	}{
+		{"system scope", "/services/system", "system", http.StatusOK, []string{"bluetooth.service"}},
+		{"user scope", "/services/user", "user", http.StatusOK, []string{"mpd.service", "snapclient.service"}},
+		{"running only", "/services/user?running=true", "user", http.StatusOK, []string{"mpd.service"}},
+		{"stopped only", "/services/user?running=false", "user", http.StatusOK, []string{"snapclient.service"}},
+		{"invalid running", "/services/user?running=maybe", "user", http.StatusBadRequest, nil},
+		{"invalid scope", "/services/global", "global", http.StatusNotFound, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req.SetPathValue("scope", tt.scope)
+			w := httptest.NewRecorder()
+			handler(w, req)
+
+			if w.Code != tt.wantStatusCode {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatusCode)
+			}
+			if tt.wantStatusCode != http.StatusOK {
+				return
+			}
+			var got []systemd.Service
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			var names []string
+			for _, svc := range got {
+				names = append(names, svc.Name)
+			}
+			if !slices.Equal(names, tt.wantNames) {
+				t.Errorf("services = %v, want %v", names, tt.wantNames)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"net/http"
+)
+
+// health is the /healthz document. Status is "degraded" while any backend
+// connection is down, e.g. MPRIS between a session bus loss and its reconnect.
+type health struct {
+	Status      string          `json:"status"`
+	Connections map[string]bool `json:"connections"`
+}
+
+// HealthHandler answers 200 when every backend connection is up and 503
+// otherwise, so supervisors can probe it directly.
+func HealthHandler(connections func() map[string]bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h := health{Status: "ok", Connections: connections()}
+		code := http.StatusOK
+		for _, up := range h.Connections {
+			if !up {
+				h.Status = "degraded"
+				code = http.StatusServiceUnavailable
+				break
+			}
+		}
+		writeJSON(w, code, h)
+	}
+}
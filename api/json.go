@@ -3,22 +3,27 @@ package api
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/b0bbywan/go-odio-api/logger"
 )
 
-const maxRequestBodySize = 1 << 20 // 1 MB
-
 type setVolumeRequest struct {
 	Volume float32 `json:"volume"`
 }
 
+type normalizeRequest struct {
+	Target float32 `json:"target"`
+}
+
 // statusError is an error carrying an HTTP status code, recognised by JSONHandler.
 type statusError struct {
-	code int
-	msg  string
+	code     int
+	msg      string
+	jsonBody bool // answer {"error": msg} instead of plain text
 }
 
 func (e *statusError) Error() string { return e.msg }
@@ -28,10 +33,19 @@ func httpError(code int, err error) error {
 	return &statusError{code: code, msg: err.Error()}
 }
 
+// jsonError is httpError answered as {"error": "<message>"}, for endpoints
+// whose clients parse their errors. api.envelope keeps its own error shape.
+func jsonError(code int, err error) error {
+	return &statusError{code: code, msg: err.Error(), jsonBody: true}
+}
+
 // JSONHandler wraps a handler returning (data, error) into an http.HandlerFunc:
-//   - statusError → that HTTP code + plain-text body
+//   - statusError → that HTTP code + plain-text body ({"error": ...} for jsonError)
 //   - plain error → 500
 //   - non-nil data → 200 with JSON body
+//
+// With api.envelope, data is wrapped as {"data": ..., "meta": ...} and errors
+// answer {"error": {...}} (see writeError).
 func JSONHandler(h func(http.ResponseWriter, *http.Request) (any, error)) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		data, err := h(w, r)
@@ -40,17 +54,43 @@ func JSONHandler(h func(http.ResponseWriter, *http.Request) (any, error)) http.H
 			var se *statusError
 			if errors.As(err, &se) {
 				code = se.code
+				if se.jsonBody && !enveloped(w) {
+					w.Header().Set("X-Content-Type-Options", "nosniff")
+					writeJSON(w, code, map[string]string{"error": se.msg})
+					return
+				}
 			}
-			http.Error(w, err.Error(), code)
+			writeError(w, err.Error(), code)
 			return
 		}
-		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(data); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
+		writeJSON(w, http.StatusOK, data)
+	}
+}
+
+// writeJSON writes v as the JSON body of a status response, wrapped as
+// {"data", "meta"} with api.envelope. Every success body goes through it.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	if enveloped(w) {
+		v = wrapEnvelope(w, v)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Warn("[api] failed to write response: %v", err)
 	}
 }
 
+// writeJSONError writes {"error":{"code":...,"message":...}} with status, for
+// errors outside any handler such as unknown routes.
+func writeJSONError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"error": map[string]string{"code": code, "message": message},
+	})
+}
+
 // withBody parses and validates a JSON request body, then calls next.
 func withBody[T any](
 	validate func(*T) error,
@@ -65,26 +105,24 @@ func withBody[T any](
 
 		contentType := r.Header.Get("Content-Type")
 		if contentType != "application/json" {
-			http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+			writeError(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
 			return
 		}
 
-		r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
-
 		var req T
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			var maxBytesErr *http.MaxBytesError
 			if errors.As(err, &maxBytesErr) {
-				http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+				writeError(w, "request body too large", http.StatusRequestEntityTooLarge)
 				return
 			}
-			http.Error(w, "invalid JSON payload", http.StatusBadRequest)
+			writeError(w, "invalid JSON payload", http.StatusBadRequest)
 			return
 		}
 
 		if validate != nil {
 			if err := validate(&req); err != nil {
-				http.Error(w, err.Error(), http.StatusBadRequest)
+				writeError(w, err.Error(), http.StatusBadRequest)
 				return
 			}
 		}
@@ -101,18 +139,54 @@ func setCacheHeader(w http.ResponseWriter, t time.Time) {
 }
 
 // listHandler wraps a backend list function into a JSONHandler that also sets
-// the X-Cache-Updated-At header from the provided timestamp function.
-func listHandler[T any](list func() (T, error), updatedAt func() time.Time) http.HandlerFunc {
+// the X-Cache-Updated-At header from the provided timestamp function, and
+// applies the limit/offset query parameters (see paginate).
+func listHandler[T any](list func() ([]T, error), updatedAt func() time.Time) http.HandlerFunc {
 	return JSONHandler(func(w http.ResponseWriter, r *http.Request) (any, error) {
 		data, err := list()
 		if err != nil {
 			return nil, err
 		}
 		setCacheHeader(w, updatedAt())
-		return data, nil
+		return paginate(w, r, data)
 	})
 }
 
+// paginate slices items by the optional ?limit=N&offset=M query parameters and
+// sets X-Total-Count to the length before slicing. Without them every item is
+// returned; an offset past the end yields an empty list.
+func paginate[T any](w http.ResponseWriter, r *http.Request, items []T) ([]T, error) {
+	q := r.URL.Query()
+	offset, err := queryCount(q.Get("offset"), 0)
+	if err != nil {
+		return nil, httpError(http.StatusBadRequest, fmt.Errorf("offset: %w", err))
+	}
+	limit, err := queryCount(q.Get("limit"), len(items))
+	if err != nil {
+		return nil, httpError(http.StatusBadRequest, fmt.Errorf("limit: %w", err))
+	}
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(len(items)))
+	if offset >= len(items) {
+		return []T{}, nil
+	}
+	// clamp before adding: offset+limit overflows for a limit near MaxInt
+	limit = min(limit, len(items)-offset)
+	return items[offset : offset+limit], nil
+}
+
+// queryCount parses a non-negative integer query value, def when empty.
+func queryCount(v string, def int) (int, error) {
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("must be a non-negative integer, got %q", v)
+	}
+	return n, nil
+}
+
 // validateVolume validates that a volume is between 0 and 1
 func validateVolume(req *setVolumeRequest) error {
 	if req.Volume < 0 || req.Volume > 1 {
@@ -120,3 +194,10 @@ func validateVolume(req *setVolumeRequest) error {
 	}
 	return nil
 }
+
+func validateNormalize(req *normalizeRequest) error {
+	if req.Target <= 0 || req.Target > 1 {
+		return errors.New("target must be greater than 0 and at most 1")
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -158,6 +159,8 @@ func TestWithBodyContentType(t *testing.T) {
 
 // TestWithBodySizeLimit tests request body size limit
 func TestWithBodySizeLimit(t *testing.T) {
+	const limit = 64 << 10
+
 	tests := []struct {
 		name     string
 		bodySize int
@@ -171,20 +174,20 @@ func TestWithBodySizeLimit(t *testing.T) {
 			wantBody: "",
 		},
 		{
-			name:     "medium body (500KB)",
-			bodySize: 500 * 1024,
+			name:     "medium body (32KB)",
+			bodySize: 32 << 10,
 			wantCode: http.StatusOK,
 			wantBody: "",
 		},
 		{
-			name:     "body at limit (1MB)",
-			bodySize: 1 << 20,
+			name:     "body at limit (64KB)",
+			bodySize: limit,
 			wantCode: http.StatusRequestEntityTooLarge,
 			wantBody: "request body too large",
 		},
 		{
-			name:     "body over limit (2MB)",
-			bodySize: 2 << 20,
+			name:     "body over limit (1MB)",
+			bodySize: 1 << 20,
 			wantCode: http.StatusRequestEntityTooLarge,
 			wantBody: "request body too large",
 		},
@@ -192,12 +195,12 @@ func TestWithBodySizeLimit(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			handler := withBody(
+			handler := maxBodyMiddleware(limit)(withBody(
 				nil,
 				func(w http.ResponseWriter, r *http.Request, req *setVolumeRequest) {
 					w.WriteHeader(http.StatusOK)
 				},
-			)
+			))
 
 			// Create a large JSON body
 			body := bytes.NewBufferString(`{"volume": 0.5, "data": "`)
@@ -208,7 +211,7 @@ func TestWithBodySizeLimit(t *testing.T) {
 			req.Header.Set("Content-Type", "application/json")
 			w := httptest.NewRecorder()
 
-			handler(w, req)
+			handler.ServeHTTP(w, req)
 
 			if w.Code != tt.wantCode {
 				t.Errorf("status code = %d, want %d", w.Code, tt.wantCode)
@@ -244,3 +247,40 @@ func TestWithBodyValidJSON(t *testing.T) {
 		t.Errorf("status code = %d, want 202", w.Code)
 	}
 }
+
+func TestPaginate(t *testing.T) {
+	items := []int{0, 1, 2, 3, 4}
+	tests := []struct {
+		query    string
+		want     []int
+		wantCode int
+	}{
+		{"", []int{0, 1, 2, 3, 4}, 0},
+		{"limit=2", []int{0, 1}, 0},
+		{"limit=2&offset=2", []int{2, 3}, 0},
+		{"offset=4&limit=10", []int{4}, 0},
+		{"offset=9", []int{}, 0},
+		{"limit=0", []int{}, 0},
+		{"offset=1&limit=9223372036854775807", []int{1, 2, 3, 4}, 0},
+		{"limit=-1", nil, http.StatusBadRequest},
+		{"offset=abc", nil, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/list?"+tt.query, nil)
+		got, err := paginate(w, r, items)
+		if tt.wantCode != 0 {
+			var se *statusError
+			if !errors.As(err, &se) || se.code != tt.wantCode {
+				t.Errorf("%q: error = %v, want status %d", tt.query, err, tt.wantCode)
+			}
+			continue
+		}
+		if err != nil || !slices.Equal(got, tt.want) || got == nil {
+			t.Errorf("%q: paginate() = %v, %v, want %v", tt.query, got, err, tt.want)
+		}
+		if total := w.Header().Get("X-Total-Count"); total != "5" {
+			t.Errorf("%q: X-Total-Count = %q, want 5", tt.query, total)
+		}
+	}
+}
@@ -16,11 +16,19 @@ func handleLogin1Error(w http.ResponseWriter, err error) {
 
 	var capErr *login1.CapabilityError
 	if errors.As(err, &capErr) {
-		http.Error(w, err.Error(), http.StatusForbidden)
+		writeError(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if errors.Is(err, login1.ErrNoPendingAction) {
+		writeError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, login1.ErrActionPending) {
+		writeError(w, err.Error(), http.StatusConflict)
 		return
 	}
 
-	http.Error(w, err.Error(), http.StatusInternalServerError)
+	writeError(w, err.Error(), http.StatusInternalServerError)
 }
 
 // withLogin1 wraps a no-arg login1 action into an http.HandlerFunc.
@@ -29,3 +37,29 @@ func withLogin1(fn func() error) http.HandlerFunc {
 		handleLogin1Error(w, fn())
 	}
 }
+
+// withLogin1Request wraps a power action honouring power.graceperiod: a
+// scheduled action answers 202 with its PendingAction, whose token
+// DELETE /power/pending takes.
+func withLogin1Request(fn func() (*login1.PendingAction, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		pending, err := fn()
+		if err != nil || pending == nil {
+			handleLogin1Error(w, err)
+			return
+		}
+		writeJSON(w, http.StatusAccepted, pending)
+	}
+}
+
+// CancelPendingHandler cancels the pending power action issued ?token=.
+func CancelPendingHandler(cancel func(token string) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("token")
+		if token == "" {
+			writeError(w, "missing token", http.StatusBadRequest)
+			return
+		}
+		handleLogin1Error(w, cancel(token))
+	}
+}
@@ -1,15 +1,65 @@
 package api
 
 import (
-	"encoding/json"
+	"bytes"
+	"context"
 	"errors"
+	"fmt"
+	"maps"
+	"math"
 	"net/http"
 	"net/url"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
 
 	"github.com/b0bbywan/go-odio-api/backend/mpris"
+	"github.com/b0bbywan/go-odio-api/logger"
 )
 
+// PlayerController is the slice of the MPRIS backend the /players routes
+// depend on. *mpris.MPRISBackend satisfies it; tests supply in-memory fakes.
+type PlayerController interface {
+	ListPlayers() ([]mpris.Player, error)
+	GonePlayers() []mpris.Player
+	CacheUpdatedAt() time.Time
+	ResolveBusName(name string) (string, error)
+	GetPlayerFromCache(busName string) (*mpris.Player, error)
+	GetPlayerStatus(busName string) (*mpris.PlayerStatus, error)
+	GetPosition(busName string) (*mpris.PositionInfo, error)
+	GetTracklist(busName string) (*mpris.TracklistResponse, error)
+	RefreshPlayer(busName string) (*mpris.Player, error)
+	Artwork(artURL string) ([]byte, bool)
+	ActionAllowed(action string) bool
+
+	Play(busName string) error
+	Pause(busName string) error
+	PlayPause(busName string) error
+	Stop(busName string) error
+	Next(busName string) error
+	Previous(busName string) error
+	Seek(busName string, offset int64) error
+	SetPosition(busName, trackID string, position int64) error
+	SetVolume(busName string, volume float64) error
+	SetLoopStatus(busName string, status mpris.LoopStatus) error
+	SetShuffle(busName string, shuffle bool) error
+	SetFullscreen(busName string, on bool) error
+	SetRating(busName string, rating float64) error
+	ApplyState(busName string, req mpris.StateRequest) (*mpris.StateResult, error)
+	SetPriority(busName string, priority int) error
+
+	GoTo(busName, trackRef string) error
+	AddTrack(busName, uri, afterTrack string, setAsCurrent bool) error
+	RemoveTrack(busName, trackRef string) error
+
+	SubscribePlayer(busName string) chan mpris.Player
+	UnsubscribePlayer(busName string, ch chan mpris.Player)
+}
+
 // withPlayer extracts the busName and calls next
 func withPlayer(
 	next func(w http.ResponseWriter, r *http.Request, busName string),
@@ -20,6 +70,56 @@ func withPlayer(
 	}
 }
 
+// withPlayerName resolves a short player name in the {player} path parameter
+// (e.g. "spotify") to its full bus name before next reads it, so every player
+// route accepts both forms. Full bus names are passed through untouched.
+func withPlayerName(resolve func(string) (string, error), next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		busName, err := resolve(r.PathValue("player"))
+		if err != nil {
+			handleMPRISError(w, err)
+			return
+		}
+		r.SetPathValue("player", busName)
+		next(w, r)
+	}
+}
+
+// withAllowedAction rejects the request with 403 when mpris.allowedactions
+// forbids action, whatever the player itself supports.
+func withAllowedAction(allowed func(string) bool, action string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !allowed(action) {
+			writeError(w, "action "+action+" is not allowed", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// ListPlayersHandler serves the cached players, optionally narrowed by the
+// status, artist and album query parameters, then paginated by limit and
+// offset. Other parameters are ignored.
+func ListPlayersHandler(m PlayerController) http.HandlerFunc {
+	return JSONHandler(func(w http.ResponseWriter, r *http.Request) (any, error) {
+		players, err := m.ListPlayers()
+		if err != nil {
+			return nil, err
+		}
+		setCacheHeader(w, m.CacheUpdatedAt())
+		q := r.URL.Query()
+		if q.Get("include_gone") == "true" {
+			// removed players kept for debugging, flagged "gone": true
+			players = append(slices.Clone(players), m.GonePlayers()...)
+		}
+		return paginate(w, r, mpris.FilterPlayers(players, mpris.PlayerFilter{
+			Status: q.Get("status"),
+			Artist: q.Get("artist"),
+			Album:  q.Get("album"),
+		}))
+	})
+}
+
 // handleMPRISError handles MPRIS errors and returns the appropriate HTTP response
 func handleMPRISError(w http.ResponseWriter, err error) {
 	if err == nil {
@@ -30,80 +130,95 @@ func handleMPRISError(w http.ResponseWriter, err error) {
 	// Handle invalid busName errors
 	var invalidBusNameErr *mpris.InvalidBusNameError
 	if errors.As(err, &invalidBusNameErr) {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	// Handle validation errors
 	var validErr *mpris.ValidationError
 	if errors.As(err, &validErr) {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// A short name matching several instances: the client must pick one
+	var ambiguousErr *mpris.AmbiguousPlayerError
+	if errors.As(err, &ambiguousErr) {
+		writeError(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	// Refreshed too recently: tell the client when to come back
+	var rateErr *mpris.RefreshRateLimitedError
+	if errors.As(err, &rateErr) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(rateErr.RetryAfter.Seconds()))))
+		writeError(w, err.Error(), http.StatusTooManyRequests)
 		return
 	}
 
 	// Handle player not found errors
 	var notFoundErr *mpris.PlayerNotFoundError
 	if errors.As(err, &notFoundErr) {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, err.Error(), http.StatusNotFound)
 		return
 	}
 
 	// Tracklist unsupported: the resource doesn't exist for this player
 	var unsupportedErr *mpris.TracklistUnsupportedError
 	if errors.As(err, &unsupportedErr) {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, err.Error(), http.StatusNotFound)
 		return
 	}
 
 	// Handle capability errors
 	var capErr *mpris.CapabilityError
 	if errors.As(err, &capErr) {
-		http.Error(w, err.Error(), http.StatusForbidden)
+		writeError(w, err.Error(), http.StatusForbidden)
 		return
 	}
 
-	http.Error(w, err.Error(), http.StatusInternalServerError)
+	writeError(w, err.Error(), http.StatusInternalServerError)
 }
 
 // Handlers for simple actions
-func PlayHandler(m *mpris.MPRISBackend) http.HandlerFunc {
+func PlayHandler(m PlayerController) http.HandlerFunc {
 	return withPlayer(func(w http.ResponseWriter, r *http.Request, busName string) {
 		handleMPRISError(w, m.Play(busName))
 	})
 }
 
-func PauseHandler(m *mpris.MPRISBackend) http.HandlerFunc {
+func PauseHandler(m PlayerController) http.HandlerFunc {
 	return withPlayer(func(w http.ResponseWriter, r *http.Request, busName string) {
 		handleMPRISError(w, m.Pause(busName))
 	})
 }
 
-func PlayPauseHandler(m *mpris.MPRISBackend) http.HandlerFunc {
+func PlayPauseHandler(m PlayerController) http.HandlerFunc {
 	return withPlayer(func(w http.ResponseWriter, r *http.Request, busName string) {
 		handleMPRISError(w, m.PlayPause(busName))
 	})
 }
 
-func StopHandler(m *mpris.MPRISBackend) http.HandlerFunc {
+func StopHandler(m PlayerController) http.HandlerFunc {
 	return withPlayer(func(w http.ResponseWriter, r *http.Request, busName string) {
 		handleMPRISError(w, m.Stop(busName))
 	})
 }
 
-func NextHandler(m *mpris.MPRISBackend) http.HandlerFunc {
+func NextHandler(m PlayerController) http.HandlerFunc {
 	return withPlayer(func(w http.ResponseWriter, r *http.Request, busName string) {
 		handleMPRISError(w, m.Next(busName))
 	})
 }
 
-func PreviousHandler(m *mpris.MPRISBackend) http.HandlerFunc {
+func PreviousHandler(m PlayerController) http.HandlerFunc {
 	return withPlayer(func(w http.ResponseWriter, r *http.Request, busName string) {
 		handleMPRISError(w, m.Previous(busName))
 	})
 }
 
 // Handlers for actions with body
-func SeekHandler(m *mpris.MPRISBackend) http.HandlerFunc {
+func SeekHandler(m PlayerController) http.HandlerFunc {
 	return withPlayer(func(w http.ResponseWriter, r *http.Request, busName string) {
 		withBody(nil, func(w http.ResponseWriter, r *http.Request, req *mpris.SeekRequest) {
 			handleMPRISError(w, m.Seek(busName, req.Offset))
@@ -111,7 +226,7 @@ func SeekHandler(m *mpris.MPRISBackend) http.HandlerFunc {
 	})
 }
 
-func SetPositionHandler(m *mpris.MPRISBackend) http.HandlerFunc {
+func SetPositionHandler(m PlayerController) http.HandlerFunc {
 	return withPlayer(func(w http.ResponseWriter, r *http.Request, busName string) {
 		withBody(nil, func(w http.ResponseWriter, r *http.Request, req *mpris.PositionRequest) {
 			handleMPRISError(w, m.SetPosition(busName, req.TrackID, req.Position))
@@ -119,7 +234,7 @@ func SetPositionHandler(m *mpris.MPRISBackend) http.HandlerFunc {
 	})
 }
 
-func SetVolumeHandler(m *mpris.MPRISBackend) http.HandlerFunc {
+func SetVolumeHandler(m PlayerController) http.HandlerFunc {
 	return withPlayer(func(w http.ResponseWriter, r *http.Request, busName string) {
 		withBody(nil, func(w http.ResponseWriter, r *http.Request, req *mpris.VolumeRequest) {
 			handleMPRISError(w, m.SetVolume(busName, req.Volume))
@@ -127,7 +242,7 @@ func SetVolumeHandler(m *mpris.MPRISBackend) http.HandlerFunc {
 	})
 }
 
-func SetLoopHandler(m *mpris.MPRISBackend) http.HandlerFunc {
+func SetLoopHandler(m PlayerController) http.HandlerFunc {
 	return withPlayer(func(w http.ResponseWriter, r *http.Request, busName string) {
 		withBody(nil, func(w http.ResponseWriter, r *http.Request, req *mpris.LoopRequest) {
 			handleMPRISError(w, m.SetLoopStatus(busName, mpris.LoopStatus(req.Loop)))
@@ -135,7 +250,7 @@ func SetLoopHandler(m *mpris.MPRISBackend) http.HandlerFunc {
 	})
 }
 
-func SetShuffleHandler(m *mpris.MPRISBackend) http.HandlerFunc {
+func SetShuffleHandler(m PlayerController) http.HandlerFunc {
 	return withPlayer(func(w http.ResponseWriter, r *http.Request, busName string) {
 		withBody(nil, func(w http.ResponseWriter, r *http.Request, req *mpris.ShuffleRequest) {
 			handleMPRISError(w, m.SetShuffle(busName, req.Shuffle))
@@ -143,6 +258,59 @@ func SetShuffleHandler(m *mpris.MPRISBackend) http.HandlerFunc {
 	})
 }
 
+func SetFullscreenHandler(m PlayerController) http.HandlerFunc {
+	return withPlayer(func(w http.ResponseWriter, r *http.Request, busName string) {
+		withBody(nil, func(w http.ResponseWriter, r *http.Request, req *mpris.FullscreenRequest) {
+			handleMPRISError(w, m.SetFullscreen(busName, req.Enabled))
+		})(w, r)
+	})
+}
+
+func SetRatingHandler(m PlayerController) http.HandlerFunc {
+	return withPlayer(func(w http.ResponseWriter, r *http.Request, busName string) {
+		withBody(nil, func(w http.ResponseWriter, r *http.Request, req *mpris.RatingRequest) {
+			handleMPRISError(w, m.SetRating(busName, req.Rating))
+		})(w, r)
+	})
+}
+
+// SetStateHandler applies a partial {volume, loop, shuffle} body. Each field
+// present must be an allowed action; the response is the reloaded player and
+// the per-field outcome.
+func SetStateHandler(m PlayerController) http.HandlerFunc {
+	return withPlayer(func(w http.ResponseWriter, r *http.Request, busName string) {
+		withBody(nil, func(w http.ResponseWriter, r *http.Request, req *mpris.StateRequest) {
+			for _, f := range []struct {
+				set    bool
+				action string
+			}{
+				{req.Volume != nil, mpris.ActionVolume},
+				{req.Loop != nil, mpris.ActionLoop},
+				{req.Shuffle != nil, mpris.ActionShuffle},
+			} {
+				if f.set && !m.ActionAllowed(f.action) {
+					writeError(w, "action "+f.action+" is not allowed", http.StatusForbidden)
+					return
+				}
+			}
+			result, err := m.ApplyState(busName, *req)
+			if err != nil {
+				handleMPRISError(w, err)
+				return
+			}
+			writeJSON(w, http.StatusOK, result)
+		})(w, r)
+	})
+}
+
+func SetPriorityHandler(m PlayerController) http.HandlerFunc {
+	return withPlayer(func(w http.ResponseWriter, r *http.Request, busName string) {
+		withBody(nil, func(w http.ResponseWriter, r *http.Request, req *mpris.PriorityRequest) {
+			handleMPRISError(w, m.SetPriority(busName, req.Priority))
+		})(w, r)
+	})
+}
+
 // withTrack extracts the {trackid} parameter: the last segment of a track's
 // object path (or the %2F-encoded full path), resolved against the cached
 // tracklist by the backend.
@@ -162,26 +330,23 @@ func TracklistHandler(getTracklist func(string) (*mpris.TracklistResponse, error
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(resp); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
+		writeJSON(w, http.StatusOK, resp)
 	})
 }
 
-func GoToHandler(m *mpris.MPRISBackend) http.HandlerFunc {
+func GoToHandler(m PlayerController) http.HandlerFunc {
 	return withTrack(func(w http.ResponseWriter, r *http.Request, busName, trackID string) {
 		handleMPRISError(w, m.GoTo(busName, trackID))
 	})
 }
 
-func RemoveTrackHandler(m *mpris.MPRISBackend) http.HandlerFunc {
+func RemoveTrackHandler(m PlayerController) http.HandlerFunc {
 	return withTrack(func(w http.ResponseWriter, r *http.Request, busName, trackID string) {
 		handleMPRISError(w, m.RemoveTrack(busName, trackID))
 	})
 }
 
-func AddTrackHandler(m *mpris.MPRISBackend) http.HandlerFunc {
+func AddTrackHandler(m PlayerController) http.HandlerFunc {
 	return withPlayer(func(w http.ResponseWriter, r *http.Request, busName string) {
 		withBody(nil, func(w http.ResponseWriter, r *http.Request, req *mpris.AddTrackRequest) {
 			handleMPRISError(w, m.AddTrack(busName, req.Uri, req.AfterTrack, req.SetAsCurrent))
@@ -189,7 +354,105 @@ func AddTrackHandler(m *mpris.MPRISBackend) http.HandlerFunc {
 	})
 }
 
-func CoverHandler(getPlayer func(string) (*mpris.Player, error)) http.HandlerFunc {
+// PositionHandler serves a player's current position and track length from
+// the cache, for clients polling playback progress.
+func PositionHandler(getPosition func(string) (*mpris.PositionInfo, error)) http.HandlerFunc {
+	return withPlayer(func(w http.ResponseWriter, r *http.Request, busName string) {
+		info, err := getPosition(busName)
+		if err != nil {
+			handleMPRISError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, info)
+	})
+}
+
+// PlayerCapabilitiesHandler serves only a cached player's capabilities, for
+// minimal clients deciding which controls to show.
+func PlayerCapabilitiesHandler(getPlayer func(string) (*mpris.Player, error)) http.HandlerFunc {
+	return withPlayer(func(w http.ResponseWriter, r *http.Request, busName string) {
+		player, err := getPlayer(busName)
+		if err != nil {
+			handleMPRISError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, player.Capabilities)
+	})
+}
+
+// PlayerMetadataHandler serves only a cached player's metadata map, {} when
+// nothing is playing.
+func PlayerMetadataHandler(getPlayer func(string) (*mpris.Player, error)) http.HandlerFunc {
+	return withPlayer(func(w http.ResponseWriter, r *http.Request, busName string) {
+		player, err := getPlayer(busName)
+		if err != nil {
+			handleMPRISError(w, err)
+			return
+		}
+
+		metadata := player.Metadata
+		if metadata == nil {
+			metadata = map[string]string{}
+		}
+		writeJSON(w, http.StatusOK, metadata)
+	})
+}
+
+// PlayerMetadataKeysHandler serves the sorted metadata keys a cached player
+// provides, [] when nothing is playing, so clients know which fields to expect.
+func PlayerMetadataKeysHandler(getPlayer func(string) (*mpris.Player, error)) http.HandlerFunc {
+	return withPlayer(func(w http.ResponseWriter, r *http.Request, busName string) {
+		player, err := getPlayer(busName)
+		if err != nil {
+			handleMPRISError(w, err)
+			return
+		}
+
+		keys := slices.Sorted(maps.Keys(player.Metadata))
+		if keys == nil {
+			keys = []string{}
+		}
+		writeJSON(w, http.StatusOK, keys)
+	})
+}
+
+// PlayerStatusHandler serves a player's playback status, volume, position and
+// loop status from the cache.
+func PlayerStatusHandler(getStatus func(string) (*mpris.PlayerStatus, error)) http.HandlerFunc {
+	return withPlayer(func(w http.ResponseWriter, r *http.Request, busName string) {
+		status, err := getStatus(busName)
+		if err != nil {
+			handleMPRISError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, status)
+	})
+}
+
+// RefreshPlayerHandler reloads a player from D-Bus, bypassing the cache, and
+// serves the fresh player.
+func RefreshPlayerHandler(refresh func(string) (*mpris.Player, error)) http.HandlerFunc {
+	return withPlayer(func(w http.ResponseWriter, r *http.Request, busName string) {
+		player, err := refresh(busName)
+		if err != nil {
+			handleMPRISError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, player)
+	})
+}
+
+// CoverHandler serves the current track's artwork: local files from the
+// prefetched artwork cache when present, else from disk; remote URLs are
+// redirected to.
+func CoverHandler(
+	getPlayer func(string) (*mpris.Player, error),
+	artwork func(string) ([]byte, bool),
+) http.HandlerFunc {
 	return withPlayer(func(w http.ResponseWriter, r *http.Request, busName string) {
 		player, err := getPlayer(busName)
 		if err != nil {
@@ -202,15 +465,16 @@ func CoverHandler(getPlayer func(string) (*mpris.Player, error)) http.HandlerFun
 		case artUrl == "":
 			http.NotFound(w, r)
 		case strings.HasPrefix(artUrl, "file://"):
-			// Standards-compliant MPRIS daemons percent-encode reserved
-			// characters in file:// URIs (RFC 3986). Parse to recover the
-			// decoded filesystem path.
-			u, err := url.Parse(artUrl)
-			if err != nil {
+			path, data, ok := localArtwork(artUrl, artwork)
+			if !ok {
 				http.NotFound(w, r)
 				return
 			}
-			http.ServeFile(w, r, u.Path)
+			if data != nil {
+				http.ServeContent(w, r, filepath.Base(path), time.Time{}, bytes.NewReader(data))
+				return
+			}
+			http.ServeFile(w, r, path)
 		case strings.HasPrefix(artUrl, "http://"), strings.HasPrefix(artUrl, "https://"):
 			http.Redirect(w, r, artUrl, http.StatusTemporaryRedirect)
 		default:
@@ -218,3 +482,95 @@ func CoverHandler(getPlayer func(string) (*mpris.Player, error)) http.HandlerFun
 		}
 	})
 }
+
+// localArtwork resolves a file:// artUrl to its path on disk, with its bytes
+// when the backend prefetched them (nil otherwise).
+func localArtwork(artUrl string, artwork func(string) ([]byte, bool)) (string, []byte, bool) {
+	path, ok := mpris.ArtworkPath(artUrl)
+	if !ok {
+		return "", nil, false
+	}
+	if data, ok := artwork(artUrl); ok {
+		return path, data, true
+	}
+	return path, nil, true
+}
+
+// PlayerWSHandler streams one player over a WebSocket: the cached player
+// first, then the full player after each property change. The subscription is
+// taken before the cache is read, so no change falls in between. The socket
+// closes when the player goes away or the client disconnects. Browsers must
+// come from the API's own host or a configured CORS origin; clients sending
+// no Origin (CLIs, hardware remotes) are accepted.
+func PlayerWSHandler(p PlayerController, origins []string) http.HandlerFunc {
+	return withPlayer(func(w http.ResponseWriter, r *http.Request, busName string) {
+		// Answer an unknown player with a plain 404 before upgrading.
+		if _, err := p.GetPlayerFromCache(busName); err != nil {
+			handleMPRISError(w, err)
+			return
+		}
+
+		ws := websocket.Server{
+			Handshake: func(_ *websocket.Config, r *http.Request) error {
+				return checkWSOrigin(r, origins)
+			},
+			Handler: func(conn *websocket.Conn) {
+				ch := p.SubscribePlayer(busName)
+				defer p.UnsubscribePlayer(busName, ch)
+				player, err := p.GetPlayerFromCache(busName)
+				if err != nil {
+					// Gone since the check: its subscribers were closed already.
+					return
+				}
+				streamPlayer(r.Context(), conn, *player, ch)
+			},
+		}
+		ws.ServeHTTP(w, r)
+	})
+}
+
+// streamPlayer sends first then every player of ch as a JSON text message,
+// until ch closes, the client goes away or ctx ends.
+func streamPlayer(ctx context.Context, conn *websocket.Conn, first mpris.Player, ch <-chan mpris.Player) {
+	// Incoming frames are ignored; reading only detects the client closing.
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		var msg []byte
+		for websocket.Message.Receive(conn, &msg) == nil {
+		}
+	}()
+
+	if err := websocket.JSON.Send(conn, first); err != nil {
+		return
+	}
+	for {
+		select {
+		case player, ok := <-ch:
+			if !ok {
+				return
+			}
+			if err := websocket.JSON.Send(conn, player); err != nil {
+				logger.Debug("[api] player websocket closed: %v", err)
+				return
+			}
+		case <-gone:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// checkWSOrigin rejects cross-site WebSocket handshakes, which CORS does not
+// cover: the Origin must be absent, the API's own host or an allowed origin.
+func checkWSOrigin(r *http.Request, origins []string) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" || slices.Contains(origins, "*") || slices.Contains(origins, origin) {
+		return nil
+	}
+	if u, err := url.Parse(origin); err == nil && u.Host == r.Host {
+		return nil
+	}
+	return fmt.Errorf("origin %s not allowed", origin)
+}
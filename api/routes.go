@@ -4,12 +4,14 @@ import (
 	"net/http"
 
 	"github.com/b0bbywan/go-odio-api/backend"
+	"github.com/b0bbywan/go-odio-api/backend/alsa"
 	"github.com/b0bbywan/go-odio-api/backend/bluetooth"
 	"github.com/b0bbywan/go-odio-api/backend/login1"
 	"github.com/b0bbywan/go-odio-api/backend/mpris"
-	"github.com/b0bbywan/go-odio-api/backend/pulseaudio"
+	"github.com/b0bbywan/go-odio-api/backend/snapcast"
 	"github.com/b0bbywan/go-odio-api/backend/systemd"
 	"github.com/b0bbywan/go-odio-api/logger"
+	"github.com/b0bbywan/go-odio-api/plugin"
 	"github.com/b0bbywan/go-odio-api/ui"
 )
 
@@ -17,10 +19,71 @@ func (s *Server) registerServerRoutes(b *backend.Backend) {
 	s.mux.HandleFunc(
 		"/server",
 		JSONHandler(func(w http.ResponseWriter, r *http.Request) (any, error) {
-			return b.GetServerDeviceInfo()
+			info, err := b.GetServerDeviceInfo()
+			if err != nil {
+				return nil, err
+			}
+			info.ReadOnly = s.config.ReadOnly
+			return info, nil
 		}),
 	)
 
+	s.mux.HandleFunc(
+		"GET /server/diagnostics",
+		JSONHandler(func(w http.ResponseWriter, r *http.Request) (any, error) {
+			diag := b.Diagnostics()
+			diag.Listens = s.config.Listens
+			return diag, nil
+		}),
+	)
+
+	s.mux.HandleFunc(
+		"GET /server/network",
+		JSONHandler(func(w http.ResponseWriter, r *http.Request) (any, error) {
+			network := b.GetNetworkInfo()
+			if s.config.Listens != nil {
+				network.Listens = s.config.Listens
+			}
+			return network, nil
+		}),
+	)
+
+	s.mux.HandleFunc(
+		"POST /server/cache/refresh",
+		JSONHandler(func(w http.ResponseWriter, r *http.Request) (any, error) {
+			return b.RefreshCaches(), nil
+		}),
+	)
+
+	s.mux.HandleFunc(
+		"GET /healthz",
+		HealthHandler(b.Connections),
+	)
+
+	if s.config.Debug != nil {
+		s.mux.HandleFunc(
+			"GET /debug/state",
+			DebugStateHandler(s.config.Debug.Settings, s.started, b.State),
+		)
+		s.mux.HandleFunc(
+			"GET /debug/cache/export",
+			JSONHandler(func(w http.ResponseWriter, r *http.Request) (any, error) {
+				return b.State(), nil
+			}),
+		)
+		s.mux.HandleFunc(
+			"POST /debug/cache/import",
+			CacheImportHandler(b.ImportState),
+		)
+		if b.Zeroconf != nil {
+			s.mux.HandleFunc(
+				"POST /zeroconf/txt",
+				ZeroconfTxtHandler(b.Zeroconf.UpdateTxtRecords),
+			)
+		}
+		logger.Warn("[api] debug routes registered at /debug, disable api.debug in production")
+	}
+
 	// SSE event stream
 	if s.sse {
 		s.mux.HandleFunc("GET /events", sseHandler(s.broadcaster))
@@ -29,7 +92,14 @@ func (s *Server) registerServerRoutes(b *backend.Backend) {
 }
 
 func (s *Server) registerUIRoutes() {
-	uiHandler := ui.NewHandler(s.config.Port, s.broadcaster)
+	uiHandler := ui.NewHandler(
+		s.config.Port,
+		s.config.UI.RefreshInterval,
+		s.config.UI.ClientTimeout,
+		s.config.UI.Locale,
+		s.config.UI.BasePath,
+		s.broadcaster,
+	)
 	uiHandler.RegisterRoutes(s.mux)
 	logger.Info("[api] UI routes registered at /ui")
 }
@@ -53,12 +123,40 @@ func (s *Server) registerBluetoothRoutes(b *bluetooth.BluetoothBackend) {
 		"POST /bluetooth/pairing_mode",
 		withBluetoothAction(b.NewPairing),
 	)
+	s.mux.HandleFunc(
+		"POST /bluetooth/pairing",
+		PairingHandler(b.NewPairing, b.GetStatus),
+	)
+	s.mux.HandleFunc(
+		"GET /bluetooth/pairing/pending",
+		JSONHandler(func(w http.ResponseWriter, r *http.Request) (any, error) {
+			pending, ok := b.PendingConfirmation()
+			if !ok {
+				return nil, httpError(http.StatusNotFound, bluetooth.ErrNoPendingConfirmation)
+			}
+			return pending, nil
+		}),
+	)
+	s.mux.HandleFunc(
+		"POST /bluetooth/pairing/confirm",
+		withBluetoothConfirm(b.ConfirmPairing),
+	)
+	s.mux.HandleFunc(
+		"POST /bluetooth/discoverable",
+		withBluetoothDiscoverable(b.SetDiscoverableOnly),
+	)
 	s.mux.HandleFunc(
 		"GET /bluetooth/devices",
 		JSONHandler(func(w http.ResponseWriter, r *http.Request) (any, error) {
 			return b.GetDevices(), nil
 		}),
 	)
+	s.mux.HandleFunc(
+		"GET /bluetooth/policy",
+		JSONHandler(func(w http.ResponseWriter, r *http.Request) (any, error) {
+			return b.Policy(), nil
+		}),
+	)
 	s.mux.HandleFunc(
 		"POST /bluetooth/scan",
 		withBluetoothAction(b.StartScan),
@@ -75,29 +173,52 @@ func (s *Server) registerBluetoothRoutes(b *bluetooth.BluetoothBackend) {
 		"POST /bluetooth/disconnect",
 		withBluetoothAddress(b.Disconnect),
 	)
+	s.mux.HandleFunc(
+		"PATCH /bluetooth/devices/{address}",
+		withBluetoothRename(b.RenameDevice),
+	)
+	s.mux.HandleFunc(
+		"POST /bluetooth/devices/{address}/profile",
+		withBluetoothProfile(b.SetDeviceProfile),
+	)
+	s.mux.HandleFunc(
+		"GET /bluetooth/devices/{address}/wait",
+		BluetoothWaitHandler(b.WaitConnected),
+	)
 }
 
 func (s *Server) registerLogin1Routes(b *login1.Login1Backend) {
 	s.mux.HandleFunc(
 		"/power",
 		JSONHandler(func(w http.ResponseWriter, r *http.Request) (any, error) {
-			return map[string]bool{
-				"reboot":    b.CanReboot,
-				"power_off": b.CanPoweroff,
-			}, nil
+			return b.Capabilities(), nil
 		}),
 	)
 	s.mux.HandleFunc(
 		"POST /power/reboot",
-		withLogin1(b.Reboot),
+		withLogin1Request(b.RequestReboot),
 	)
 	s.mux.HandleFunc(
 		"POST /power/power_off",
-		withLogin1(b.PowerOff),
+		withLogin1Request(b.RequestPowerOff),
+	)
+	s.mux.HandleFunc(
+		"GET /power/pending",
+		JSONHandler(func(w http.ResponseWriter, r *http.Request) (any, error) {
+			pending, ok := b.Pending()
+			if !ok {
+				return nil, httpError(http.StatusNotFound, login1.ErrNoPendingAction)
+			}
+			return pending, nil
+		}),
+	)
+	s.mux.HandleFunc(
+		"DELETE /power/pending",
+		CancelPendingHandler(b.CancelPending),
 	)
 }
 
-func (s *Server) registerPulseRoutes(b *pulseaudio.PulseAudioBackend) {
+func (s *Server) registerPulseRoutes(b AudioController) {
 	s.mux.HandleFunc(
 		"GET /audio",
 		AudioHandler(b),
@@ -112,6 +233,10 @@ func (s *Server) registerPulseRoutes(b *pulseaudio.PulseAudioBackend) {
 			return b.ServerInfo()
 		}),
 	)
+	s.mux.HandleFunc(
+		"GET /audio/state",
+		AudioStateHandler(b.ServerInfo, b.ListClients),
+	)
 	s.mux.HandleFunc(
 		"POST /audio/server/mute",
 		MuteMasterHandler(b),
@@ -122,7 +247,15 @@ func (s *Server) registerPulseRoutes(b *pulseaudio.PulseAudioBackend) {
 	)
 	s.mux.HandleFunc(
 		"/audio/clients",
-		listHandler(b.ListClients, b.CacheUpdatedAt),
+		ListClientsHandler(b),
+	)
+	s.mux.HandleFunc(
+		"GET /audio/clients/{sink}",
+		ClientHandler(b.GetClient, b.RefreshClient),
+	)
+	s.mux.HandleFunc(
+		"GET /audio/clients/{sink}/volume",
+		ClientVolumeHandler(b.GetClient),
 	)
 	s.mux.HandleFunc(
 		"POST /audio/clients/{sink}/mute",
@@ -132,6 +265,10 @@ func (s *Server) registerPulseRoutes(b *pulseaudio.PulseAudioBackend) {
 		"POST /audio/clients/{sink}/volume",
 		SetVolumeClientHandler(b),
 	)
+	s.mux.HandleFunc(
+		"POST /audio/clients/{sink}/normalize",
+		NormalizeClientHandler(b),
+	)
 	s.mux.HandleFunc(
 		"/audio/outputs",
 		listHandler(b.ListOutputs, b.OutputCacheUpdatedAt),
@@ -148,6 +285,57 @@ func (s *Server) registerPulseRoutes(b *pulseaudio.PulseAudioBackend) {
 		"POST /audio/outputs/{output}/volume",
 		SetVolumeOutputHandler(b),
 	)
+	s.mux.HandleFunc(
+		"POST /audio/loopbacks",
+		CreateLoopbackHandler(b.CreateLoopback),
+	)
+	s.mux.HandleFunc(
+		"DELETE /audio/loopbacks/{module_index}",
+		RemoveLoopbackHandler(b.RemoveLoopback),
+	)
+}
+
+func (s *Server) registerALSARoutes(b *alsa.ALSABackend) {
+	s.mux.HandleFunc(
+		"GET /audio/cards",
+		JSONHandler(func(w http.ResponseWriter, r *http.Request) (any, error) {
+			return b.ListCards()
+		}),
+	)
+}
+
+func (s *Server) registerSnapcastRoutes(b *snapcast.SnapcastBackend) {
+	s.mux.HandleFunc(
+		"GET /snapcast/clients",
+		ListSnapcastClientsHandler(b),
+	)
+	s.mux.HandleFunc(
+		"GET /snapcast/clients/{client}",
+		SnapcastClientHandler(b),
+	)
+	s.mux.HandleFunc(
+		"POST /snapcast/clients/{client}/volume",
+		SetSnapcastVolumeHandler(b),
+	)
+	s.mux.HandleFunc(
+		"POST /snapcast/clients/{client}/mute",
+		SetSnapcastMuteHandler(b),
+	)
+}
+
+// registerWatchedRoute serves the watched units whether or not the systemd
+// backend runs: without any unit configured systemd.New disables it, and
+// clients then get empty lists instead of a 404.
+func (s *Server) registerWatchedRoute(b *systemd.SystemdBackend) {
+	s.mux.HandleFunc(
+		"GET /services/watched",
+		JSONHandler(func(w http.ResponseWriter, r *http.Request) (any, error) {
+			if b == nil {
+				return systemd.WatchedUnits{System: []string{}, User: []string{}}, nil
+			}
+			return b.WatchedUnits(), nil
+		}),
+	)
 }
 
 func (s *Server) registerSystemdRoutes(b *systemd.SystemdBackend) {
@@ -155,6 +343,14 @@ func (s *Server) registerSystemdRoutes(b *systemd.SystemdBackend) {
 		"/services",
 		listHandler(b.PublicServices, b.CacheUpdatedAt),
 	)
+	s.mux.HandleFunc(
+		"GET /services/{scope}",
+		ScopeServicesHandler(b.ListServicesByScope, b.CacheUpdatedAt),
+	)
+	s.mux.HandleFunc(
+		"GET /services/{scope}/{unit}/logs/stream",
+		ServiceLogStreamHandler(b.FollowJournal, b.LogStreamMax()),
+	)
 	s.mux.HandleFunc(
 		"POST /services/{scope}/{unit}/enable",
 		withService(b, b.EnableService),
@@ -169,81 +365,181 @@ func (s *Server) registerSystemdRoutes(b *systemd.SystemdBackend) {
 	)
 	s.mux.HandleFunc(
 		"POST /services/{scope}/{unit}/stop",
-		withService(b, b.StopService),
+		StopServiceHandler(b),
 	)
 	s.mux.HandleFunc(
 		"POST /services/{scope}/{unit}/restart",
 		withService(b, b.RestartService),
 	)
+	s.mux.HandleFunc(
+		"POST /services/{scope}/batch",
+		ServiceBatchHandler(b, map[string]func(string, systemd.UnitScope) error{
+			"enable":  b.EnableService,
+			"disable": b.DisableService,
+			"start":   b.StartService,
+			"stop":    b.StopService,
+			"restart": b.RestartService,
+		}),
+	)
+	s.mux.HandleFunc(
+		"POST /services/{scope}/restart-all",
+		ServiceRestartAllHandler(b.ScopeUnits, b.RestartService),
+	)
 }
 
-func (s *Server) registerMPRISRoutes(b *mpris.MPRISBackend) {
+func (s *Server) registerMPRISRoutes(b PlayerController) {
 	s.mux.HandleFunc(
 		"/players",
-		listHandler(b.ListPlayers, b.CacheUpdatedAt),
+		ListPlayersHandler(b),
 	)
-	s.mux.HandleFunc(
+
+	// Every /players/{player} route also accepts the short player name.
+	handlePlayer := func(pattern string, handler http.HandlerFunc) {
+		s.mux.HandleFunc(pattern, withPlayerName(b.ResolveBusName, handler))
+	}
+	handlePlayer(
 		"GET /players/{player}/cover",
-		CoverHandler(b.GetPlayerFromCache),
+		CoverHandler(b.GetPlayerFromCache, b.Artwork),
 	)
-	s.mux.HandleFunc(
+	handlePlayer(
+		"GET /players/{player}/art-colors",
+		ArtColorsHandler(b.GetPlayerFromCache, b.Artwork, s.remoteArtwork()),
+	)
+	handlePlayer(
+		"GET /players/{player}/capabilities",
+		PlayerCapabilitiesHandler(b.GetPlayerFromCache),
+	)
+	handlePlayer(
+		"GET /players/{player}/metadata",
+		PlayerMetadataHandler(b.GetPlayerFromCache),
+	)
+	handlePlayer(
+		"GET /players/{player}/metadata/keys",
+		PlayerMetadataKeysHandler(b.GetPlayerFromCache),
+	)
+	handlePlayer(
+		"GET /players/{player}/status",
+		PlayerStatusHandler(b.GetPlayerStatus),
+	)
+	handlePlayer(
+		"POST /players/{player}/refresh",
+		RefreshPlayerHandler(b.RefreshPlayer),
+	)
+	handlePlayer(
 		"POST /players/{player}/play",
-		PlayHandler(b),
+		withAllowedAction(b.ActionAllowed, mpris.ActionPlay, PlayHandler(b)),
 	)
-	s.mux.HandleFunc(
+	handlePlayer(
 		"POST /players/{player}/pause",
-		PauseHandler(b),
+		withAllowedAction(b.ActionAllowed, mpris.ActionPause, PauseHandler(b)),
 	)
-	s.mux.HandleFunc(
+	handlePlayer(
 		"POST /players/{player}/play_pause",
-		PlayPauseHandler(b),
+		withAllowedAction(b.ActionAllowed, mpris.ActionPlayPause, PlayPauseHandler(b)),
 	)
-	s.mux.HandleFunc(
+	handlePlayer(
 		"POST /players/{player}/stop",
-		StopHandler(b),
+		withAllowedAction(b.ActionAllowed, mpris.ActionStop, StopHandler(b)),
 	)
-	s.mux.HandleFunc(
+	handlePlayer(
 		"POST /players/{player}/next",
-		NextHandler(b),
+		withAllowedAction(b.ActionAllowed, mpris.ActionNext, NextHandler(b)),
 	)
-	s.mux.HandleFunc(
+	handlePlayer(
 		"POST /players/{player}/previous",
-		PreviousHandler(b),
+		withAllowedAction(b.ActionAllowed, mpris.ActionPrevious, PreviousHandler(b)),
 	)
-	s.mux.HandleFunc(
+	handlePlayer(
 		"POST /players/{player}/seek",
-		SeekHandler(b),
+		withAllowedAction(b.ActionAllowed, mpris.ActionSeek, SeekHandler(b)),
 	)
-	s.mux.HandleFunc(
+	handlePlayer(
+		"GET /players/{player}/position",
+		PositionHandler(b.GetPosition),
+	)
+	handlePlayer(
 		"POST /players/{player}/position",
-		SetPositionHandler(b),
+		withAllowedAction(b.ActionAllowed, mpris.ActionPosition, SetPositionHandler(b)),
 	)
-	s.mux.HandleFunc(
+	handlePlayer(
 		"POST /players/{player}/volume",
-		SetVolumeHandler(b),
+		withAllowedAction(b.ActionAllowed, mpris.ActionVolume, SetVolumeHandler(b)),
 	)
-	s.mux.HandleFunc(
+	handlePlayer(
 		"POST /players/{player}/loop",
-		SetLoopHandler(b),
+		withAllowedAction(b.ActionAllowed, mpris.ActionLoop, SetLoopHandler(b)),
 	)
-	s.mux.HandleFunc(
+	handlePlayer(
 		"POST /players/{player}/shuffle",
-		SetShuffleHandler(b),
+		withAllowedAction(b.ActionAllowed, mpris.ActionShuffle, SetShuffleHandler(b)),
 	)
-	s.mux.HandleFunc(
+	handlePlayer(
+		"POST /players/{player}/fullscreen",
+		withAllowedAction(b.ActionAllowed, mpris.ActionFullscreen, SetFullscreenHandler(b)),
+	)
+	handlePlayer(
+		"POST /players/{player}/rating",
+		withAllowedAction(b.ActionAllowed, mpris.ActionRating, SetRatingHandler(b)),
+	)
+	handlePlayer(
+		"POST /players/{player}/state",
+		SetStateHandler(b),
+	)
+	handlePlayer(
+		"POST /players/{player}/priority",
+		SetPriorityHandler(b),
+	)
+	handlePlayer(
 		"GET /players/{player}/tracklist",
 		TracklistHandler(b.GetTracklist),
 	)
-	s.mux.HandleFunc(
+	handlePlayer(
 		"POST /players/{player}/tracklist/goto/{trackid}",
-		GoToHandler(b),
+		withAllowedAction(b.ActionAllowed, mpris.ActionTracklist, GoToHandler(b)),
 	)
-	s.mux.HandleFunc(
+	handlePlayer(
 		"POST /players/{player}/tracklist/add",
-		AddTrackHandler(b),
+		withAllowedAction(b.ActionAllowed, mpris.ActionTracklist, AddTrackHandler(b)),
 	)
-	s.mux.HandleFunc(
+	handlePlayer(
 		"POST /players/{player}/tracklist/remove/{trackid}",
-		RemoveTrackHandler(b),
+		withAllowedAction(b.ActionAllowed, mpris.ActionTracklist, RemoveTrackHandler(b)),
+	)
+
+	// Per-player live state over WebSocket
+	handlePlayer(
+		"GET /ws/players/{player}",
+		PlayerWSHandler(b, s.corsOrigins()),
 	)
+
+	// Per-player event stream and recent history
+	if s.sse {
+		handlePlayer(
+			"GET /players/{player}/events",
+			PlayerEventsHandler(s.broadcaster.History),
+		)
+		handlePlayer(
+			"GET /players/{player}/events/stream",
+			playerSSEHandler(s.broadcaster),
+		)
+	}
+}
+
+func (s *Server) registerPluginRoutes(p plugin.Backend) {
+	for _, route := range p.Routes() {
+		s.registerPluginRoute(route)
+	}
+}
+
+// registerPluginRoute mounts one plugin route. ServeMux panics on a pattern
+// that is invalid or clashes with a registered one; such a route is skipped
+// rather than taking the server down.
+func (s *Server) registerPluginRoute(route plugin.Route) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("[api] plugin route %q not registered: %v", route.Pattern, r)
+		}
+	}()
+	s.mux.HandleFunc(route.Pattern, route.Handler)
+	logger.Info("[api] plugin route registered at %s", route.Pattern)
 }
@@ -2,13 +2,20 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"os"
 	"slices"
+	"strings"
 	"sync"
+	"syscall"
 	"time"
 
+	"github.com/coreos/go-systemd/v22/activation"
+	"golang.org/x/sync/errgroup"
+
 	"github.com/b0bbywan/go-odio-api/backend"
 	"github.com/b0bbywan/go-odio-api/config"
 	"github.com/b0bbywan/go-odio-api/logger"
@@ -20,6 +27,15 @@ type Server struct {
 	ui          bool
 	sse         bool
 	broadcaster *backend.Broadcaster
+	started     time.Time
+
+	// http servers of the current Run, drained by Shutdown
+	mu      sync.Mutex
+	servers []*http.Server
+	// closed by Shutdown: ends event streams, which would otherwise hold the
+	// drain open until its timeout
+	draining     chan struct{}
+	drainingOnce sync.Once
 }
 
 func NewServer(cfg *config.ApiConfig, b *backend.Backend) *Server {
@@ -38,6 +54,8 @@ func NewServer(cfg *config.ApiConfig, b *backend.Backend) *Server {
 		ui:          cfg.UI != nil && cfg.UI.Enabled,
 		sse:         cfg.SSE != nil && cfg.SSE.Enabled,
 		broadcaster: broadcaster,
+		started:     time.Now(),
+		draining:    make(chan struct{}),
 	}
 	server.register(b)
 	return server
@@ -45,14 +63,29 @@ func NewServer(cfg *config.ApiConfig, b *backend.Backend) *Server {
 
 func (s *Server) Run(ctx context.Context) error {
 	var handler http.Handler = s.mux
+	if s.config.ReadOnly {
+		handler = readOnlyMiddleware(handler)
+	}
+	if s.config.MaxBodyBytes > 0 {
+		handler = maxBodyMiddleware(s.config.MaxBodyBytes)(handler)
+	}
 	if s.config.CORS != nil {
 		handler = corsMiddleware(s.config.CORS)(handler)
 	}
+	if s.config.Envelope {
+		handler = envelopeMiddleware(handler)
+	}
+	handler = drainMiddleware(s.draining)(handler)
+
+	listeners, err := s.listen()
+	if err != nil {
+		return err
+	}
 
-	servers := make([]*http.Server, len(s.config.Listens))
-	for i, addr := range s.config.Listens {
+	servers := make([]*http.Server, len(listeners))
+	for i, ln := range listeners {
 		servers[i] = &http.Server{
-			Addr:    addr,
+			Addr:    ln.Addr().String(),
 			Handler: handler,
 			// Derive request contexts from ctx so that long-lived handlers
 			// (e.g. SSE) exit cleanly when the application shuts down,
@@ -61,35 +94,203 @@ func (s *Server) Run(ctx context.Context) error {
 		}
 	}
 
-	// Shutdown all servers on context cancellation
-	go func() {
-		<-ctx.Done()
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	s.mu.Lock()
+	select {
+	case <-s.draining:
+		// Shutdown ran before anything was served
+		s.mu.Unlock()
+		for _, ln := range listeners {
+			_ = ln.Close()
+		}
+		return nil
+	default:
+	}
+	s.servers = servers
+	s.mu.Unlock()
+
+	// One goroutine per listener; the first failure, or ctx, shuts them all down
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		select {
+		case <-gctx.Done():
+		case <-s.draining:
+			// Shutdown was called directly and is draining the servers
+			return nil
+		}
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout())
 		defer cancel()
-		for _, srv := range servers {
-			if err := srv.Shutdown(shutdownCtx); err != nil {
-				logger.Info("[api] server %s shutdown error: %v", srv.Addr, err)
-			}
+		if err := s.Shutdown(shutdownCtx); err != nil {
+			logger.Info("[api] %v", err)
 		}
-	}()
+		return nil
+	})
+	for i, srv := range servers {
+		ln := listeners[i]
+		g.Go(func() error {
+			logger.Info("[api] http server running on %s", srv.Addr)
+			if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+				return fmt.Errorf("server %s: %w", srv.Addr, err)
+			}
+			return nil
+		})
+	}
 
-	// Start one goroutine per listen address
-	errCh := make(chan error, len(servers))
-	var wg sync.WaitGroup
+	return g.Wait()
+}
+
+// Shutdown stops accepting connections and waits, until ctx ends, for the
+// in-flight requests to complete. Event streams are told to end first, as
+// they would never complete on their own. Safe to call more than once.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.drainingOnce.Do(func() { close(s.draining) })
+
+	s.mu.Lock()
+	servers := s.servers
+	s.mu.Unlock()
+
+	var errs []error
 	for _, srv := range servers {
-		wg.Add(1)
-		go func(srv *http.Server) {
-			defer wg.Done()
-			logger.Info("[api] http server running on %s", srv.Addr)
-			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-				errCh <- fmt.Errorf("server %s: %w", srv.Addr, err)
+		if err := srv.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("server %s shutdown: %w", srv.Addr, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// shutdownTimeout returns api.shutdown_timeout.
+func (s *Server) shutdownTimeout() time.Duration {
+	if s.config == nil || s.config.ShutdownTimeout <= 0 {
+		return 5 * time.Second
+	}
+	return s.config.ShutdownTimeout
+}
+
+// corsOrigins returns the configured CORS origins, nil when CORS is disabled.
+func (s *Server) corsOrigins() []string {
+	if s.config == nil || s.config.CORS == nil {
+		return nil
+	}
+	return s.config.CORS.Origins
+}
+
+// remoteArtwork reports whether api.remote_artwork lets the server fetch
+// http(s) covers.
+func (s *Server) remoteArtwork() bool {
+	return s.config != nil && s.config.RemoteArtwork
+}
+
+// systemdListeners returns the sockets systemd passed on activation, replaced
+// in tests.
+var systemdListeners = activation.Listeners
+
+// listen binds every address up front: a taken port fails Run before anything
+// is served instead of leaving the other addresses running alone. With
+// api.systemd_socket the sockets come pre-bound from systemd instead, so the
+// unit can restart without refusing connections.
+func (s *Server) listen() ([]net.Listener, error) {
+	if s.config.SystemdSocket {
+		activated, err := systemdListeners()
+		if err != nil {
+			return nil, fmt.Errorf("systemd socket activation: %w", err)
+		}
+		listeners := make([]net.Listener, 0, len(activated))
+		for _, ln := range activated {
+			// nil for a passed fd that is not a stream socket
+			if ln != nil {
+				listeners = append(listeners, ln)
 			}
-		}(srv)
+		}
+		if len(listeners) == 0 {
+			return nil, errors.New("api.systemd_socket is set but systemd passed no listening socket")
+		}
+		return listeners, nil
 	}
 
-	wg.Wait()
-	close(errCh)
-	return <-errCh
+	listeners := make([]net.Listener, 0, len(s.config.Listens))
+	for _, addr := range s.config.Listens {
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			for _, l := range listeners {
+				_ = l.Close()
+			}
+			return nil, fmt.Errorf("server %s: %w", addr, err)
+		}
+		listeners = append(listeners, ln)
+	}
+	if s.config.Socket != "" {
+		ln, err := listenUnix(s.config.Socket)
+		if err != nil {
+			for _, l := range listeners {
+				_ = l.Close()
+			}
+			return nil, fmt.Errorf("server %s: %w", s.config.Socket, err)
+		}
+		listeners = append(listeners, ln)
+	}
+	return listeners, nil
+}
+
+// socketMode lets the owner and its group (e.g. a companion CLI's users)
+// reach the API socket, nobody else.
+const socketMode = 0o660
+
+// listenUnix listens on a unix socket at path. A stale socket left by an
+// unclean exit, one nobody accepts on anymore, is removed first; a live
+// socket or any other file there is an error rather than silently taken over.
+func listenUnix(path string) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		conn, err := net.DialTimeout("unix", path, time.Second)
+		if err == nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("%s is in use by another process", path)
+		}
+		if !errors.Is(err, syscall.ECONNREFUSED) {
+			return nil, fmt.Errorf("probe socket: %w", err)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("remove stale socket: %w", err)
+		}
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, socketMode); err != nil {
+		_ = ln.Close()
+		return nil, fmt.Errorf("chmod socket: %w", err)
+	}
+	return ln, nil
+}
+
+// routeMethods are tried against the mux to tell a wrong method from an
+// unknown path.
+var routeMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// unmatched answers requests only the catch-all "/" route matches. The
+// catch-all shadows the mux's own 405, so the path is matched again under
+// each method: if any other route takes it, this is a 405 listing them in
+// Allow, else a 404. The root itself never reveals anything.
+func (s *Server) unmatched(w http.ResponseWriter, r *http.Request) {
+	var allowed []string
+	if r.URL.Path != "/" {
+		for _, method := range routeMethods {
+			probe := r.Clone(r.Context())
+			probe.Method = method
+			if _, pattern := s.mux.Handler(probe); pattern != "/" && pattern != "" {
+				allowed = append(allowed, method)
+			}
+		}
+	}
+	if len(allowed) > 0 {
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		writeJSONError(w, http.StatusMethodNotAllowed, "method_not_allowed",
+			fmt.Sprintf("method %s not allowed on %s", r.Method, r.URL.Path))
+		return
+	}
+	writeJSONError(w, http.StatusNotFound, "not_found", "no route for "+r.URL.Path)
 }
 
 func (s *Server) register(b *backend.Backend) {
@@ -97,15 +298,8 @@ func (s *Server) register(b *backend.Backend) {
 		return
 	}
 
-	// 404 on root for security
-	s.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path == "/" {
-			http.NotFound(w, r)
-			return
-		}
-		// Return 404 for all other unmatched paths
-		http.NotFound(w, r)
-	})
+	// 404 on root for security, JSON 404/405 for every other unmatched route
+	s.mux.HandleFunc("/", s.unmatched)
 
 	// server routes
 	s.registerServerRoutes(b)
@@ -128,7 +322,18 @@ func (s *Server) register(b *backend.Backend) {
 		s.registerPulseRoutes(b.Pulse)
 	}
 
+	// alsa routes, only created when pulse is disabled
+	if b.ALSA != nil {
+		s.registerALSARoutes(b.ALSA)
+	}
+
+	// snapcast routes
+	if b.Snapcast != nil {
+		s.registerSnapcastRoutes(b.Snapcast)
+	}
+
 	// systemd routes
+	s.registerWatchedRoute(b.Systemd)
 	if b.Systemd != nil {
 		s.registerSystemdRoutes(b.Systemd)
 	}
@@ -142,6 +347,69 @@ func (s *Server) register(b *backend.Backend) {
 	if b.MPRIS != nil {
 		s.registerMPRISRoutes(b.MPRIS)
 	}
+
+	// plugin routes, after the built-in ones so a clash is the plugin's loss
+	for _, p := range b.Plugins {
+		s.registerPluginRoutes(p)
+	}
+}
+
+// maxBodyMiddleware caps every request body at limit bytes. Reads past the cap
+// fail with *http.MaxBytesError, which withBody turns into a 413.
+func maxBodyMiddleware(limit int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// drainMiddleware ends event streams (SSE, WebSocket) once draining is
+// closed, so a graceful shutdown only waits for the plain requests.
+func drainMiddleware(draining <-chan struct{}) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !isStream(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ctx, cancel := context.WithCancel(r.Context())
+			defer cancel()
+			go func() {
+				select {
+				case <-draining:
+					cancel()
+				case <-ctx.Done():
+				}
+			}()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// isStream reports whether r opens a long-lived event stream, or a long-poll
+// such as /bluetooth/devices/{address}/wait (up to 5m), which must not hold
+// the drain either.
+func isStream(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream") ||
+		strings.EqualFold(r.Header.Get("Upgrade"), "websocket") ||
+		(strings.HasPrefix(r.URL.Path, "/bluetooth/devices/") && strings.HasSuffix(r.URL.Path, "/wait"))
+}
+
+// readOnlyMiddleware rejects every mutating method with 405 before routing,
+// so no backend can be written to whatever routes it registered.
+func readOnlyMiddleware(next http.Handler) http.Handler {
+	logger.Info("[api] read-only mode enabled, mutating requests are rejected")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			w.Header().Set("Allow", "GET, HEAD, OPTIONS")
+			writeError(w, "API is in read-only mode", http.StatusMethodNotAllowed)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
 }
 
 func corsMiddleware(cfg *config.CORSConfig) func(http.Handler) http.Handler {
@@ -161,7 +429,7 @@ func corsMiddleware(cfg *config.CORSConfig) func(http.Handler) http.Handler {
 			}
 
 			if r.Method == http.MethodOptions {
-				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PATCH, DELETE, OPTIONS")
 				w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
 				w.WriteHeader(http.StatusNoContent)
 				return
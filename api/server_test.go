@@ -1,12 +1,24 @@
 package api
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/b0bbywan/go-odio-api/backend"
 	"github.com/b0bbywan/go-odio-api/config"
+	"github.com/b0bbywan/go-odio-api/events"
+	"github.com/b0bbywan/go-odio-api/plugin"
 )
 
 // emptyBackend returns a non-nil backend with no sub-backends initialized,
@@ -67,6 +79,58 @@ func TestServer_RootReturns404(t *testing.T) {
 	}
 }
 
+// TestServer_UnmatchedRoutesAnswerJSON: unknown paths are a JSON 404, a known
+// path under the wrong method a JSON 405 with Allow; the root stays a 404.
+func TestServer_UnmatchedRoutesAnswerJSON(t *testing.T) {
+	cfg := &config.ApiConfig{
+		Enabled: true,
+		Port:    8080,
+		UI:      &config.UIConfig{Enabled: false},
+	}
+	s := NewServer(cfg, emptyBackend())
+
+	tests := []struct {
+		method    string
+		path      string
+		wantCode  int
+		wantError string
+		wantAllow string
+	}{
+		{http.MethodGet, "/", http.StatusNotFound, "not_found", ""},
+		{http.MethodPost, "/", http.StatusNotFound, "not_found", ""},
+		{http.MethodGet, "/nope", http.StatusNotFound, "not_found", ""},
+		{http.MethodPost, "/server/network", http.StatusMethodNotAllowed, "method_not_allowed", "GET"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			s.mux.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantCode)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", ct)
+			}
+			if allow := w.Header().Get("Allow"); allow != tt.wantAllow {
+				t.Errorf("Allow = %q, want %q", allow, tt.wantAllow)
+			}
+			var body struct {
+				Error struct {
+					Code    string `json:"code"`
+					Message string `json:"message"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("invalid JSON body %q: %v", w.Body.String(), err)
+			}
+			if body.Error.Code != tt.wantError || body.Error.Message == "" {
+				t.Errorf("error = %+v, want code %q with a message", body.Error, tt.wantError)
+			}
+		})
+	}
+}
+
 // TestServer_UIDisabled verifies that /ui returns 404 when UI is disabled
 func TestServer_UIDisabled(t *testing.T) {
 	cfg := &config.ApiConfig{
@@ -277,6 +341,28 @@ func TestRoutesWithDisabledBackends(t *testing.T) {
 	}
 }
 
+// TestWatchedUnitsWithoutSystemdBackend: systemd enabled with no unit
+// configured leaves no backend, yet /services/watched answers empty lists.
+func TestWatchedUnitsWithoutSystemdBackend(t *testing.T) {
+	cfg := &config.ApiConfig{
+		Enabled: true,
+		Port:    8018,
+		Listens: []string{"127.0.0.1:8018"},
+	}
+	server := NewServer(cfg, &backend.Backend{Systemd: nil})
+
+	req := httptest.NewRequest("GET", "/services/watched", nil)
+	w := httptest.NewRecorder()
+	server.mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /services/watched = %d, want 200", w.Code)
+	}
+	if got, want := strings.TrimSpace(w.Body.String()), `{"system":[],"user":[]}`; got != want {
+		t.Errorf("body = %s, want %s", got, want)
+	}
+}
+
 // TestRoutesWithEnabledSystemdBackend verifies systemd routes exist when backend is enabled
 func TestRoutesWithEnabledSystemdBackend(t *testing.T) {
 	cfg := &config.ApiConfig{
@@ -404,3 +490,690 @@ func TestRouteMethodRestrictions(t *testing.T) {
 		})
 	}
 }
+
+// TestReadOnlyMiddleware verifies that mutating methods are rejected with 405
+// while reads pass through
+func TestReadOnlyMiddleware(t *testing.T) {
+	handler := readOnlyMiddleware(nopHandler)
+
+	tests := []struct {
+		method   string
+		wantCode int
+	}{
+		{http.MethodGet, http.StatusOK},
+		{http.MethodHead, http.StatusOK},
+		{http.MethodOptions, http.StatusOK},
+		{http.MethodPost, http.StatusMethodNotAllowed},
+		{http.MethodPut, http.StatusMethodNotAllowed},
+		{http.MethodPatch, http.StatusMethodNotAllowed},
+		{http.MethodDelete, http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/players/org.mpris.MediaPlayer2.spotify/play", nil)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("%s = %d, want %d", tt.method, w.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+// TestReadOnlyKeepsDashboardRoutes verifies the "look but don't touch" set a
+// dashboard needs stays readable while every action on it is rejected.
+func TestReadOnlyKeepsDashboardRoutes(t *testing.T) {
+	handler := readOnlyMiddleware(nopHandler)
+
+	for _, path := range []string{"/players", "/audio/state", "/services", "/bluetooth", "/server"} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("GET %s = %d, want 200", path, w.Code)
+		}
+	}
+
+	for _, path := range []string{
+		"/players/org.mpris.MediaPlayer2.spotify/stop",
+		"/audio/server/volume",
+		"/services/user/mpd.service/restart",
+		"/bluetooth/power_up",
+		"/server/cache/refresh",
+	} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, nil))
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("POST %s = %d, want 405", path, w.Code)
+		}
+	}
+}
+
+// TestServerRouteAdvertisesReadOnly verifies that /server reports the read-only mode
+func TestServerRouteAdvertisesReadOnly(t *testing.T) {
+	for _, readOnly := range []bool{false, true} {
+		cfg := &config.ApiConfig{
+			Enabled:  true,
+			Port:     8080,
+			ReadOnly: readOnly,
+			UI:       &config.UIConfig{Enabled: false},
+		}
+		s := NewServer(cfg, emptyBackend())
+
+		req := httptest.NewRequest(http.MethodGet, "/server", nil)
+		w := httptest.NewRecorder()
+		s.mux.ServeHTTP(w, req)
+
+		var info backend.ServerDeviceInfo
+		if err := json.NewDecoder(w.Body).Decode(&info); err != nil {
+			t.Fatalf("decode /server: %v", err)
+		}
+		if info.ReadOnly != readOnly {
+			t.Errorf("readonly = %v, want %v", info.ReadOnly, readOnly)
+		}
+	}
+}
+
+// TestDebugStateRoute verifies that /debug/state only exists with api.debug
+// and reports version, config and state in one document.
+func TestDebugStateRoute(t *testing.T) {
+	for _, debug := range []bool{false, true} {
+		cfg := &config.ApiConfig{
+			Enabled: true,
+			Port:    8080,
+			UI:      &config.UIConfig{Enabled: false},
+		}
+		if debug {
+			cfg.Debug = &config.DebugConfig{Settings: map[string]any{"bind": "lo"}}
+		}
+		s := NewServer(cfg, emptyBackend())
+
+		req := httptest.NewRequest(http.MethodGet, "/debug/state", nil)
+		w := httptest.NewRecorder()
+		s.mux.ServeHTTP(w, req)
+
+		if !debug {
+			if w.Code != http.StatusNotFound {
+				t.Errorf("debug off: status = %d, want 404", w.Code)
+			}
+			continue
+		}
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("debug on: status = %d, want 200", w.Code)
+		}
+		var got debugState
+		if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+			t.Fatalf("decode /debug/state: %v", err)
+		}
+		if got.Version != config.AppVersion || got.Goroutines <= 0 || got.Uptime == "" {
+			t.Errorf("got version=%q goroutines=%d uptime=%q", got.Version, got.Goroutines, got.Uptime)
+		}
+		if got.Config["bind"] != "lo" {
+			t.Errorf("config = %v, want the debug settings", got.Config)
+		}
+	}
+}
+
+// TestDebugCacheRoutes verifies the cache export/import pair is registered
+// with api.debug and accepts its own export format.
+func TestDebugCacheRoutes(t *testing.T) {
+	cfg := &config.ApiConfig{
+		Enabled: true,
+		Port:    8080,
+		UI:      &config.UIConfig{Enabled: false},
+		Debug:   &config.DebugConfig{},
+	}
+	s := NewServer(cfg, emptyBackend())
+
+	w := httptest.NewRecorder()
+	s.mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/cache/export", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("export: status = %d, want 200", w.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/debug/cache/import", w.Body)
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	s.mux.ServeHTTP(w, req)
+	if w.Code != http.StatusAccepted {
+		t.Errorf("import: status = %d, want 202", w.Code)
+	}
+}
+
+// TestCacheImportHandler verifies the posted document reaches the backend.
+func TestCacheImportHandler(t *testing.T) {
+	var got backend.State
+	handler := CacheImportHandler(func(st backend.State) { got = st })
+
+	body := `{"players":[{"bus_name":"org.mpris.MediaPlayer2.spotify"}],"bluetooth":{"powered":true}}`
+	req := httptest.NewRequest(http.MethodPost, "/debug/cache/import", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202", w.Code)
+	}
+	if len(got.Players) != 1 || got.Players[0].BusName != "org.mpris.MediaPlayer2.spotify" {
+		t.Errorf("players = %v", got.Players)
+	}
+	if got.Bluetooth == nil || !got.Bluetooth.Powered {
+		t.Errorf("bluetooth = %+v, want powered", got.Bluetooth)
+	}
+}
+
+func TestZeroconfTxtHandler(t *testing.T) {
+	var got []string
+	handler := ZeroconfTxtHandler(func(records []string) error {
+		if slices.Contains(records, "=bad") {
+			return errors.New("TXT record \"=bad\" has an empty key")
+		}
+		got = records
+		return nil
+	})
+
+	tests := []struct {
+		body string
+		want int
+	}{
+		{`{"records":["version=1.0","bt=1"]}`, http.StatusAccepted},
+		{`{"records":["=bad"]}`, http.StatusBadRequest},
+		{`not json`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/zeroconf/txt", strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		handler(w, req)
+		if w.Code != tt.want {
+			t.Errorf("body %s: status = %d, want %d", tt.body, w.Code, tt.want)
+		}
+	}
+	if !slices.Equal(got, []string{"version=1.0", "bt=1"}) {
+		t.Errorf("records = %v, want [version=1.0 bt=1]", got)
+	}
+}
+
+func TestHealthHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		connections map[string]bool
+		wantCode    int
+		wantStatus  string
+	}{
+		{"no connections", map[string]bool{}, http.StatusOK, "ok"},
+		{"all up", map[string]bool{"mpris": true, "pulseaudio": true}, http.StatusOK, "ok"},
+		{"mpris down", map[string]bool{"mpris": false, "pulseaudio": true}, http.StatusServiceUnavailable, "degraded"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := HealthHandler(func() map[string]bool { return tt.connections })
+			w := httptest.NewRecorder()
+			handler(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+			if w.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantCode)
+			}
+			var got health
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if got.Status != tt.wantStatus || len(got.Connections) != len(tt.connections) {
+				t.Errorf("got %+v, want status %q", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
+// freeAddr returns a loopback address with a port nothing listens on.
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+	return addr
+}
+
+// TestRunServesEveryListen verifies Run serves all addresses at once and
+// returns cleanly on shutdown.
+func TestRunServesEveryListen(t *testing.T) {
+	addrs := []string{freeAddr(t), freeAddr(t)}
+	s := NewServer(&config.ApiConfig{
+		Enabled: true,
+		Listens: addrs,
+		UI:      &config.UIConfig{Enabled: false},
+	}, emptyBackend())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+
+	for _, addr := range addrs {
+		var resp *http.Response
+		var err error
+		for range 50 {
+			if resp, err = http.Get("http://" + addr + "/healthz"); err == nil {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if err != nil {
+			t.Fatalf("GET %s: %v", addr, err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("%s: status = %d, want 200", addr, resp.StatusCode)
+		}
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Run() = %v, want nil after shutdown", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() did not return after cancel")
+	}
+}
+
+// TestShutdownDrainsInFlightRequests verifies Shutdown lets a slow request
+// finish before Run returns, while an event stream is ended right away.
+func TestShutdownDrainsInFlightRequests(t *testing.T) {
+	addr := freeAddr(t)
+	s := NewServer(&config.ApiConfig{
+		Enabled:         true,
+		Listens:         []string{addr},
+		UI:              &config.UIConfig{Enabled: false},
+		ShutdownTimeout: 5 * time.Second,
+	}, emptyBackend())
+
+	started := make(chan struct{}, 2)
+	s.mux.HandleFunc("GET /slow", func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		time.Sleep(300 * time.Millisecond)
+		_, _ = w.Write([]byte("done"))
+	})
+	s.mux.HandleFunc("GET /stream", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		started <- struct{}{}
+		<-r.Context().Done()
+	})
+
+	// One connection per request: a spare keep-alive dial would sit in
+	// StateNew, which Shutdown only reaps after 5s.
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runDone := make(chan error, 1)
+	go func() { runDone <- s.Run(ctx) }()
+
+	var err error
+	for range 50 {
+		var resp *http.Response
+		if resp, err = client.Get("http://" + addr + "/healthz"); err == nil {
+			_ = resp.Body.Close()
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("server not up: %v", err)
+	}
+
+	type result struct {
+		body string
+		err  error
+		at   time.Time
+	}
+	slow := make(chan result, 1)
+	go func() {
+		resp, err := client.Get("http://" + addr + "/slow")
+		if err != nil {
+			slow <- result{err: err}
+			return
+		}
+		defer func() { _ = resp.Body.Close() }()
+		var b strings.Builder
+		_, err = io.Copy(&b, resp.Body)
+		slow <- result{body: b.String(), err: err, at: time.Now()}
+	}()
+	streamReq, _ := http.NewRequest(http.MethodGet, "http://"+addr+"/stream", nil)
+	streamReq.Header.Set("Accept", "text/event-stream")
+	streamResp, err := client.Do(streamReq)
+	if err != nil {
+		t.Fatalf("GET /stream: %v", err)
+	}
+	defer func() { _ = streamResp.Body.Close() }()
+	<-started
+	<-started
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancelShutdown()
+	if err := s.Shutdown(shutdownCtx); err != nil {
+		t.Fatalf("Shutdown() = %v, want nil (stream should not hold the drain)", err)
+	}
+	shutdownAt := time.Now()
+
+	res := <-slow
+	if res.err != nil || res.body != "done" {
+		t.Fatalf("slow request = %q, %v; want it completed", res.body, res.err)
+	}
+	if res.at.After(shutdownAt) {
+		t.Errorf("slow request completed %s after Shutdown returned", res.at.Sub(shutdownAt))
+	}
+
+	select {
+	case err := <-runDone:
+		if err != nil {
+			t.Errorf("Run() = %v, want nil after Shutdown", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run() did not return after Shutdown")
+	}
+	if _, err := client.Get("http://" + addr + "/healthz"); err == nil {
+		t.Error("server still accepting connections after Shutdown")
+	}
+}
+
+// TestRunFailsOnTakenAddress verifies a bind failure is returned before
+// anything is served.
+func TestRunFailsOnTakenAddress(t *testing.T) {
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer func() { _ = taken.Close() }()
+
+	free := freeAddr(t)
+	s := NewServer(&config.ApiConfig{
+		Enabled: true,
+		Listens: []string{free, taken.Addr().String()},
+		UI:      &config.UIConfig{Enabled: false},
+	}, emptyBackend())
+
+	if err := s.Run(context.Background()); err == nil {
+		t.Fatal("Run() = nil, want a bind error")
+	}
+	ln, err := net.Listen("tcp", free)
+	if err != nil {
+		t.Errorf("%s still bound after Run failed: %v", free, err)
+	} else {
+		_ = ln.Close()
+	}
+}
+
+// TestRunServesUnixSocket verifies the API answers over api.socket, that a
+// stale socket is replaced and the socket is group-accessible only.
+func TestRunServesUnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.sock")
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	// Leave the file behind, as a crashed process would.
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	_ = stale.Close()
+
+	s := NewServer(&config.ApiConfig{
+		Enabled: true,
+		Listens: []string{freeAddr(t)},
+		Socket:  path,
+		UI:      &config.UIConfig{Enabled: false},
+	}, emptyBackend())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", path)
+		},
+	}}
+	var resp *http.Response
+	for range 50 {
+		if resp, err = client.Get("http://odio/healthz"); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("GET over unix socket: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat socket: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != socketMode {
+		t.Errorf("socket mode = %o, want %o", perm, socketMode)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Run() = %v, want nil after shutdown", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() did not return after cancel")
+	}
+}
+
+// TestRunRefusesLiveSocket verifies a socket another process still accepts on
+// is not unlinked and fails Run.
+func TestRunRefusesLiveSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.sock")
+	live, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer func() { _ = live.Close() }()
+
+	s := NewServer(&config.ApiConfig{
+		Enabled: true,
+		Socket:  path,
+		UI:      &config.UIConfig{Enabled: false},
+	}, emptyBackend())
+
+	if err := s.Run(context.Background()); err == nil {
+		t.Fatal("Run() = nil, want an error for a socket in use")
+	}
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("live socket was taken over: %v", err)
+	}
+	_ = conn.Close()
+}
+
+// TestRunRefusesNonSocketPath verifies a regular file at api.socket is left
+// alone and fails Run.
+func TestRunRefusesNonSocketPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.sock")
+	if err := os.WriteFile(path, []byte("keep"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewServer(&config.ApiConfig{
+		Enabled: true,
+		Socket:  path,
+		UI:      &config.UIConfig{Enabled: false},
+	}, emptyBackend())
+
+	if err := s.Run(context.Background()); err == nil {
+		t.Fatal("Run() = nil, want an error for a non-socket path")
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "keep" {
+		t.Errorf("file at socket path was modified: %q, %v", data, err)
+	}
+}
+
+// TestRunServesSystemdSockets verifies api.systemd_socket serves the sockets
+// systemd passed, ignoring Listens.
+func TestRunServesSystemdSockets(t *testing.T) {
+	passed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	orig := systemdListeners
+	systemdListeners = func() ([]net.Listener, error) { return []net.Listener{nil, passed}, nil }
+	t.Cleanup(func() { systemdListeners = orig })
+
+	unused := freeAddr(t)
+	s := NewServer(&config.ApiConfig{
+		Enabled:       true,
+		Listens:       []string{unused},
+		SystemdSocket: true,
+		UI:            &config.UIConfig{Enabled: false},
+	}, emptyBackend())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+
+	var resp *http.Response
+	for range 50 {
+		if resp, err = http.Get("http://" + passed.Addr().String() + "/healthz"); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("GET on the passed socket: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode)
+	}
+	if ln, err := net.Listen("tcp", unused); err != nil {
+		t.Errorf("%s bound despite api.systemd_socket: %v", unused, err)
+	} else {
+		_ = ln.Close()
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Run() = %v, want nil after shutdown", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() did not return after cancel")
+	}
+}
+
+// TestRunFailsWithoutSystemdSockets verifies api.systemd_socket without any
+// socket from systemd fails Run instead of serving nothing.
+func TestRunFailsWithoutSystemdSockets(t *testing.T) {
+	orig := systemdListeners
+	systemdListeners = func() ([]net.Listener, error) { return nil, nil }
+	t.Cleanup(func() { systemdListeners = orig })
+
+	s := NewServer(&config.ApiConfig{
+		Enabled:       true,
+		SystemdSocket: true,
+		UI:            &config.UIConfig{Enabled: false},
+	}, emptyBackend())
+
+	if err := s.Run(context.Background()); err == nil {
+		t.Fatal("Run() = nil, want an error when systemd passed no socket")
+	}
+}
+
+// TestCacheRefreshRoute verifies POST /server/cache/refresh answers with the
+// per-backend outcome map.
+func TestCacheRefreshRoute(t *testing.T) {
+	s := NewServer(&config.ApiConfig{
+		Enabled: true,
+		Port:    8080,
+		UI:      &config.UIConfig{Enabled: false},
+	}, emptyBackend())
+
+	w := httptest.NewRecorder()
+	s.mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/server/cache/refresh", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	var got map[string]backend.CacheRefresh
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("got %v, want no backends", got)
+	}
+}
+
+type fakePlugin struct{ routes []plugin.Route }
+
+func (p *fakePlugin) Start() error                { return nil }
+func (p *fakePlugin) Close()                      {}
+func (p *fakePlugin) Routes() []plugin.Route      { return p.routes }
+func (p *fakePlugin) Events() <-chan events.Event { return nil }
+
+// TestPluginRoutes verifies plugin routes are mounted and that one clashing
+// with a built-in route is skipped instead of panicking.
+func TestPluginRoutes(t *testing.T) {
+	p := &fakePlugin{routes: []plugin.Route{
+		{Pattern: "GET /squeezebox/players", Handler: func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("squeezebox"))
+		}},
+		{Pattern: "GET /healthz", Handler: func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		}},
+	}}
+	s := NewServer(&config.ApiConfig{
+		Enabled: true,
+		UI:      &config.UIConfig{Enabled: false},
+	}, &backend.Backend{Plugins: []plugin.Backend{p}})
+
+	w := httptest.NewRecorder()
+	s.mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/squeezebox/players", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "squeezebox" {
+		t.Errorf("plugin route: status = %d, body = %q", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	s.mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("/healthz status = %d, want the built-in 200", w.Code)
+	}
+}
+
+func TestDrainMiddlewareCancelsLongPoll(t *testing.T) {
+	draining := make(chan struct{})
+	handler := drainMiddleware(draining)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		writeError(w, "wait interrupted", http.StatusServiceUnavailable)
+	}))
+
+	done := make(chan int, 1)
+	go func() {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/bluetooth/devices/AA:BB:CC:DD:EE:FF/wait", nil))
+		done <- w.Code
+	}()
+	close(draining)
+
+	select {
+	case code := <-done:
+		if code != http.StatusServiceUnavailable {
+			t.Errorf("status = %d, want 503", code)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("long-poll kept running after the drain started")
+	}
+}
@@ -1,8 +1,14 @@
 package api
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
+	"time"
 
 	"github.com/b0bbywan/go-odio-api/backend/systemd"
 )
@@ -13,23 +19,43 @@ func handleSystemdError(w http.ResponseWriter, err error) {
 		w.WriteHeader(http.StatusAccepted)
 		return
 	}
+	writeError(w, err.Error(), systemdErrorStatus(err))
+}
+
+// systemdErrorStatus maps a systemd action error to its HTTP status.
+func systemdErrorStatus(err error) int {
+	// Malformed unit name, whatever the scope
+	var invalidNameErr *systemd.InvalidUnitNameError
+	if errors.As(err, &invalidNameErr) {
+		return http.StatusBadRequest
+	}
 
 	// Handle system scope permission errors - always forbidden
 	var permSysErr *systemd.PermissionSystemError
 	if errors.As(err, &permSysErr) {
-		http.Error(w, err.Error(), http.StatusForbidden)
-		return
+		return http.StatusForbidden
 	}
 
 	// Handle user scope permission errors - forbidden for non-whitelisted units
 	var permUserErr *systemd.PermissionUserError
 	if errors.As(err, &permUserErr) {
-		http.Error(w, err.Error(), http.StatusForbidden)
-		return
+		return http.StatusForbidden
+	}
+
+	// Reading a unit outside the whitelist
+	var notWatchedErr *systemd.NotWatchedError
+	if errors.As(err, &notWatchedErr) {
+		return http.StatusForbidden
+	}
+
+	// The unit did not reach inactive within the wait
+	var stopTimeoutErr *systemd.StopTimeoutError
+	if errors.As(err, &stopTimeoutErr) {
+		return http.StatusGatewayTimeout
 	}
 
 	// All other errors are internal server errors
-	http.Error(w, err.Error(), http.StatusInternalServerError)
+	return http.StatusInternalServerError
 }
 
 func withService(
@@ -40,21 +66,227 @@ func withService(
 	return func(w http.ResponseWriter, r *http.Request) {
 		scope, ok := systemd.ParseUnitScope(r.PathValue("scope"))
 		if !ok {
-			http.Error(w, "invalid scope", http.StatusNotFound)
+			writeError(w, "invalid scope", http.StatusNotFound)
 			return
 		}
 
 		unit := r.PathValue("unit")
 		if unit == "" {
-			http.Error(w, "missing unit name", http.StatusNotFound)
+			writeError(w, "missing unit name", http.StatusNotFound)
 			return
 		}
 
 		if sd.IsInternal(unit, scope) {
-			http.Error(w, "unknown unit", http.StatusNotFound)
+			writeError(w, "unknown unit", http.StatusNotFound)
 			return
 		}
 
 		handleSystemdError(w, fn(unit, scope))
 	}
 }
+
+// StopServiceHandler stops a unit. With ?wait=true it answers only once the
+// unit is inactive or failed, bounded by ?timeout= (a Go duration, default
+// systemd.timeout); a unit still stopping then is a 504.
+func StopServiceHandler(sd *systemd.SystemdBackend) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		wait := false
+		if raw := q.Get("wait"); raw != "" {
+			var err error
+			if wait, err = strconv.ParseBool(raw); err != nil {
+				writeError(w, "wait must be true or false", http.StatusBadRequest)
+				return
+			}
+		}
+		if !wait {
+			withService(sd, sd.StopService)(w, r)
+			return
+		}
+
+		var timeout time.Duration
+		if raw := q.Get("timeout"); raw != "" {
+			var err error
+			if timeout, err = time.ParseDuration(raw); err != nil || timeout <= 0 {
+				writeError(w, "timeout must be a positive duration like 30s", http.StatusBadRequest)
+				return
+			}
+		}
+		withService(sd, func(unit string, scope systemd.UnitScope) error {
+			return sd.StopServiceAndWait(unit, scope, timeout)
+		})(w, r)
+	}
+}
+
+// serviceBatchResult is the per-unit outcome of a batch action.
+type serviceBatchResult struct {
+	Unit   string `json:"unit"`
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ServiceBatchHandler applies one action to several units of a scope. Each unit
+// goes through the same checks as the single-unit routes, so a disallowed unit
+// only fails its own entry; the response lists every unit's status.
+func ServiceBatchHandler(
+	sd *systemd.SystemdBackend,
+	actions map[string]func(string, systemd.UnitScope) error,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		scope, ok := systemd.ParseUnitScope(r.PathValue("scope"))
+		if !ok {
+			writeError(w, "invalid scope", http.StatusNotFound)
+			return
+		}
+
+		validate := func(req *systemd.BatchRequest) error {
+			if _, ok := actions[req.Action]; !ok {
+				return fmt.Errorf("unknown action: %q", req.Action)
+			}
+			if len(req.Units) == 0 {
+				return errors.New("units must not be empty")
+			}
+			return nil
+		}
+
+		withBody(validate, func(w http.ResponseWriter, r *http.Request, req *systemd.BatchRequest) {
+			fn := actions[req.Action]
+			results := make([]serviceBatchResult, 0, len(req.Units))
+			for _, unit := range req.Units {
+				if unit == "" || sd.IsInternal(unit, scope) {
+					results = append(results, serviceBatchResult{Unit: unit, Status: http.StatusNotFound, Error: "unknown unit"})
+					continue
+				}
+				res := serviceBatchResult{Unit: unit, Status: http.StatusAccepted}
+				if err := fn(unit, scope); err != nil {
+					res.Status, res.Error = systemdErrorStatus(err), err.Error()
+				}
+				results = append(results, res)
+			}
+			writeJSON(w, http.StatusOK, results)
+		})(w, r)
+	}
+}
+
+// ScopeServicesHandler lists the public services of {scope}, only the running
+// ones with ?running=true (or the stopped ones with false).
+func ScopeServicesHandler(
+	list func(systemd.UnitScope) ([]systemd.Service, error),
+	updatedAt func() time.Time,
+) http.HandlerFunc {
+	return JSONHandler(func(w http.ResponseWriter, r *http.Request) (any, error) {
+		scope, ok := systemd.ParseUnitScope(r.PathValue("scope"))
+		if !ok {
+			return nil, httpError(http.StatusNotFound, errors.New("invalid scope"))
+		}
+		var running *bool
+		if raw := r.URL.Query().Get("running"); raw != "" {
+			v, err := strconv.ParseBool(raw)
+			if err != nil {
+				return nil, httpError(http.StatusBadRequest, errors.New("running must be true or false"))
+			}
+			running = &v
+		}
+
+		services, err := list(scope)
+		if err != nil {
+			return nil, err
+		}
+		if running != nil {
+			services = slices.DeleteFunc(services, func(svc systemd.Service) bool {
+				return svc.Running != *running
+			})
+		}
+		setCacheHeader(w, updatedAt())
+		return paginate(w, r, services)
+	})
+}
+
+// ServiceRestartAllHandler restarts every unit the listener watches in a
+// scope, one after the other. Units come from the watched whitelist only, and
+// each still goes through the permission check: every system unit gets 403.
+func ServiceRestartAllHandler(
+	units func(systemd.UnitScope) []string,
+	restart func(string, systemd.UnitScope) error,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		scope, ok := systemd.ParseUnitScope(r.PathValue("scope"))
+		if !ok {
+			writeError(w, "invalid scope", http.StatusNotFound)
+			return
+		}
+
+		names := units(scope)
+		results := make([]serviceBatchResult, 0, len(names))
+		for _, unit := range names {
+			res := serviceBatchResult{Unit: unit, Status: http.StatusAccepted}
+			if err := restart(unit, scope); err != nil {
+				res.Status, res.Error = systemdErrorStatus(err), err.Error()
+			}
+			results = append(results, res)
+		}
+		writeJSON(w, http.StatusOK, results)
+	}
+}
+
+// ServiceLogStreamHandler streams the new journal entries of a whitelisted
+// unit as SSE data events. The stream ends when the client disconnects or
+// after maxDuration (0 = no limit).
+func ServiceLogStreamHandler(
+	follow func(context.Context, string, systemd.UnitScope) (<-chan systemd.JournalEntry, error),
+	maxDuration time.Duration,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		scope, ok := systemd.ParseUnitScope(r.PathValue("scope"))
+		if !ok {
+			writeError(w, "invalid scope", http.StatusNotFound)
+			return
+		}
+		unit := r.PathValue("unit")
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			writeError(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		ctx := r.Context()
+		if maxDuration > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, maxDuration)
+			defer cancel()
+		}
+
+		entries, err := follow(ctx, unit, scope)
+		if err != nil {
+			writeError(w, err.Error(), systemdErrorStatus(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case entry, ok := <-entries:
+				if !ok {
+					return
+				}
+				data, err := json.Marshal(entry)
+				if err != nil {
+					continue
+				}
+				if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+					return
+				}
+				flusher.Flush()
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/b0bbywan/go-odio-api/backend/snapcast"
+)
+
+// withSnapcastClient extracts the {client} path parameter and calls next
+func withSnapcastClient(
+	next func(w http.ResponseWriter, r *http.Request, id string),
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("client")
+		if id == "" {
+			writeError(w, "missing client", http.StatusNotFound)
+			return
+		}
+		next(w, r, id)
+	}
+}
+
+// snapcastErrorStatus maps a Snapcast backend error to an HTTP status code.
+// Anything that is not a known client-side error comes from the snapserver or
+// the connection to it, hence 502.
+func snapcastErrorStatus(err error) int {
+	var notFoundErr *snapcast.ClientNotFoundError
+	if errors.As(err, &notFoundErr) {
+		return http.StatusNotFound
+	}
+
+	var validErr *snapcast.ValidationError
+	if errors.As(err, &validErr) {
+		return http.StatusBadRequest
+	}
+
+	return http.StatusBadGateway
+}
+
+func handleSnapcastError(w http.ResponseWriter, err error) {
+	if err == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	writeError(w, err.Error(), snapcastErrorStatus(err))
+}
+
+func ListSnapcastClientsHandler(s *snapcast.SnapcastBackend) http.HandlerFunc {
+	return JSONHandler(func(w http.ResponseWriter, r *http.Request) (any, error) {
+		clients, err := s.ListClients()
+		if err != nil {
+			return nil, httpError(snapcastErrorStatus(err), err)
+		}
+		return clients, nil
+	})
+}
+
+func SnapcastClientHandler(s *snapcast.SnapcastBackend) http.HandlerFunc {
+	return JSONHandler(func(w http.ResponseWriter, r *http.Request) (any, error) {
+		client, err := s.GetClient(r.PathValue("client"))
+		if err != nil {
+			return nil, httpError(snapcastErrorStatus(err), err)
+		}
+		return client, nil
+	})
+}
+
+func SetSnapcastVolumeHandler(s *snapcast.SnapcastBackend) http.HandlerFunc {
+	return withSnapcastClient(func(w http.ResponseWriter, r *http.Request, id string) {
+		withBody(nil, func(w http.ResponseWriter, r *http.Request, req *snapcast.VolumeRequest) {
+			handleSnapcastError(w, s.SetVolume(id, req.Volume))
+		})(w, r)
+	})
+}
+
+func SetSnapcastMuteHandler(s *snapcast.SnapcastBackend) http.HandlerFunc {
+	return withSnapcastClient(func(w http.ResponseWriter, r *http.Request, id string) {
+		withBody(nil, func(w http.ResponseWriter, r *http.Request, req *snapcast.MuteRequest) {
+			handleSnapcastError(w, s.SetMuted(id, req.Muted))
+		})(w, r)
+	})
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/b0bbywan/go-odio-api/backend"
+	"github.com/b0bbywan/go-odio-api/backend/mpris"
 	"github.com/b0bbywan/go-odio-api/events"
 	"github.com/b0bbywan/go-odio-api/logger"
 )
@@ -20,65 +21,129 @@ func sseHandler(b *backend.Broadcaster) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		filter, err := parseFilter(r)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			writeError(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 
 		keepAliveDuration, err := parseKeepAlive(r)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			writeError(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 
-		w.Header().Set("Content-Type", "text/event-stream")
-		w.Header().Set("Cache-Control", "no-cache")
-		w.Header().Set("Connection", "keep-alive")
-		w.Header().Set("X-Accel-Buffering", "no")
+		streamEvents(w, r, keepAliveDuration, b.SubscribeFunc(filter), b.Unsubscribe, nil)
+	}
+}
 
-		flusher, ok := w.(http.Flusher)
-		if !ok {
-			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+// playerSSEHandler streams only the MPRIS events of the {player} path
+// parameter; batched position events are narrowed to that player.
+func playerSSEHandler(b *backend.Broadcaster) http.HandlerFunc {
+	return withPlayer(func(w http.ResponseWriter, r *http.Request, busName string) {
+		keepAliveDuration, err := parseKeepAlive(r)
+		if err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 
-		if err := sendServerInfoToFlusher(flusher, w, "connected"); err != nil {
-			return
-		}
+		ch := b.SubscribeFunc(func(e events.Event) bool {
+			_, ok := mpris.PlayerEvent(e, busName)
+			return ok
+		})
+		streamEvents(w, r, keepAliveDuration, ch, b.Unsubscribe, func(e events.Event) (events.Event, bool) {
+			return mpris.PlayerEvent(e, busName)
+		})
+	})
+}
+
+// streamEvents writes the events of ch as SSE until the client goes away,
+// then unsubscribes. narrow, when set, may rewrite or drop each event.
+func streamEvents(
+	w http.ResponseWriter,
+	r *http.Request,
+	keepAliveDuration time.Duration,
+	ch chan events.Event,
+	unsubscribe func(chan events.Event),
+	narrow func(events.Event) (events.Event, bool),
+) {
+	defer unsubscribe(ch)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	if err := sendServerInfoToFlusher(flusher, w, "connected"); err != nil {
+		return
+	}
 
-		ch := b.SubscribeFunc(filter)
-		defer b.Unsubscribe(ch)
-		keepAlive := time.NewTimer(keepAliveDuration)
-		defer keepAlive.Stop()
+	keepAlive := time.NewTimer(keepAliveDuration)
+	defer keepAlive.Stop()
 
-		for {
-			select {
-			case <-r.Context().Done():
-				if err := sendServerInfoToFlusher(flusher, w, "bye"); err != nil {
-					logger.Warn("[sse] failed to close events connection: %v", err)
-				}
+	for {
+		select {
+		case <-r.Context().Done():
+			if err := sendServerInfoToFlusher(flusher, w, "bye"); err != nil {
+				logger.Warn("[sse] failed to close events connection: %v", err)
+			}
+			return
+		case <-keepAlive.C:
+			if err := sendServerInfoToFlusher(flusher, w, "love"); err != nil {
+				logger.Warn("[sse] failed to send keepalive, closing: %v", err)
 				return
-			case <-keepAlive.C:
-				if err := sendServerInfoToFlusher(flusher, w, "love"); err != nil {
-					logger.Warn("[sse] failed to send keepalive, closing: %v", err)
-					return
-				}
-				keepAlive.Reset(keepAliveDuration)
-			case e, ok := <-ch:
-				if !ok {
-					return
-				}
-				if e.Internal {
-					continue // bus-only event, not for external clients
-				}
-				if err := sendToFlusher(flusher, w, e); err != nil {
-					return
+			}
+			keepAlive.Reset(keepAliveDuration)
+		case e, ok := <-ch:
+			if !ok {
+				return
+			}
+			if e.Internal {
+				continue // bus-only event, not for external clients
+			}
+			if narrow != nil {
+				if e, ok = narrow(e); !ok {
+					continue
 				}
-				keepAlive.Reset(keepAliveDuration)
 			}
+			if err := sendToFlusher(flusher, w, e); err != nil {
+				return
+			}
+			keepAlive.Reset(keepAliveDuration)
 		}
 	}
 }
 
+// playerEventsLimit is how many past events GET /players/{player}/events returns.
+const playerEventsLimit = 20
+
+// eventRecord is the JSON form of a past event.
+type eventRecord struct {
+	Type string `json:"type"`
+	Data any    `json:"data"`
+}
+
+// PlayerEventsHandler returns the last events of the {player} path parameter
+// from the broadcaster history, oldest first.
+func PlayerEventsHandler(history func() []events.Event) http.HandlerFunc {
+	return withPlayer(func(w http.ResponseWriter, r *http.Request, busName string) {
+		past := history()
+		records := make([]eventRecord, 0, playerEventsLimit)
+		for i := len(past) - 1; i >= 0 && len(records) < playerEventsLimit; i-- {
+			if e, ok := mpris.PlayerEvent(past[i], busName); ok {
+				records = append(records, eventRecord{Type: e.Type, Data: e.Data})
+			}
+		}
+		slices.Reverse(records)
+
+		writeJSON(w, http.StatusOK, records)
+	})
+}
+
 func sendServerInfoToFlusher(flusher http.Flusher, w http.ResponseWriter, message string) error {
 	return sendToFlusher(
 		flusher,
@@ -95,7 +160,7 @@ func sendToFlusher(flusher http.Flusher, w http.ResponseWriter, e events.Event)
 	}
 	if _, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data); err != nil {
 		logger.Error("[sse] failed to write to flusher: %v", err)
-		http.Error(w, "failed to send data to flusher", http.StatusInternalServerError)
+		writeError(w, "failed to send data to flusher", http.StatusInternalServerError)
 		return err
 	}
 	flusher.Flush()
@@ -3,6 +3,7 @@ package api
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -384,3 +385,85 @@ func TestSSEHandler_EventDelivery(t *testing.T) {
 		t.Errorf("expected 'event: %s' line in SSE body, got: %q", events.TypePlayerUpdated, body)
 	}
 }
+
+// TestPlayerSSEHandler_FiltersByPlayer verifies only the path player's events
+// are streamed, with batched positions narrowed to it.
+func TestPlayerSSEHandler_FiltersByPlayer(t *testing.T) {
+	upstream := make(chan events.Event)
+	b := backend.NewBroadcaster(context.Background(), upstream)
+
+	req := httptest.NewRequest(http.MethodGet, "/players/org.mpris.MediaPlayer2.mpd/events/stream", nil)
+	req.SetPathValue("player", "org.mpris.MediaPlayer2.mpd")
+	ctx, cancel := context.WithCancel(context.Background())
+	req = req.WithContext(ctx)
+
+	w := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		playerSSEHandler(b)(w, req)
+	}()
+
+	time.Sleep(20 * time.Millisecond) // let the handler subscribe
+	upstream <- events.Event{Type: events.TypePlayerRemoved, Data: map[string]string{"bus_name": "org.mpris.MediaPlayer2.vlc"}}
+	upstream <- events.Event{Type: events.TypePlayerPosition, Data: []map[string]any{
+		{"bus_name": "org.mpris.MediaPlayer2.vlc", "position": int64(1)},
+		{"bus_name": "org.mpris.MediaPlayer2.mpd", "position": int64(2)},
+	}}
+	upstream <- events.Event{Type: events.TypeServiceUpdated, Data: "mpd.service"}
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	<-done
+
+	body := w.Body.String()
+	if strings.Contains(body, "vlc") {
+		t.Errorf("other player's events should be dropped, got: %q", body)
+	}
+	if strings.Contains(body, "mpd.service") {
+		t.Errorf("non-MPRIS events should be dropped, got: %q", body)
+	}
+	if !strings.Contains(body, `"position":2`) {
+		t.Errorf("expected the player's position entry, got: %q", body)
+	}
+}
+
+func TestPlayerEventsHandler(t *testing.T) {
+	var history []events.Event
+	for i := range playerEventsLimit + 5 {
+		history = append(history, events.Event{
+			Type: events.TypePlayerTracklist,
+			Data: map[string]any{"bus_name": "org.mpris.MediaPlayer2.mpd", "seq": i},
+		})
+		history = append(history, events.Event{
+			Type: events.TypePlayerTracklist,
+			Data: map[string]any{"bus_name": "org.mpris.MediaPlayer2.vlc", "seq": i},
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/players/org.mpris.MediaPlayer2.mpd/events", nil)
+	req.SetPathValue("player", "org.mpris.MediaPlayer2.mpd")
+	w := httptest.NewRecorder()
+	PlayerEventsHandler(func() []events.Event { return history })(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var got []struct {
+		Type string         `json:"type"`
+		Data map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(got) != playerEventsLimit {
+		t.Fatalf("got %d events, want %d", len(got), playerEventsLimit)
+	}
+	if first, last := got[0].Data["seq"], got[len(got)-1].Data["seq"]; first != float64(5) || last != float64(playerEventsLimit+4) {
+		t.Errorf("seq range = %v..%v, want 5..%d oldest first", first, last, playerEventsLimit+4)
+	}
+	for _, e := range got {
+		if e.Data["bus_name"] != "org.mpris.MediaPlayer2.mpd" {
+			t.Errorf("unexpected event for %v", e.Data["bus_name"])
+		}
+	}
+}
@@ -35,12 +35,12 @@ func withUpgradeAction(action func() error) http.HandlerFunc {
 		case err == nil:
 			w.WriteHeader(http.StatusAccepted)
 		case errors.Is(err, upgrade.ErrUnitNotConfigured):
-			http.Error(w, err.Error(), http.StatusNotFound)
+			writeError(w, err.Error(), http.StatusNotFound)
 		case errors.Is(err, upgrade.ErrUpgradeInProgress):
-			http.Error(w, err.Error(), http.StatusConflict)
+			writeError(w, err.Error(), http.StatusConflict)
 		default:
 			// systemd/D-Bus trigger failure upstream of us.
-			http.Error(w, err.Error(), http.StatusBadGateway)
+			writeError(w, err.Error(), http.StatusBadGateway)
 		}
 	}
 }
@@ -0,0 +1,109 @@
+package alsa
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/b0bbywan/go-odio-api/config"
+	"github.com/b0bbywan/go-odio-api/logger"
+)
+
+// "card 0: PCH [HDA Intel PCH], device 0: ALC257 Analog [ALC257 Analog]"
+var deviceLineRegex = regexp.MustCompile(`^card (\d+): (\S+) \[(.*)\], device (\d+): (.*) \[(.*)\]$`)
+
+// "  Subdevices: 1/1"
+var subdevicesRegex = regexp.MustCompile(`^\s+Subdevices: \d+/(\d+)$`)
+
+// New creates an ALSA backend. It returns nil when disabled, and wraps
+// config.ErrBackendDisabled when aplay (alsa-utils) is not installed, as the
+// backend has nothing to list then.
+func New(ctx context.Context, cfg *config.ALSAConfig) (*ALSABackend, error) {
+	if cfg == nil || !cfg.Enabled {
+		return nil, nil
+	}
+
+	aplay, err := exec.LookPath("aplay")
+	if err != nil {
+		logger.Warn("[alsa] aplay not found, install alsa-utils to list cards")
+		return nil, fmt.Errorf("%w: aplay not found: %v", config.ErrBackendDisabled, err)
+	}
+
+	return &ALSABackend{
+		ctx:     ctx,
+		aplay:   aplay,
+		timeout: cfg.Timeout,
+	}, nil
+}
+
+func (a *ALSABackend) Start() error {
+	cards, err := a.ListCards()
+	if err != nil {
+		return err
+	}
+	logger.Info("[alsa] backend started, %d playback card(s)", len(cards))
+	return nil
+}
+
+// ListCards runs `aplay -l` and returns the playback cards. The list is read
+// on each call: it is cheap and only changes when hardware is plugged.
+func (a *ALSABackend) ListCards() ([]Card, error) {
+	ctx, cancel := context.WithTimeout(a.ctx, a.timeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, a.aplay, "-l").Output()
+	if err != nil {
+		return nil, fmt.Errorf("aplay -l: %w", err)
+	}
+	return parseCards(string(out)), nil
+}
+
+// parseCards parses `aplay -l` output. aplay prints one line per device, so
+// cards are rebuilt by grouping consecutive devices of the same card index.
+func parseCards(out string) []Card {
+	cards := make([]Card, 0)
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if m := subdevicesRegex.FindStringSubmatch(line); m != nil {
+			if len(cards) == 0 {
+				continue
+			}
+			card := &cards[len(cards)-1]
+			if len(card.Devices) > 0 {
+				card.Devices[len(card.Devices)-1].Subdevices, _ = strconv.Atoi(m[1])
+			}
+			continue
+		}
+
+		m := deviceLineRegex.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		cardIndex, _ := strconv.Atoi(m[1])
+		deviceIndex, _ := strconv.Atoi(m[4])
+
+		if len(cards) == 0 || cards[len(cards)-1].Index != cardIndex {
+			cards = append(cards, Card{
+				Index:   cardIndex,
+				ID:      m[2],
+				Name:    m[3],
+				Devices: make([]Device, 0, 1),
+			})
+		}
+		card := &cards[len(cards)-1]
+		card.Devices = append(card.Devices, Device{
+			Index: deviceIndex,
+			ID:    m[5],
+			Name:  m[6],
+		})
+	}
+	return cards
+}
+
+func (a *ALSABackend) Close() {}
@@ -0,0 +1,63 @@
+package alsa
+
+import (
+	"context"
+	"testing"
+
+	"github.com/b0bbywan/go-odio-api/config"
+)
+
+const aplayOutput = `**** List of PLAYBACK Hardware Devices ****
+card 0: PCH [HDA Intel PCH], device 0: ALC257 Analog [ALC257 Analog]
+  Subdevices: 1/1
+  Subdevice #0: subdevice #0
+card 0: PCH [HDA Intel PCH], device 3: HDMI 0 [HDMI 0]
+  Subdevices: 1/1
+  Subdevice #0: subdevice #0
+card 1: sndrpihifiberry [snd_rpi_hifiberry_dacplus], device 0: HiFiBerry DAC+ HiFi pcm512x-hifi-0 [HiFiBerry DAC+ HiFi pcm512x-hifi-0]
+  Subdevices: 8/8
+  Subdevice #0: subdevice #0
+`
+
+func TestParseCards(t *testing.T) {
+	cards := parseCards(aplayOutput)
+	if len(cards) != 2 {
+		t.Fatalf("parseCards() = %d cards, want 2", len(cards))
+	}
+
+	pch := cards[0]
+	if pch.Index != 0 || pch.ID != "PCH" || pch.Name != "HDA Intel PCH" {
+		t.Errorf("cards[0] = %+v", pch)
+	}
+	if len(pch.Devices) != 2 {
+		t.Fatalf("cards[0] has %d devices, want 2", len(pch.Devices))
+	}
+	if d := pch.Devices[1]; d.Index != 3 || d.ID != "HDMI 0" || d.Subdevices != 1 {
+		t.Errorf("cards[0].Devices[1] = %+v", d)
+	}
+
+	dac := cards[1]
+	if dac.Index != 1 || dac.Name != "snd_rpi_hifiberry_dacplus" {
+		t.Errorf("cards[1] = %+v", dac)
+	}
+	if len(dac.Devices) != 1 || dac.Devices[0].Subdevices != 8 {
+		t.Errorf("cards[1].Devices = %+v", dac.Devices)
+	}
+}
+
+func TestParseCardsEmpty(t *testing.T) {
+	// aplay prints this on stderr with no soundcard; stdout is empty
+	cards := parseCards("")
+	if cards == nil || len(cards) != 0 {
+		t.Errorf("parseCards(\"\") = %#v, want empty non-nil slice", cards)
+	}
+}
+
+func TestNewDisabled(t *testing.T) {
+	for _, cfg := range []*config.ALSAConfig{nil, {Enabled: false}} {
+		a, err := New(context.Background(), cfg)
+		if a != nil || err != nil {
+			t.Errorf("New(%v) = %v, %v, want nil, nil", cfg, a, err)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package alsa
+
+import (
+	"context"
+	"time"
+)
+
+// ALSABackend lists ALSA playback hardware when PulseAudio is not in use.
+type ALSABackend struct {
+	ctx     context.Context
+	aplay   string
+	timeout time.Duration
+}
+
+// Card is an ALSA sound card with its playback devices.
+type Card struct {
+	Index   int      `json:"index"`
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Devices []Device `json:"devices"`
+}
+
+// Device is a playback PCM device of a card (hw:<card>,<device>).
+type Device struct {
+	Index      int    `json:"index"`
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Subdevices int    `json:"subdevices"`
+}
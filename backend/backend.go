@@ -2,73 +2,165 @@ package backend
 
 import (
 	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/b0bbywan/go-odio-api/backend/alsa"
 	"github.com/b0bbywan/go-odio-api/backend/bluetooth"
+	"github.com/b0bbywan/go-odio-api/backend/gpio"
 	"github.com/b0bbywan/go-odio-api/backend/login1"
 	"github.com/b0bbywan/go-odio-api/backend/mpris"
 	"github.com/b0bbywan/go-odio-api/backend/pulseaudio"
+	"github.com/b0bbywan/go-odio-api/backend/snapcast"
 	"github.com/b0bbywan/go-odio-api/backend/systemd"
 	"github.com/b0bbywan/go-odio-api/backend/upgrade"
 	"github.com/b0bbywan/go-odio-api/backend/zeroconf"
 	"github.com/b0bbywan/go-odio-api/config"
+	"github.com/b0bbywan/go-odio-api/logger"
+	"github.com/b0bbywan/go-odio-api/plugin"
 )
 
 type Backend struct {
+	ALSA      *alsa.ALSABackend
 	Bluetooth *bluetooth.BluetoothBackend
+	GPIO      *gpio.GPIOBackend
 	Login1    *login1.Login1Backend
 	MPRIS     *mpris.MPRISBackend
 	Pulse     *pulseaudio.PulseAudioBackend
+	Snapcast  *snapcast.SnapcastBackend
 	Systemd   *systemd.SystemdBackend
 	Upgrade   *upgrade.UpgradeBackend
 	Zeroconf  *zeroconf.ZeroConfBackend
+	Plugins   []plugin.Backend
 
 	broadcaster *Broadcaster
+	diagnostics *Diagnostics
+	refreshMu   sync.Mutex // serializes RefreshCaches
 }
 
 func New(
 	ctx context.Context,
+	alsacfg *config.ALSAConfig,
 	btcfg *config.BluetoothConfig,
+	evcfg *config.EventsConfig,
+	gpiocfg *config.GPIOConfig,
 	login1cfg *config.Login1Config,
 	mpriscfg *config.MPRISConfig,
+	plugincfg *config.PluginConfig,
 	pulscfg *config.PulseAudioConfig,
+	snapcfg *config.SnapcastConfig,
 	syscfg *config.SystemdConfig,
 	upgcfg *config.UpgradeConfig,
 	zerocfg *config.ZeroConfig,
 ) (*Backend, error) {
 	var b Backend
 	var err error
+	b.diagnostics = newDiagnostics(btcfg, syscfg)
+	diag := b.diagnostics
 
-	if b.Bluetooth, err = bluetooth.New(ctx, btcfg); err != nil {
+	// D-Bus backends are bounded by their startup timeout: their constructor
+	// gets a startCtx with that deadline, and a stalled service disables its
+	// backend instead of blocking startup. ctx remains their lifetime context.
+	btEnabled := btcfg != nil && btcfg.Enabled
+	if btEnabled {
+		b.Bluetooth, err = withStartupTimeout(ctx, "bluetooth", btcfg.StartupTimeout, func(startCtx context.Context) (*bluetooth.BluetoothBackend, error) {
+			return bluetooth.New(ctx, startCtx, btcfg)
+		})
+	}
+	if err = diag.record("bluetooth", btEnabled, b.Bluetooth != nil, err); err != nil {
 		return nil, err
 	}
 
-	if b.Login1, err = login1.New(ctx, login1cfg); err != nil {
+	b.Login1, err = login1.New(ctx, login1cfg)
+	if err = diag.record("power", login1cfg != nil && login1cfg.Enabled, b.Login1 != nil, err); err != nil {
 		return nil, err
 	}
 
-	if b.MPRIS, err = mpris.New(ctx, mpriscfg); err != nil {
+	mprisEnabled := mpriscfg != nil && mpriscfg.Enabled
+	if mprisEnabled {
+		b.MPRIS, err = withStartupTimeout(ctx, "mpris", mpriscfg.StartupTimeout, func(startCtx context.Context) (*mpris.MPRISBackend, error) {
+			return mpris.New(ctx, startCtx, mpriscfg)
+		})
+	}
+	if err = diag.record("mpris", mprisEnabled, b.MPRIS != nil, err); err != nil {
 		return nil, err
 	}
 
-	if b.Pulse, err = pulseaudio.New(ctx, pulscfg); err != nil {
+	// PulseAudio's New does no I/O: its startup timeout bounds the first dial,
+	// made by Start.
+	b.Pulse, err = pulseaudio.New(ctx, pulscfg)
+	if err = diag.record("pulseaudio", pulscfg != nil && pulscfg.Enabled, b.Pulse != nil, err); err != nil {
 		return nil, err
 	}
 
-	if b.Systemd, err = systemd.New(ctx, syscfg); err != nil {
+	// ALSA is a read-only fallback for setups without PulseAudio.
+	alsaEnabled := alsacfg != nil && alsacfg.Enabled
+	if b.Pulse == nil {
+		b.ALSA, err = alsa.New(ctx, alsacfg)
+		if err = diag.record("alsa", alsaEnabled, b.ALSA != nil, err); err != nil {
+			return nil, err
+		}
+	} else {
+		if alsaEnabled {
+			logger.Info("[backend] PulseAudio enabled, ALSA fallback not used")
+		}
+		diag.skip("alsa", alsaEnabled, "not used while PulseAudio is running")
+	}
+
+	b.Snapcast, err = snapcast.New(ctx, snapcfg)
+	if err = diag.record("snapcast", snapcfg != nil && snapcfg.Enabled, b.Snapcast != nil, err); err != nil {
+		return nil, err
+	}
+
+	sysEnabled := syscfg != nil && syscfg.Enabled
+	if sysEnabled {
+		b.Systemd, err = withStartupTimeout(ctx, "systemd", syscfg.StartupTimeout, func(startCtx context.Context) (*systemd.SystemdBackend, error) {
+			return systemd.New(ctx, startCtx, syscfg)
+		})
+	}
+	if err = diag.record("systemd", sysEnabled, b.Systemd != nil, err); err != nil {
 		return nil, err
 	}
 
 	// Upgrade delegates unit triggers to the systemd backend, so it must be
 	// created after it.
-	if b.Upgrade, err = upgrade.New(ctx, upgcfg, b.Systemd); err != nil {
+	b.Upgrade, err = upgrade.New(ctx, upgcfg, b.Systemd)
+	if err = diag.record("upgrade", upgcfg != nil && upgcfg.Enabled, b.Upgrade != nil, err); err != nil {
+		return nil, err
+	}
+
+	b.Zeroconf, err = zeroconf.New(ctx, zerocfg)
+	if err = diag.record("zeroconf", zerocfg != nil && zerocfg.Enabled, b.Zeroconf != nil, err); err != nil {
 		return nil, err
 	}
 
-	if b.Zeroconf, err = zeroconf.New(ctx, zerocfg); err != nil {
+	// GPIO buttons drive the other backends, so they must exist first.
+	b.GPIO, err = gpio.New(ctx, gpiocfg, b.gpioActions())
+	if err = diag.record("gpio", gpiocfg != nil && gpiocfg.Enabled, b.GPIO != nil, err); err != nil {
 		return nil, err
 	}
 
-	b.broadcaster = newBroadcasterFromBackend(ctx, &b)
+	// Plugins come last so they can never shadow a built-in backend.
+	if plugincfg != nil && len(plugincfg.Paths) > 0 && !plugin.Supported {
+		logger.Warn("[backend] plugins unsupported in this build (needs cgo), skipping %d plugin(s)", len(plugincfg.Paths))
+	} else if plugincfg != nil {
+		for _, path := range plugincfg.Paths {
+			p, err := plugin.Load(path)
+			if err != nil {
+				return nil, err
+			}
+			logger.Info("[backend] plugin %s loaded", path)
+			b.Plugins = append(b.Plugins, p)
+		}
+	}
+
+	var dedupWindow time.Duration
+	if evcfg != nil {
+		dedupWindow = evcfg.DedupWindow
+	}
+	b.broadcaster = newBroadcasterFromBackend(ctx, &b, dedupWindow)
 
 	// Upgrade consumes the bus to track its run unit's lifecycle (a service.updated
 	// event); wired here, once the broadcaster exists.
@@ -85,7 +177,34 @@ func (b *Backend) Broadcaster() *Broadcaster {
 	return b.broadcaster
 }
 
+// Diagnostics returns a copy of the startup decisions made by New.
+func (b *Backend) Diagnostics() Diagnostics {
+	if b.diagnostics == nil {
+		return Diagnostics{Backends: map[string]BackendStatus{}}
+	}
+	return *b.diagnostics
+}
+
+// Connections reports whether each backend holding a long-lived connection
+// (MPRIS session bus, audio server) is currently connected.
+func (b *Backend) Connections() map[string]bool {
+	conns := make(map[string]bool)
+	if b.MPRIS != nil {
+		conns["mpris"] = b.MPRIS.Connected()
+	}
+	if b.Pulse != nil {
+		conns["pulseaudio"] = b.Pulse.Connected()
+	}
+	return conns
+}
+
 func (b *Backend) Start() error {
+	if b.ALSA != nil {
+		if err := b.ALSA.Start(); err != nil {
+			return err
+		}
+	}
+
 	if b.Bluetooth != nil {
 		if err := b.Bluetooth.Start(); err != nil {
 			return err
@@ -104,6 +223,12 @@ func (b *Backend) Start() error {
 		}
 	}
 
+	if b.Snapcast != nil {
+		if err := b.Snapcast.Start(); err != nil {
+			return err
+		}
+	}
+
 	if b.Systemd != nil {
 		if err := b.Systemd.Start(); err != nil {
 			return err
@@ -122,10 +247,79 @@ func (b *Backend) Start() error {
 		}
 	}
 
+	if b.GPIO != nil {
+		if err := b.GPIO.Start(); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range b.Plugins {
+		if err := p.Start(); err != nil {
+			return err
+		}
+	}
+
+	b.announceRunningBackends()
 	return nil
 }
 
+// announceRunningBackends corrects the backend flags zeroconf announces from
+// the config (bt=1...) once every backend started: one New disabled, e.g.
+// login1 without any allowed action, is announced as 0.
+func (b *Backend) announceRunningBackends() {
+	if b.Zeroconf == nil {
+		return
+	}
+	current := b.Zeroconf.TxtRecords()
+	records := runningTxtRecords(current, b.runningBackends())
+	if slices.Equal(records, current) {
+		return
+	}
+	if err := b.Zeroconf.UpdateTxtRecords(records); err != nil {
+		logger.Warn("[zeroconf] failed to update TXT records: %v", err)
+	}
+}
+
+// runningBackends maps the zeroconf backend flags to whether they run.
+func (b *Backend) runningBackends() map[string]bool {
+	return map[string]bool{
+		"alsa":     b.ALSA != nil,
+		"bt":       b.Bluetooth != nil,
+		"gpio":     b.GPIO != nil,
+		"mpris":    b.MPRIS != nil,
+		"power":    b.Login1 != nil,
+		"pulse":    b.Pulse != nil,
+		"snapcast": b.Snapcast != nil,
+		"systemd":  b.Systemd != nil,
+		"upgrade":  b.Upgrade != nil,
+	}
+}
+
+// runningTxtRecords rewrites the flag records of records (key=0|1) from
+// running; any other record is kept as is.
+func runningTxtRecords(records []string, running map[string]bool) []string {
+	out := make([]string, 0, len(records))
+	for _, r := range records {
+		key, _, _ := strings.Cut(r, "=")
+		if on, ok := running[key]; ok {
+			r = key + "=0"
+			if on {
+				r = key + "=1"
+			}
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
 func (b *Backend) Close() {
+	// Release the buttons first so no press reaches a closing backend.
+	if b.GPIO != nil {
+		b.GPIO.Close()
+	}
+	if b.ALSA != nil {
+		b.ALSA.Close()
+	}
 	if b.Bluetooth != nil {
 		b.Bluetooth.Close()
 	}
@@ -138,6 +332,9 @@ func (b *Backend) Close() {
 	if b.Pulse != nil {
 		b.Pulse.Close()
 	}
+	if b.Snapcast != nil {
+		b.Snapcast.Close()
+	}
 	if b.Systemd != nil {
 		b.Systemd.Close()
 	}
@@ -147,4 +344,37 @@ func (b *Backend) Close() {
 	if b.Zeroconf != nil {
 		b.Zeroconf.Close()
 	}
+	for _, p := range b.Plugins {
+		p.Close()
+	}
+}
+
+// gpioActions binds the GPIO action names to the enabled backends. MPRIS
+// actions target the active player at press time.
+func (b *Backend) gpioActions() map[string]gpio.Action {
+	actions := make(map[string]gpio.Action)
+	if m := b.MPRIS; m != nil {
+		onActive := func(fn func(string) error) gpio.Action {
+			return func() error {
+				busName, err := m.ActivePlayer()
+				if err != nil {
+					return err
+				}
+				return fn(busName)
+			}
+		}
+		actions[gpio.ActionMPRISPlay] = onActive(m.Play)
+		actions[gpio.ActionMPRISPause] = onActive(m.Pause)
+		actions[gpio.ActionMPRISPlayPause] = onActive(m.PlayPause)
+		actions[gpio.ActionMPRISStop] = onActive(m.Stop)
+		actions[gpio.ActionMPRISNext] = onActive(m.Next)
+		actions[gpio.ActionMPRISPrevious] = onActive(m.Previous)
+	}
+	if b.Pulse != nil {
+		actions[gpio.ActionAudioMute] = b.Pulse.ToggleMuteMaster
+	}
+	if b.Bluetooth != nil {
+		actions[gpio.ActionBluetoothPair] = b.Bluetooth.NewPairing
+	}
+	return actions
 }
@@ -2,10 +2,16 @@ package backend
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/b0bbywan/go-odio-api/backend/login1"
+	"github.com/b0bbywan/go-odio-api/backend/mpris"
+	"github.com/b0bbywan/go-odio-api/backend/zeroconf"
 	"github.com/b0bbywan/go-odio-api/config"
 )
 
@@ -70,7 +76,7 @@ func TestBackendDisabled(t *testing.T) {
 			zeroconfCfg := &config.ZeroConfig{Enabled: tt.zeroconfEnabled}
 			upgradeCfg := &config.UpgradeConfig{Enabled: tt.upgradeEnabled}
 
-			backend, err := New(ctx, bluetoothCfg, login1Cfg, mprisCfg, pulseCfg, systemdCfg, upgradeCfg, zeroconfCfg)
+			backend, err := New(ctx, &config.ALSAConfig{}, bluetoothCfg, &config.EventsConfig{}, &config.GPIOConfig{}, login1Cfg, mprisCfg, &config.PluginConfig{}, pulseCfg, &config.SnapcastConfig{}, systemdCfg, upgradeCfg, zeroconfCfg)
 
 			// Bluetooth and other D-Bus backends may fail in test environment
 			// This is expected and we should skip the test
@@ -115,6 +121,79 @@ func TestBackendDisabled(t *testing.T) {
 	}
 }
 
+// TestBackendDiagnostics verifies that New records why an enabled backend is
+// not running, and leaves disabled ones without an error.
+func TestBackendDiagnostics(t *testing.T) {
+	backend, err := New(
+		context.Background(),
+		&config.ALSAConfig{},
+		&config.BluetoothConfig{Enabled: false},
+		&config.EventsConfig{},
+		&config.GPIOConfig{},
+		&config.Login1Config{Enabled: false},
+		&config.MPRISConfig{Enabled: false},
+		&config.PluginConfig{},
+		&config.PulseAudioConfig{Enabled: false},
+		&config.SnapcastConfig{},
+		&config.SystemdConfig{Enabled: true},
+		&config.UpgradeConfig{Enabled: false},
+		&config.ZeroConfig{Enabled: false},
+	)
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	diag := backend.Diagnostics()
+	want := BackendStatus{Enabled: true, Error: "systemd: backend disabled: no unit configured"}
+	if got := diag.Backends["systemd"]; got != want {
+		t.Errorf("systemd status = %+v, want %+v", got, want)
+	}
+	if got := diag.Backends["mpris"]; got != (BackendStatus{}) {
+		t.Errorf("mpris status = %+v, want disabled without error", got)
+	}
+	if len(diag.Backends) != 10 {
+		t.Errorf("got %d backend statuses, want 10", len(diag.Backends))
+	}
+	if diag.Systemd == nil || diag.Systemd.SystemUnits != 0 || diag.Systemd.UserUnits != 0 {
+		t.Errorf("systemd diagnostics = %+v, want zero watched units", diag.Systemd)
+	}
+	if diag.Bluetooth != nil {
+		t.Errorf("bluetooth diagnostics = %+v, want nil when disabled", diag.Bluetooth)
+	}
+}
+
+func TestDiagnosticsRecord(t *testing.T) {
+	d := newDiagnostics(nil, nil)
+
+	timeout := &startupTimeoutError{name: "mpris", timeout: time.Second}
+	if err := d.record("mpris", true, false, timeout); err != nil {
+		t.Errorf("record() with a startup timeout = %v, want nil", err)
+	}
+	if got := d.Backends["mpris"].Error; got != timeout.Error() {
+		t.Errorf("mpris error = %q, want %q", got, timeout.Error())
+	}
+
+	disabled := fmt.Errorf("%w: aplay not found", config.ErrBackendDisabled)
+	if err := d.record("alsa", true, false, disabled); err != nil {
+		t.Errorf("record() with a disabled backend = %v, want nil", err)
+	}
+	if got, want := d.Backends["alsa"].Error, "alsa: backend disabled: aplay not found"; got != want {
+		t.Errorf("alsa error = %q, want %q", got, want)
+	}
+
+	if err := d.record("snapcast", true, false, nil); err != nil {
+		t.Errorf("record() = %v, want nil", err)
+	}
+	if got, want := d.Backends["snapcast"].Error, "snapcast: "+notStarted; got != want {
+		t.Errorf("snapcast error = %q, want %q", got, want)
+	}
+
+	boom := errors.New("boom")
+	if err := d.record("pulseaudio", true, false, boom); !errors.Is(err, boom) {
+		t.Errorf("record() = %v, want %v", err, boom)
+	}
+}
+
 // TestSystemdWithEmptyConfig verifies systemd backend is nil with no services configured
 func TestSystemdWithEmptyConfig(t *testing.T) {
 	ctx := context.Background()
@@ -127,10 +206,15 @@ func TestSystemdWithEmptyConfig(t *testing.T) {
 
 	backend, err := New(
 		ctx,
+		&config.ALSAConfig{Enabled: false},
 		&config.BluetoothConfig{Enabled: false},
+		&config.EventsConfig{},
+		&config.GPIOConfig{Enabled: false},
 		&config.Login1Config{Enabled: false},
 		&config.MPRISConfig{Enabled: false},
+		&config.PluginConfig{},
 		&config.PulseAudioConfig{Enabled: false},
+		&config.SnapcastConfig{Enabled: false},
 		systemdCfg,
 		&config.UpgradeConfig{Enabled: false},
 		&config.ZeroConfig{Enabled: false},
@@ -156,10 +240,15 @@ func TestZeroconfWithLocalhostBind(t *testing.T) {
 
 	backend, err := New(
 		ctx,
+		&config.ALSAConfig{Enabled: false},
 		&config.BluetoothConfig{Enabled: false},
+		&config.EventsConfig{},
+		&config.GPIOConfig{Enabled: false},
 		&config.Login1Config{Enabled: false},
 		&config.MPRISConfig{Enabled: false},
+		&config.PluginConfig{},
 		&config.PulseAudioConfig{Enabled: false},
+		&config.SnapcastConfig{Enabled: false},
 		&config.SystemdConfig{Enabled: false},
 		&config.UpgradeConfig{Enabled: false},
 		zeroconfCfg,
@@ -213,10 +302,15 @@ func TestLogin1DisabledInBackend(t *testing.T) {
 
 	backend, err := New(
 		ctx,
+		&config.ALSAConfig{Enabled: false},
 		&config.BluetoothConfig{Enabled: false},
+		&config.EventsConfig{},
+		&config.GPIOConfig{Enabled: false},
 		login1Cfg,
 		&config.MPRISConfig{Enabled: false},
+		&config.PluginConfig{},
 		&config.PulseAudioConfig{Enabled: false},
+		&config.SnapcastConfig{Enabled: false},
 		&config.SystemdConfig{Enabled: false},
 		&config.UpgradeConfig{Enabled: false},
 		&config.ZeroConfig{Enabled: false},
@@ -245,10 +339,15 @@ func TestLogin1DisabledWithCapabilities(t *testing.T) {
 
 	backend, err := New(
 		ctx,
+		&config.ALSAConfig{Enabled: false},
 		&config.BluetoothConfig{Enabled: false},
+		&config.EventsConfig{},
+		&config.GPIOConfig{Enabled: false},
 		login1Cfg,
 		&config.MPRISConfig{Enabled: false},
+		&config.PluginConfig{},
 		&config.PulseAudioConfig{Enabled: false},
+		&config.SnapcastConfig{Enabled: false},
 		&config.SystemdConfig{Enabled: false},
 		&config.UpgradeConfig{Enabled: false},
 		&config.ZeroConfig{Enabled: false},
@@ -282,10 +381,15 @@ func TestBackendNew_Login1FieldInitialisedToNil(t *testing.T) {
 
 	backend, err := New(
 		ctx,
+		&config.ALSAConfig{Enabled: false},
 		&config.BluetoothConfig{Enabled: false},
+		&config.EventsConfig{},
+		&config.GPIOConfig{Enabled: false},
 		&config.Login1Config{Enabled: false},
 		&config.MPRISConfig{Enabled: false},
+		&config.PluginConfig{},
 		&config.PulseAudioConfig{Enabled: false},
+		&config.SnapcastConfig{Enabled: false},
 		&config.SystemdConfig{Enabled: false, SystemServices: []config.SystemdService{}, UserServices: []config.SystemdService{}},
 		&config.UpgradeConfig{Enabled: false},
 		&config.ZeroConfig{Enabled: false},
@@ -349,3 +453,67 @@ func TestGetServerDeviceInfo_PowerField(t *testing.T) {
 func TestNew_Login1NoCapabilityEnabled_RequiresDbus(t *testing.T) {
 	t.Skip("reaching the 'no capability enabled' early-return requires a live D-Bus system connection; tested via integration tests")
 }
+
+func TestStateWithoutBackends(t *testing.T) {
+	st := (&Backend{}).State()
+	if st.Players != nil || st.AudioServer != nil || st.Services != nil || st.Bluetooth != nil || st.Errors != nil {
+		t.Errorf("State() of a backend-less server = %+v, want empty", st)
+	}
+}
+
+func TestRefreshCachesWithoutBackends(t *testing.T) {
+	b := &Backend{}
+	for range 2 { // idempotent
+		if got := b.RefreshCaches(); len(got) != 0 {
+			t.Errorf("RefreshCaches() = %v, want no entries", got)
+		}
+	}
+}
+
+func TestGetNetworkInfo(t *testing.T) {
+	b := &Backend{}
+	info := b.GetNetworkInfo()
+	if info.Zeroconf.Active || info.Zeroconf.Interfaces == nil || info.Listens == nil {
+		t.Errorf("without zeroconf: got %+v, want inactive with empty (non-nil) lists", info)
+	}
+
+	b.Zeroconf = &zeroconf.ZeroConfBackend{Config: &config.ZeroConfig{
+		ServiceType: "_http._tcp",
+		Port:        8018,
+		Listen:      []net.Interface{{Name: "eth0"}, {Name: "wlan0"}},
+	}}
+	info = b.GetNetworkInfo()
+	if !info.Zeroconf.Active || info.Zeroconf.Port != 8018 {
+		t.Errorf("with zeroconf: got %+v", info.Zeroconf)
+	}
+	if got := info.Zeroconf.Interfaces; len(got) != 2 || got[0] != "eth0" || got[1] != "wlan0" {
+		t.Errorf("Interfaces = %v, want [eth0 wlan0]", got)
+	}
+}
+
+func TestRunningTxtRecords(t *testing.T) {
+	records := []string{"version=1.0", "bt=1", "power=1", "mpris=0", "custom=1"}
+	running := map[string]bool{"bt": true, "power": false, "mpris": true}
+
+	got := runningTxtRecords(records, running)
+	want := []string{"version=1.0", "bt=1", "power=0", "mpris=1", "custom=1"}
+	if !slices.Equal(got, want) {
+		t.Errorf("runningTxtRecords() = %v, want %v", got, want)
+	}
+}
+
+// TestGetServerDeviceInfo_CacheAges: backends whose cache never loaded are
+// left out rather than reported with a zero time.
+func TestGetServerDeviceInfo_CacheAges(t *testing.T) {
+	b := &Backend{MPRIS: &mpris.MPRISBackend{}}
+	info, err := b.GetServerDeviceInfo()
+	if err != nil {
+		t.Fatalf("GetServerDeviceInfo() returned error: %v", err)
+	}
+	if info.CacheAges == nil {
+		t.Fatal("CacheAges should be an empty map, not nil")
+	}
+	if at, ok := info.CacheAges["mpris"]; ok {
+		t.Errorf("CacheAges[mpris] = %v, want absent before the first load", at)
+	}
+}
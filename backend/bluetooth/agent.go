@@ -1,6 +1,8 @@
 package bluetooth
 
 import (
+	"fmt"
+
 	"github.com/godbus/dbus/v5"
 
 	"github.com/b0bbywan/go-odio-api/logger"
@@ -26,8 +28,16 @@ func (a *bluezAgent) Release() *dbus.Error {
 	return nil
 }
 
+// RequestPinCode answers defaultPinCode as NoInputNoOutput; as DisplayYesNo
+// it rejects the legacy pairing, which the user could not confirm.
 func (a *bluezAgent) RequestPinCode(device dbus.ObjectPath) (string, *dbus.Error) {
 	logger.Debug("[bluetooth] Agent.RequestPinCode for %s", device)
+	if err := a.agentAllows(device); err != nil {
+		return "", err
+	}
+	if err := a.rejectUnconfirmable(device); err != nil {
+		return "", err
+	}
 	a.backend.trustDevice(device)
 	return defaultPinCode, nil
 }
@@ -37,8 +47,16 @@ func (a *bluezAgent) DisplayPinCode(device dbus.ObjectPath, pincode string) *dbu
 	return nil
 }
 
+// RequestPasskey answers defaultPassKey as NoInputNoOutput; as DisplayYesNo
+// it rejects the pairing, like RequestPinCode.
 func (a *bluezAgent) RequestPasskey(device dbus.ObjectPath) (uint32, *dbus.Error) {
 	logger.Debug("[bluetooth] Agent.RequestPasskey for %s", device)
+	if err := a.agentAllows(device); err != nil {
+		return 0, err
+	}
+	if err := a.rejectUnconfirmable(device); err != nil {
+		return 0, err
+	}
 	a.backend.trustDevice(device)
 	return defaultPassKey, nil
 }
@@ -48,23 +66,59 @@ func (a *bluezAgent) DisplayPasskey(device dbus.ObjectPath, passkey uint32, ente
 	return nil
 }
 
+// RequestConfirmation auto-accepts as NoInputNoOutput; as DisplayYesNo it
+// blocks until the user confirms the passkey over the API.
 func (a *bluezAgent) RequestConfirmation(device dbus.ObjectPath, passkey uint32) *dbus.Error {
-	logger.Debug("[bluetooth] Agent.RequestConfirmation for %s (passkey: %06d) — auto-accepting", device, passkey)
+	if err := a.agentAllows(device); err != nil {
+		return err
+	}
+	if a.backend.confirmsPairing() {
+		logger.Debug("[bluetooth] Agent.RequestConfirmation for %s (passkey: %06d) — awaiting user", device, passkey)
+		if !a.backend.awaitConfirmation(device, fmt.Sprintf("%06d", passkey)) {
+			return dbus.NewError(AGENT_REJECTED, nil)
+		}
+	} else {
+		logger.Debug("[bluetooth] Agent.RequestConfirmation for %s (passkey: %06d) — auto-accepting", device, passkey)
+	}
 	a.backend.trustDevice(device)
 	return nil
 }
 
+// RequestAuthorization auto-accepts an incoming pairing as NoInputNoOutput;
+// as DisplayYesNo it blocks until the user accepts it over the API, the
+// pending confirmation then carrying no passkey.
 func (a *bluezAgent) RequestAuthorization(device dbus.ObjectPath) *dbus.Error {
-	logger.Debug("[bluetooth] Agent.RequestAuthorization for %s — auto-accepting", device)
+	if err := a.agentAllows(device); err != nil {
+		return err
+	}
+	if !a.backend.confirmsPairing() {
+		logger.Debug("[bluetooth] Agent.RequestAuthorization for %s — auto-accepting", device)
+		return nil
+	}
+	logger.Debug("[bluetooth] Agent.RequestAuthorization for %s — awaiting user", device)
+	if !a.backend.awaitConfirmation(device, "") {
+		return dbus.NewError(AGENT_REJECTED, nil)
+	}
 	return nil
 }
 
+// rejectUnconfirmable refuses, as DisplayYesNo, the PIN and passkey requests
+// of legacy pairing: answering a fixed code would pair without the user.
+func (a *bluezAgent) rejectUnconfirmable(device dbus.ObjectPath) *dbus.Error {
+	if !a.backend.confirmsPairing() {
+		return nil
+	}
+	logger.Info("[bluetooth] rejecting legacy pairing with %s: it cannot be confirmed", addressFromPath(device))
+	return dbus.NewError(AGENT_REJECTED, nil)
+}
+
 func (a *bluezAgent) AuthorizeService(device dbus.ObjectPath, uuid string) *dbus.Error {
 	logger.Debug("[bluetooth] Agent.AuthorizeService for %s (uuid: %s) — auto-accepting", device, uuid)
-	return nil
+	return a.agentAllows(device)
 }
 
 func (a *bluezAgent) Cancel() *dbus.Error {
 	logger.Debug("[bluetooth] Agent.Cancel called")
+	a.backend.cancelConfirmation()
 	return nil
 }
@@ -0,0 +1,94 @@
+package bluetooth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/b0bbywan/go-odio-api/logger"
+)
+
+// autoConnectTrusted reconnects every trusted, disconnected device after a
+// power-up, for speakers that never initiate the connection themselves.
+// At most autoConnectConcurrency devices connect at once, each bounded by
+// autoConnectTimeout so an unreachable one cannot hold a slot forever.
+func (b *BluetoothBackend) autoConnectTrusted() {
+	devices, err := b.listDevices()
+	if err != nil {
+		logger.Warn("[bluetooth] autoconnect: failed to list devices: %v", err)
+		return
+	}
+	addresses := autoConnectCandidates(devices)
+	if len(addresses) == 0 {
+		logger.Debug("[bluetooth] autoconnect: no trusted device to reconnect")
+		return
+	}
+
+	logger.Info("[bluetooth] autoconnect: trying %d trusted device(s)", len(addresses))
+	connectBounded(b.ctx, addresses, b.autoConnectConcurrency, b.autoConnectTimeout, b.Connect,
+		func(address string, err error) {
+			if err != nil {
+				logger.Warn("[bluetooth] autoconnect: %s failed: %v", address, err)
+				return
+			}
+			logger.Info("[bluetooth] autoconnect: %s connected", address)
+		})
+}
+
+// autoConnectCandidates returns the addresses of trusted devices that are not
+// already connected.
+func autoConnectCandidates(devices []BluetoothDevice) []string {
+	var addresses []string
+	for _, d := range devices {
+		if d.Trusted && !d.Connected {
+			addresses = append(addresses, d.Address)
+		}
+	}
+	return addresses
+}
+
+// connectBounded runs connect on every address with at most limit calls in
+// flight, reporting each outcome through done. A call still running after
+// timeout is reported as a timeout and its slot released; the D-Bus call
+// itself ends on its own within the backend's call timeout.
+func connectBounded(
+	ctx context.Context,
+	addresses []string,
+	limit int,
+	timeout time.Duration,
+	connect func(string) error,
+	done func(string, error),
+) {
+	if limit < 1 {
+		limit = 1
+	}
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+
+	for _, address := range addresses {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		}
+		wg.Add(1)
+		go func(address string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result := make(chan error, 1)
+			go func() { result <- connect(address) }()
+
+			select {
+			case err := <-result:
+				done(address, err)
+			case <-time.After(timeout):
+				done(address, &dbusTimeoutError{})
+			case <-ctx.Done():
+				done(address, ctx.Err())
+			}
+		}(address)
+	}
+	wg.Wait()
+}
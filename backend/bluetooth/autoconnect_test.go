@@ -0,0 +1,99 @@
+package bluetooth
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAutoConnectCandidates(t *testing.T) {
+	devices := []BluetoothDevice{
+		{Address: "AA:AA:AA:AA:AA:AA", Trusted: true},
+		{Address: "BB:BB:BB:BB:BB:BB", Trusted: true, Connected: true},
+		{Address: "CC:CC:CC:CC:CC:CC", Paired: true},
+		{Address: "DD:DD:DD:DD:DD:DD", Trusted: true, Bonded: true},
+	}
+
+	got := autoConnectCandidates(devices)
+	want := []string{"AA:AA:AA:AA:AA:AA", "DD:DD:DD:DD:DD:DD"}
+	if !slices.Equal(got, want) {
+		t.Errorf("autoConnectCandidates() = %v, want %v", got, want)
+	}
+}
+
+func TestConnectBoundedLimitsConcurrency(t *testing.T) {
+	addresses := []string{"a", "b", "c", "d", "e"}
+	var inFlight, peak atomic.Int32
+
+	connect := func(string) error {
+		n := inFlight.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		inFlight.Add(-1)
+		return nil
+	}
+
+	var mu sync.Mutex
+	results := map[string]error{}
+	connectBounded(context.Background(), addresses, 2, time.Second, connect, func(a string, err error) {
+		mu.Lock()
+		results[a] = err
+		mu.Unlock()
+	})
+
+	if len(results) != len(addresses) {
+		t.Fatalf("got %d outcomes, want %d", len(results), len(addresses))
+	}
+	if p := peak.Load(); p > 2 {
+		t.Errorf("peak concurrency = %d, want <= 2", p)
+	}
+}
+
+func TestConnectBoundedTimeout(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+
+	connect := func(address string) error {
+		if address == "stuck" {
+			<-block
+			return nil
+		}
+		if address == "broken" {
+			return errors.New("refused")
+		}
+		return nil
+	}
+
+	var mu sync.Mutex
+	results := map[string]error{}
+	start := time.Now()
+	connectBounded(context.Background(), []string{"stuck", "ok", "broken"}, 1, 50*time.Millisecond, connect,
+		func(a string, err error) {
+			mu.Lock()
+			results[a] = err
+			mu.Unlock()
+		})
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("connectBounded took %v, stuck device should not stall the others", elapsed)
+	}
+	var timeoutErr *dbusTimeoutError
+	if !errors.As(results["stuck"], &timeoutErr) {
+		t.Errorf("stuck error = %v, want timeout", results["stuck"])
+	}
+	if results["ok"] != nil {
+		t.Errorf("ok error = %v, want nil", results["ok"])
+	}
+	if results["broken"] == nil {
+		t.Error("broken error = nil, want an error")
+	}
+}
@@ -13,43 +13,70 @@ import (
 	"github.com/b0bbywan/go-odio-api/logger"
 )
 
-// New creates a new Bluetooth backend
-func New(ctx context.Context, cfg *config.BluetoothConfig) (*BluetoothBackend, error) {
+// New creates a new Bluetooth backend. ctx bounds its lifetime, startCtx only
+// the system bus dial and the BlueZ queries made while starting.
+func New(ctx, startCtx context.Context, cfg *config.BluetoothConfig) (*BluetoothBackend, error) {
 	if cfg == nil || !cfg.Enabled {
 		return nil, nil
 	}
 
-	conn, err := dbus.ConnectSystemBus()
+	policy, err := newDevicePolicy(cfg.AllowList, cfg.BlockList)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := dialBus(startCtx)
 	if err != nil {
 		return nil, err
 	}
 
 	backend := BluetoothBackend{
-		conn:           conn,
-		ctx:            ctx,
-		timeout:        cfg.Timeout,
-		pairingTimeout: cfg.PairingTimeout,
-		idleTimeout:    cfg.IdleTimeout,
-		scanTimeout:    cfg.ScanTimeout,
-		powerOnStart:   cfg.PowerOnStart,
-		statusCache:    cache.New[BluetoothStatus](0), // no expiration
-		events:         make(chan events.Event, 16),
-	}
-
-	if err = backend.CheckBluetoothSupport(); err != nil {
-		logger.Error("[bluetooth] Not Supported")
+		conn:                   conn,
+		ctx:                    ctx,
+		timeout:                cfg.Timeout,
+		pairingTimeout:         cfg.PairingTimeout,
+		idleTimeout:            cfg.IdleTimeout,
+		idlePowerOff:           cfg.IdlePowerOff,
+		scanTimeout:            cfg.ScanTimeout,
+		powerOnStart:           cfg.PowerOnStart,
+		autoPowerUp:            cfg.AutoPowerUp,
+		autoConnect:            cfg.AutoConnect,
+		autoConnectTimeout:     cfg.AutoConnectTimeout,
+		autoConnectConcurrency: cfg.AutoConnectConcurrency,
+		policy:                 policy,
+		agentCapability:        cfg.AgentCapability,
+		statusCache:            cache.New[BluetoothStatus](0), // no expiration
+		events:                 make(chan events.Event, 16),
+	}
+
+	if err = backend.CheckBluetoothSupport(startCtx); err != nil {
 		backend.Close()
-		return nil, nil
+		if ctxErr := startCtx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		logger.Error("[bluetooth] Not Supported")
+		return nil, fmt.Errorf("%w: bluetooth not supported: %v", config.ErrBackendDisabled, err)
 	}
 
 	backend.syncAdapterState()
 	return &backend, nil
 }
 
+// Start powers the adapter up when bluetooth.poweronstart is set, so the box
+// accepts connections right after boot; the idle timer then applies as after
+// any power-up.
 func (b *BluetoothBackend) Start() error {
 	if !b.powerOnStart {
 		return nil
 	}
+	return b.doPowerUp()
+}
+
+// doPowerUp runs PowerUp, or its test replacement.
+func (b *BluetoothBackend) doPowerUp() error {
+	if b.powerUp != nil {
+		return b.powerUp()
+	}
 	return b.PowerUp()
 }
 
@@ -89,6 +116,9 @@ func (b *BluetoothBackend) PowerUp() error {
 
 	// status and refresh follow the Powered=true signal.
 	logger.Info("[bluetooth] Bluetooth ready to connect to already known devices")
+	if b.autoConnect {
+		go b.autoConnectTrusted()
+	}
 	return nil
 }
 
@@ -147,14 +177,16 @@ func (b *BluetoothBackend) cleanupPoweredState() {
 		s.Pairable = false
 		s.PairingActive = false
 		s.PairingUntil = nil
+		s.DiscoverableUntil = nil
 		s.Scanning = false
 		s.KnownDevices = nil
 	})
 }
 
 func (b *BluetoothBackend) NewPairing() error {
-	// Prevent resetting BlueZ timeouts on an already-active pairing session
-	if b.isDiscoverable() {
+	// Prevent resetting BlueZ timeouts on an already-active pairing session; a
+	// discoverable-only window is upgraded to full pairing instead.
+	if b.isDiscoverable() && b.GetStatus().DiscoverableUntil == nil {
 		logger.Info("[bluetooth] pairing already in progress")
 		return nil
 	}
@@ -192,17 +224,77 @@ func (b *BluetoothBackend) NewPairing() error {
 		return err
 	}
 
+	b.discoverableTimer.Cancel()
 	pairingUntil := time.Now().Add(b.pairingTimeout)
 	b.updateStatus(func(s *BluetoothStatus) {
 		s.Powered = true
 		s.PairingActive = true
 		s.PairingUntil = &pairingUntil
+		s.DiscoverableUntil = nil
 	})
 
 	logger.Info("[bluetooth] Bluetooth pairing mode enabled")
 	return nil
 }
 
+// SetDiscoverableOnly makes the adapter visible for duration without making it
+// pairable, e.g. so a device can find it during setup. Calling it again restarts
+// the window; during pairing mode the adapter is already visible and it is a
+// no-op.
+func (b *BluetoothBackend) SetDiscoverableOnly(duration time.Duration) error {
+	if duration <= 0 {
+		return fmt.Errorf("%w: %v", ErrInvalidDuration, duration)
+	}
+	if b.GetStatus().PairingActive {
+		logger.Info("[bluetooth] pairing in progress, adapter already discoverable")
+		return nil
+	}
+
+	if powered := b.isAdapterOn(); !powered {
+		unblockIfSoftBlocked()
+		if err := b.PowerOnAdapter(true); err != nil {
+			return err
+		}
+	}
+
+	// Our timer ends the window; keep BlueZ from ending it early.
+	if err := b.setAdapterProp(DISCOVERABLE_TIMEOUT, uint32(0)); err != nil {
+		logger.Warn("[bluetooth] failed to clear adapter %s: %v", DISCOVERABLE_TIMEOUT, err)
+		return err
+	}
+	if err := b.SetDiscoverable(true); err != nil {
+		return err
+	}
+
+	b.discoverableTimer.Cancel()
+	b.discoverableTimer.Start(duration, func() {
+		logger.Info("[bluetooth] discoverable window of %v elapsed", duration)
+		if err := b.stopDiscoverableOnly(); err != nil {
+			logger.Warn("[bluetooth] failed to end discoverable window: %v", err)
+		}
+	})
+	until := time.Now().Add(duration)
+	b.updateStatus(func(s *BluetoothStatus) {
+		s.Powered = true
+		s.DiscoverableUntil = &until
+	})
+	logger.Info("[bluetooth] adapter discoverable for %v", duration)
+	return nil
+}
+
+// stopDiscoverableOnly hides the adapter again, unless pairing mode took over
+// the window and now owns the Discoverable flag.
+func (b *BluetoothBackend) stopDiscoverableOnly() error {
+	b.discoverableTimer.Cancel()
+	if b.GetStatus().PairingActive {
+		return nil
+	}
+	b.updateStatus(func(s *BluetoothStatus) {
+		s.DiscoverableUntil = nil
+	})
+	return b.SetDiscoverable(false)
+}
+
 // isDeviceBonded reports whether we still hold the device's pairing key, read
 // from the cached device list — the same Bonded flag the UI shows, so the
 // connect decision matches what the user saw. A bonded device reconnects
@@ -224,6 +316,9 @@ func (b *BluetoothBackend) Connect(address string) error {
 	if err := validateAddress(address); err != nil {
 		return err
 	}
+	if !b.policy.Allows(address) {
+		return fmt.Errorf("%w: %s", ErrDeviceNotAllowed, address)
+	}
 	path := devicePath(address)
 	logger.Info("[bluetooth] connecting to %s", address)
 	// An unbonded target needs a fresh bond, so open the pairable window for the
@@ -273,6 +368,29 @@ func (b *BluetoothBackend) Disconnect(address string) error {
 	return nil
 }
 
+// maxAliasLength is BlueZ's device name limit, in bytes.
+const maxAliasLength = 248
+
+// RenameDevice sets the device's Alias, which BlueZ persists across restarts;
+// an empty alias reverts to the advertised name. The cached device list is
+// refreshed right away so the new name shows without waiting for a signal.
+func (b *BluetoothBackend) RenameDevice(address, alias string) error {
+	if err := validateAddress(address); err != nil {
+		return err
+	}
+	if len(alias) > maxAliasLength {
+		return fmt.Errorf("%w: %d bytes, at most %d", ErrInvalidAlias, len(alias), maxAliasLength)
+	}
+	obj := b.getObj(BLUETOOTH_PREFIX, string(devicePath(address)))
+	if err := b.setProperty(obj, BLUETOOTH_DEVICE, BT_PROP_ALIAS, alias); err != nil {
+		logger.Warn("[bluetooth] failed to rename %s: %v", address, err)
+		return fmt.Errorf("could not rename %s: %w", address, err)
+	}
+	logger.Info("[bluetooth] renamed %s to %q", address, alias)
+	b.refreshDevices()
+	return nil
+}
+
 // onSignal dispatches PropertiesChanged (adapter/device) and InterfacesAdded
 // (scan discovery) signals to their handlers.
 func (b *BluetoothBackend) cancelIdleTimer() {
@@ -290,17 +408,25 @@ func (b *BluetoothBackend) checkAndStartIdleTimer() {
 		return
 	}
 
-	armed := b.idleTimer.Start(b.idleTimeout, func() {
-		logger.Info("[bluetooth] idle timeout reached after %v, powering down", b.idleTimeout)
-		if err := b.PowerOffAdapter(); err != nil {
-			logger.Warn("[bluetooth] failed to power down: %v", err)
-		}
-	})
+	armed := b.idleTimer.Start(b.idleTimeout, b.onIdleTimeout)
 	if armed {
 		logger.Info("[bluetooth] idle timer started (%v)", b.idleTimeout)
 	}
 }
 
+// onIdleTimeout powers the adapter down once idle, unless bluetooth.idle.poweroff
+// is off: inactivity is then only logged and the adapter stays up.
+func (b *BluetoothBackend) onIdleTimeout() {
+	if !b.idlePowerOff {
+		logger.Info("[bluetooth] idle timeout reached after %v, power-down disabled", b.idleTimeout)
+		return
+	}
+	logger.Info("[bluetooth] idle timeout reached after %v, powering down", b.idleTimeout)
+	if err := b.PowerOffAdapter(); err != nil {
+		logger.Warn("[bluetooth] failed to power down: %v", err)
+	}
+}
+
 func (b *BluetoothBackend) Close() {
 	b.unregisterAgent()
 	if err := b.PowerDown(); err != nil {
@@ -325,6 +451,19 @@ func (b *BluetoothBackend) GetStatus() BluetoothStatus {
 	return status
 }
 
+// ImportStatus replaces the cached status, e.g. with a debug export, without
+// asking BlueZ.
+func (b *BluetoothBackend) ImportStatus(status BluetoothStatus) {
+	const statusKey = "current"
+	b.statusCache.Clear()
+	b.statusCache.Set(statusKey, status)
+}
+
+// CacheUpdatedAt returns the last time the status cache was written to.
+func (b *BluetoothBackend) CacheUpdatedAt() time.Time {
+	return b.statusCache.UpdatedAt()
+}
+
 func (b *BluetoothBackend) updateStatus(fn func(*BluetoothStatus)) {
 	const statusKey = "current"
 	status, _ := b.statusCache.Get(statusKey)
@@ -341,6 +480,14 @@ func (b *BluetoothBackend) notify(status BluetoothStatus) {
 	}
 }
 
+func (b *BluetoothBackend) notifyConnection(conn DeviceConnection) {
+	select {
+	case b.events <- events.Event{Type: events.TypeBluetoothConnection, Data: conn}:
+	default:
+		logger.Warn("[bluetooth] event channel full, dropping %s event", events.TypeBluetoothConnection)
+	}
+}
+
 func (b *BluetoothBackend) Events() <-chan events.Event {
 	return b.events
 }
@@ -363,6 +510,34 @@ func (b *BluetoothBackend) refreshDevices() {
 	})
 }
 
+// RefreshStatus rereads the adapter state and, when powered, the device list
+// from BlueZ into the cached status. Unlike the signal-driven updates it
+// reports D-Bus failures.
+func (b *BluetoothBackend) RefreshStatus() error {
+	v, err := b.getAdapterProp(BT_STATE_POWERED)
+	if err != nil {
+		return err
+	}
+	powered, _ := extractBool(v)
+
+	var devices []BluetoothDevice
+	if powered {
+		if devices, err = b.listDevices(); err != nil {
+			return err
+		}
+	}
+	pairable := b.isPairable()
+	discoverable := b.isDiscoverable()
+
+	b.updateStatus(func(s *BluetoothStatus) {
+		s.Powered = powered
+		s.Pairable = pairable
+		s.Discoverable = discoverable
+		s.KnownDevices = devices // nil while powered off, as after a power-down
+	})
+	return nil
+}
+
 // GetDevices returns the current device list.
 func (b *BluetoothBackend) GetDevices() []BluetoothDevice {
 	devices := b.GetStatus().KnownDevices
@@ -383,7 +558,7 @@ func (b *BluetoothBackend) registerAgent() error {
 	}
 
 	manager := b.getObj(BLUETOOTH_PREFIX, BLUEZ_PATH)
-	if err := b.RequestNoInputOutputAgent(manager); err != nil {
+	if err := b.RequestAgent(manager); err != nil {
 		return err
 	}
 
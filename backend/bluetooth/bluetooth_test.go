@@ -1,6 +1,8 @@
 package bluetooth
 
 import (
+	"errors"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -397,6 +399,30 @@ func TestOnPropertiesChangedNeverStops(t *testing.T) {
 	}
 }
 
+func TestOnSignalConnectedEmitsConnection(t *testing.T) {
+	b := newTestBackend()
+	b.onSignal(&dbus.Signal{
+		Path: "/org/bluez/hci0/dev_40_C1_F6_D4_67_88",
+		Body: []interface{}{
+			"org.bluez.Device1",
+			map[string]dbus.Variant{"Connected": dbus.MakeVariant(true)},
+		},
+	})
+
+	select {
+	case ev := <-b.events:
+		if ev.Type != events.TypeBluetoothConnection {
+			t.Fatalf("event type = %q, want %q", ev.Type, events.TypeBluetoothConnection)
+		}
+		want := DeviceConnection{Address: "40:C1:F6:D4:67:88", Connected: true}
+		if got, _ := ev.Data.(DeviceConnection); got != want {
+			t.Errorf("event data = %+v, want %+v", ev.Data, want)
+		}
+	default:
+		t.Fatal("no event emitted for connected=true")
+	}
+}
+
 func TestCancelIdleTimer(t *testing.T) {
 	t.Run("connected signal cancels idle timer", func(t *testing.T) {
 		b := &BluetoothBackend{}
@@ -527,6 +553,20 @@ func TestCheckAndStartIdleTimerNotPowered(t *testing.T) {
 	}
 }
 
+// TestOnIdleTimeoutPowerOffDisabled: with bluetooth.idle.poweroff off the idle
+// timeout only logs, it must not reach the adapter (nil conn here).
+func TestOnIdleTimeoutPowerOffDisabled(t *testing.T) {
+	b := newTestBackend()
+	b.idleTimeout = time.Hour
+	b.seedStatus(BluetoothStatus{Powered: true})
+
+	b.onIdleTimeout()
+
+	if !b.GetStatus().Powered {
+		t.Error("adapter should stay powered when idle power-off is disabled")
+	}
+}
+
 // interfacesAddedSignal builds an InterfacesAdded signal for a device.
 func interfacesAddedSignal(path string, dev map[string]dbus.Variant) *dbus.Signal {
 	return &dbus.Signal{
@@ -588,3 +628,191 @@ func drainHasEvent(b *BluetoothBackend, eventType string) bool {
 		}
 	}
 }
+
+func TestSetDiscoverableOnlyRejectsDuration(t *testing.T) {
+	b := newTestBackend()
+	for _, d := range []time.Duration{0, -time.Second} {
+		if err := b.SetDiscoverableOnly(d); !errors.Is(err, ErrInvalidDuration) {
+			t.Errorf("SetDiscoverableOnly(%v) error = %v, want ErrInvalidDuration", d, err)
+		}
+	}
+}
+
+// TestOnSignalDiscoverableOffEndsWindow: the adapter going hidden (our timer,
+// BlueZ or another client) clears DiscoverableUntil and disarms the timer.
+func TestOnSignalDiscoverableOffEndsWindow(t *testing.T) {
+	b := newTestBackend()
+	until := time.Now().Add(time.Minute)
+	b.seedStatus(BluetoothStatus{Powered: true, Discoverable: true, DiscoverableUntil: &until})
+	b.discoverableTimer.Start(time.Hour, func() {})
+
+	b.onSignal(adapterSignal(map[string]dbus.Variant{
+		"Discoverable": dbus.MakeVariant(false),
+	}))
+
+	got := b.GetStatus()
+	if got.Discoverable || got.DiscoverableUntil != nil {
+		t.Errorf("status = discoverable %v until %v, want hidden with no window", got.Discoverable, got.DiscoverableUntil)
+	}
+	if b.discoverableTimer.Cancel() {
+		t.Error("discoverable timer should already be disarmed")
+	}
+}
+
+func TestCacheUpdatedAt(t *testing.T) {
+	b := newTestBackend()
+	if !b.CacheUpdatedAt().IsZero() {
+		t.Fatal("CacheUpdatedAt should be zero before any status write")
+	}
+	before := time.Now()
+	b.updateStatus(func(s *BluetoothStatus) { s.Powered = true })
+	if at := b.CacheUpdatedAt(); at.Before(before) {
+		t.Errorf("CacheUpdatedAt = %v, want at or after %v", at, before)
+	}
+}
+
+func TestStartPowerOnStart(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		b := newTestBackend()
+		b.powerOnStart = enabled
+		called := false
+		b.powerUp = func() error {
+			called = true
+			return nil
+		}
+
+		if err := b.Start(); err != nil {
+			t.Fatalf("poweronstart=%v: Start() = %v", enabled, err)
+		}
+		if called != enabled {
+			t.Errorf("poweronstart=%v: power-up called = %v, want %v", enabled, called, enabled)
+		}
+	}
+}
+
+func TestOnSignalAutoPowerUp(t *testing.T) {
+	const path = "/org/bluez/hci0/dev_AA_BB_CC_DD_EE_FF"
+	untrusted := map[string]dbus.Variant{"Address": dbus.MakeVariant("AA:BB:CC:DD:EE:FF")}
+	trusted := map[string]dbus.Variant{
+		"Address": dbus.MakeVariant("AA:BB:CC:DD:EE:FF"),
+		"Trusted": dbus.MakeVariant(true),
+	}
+	knownTrustedStatus := BluetoothStatus{KnownDevices: []BluetoothDevice{{Address: "AA:BB:CC:DD:EE:FF", Trusted: true}}}
+
+	tests := []struct {
+		name        string
+		autoPowerUp bool
+		status      BluetoothStatus
+		props       map[string]dbus.Variant
+		want        bool
+	}{
+		{"trusted in signal", true, BluetoothStatus{}, trusted, true},
+		{"trusted from known devices", true, knownTrustedStatus, untrusted, true},
+		{"untrusted device", true, BluetoothStatus{}, untrusted, false},
+		{"already powered", true, BluetoothStatus{Powered: true}, trusted, false},
+		{"option disabled", false, BluetoothStatus{}, trusted, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBackend()
+			b.autoPowerUp = tt.autoPowerUp
+			b.seedStatus(tt.status)
+			called := make(chan struct{}, 1)
+			b.powerUp = func() error {
+				called <- struct{}{}
+				return nil
+			}
+
+			b.onSignal(interfacesAddedSignal(path, tt.props))
+
+			select {
+			case <-called:
+				if !tt.want {
+					t.Error("power-up called, want none")
+				}
+			case <-time.After(50 * time.Millisecond):
+				if tt.want {
+					t.Error("power-up not called")
+				}
+			}
+		})
+	}
+}
+
+func TestOnSignalAutoPowerUpAdapterAdded(t *testing.T) {
+	adapterAdded := func(path string, powered bool) *dbus.Signal {
+		return &dbus.Signal{
+			Name: DBUS_OBJ_MANAGER + ".InterfacesAdded",
+			Path: "/",
+			Body: []interface{}{
+				dbus.ObjectPath(path),
+				map[string]map[string]dbus.Variant{
+					BLUETOOTH_ADAPTER: {"Powered": dbus.MakeVariant(powered)},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		autoPowerUp bool
+		sig         *dbus.Signal
+		want        bool
+	}{
+		{"unpowered adapter added", true, adapterAdded(BLUETOOTH_PATH, false), true},
+		{"powered adapter added", true, adapterAdded(BLUETOOTH_PATH, true), false},
+		{"another adapter", true, adapterAdded(BLUEZ_PATH+"/hci1", false), false},
+		{"option disabled", false, adapterAdded(BLUETOOTH_PATH, false), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBackend()
+			b.autoPowerUp = tt.autoPowerUp
+			called := make(chan struct{}, 1)
+			b.powerUp = func() error {
+				called <- struct{}{}
+				return nil
+			}
+
+			b.onSignal(tt.sig)
+
+			select {
+			case <-called:
+				if !tt.want {
+					t.Error("power-up called, want none")
+				}
+			case <-time.After(50 * time.Millisecond):
+				if tt.want {
+					t.Error("power-up not called")
+				}
+			}
+		})
+	}
+}
+
+func TestAutoPowerUpSingleFlight(t *testing.T) {
+	const path = "/org/bluez/hci0/dev_AA_BB_CC_DD_EE_FF"
+	trusted := map[string]dbus.Variant{
+		"Address": dbus.MakeVariant("AA:BB:CC:DD:EE:FF"),
+		"Trusted": dbus.MakeVariant(true),
+	}
+	b := newTestBackend()
+	b.autoPowerUp = true
+	release := make(chan struct{})
+	var calls atomic.Int32
+	b.powerUp = func() error {
+		calls.Add(1)
+		<-release
+		return nil
+	}
+
+	for range 5 {
+		b.onSignal(interfacesAddedSignal(path, trusted))
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("power-up called %d times for a burst, want 1", got)
+	}
+}
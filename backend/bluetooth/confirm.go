@@ -0,0 +1,122 @@
+package bluetooth
+
+import (
+	"time"
+
+	"github.com/godbus/dbus/v5"
+
+	"github.com/b0bbywan/go-odio-api/logger"
+)
+
+// confirmationTimeout bounds how long a numeric comparison waits for the
+// user before it is rejected; replaced in tests.
+var confirmationTimeout = 30 * time.Second
+
+// pendingConfirmation is the comparison the agent is blocked on; reply gets
+// the user's answer once.
+type pendingConfirmation struct {
+	PendingConfirmation
+	reply chan bool
+}
+
+// capability returns the agent IO capability registered with BlueZ.
+func (b *BluetoothBackend) capability() string {
+	if b.agentCapability == "" {
+		return AGENT_CAPABILITY
+	}
+	return b.agentCapability
+}
+
+// confirmsPairing reports whether numeric comparisons wait for the user.
+func (b *BluetoothBackend) confirmsPairing() bool {
+	return b.capability() == AGENT_DISPLAY_YES_NO
+}
+
+// awaitConfirmation publishes a comparison for device and blocks until the
+// user answers, BlueZ cancels or confirmationTimeout expires. A newer
+// request replaces, and rejects, an unanswered one. passkey is empty for a
+// pairing request without one.
+func (b *BluetoothBackend) awaitConfirmation(device dbus.ObjectPath, passkey string) bool {
+	p := &pendingConfirmation{
+		PendingConfirmation: PendingConfirmation{
+			Address:   addressFromPath(device),
+			Passkey:   passkey,
+			ExpiresAt: time.Now().Add(confirmationTimeout),
+		},
+		reply: make(chan bool, 1),
+	}
+
+	b.confirmMu.Lock()
+	if prev := b.pendingConfirm; prev != nil {
+		prev.reply <- false
+	}
+	b.pendingConfirm = p
+	b.confirmMu.Unlock()
+	if p.Passkey == "" {
+		logger.Info("[bluetooth] pairing with %s awaits confirmation", p.Address)
+	} else {
+		logger.Info("[bluetooth] pairing with %s awaits confirmation of passkey %s", p.Address, p.Passkey)
+	}
+
+	timer := time.NewTimer(confirmationTimeout)
+	defer timer.Stop()
+	select {
+	case accept := <-p.reply:
+		return accept
+	case <-timer.C:
+		b.clearConfirmation(p)
+		logger.Info("[bluetooth] pairing confirmation for %s timed out", p.Address)
+		return false
+	}
+}
+
+// PendingConfirmation returns the comparison awaiting the user, if any.
+func (b *BluetoothBackend) PendingConfirmation() (*PendingConfirmation, bool) {
+	b.confirmMu.Lock()
+	defer b.confirmMu.Unlock()
+	if b.pendingConfirm == nil {
+		return nil, false
+	}
+	pending := b.pendingConfirm.PendingConfirmation
+	return &pending, true
+}
+
+// ConfirmPairing answers the pending comparison: accept pairs the device,
+// otherwise BlueZ is told the passkeys did not match.
+func (b *BluetoothBackend) ConfirmPairing(accept bool) error {
+	b.confirmMu.Lock()
+	p := b.pendingConfirm
+	b.pendingConfirm = nil
+	b.confirmMu.Unlock()
+	if p == nil {
+		return ErrNoPendingConfirmation
+	}
+	if accept {
+		logger.Info("[bluetooth] pairing with %s confirmed", p.Address)
+	} else {
+		logger.Info("[bluetooth] pairing with %s rejected", p.Address)
+	}
+	p.reply <- accept
+	return nil
+}
+
+// cancelConfirmation rejects the pending comparison, e.g. when BlueZ gives
+// up on the request.
+func (b *BluetoothBackend) cancelConfirmation() {
+	b.confirmMu.Lock()
+	p := b.pendingConfirm
+	b.pendingConfirm = nil
+	b.confirmMu.Unlock()
+	if p != nil {
+		p.reply <- false
+	}
+}
+
+// clearConfirmation drops p if it is still the pending comparison.
+func (b *BluetoothBackend) clearConfirmation(p *pendingConfirmation) {
+	b.confirmMu.Lock()
+	defer b.confirmMu.Unlock()
+	if b.pendingConfirm == p {
+		b.pendingConfirm = nil
+	}
+}
@@ -0,0 +1,178 @@
+package bluetooth
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const confirmDevice = dbus.ObjectPath("/org/bluez/hci0/dev_40_C1_F6_D4_67_88")
+
+// startConfirmation runs awaitConfirmation in the background and waits for
+// it to publish its comparison.
+func startConfirmation(t *testing.T, b *BluetoothBackend, passkey uint32) <-chan bool {
+	t.Helper()
+	result := make(chan bool, 1)
+	go func() { result <- b.awaitConfirmation(confirmDevice, fmt.Sprintf("%06d", passkey)) }()
+	deadline := time.Now().Add(time.Second)
+	for {
+		if p, ok := b.PendingConfirmation(); ok && p.Passkey == fmt.Sprintf("%06d", passkey) {
+			return result
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("confirmation never became pending")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestAwaitConfirmation(t *testing.T) {
+	t.Run("accepted", func(t *testing.T) {
+		b := newTestBackend()
+		result := startConfirmation(t, b, 42)
+		p, _ := b.PendingConfirmation()
+		if p.Address != "40:C1:F6:D4:67:88" || p.Passkey != "000042" {
+			t.Errorf("pending = %+v", p)
+		}
+		if err := b.ConfirmPairing(true); err != nil {
+			t.Fatalf("ConfirmPairing(true) = %v", err)
+		}
+		if !<-result {
+			t.Error("awaitConfirmation() = false, want true")
+		}
+		if _, ok := b.PendingConfirmation(); ok {
+			t.Error("confirmation still pending after the answer")
+		}
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		b := newTestBackend()
+		result := startConfirmation(t, b, 123456)
+		if err := b.ConfirmPairing(false); err != nil {
+			t.Fatalf("ConfirmPairing(false) = %v", err)
+		}
+		if <-result {
+			t.Error("awaitConfirmation() = true, want false")
+		}
+	})
+
+	t.Run("times out", func(t *testing.T) {
+		saved := confirmationTimeout
+		confirmationTimeout = 20 * time.Millisecond
+		defer func() { confirmationTimeout = saved }()
+
+		b := newTestBackend()
+		result := startConfirmation(t, b, 1)
+		select {
+		case accepted := <-result:
+			if accepted {
+				t.Error("timed out confirmation accepted")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("awaitConfirmation did not time out")
+		}
+		if err := b.ConfirmPairing(true); !errors.Is(err, ErrNoPendingConfirmation) {
+			t.Errorf("ConfirmPairing after timeout = %v, want ErrNoPendingConfirmation", err)
+		}
+	})
+
+	t.Run("cancelled by BlueZ", func(t *testing.T) {
+		b := newTestBackend()
+		result := startConfirmation(t, b, 7)
+		agent := &bluezAgent{backend: b}
+		_ = agent.Cancel()
+		if <-result {
+			t.Error("cancelled confirmation accepted")
+		}
+	})
+
+	t.Run("newer request replaces", func(t *testing.T) {
+		b := newTestBackend()
+		first := startConfirmation(t, b, 1)
+		second := startConfirmation(t, b, 2)
+		if <-first {
+			t.Error("replaced confirmation accepted")
+		}
+		_ = b.ConfirmPairing(false)
+		<-second
+	})
+}
+
+func TestConfirmPairingWithoutPending(t *testing.T) {
+	b := newTestBackend()
+	if err := b.ConfirmPairing(true); !errors.Is(err, ErrNoPendingConfirmation) {
+		t.Errorf("ConfirmPairing() = %v, want ErrNoPendingConfirmation", err)
+	}
+}
+
+func TestAgentRejectsUnconfirmedPairing(t *testing.T) {
+	b := newTestBackend()
+	b.agentCapability = AGENT_DISPLAY_YES_NO
+	agent := &bluezAgent{backend: b}
+
+	done := make(chan *dbus.Error, 1)
+	go func() { done <- agent.RequestConfirmation(confirmDevice, 987654) }()
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, ok := b.PendingConfirmation(); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("DisplayYesNo agent did not wait for confirmation")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := b.ConfirmPairing(false); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err == nil || err.Name != AGENT_REJECTED {
+		t.Errorf("RequestConfirmation() = %v, want %s", err, AGENT_REJECTED)
+	}
+}
+
+// TestAgentDisplayYesNoGatesEveryPairing: as DisplayYesNo, an incoming
+// pairing without passkey waits for the user too, and legacy PIN or passkey
+// requests are rejected rather than answered with a fixed code.
+func TestAgentDisplayYesNoGatesEveryPairing(t *testing.T) {
+	b := newTestBackend()
+	b.agentCapability = AGENT_DISPLAY_YES_NO
+	agent := &bluezAgent{backend: b}
+
+	if _, err := agent.RequestPinCode(confirmDevice); err == nil || err.Name != AGENT_REJECTED {
+		t.Errorf("RequestPinCode() error = %v, want %s", err, AGENT_REJECTED)
+	}
+	if _, err := agent.RequestPasskey(confirmDevice); err == nil || err.Name != AGENT_REJECTED {
+		t.Errorf("RequestPasskey() error = %v, want %s", err, AGENT_REJECTED)
+	}
+
+	for _, accept := range []bool{true, false} {
+		done := make(chan *dbus.Error, 1)
+		go func() { done <- agent.RequestAuthorization(confirmDevice) }()
+		deadline := time.Now().Add(time.Second)
+		for {
+			if p, ok := b.PendingConfirmation(); ok {
+				if p.Passkey != "" {
+					t.Errorf("pending passkey = %q, want none", p.Passkey)
+				}
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("DisplayYesNo agent authorized the pairing without the user")
+			}
+			time.Sleep(time.Millisecond)
+		}
+		if err := b.ConfirmPairing(accept); err != nil {
+			t.Fatal(err)
+		}
+		err := <-done
+		if accept && err != nil {
+			t.Errorf("RequestAuthorization() accepted = %v, want nil", err)
+		}
+		if !accept && (err == nil || err.Name != AGENT_REJECTED) {
+			t.Errorf("RequestAuthorization() rejected = %v, want %s", err, AGENT_REJECTED)
+		}
+	}
+}
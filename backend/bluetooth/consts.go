@@ -20,8 +20,12 @@ const (
 	DEVICE_CONNECT    = BLUETOOTH_DEVICE + ".Connect"
 	DEVICE_DISCONNECT = BLUETOOTH_DEVICE + ".Disconnect"
 
-	AGENT_IFACE   = BLUETOOTH_PREFIX + ".Agent1"
-	AGENT_MANAGER = BLUETOOTH_PREFIX + ".AgentManager1"
+	DEVICE_CONNECT_PROFILE    = BLUETOOTH_DEVICE + ".ConnectProfile"
+	DEVICE_DISCONNECT_PROFILE = BLUETOOTH_DEVICE + ".DisconnectProfile"
+
+	AGENT_IFACE    = BLUETOOTH_PREFIX + ".Agent1"
+	AGENT_REJECTED = BLUETOOTH_PREFIX + ".Error.Rejected"
+	AGENT_MANAGER  = BLUETOOTH_PREFIX + ".AgentManager1"
 
 	REGISTER_AGENT   = AGENT_MANAGER + ".RegisterAgent"
 	REQUEST_AGENT    = AGENT_MANAGER + ".RequestDefaultAgent"
@@ -32,6 +36,7 @@ const (
 	AGENT_PATH     = BLUEZ_PATH + "/go_odio_agent"
 
 	AGENT_CAPABILITY     = "NoInputNoOutput"
+	AGENT_DISPLAY_YES_NO = "DisplayYesNo"
 	DISCOVERABLE_TIMEOUT = "DiscoverableTimeout"
 	PAIRABLE_TIMEOUT     = "PairableTimeout"
 
@@ -44,6 +49,10 @@ const (
 	BT_PROP_ADAPTER = "Adapter"
 	BT_PROP_ADDRESS = "Address"
 	BT_PROP_NAME    = "Name"
+	BT_PROP_ALIAS   = "Alias"
+	BT_PROP_UUIDS   = "UUIDs"
+	BT_PROP_RSSI    = "RSSI"
+	BT_PROP_TXPOWER = "TxPower"
 )
 
 type BluetoothState string
@@ -1,6 +1,7 @@
 package bluetooth
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strings"
@@ -14,6 +15,33 @@ import (
 
 var macRegex = regexp.MustCompile(`^([0-9A-Fa-f]{2}:){5}[0-9A-Fa-f]{2}$`)
 
+// dialBus opens a system bus connection, giving up when ctx ends: the dial
+// itself takes no context, so it runs aside and a connection it completes too
+// late is closed.
+func dialBus(ctx context.Context) (*dbus.Conn, error) {
+	type result struct {
+		conn *dbus.Conn
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		conn, err := dbus.ConnectSystemBus()
+		done <- result{conn, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.conn, r.err
+	case <-ctx.Done():
+		go func() {
+			if r := <-done; r.conn != nil {
+				_ = r.conn.Close()
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
+
 // callWithTimeout executes a D-Bus call with timeout
 func callWithTimeout(call *dbus.Call, timeout time.Duration) error {
 	done := make(chan error, 1)
@@ -130,7 +158,7 @@ func (b *BluetoothBackend) getObj(prefix, path string) dbus.BusObject {
 // iterateAdapterDevices iterates over all devices belonging to our adapter
 // and calls the provided function for each device
 func (b *BluetoothBackend) iterateAdapterDevices(fn func(path dbus.ObjectPath, props map[string]dbus.Variant) bool) error {
-	managedObjects, err := b.getManagedObjects()
+	managedObjects, err := b.getManagedObjects(context.Background())
 	if err != nil {
 		return err
 	}
@@ -169,20 +197,29 @@ func (b *BluetoothBackend) listDevices() ([]BluetoothDevice, error) {
 	devices := []BluetoothDevice{}
 
 	err := b.iterateAdapterDevices(func(path dbus.ObjectPath, props map[string]dbus.Variant) bool {
-		devices = append(devices, BluetoothDevice{
-			Address:   extractString(props, BT_PROP_ADDRESS),
-			Name:      extractString(props, BT_PROP_NAME),
-			Paired:    extractBoolProp(props, BT_STATE_PAIRED),
-			Bonded:    extractBoolProp(props, BT_STATE_BONDED),
-			Trusted:   extractBoolProp(props, BT_STATE_TRUSTED),
-			Connected: extractBoolProp(props, BT_STATE_CONNECTED),
-		})
+		devices = append(devices, deviceFromProps(props))
 		return true
 	})
 
 	return devices, err
 }
 
+// deviceFromProps builds a BluetoothDevice from its org.bluez.Device1 properties.
+func deviceFromProps(props map[string]dbus.Variant) BluetoothDevice {
+	return BluetoothDevice{
+		Address:           extractString(props, BT_PROP_ADDRESS),
+		Name:              extractString(props, BT_PROP_NAME),
+		Alias:             extractString(props, BT_PROP_ALIAS),
+		Paired:            extractBoolProp(props, BT_STATE_PAIRED),
+		Bonded:            extractBoolProp(props, BT_STATE_BONDED),
+		Trusted:           extractBoolProp(props, BT_STATE_TRUSTED),
+		Connected:         extractBoolProp(props, BT_STATE_CONNECTED),
+		ConnectedProfiles: profileNames(props),
+		RSSI:              extractInt16(props, BT_PROP_RSSI),
+		TxPower:           extractInt16(props, BT_PROP_TXPOWER),
+	}
+}
+
 func extractString(props map[string]dbus.Variant, key string) string {
 	if v, ok := props[key]; ok {
 		if s, ok := v.Value().(string); ok {
@@ -192,6 +229,16 @@ func extractString(props map[string]dbus.Variant, key string) string {
 	return ""
 }
 
+// extractInt16 returns a pointer to an int16 property, nil when absent.
+func extractInt16(props map[string]dbus.Variant, key string) *int16 {
+	if v, ok := props[key]; ok {
+		if n, ok := v.Value().(int16); ok {
+			return &n
+		}
+	}
+	return nil
+}
+
 func extractBoolProp(props map[string]dbus.Variant, key BluetoothState) bool {
 	if v, ok := props[key.String()]; ok {
 		if b, ok := v.Value().(bool); ok {
@@ -285,6 +332,16 @@ func (b *BluetoothBackend) isDiscoverable() bool {
 	return b.getAdapterBoolProp(BT_STATE_DISCOVERABLE)
 }
 
+func (b *BluetoothBackend) isDeviceConnected(path dbus.ObjectPath) bool {
+	v, err := b.getProperty(b.getObj(BLUETOOTH_PREFIX, string(path)), BLUETOOTH_DEVICE, BT_STATE_CONNECTED.String())
+	if err != nil {
+		logger.Warn("[bluetooth] failed to get %s Connected: %v", path, err)
+		return false
+	}
+	val, _ := extractBool(v)
+	return val
+}
+
 func (b *BluetoothBackend) hasConnectedDevices() bool {
 	connected := false
 	err := b.iterateAdapterDevices(func(path dbus.ObjectPath, props map[string]dbus.Variant) bool {
@@ -353,18 +410,20 @@ func (b *BluetoothBackend) SetTimeOut(prop string) error {
 	return nil
 }
 
-func (b *BluetoothBackend) getManagedObjects() (map[dbus.ObjectPath]map[string]map[string]dbus.Variant, error) {
+func (b *BluetoothBackend) getManagedObjects(ctx context.Context) (map[dbus.ObjectPath]map[string]map[string]dbus.Variant, error) {
 	objManager := b.getObj(BLUETOOTH_PREFIX, "/")
 	var managedObjects map[dbus.ObjectPath]map[string]map[string]dbus.Variant
-	if err := objManager.Call(MANAGED_OBJECTS, 0).Store(&managedObjects); err != nil {
+	if err := objManager.CallWithContext(ctx, MANAGED_OBJECTS, 0).Store(&managedObjects); err != nil {
 		logger.Warn("[bluetooth] failed to query BlueZ managed objects: %v", err)
 		return nil, err
 	}
 	return managedObjects, nil
 }
 
-func (b *BluetoothBackend) CheckBluetoothSupport() error {
-	managedObjects, err := b.getManagedObjects()
+// CheckBluetoothSupport reports whether BlueZ exposes an adapter, giving up
+// when ctx is done.
+func (b *BluetoothBackend) CheckBluetoothSupport(ctx context.Context) error {
+	managedObjects, err := b.getManagedObjects(ctx)
 	if err != nil {
 		return err
 	}
@@ -384,14 +443,17 @@ func (b *BluetoothBackend) CheckBluetoothSupport() error {
 	return nil
 }
 
-func (b *BluetoothBackend) RequestNoInputOutputAgent(manager dbus.BusObject) error {
+// RequestAgent registers our agent with its configured IO capability and
+// makes it the default one.
+func (b *BluetoothBackend) RequestAgent(manager dbus.BusObject) error {
+	capability := b.capability()
 	if err := b.callMethod(
 		manager,
 		REGISTER_AGENT,
 		dbus.ObjectPath(AGENT_PATH),
-		AGENT_CAPABILITY,
+		capability,
 	); err != nil {
-		logger.Warn("[bluetooth] failed to register agent with capability %s: %v", AGENT_CAPABILITY, err)
+		logger.Warn("[bluetooth] failed to register agent with capability %s: %v", capability, err)
 		return err
 	}
 
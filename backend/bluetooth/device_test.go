@@ -1,7 +1,9 @@
 package bluetooth
 
 import (
+	"encoding/json"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/godbus/dbus/v5"
@@ -115,3 +117,42 @@ func TestParseInterfacesAdded(t *testing.T) {
 		}
 	})
 }
+
+func TestDeviceFromPropsSignal(t *testing.T) {
+	d := deviceFromProps(map[string]dbus.Variant{
+		BT_PROP_ADDRESS: dbus.MakeVariant("40:C1:F6:D4:67:88"),
+		BT_PROP_RSSI:    dbus.MakeVariant(int16(-62)),
+		BT_PROP_TXPOWER: dbus.MakeVariant(int16(4)),
+		"Connected":     dbus.MakeVariant(true),
+	})
+	if d.RSSI == nil || *d.RSSI != -62 {
+		t.Errorf("RSSI = %v, want -62", d.RSSI)
+	}
+	if d.TxPower == nil || *d.TxPower != 4 {
+		t.Errorf("TxPower = %v, want 4", d.TxPower)
+	}
+	if !d.Connected {
+		t.Error("Connected = false, want true")
+	}
+
+	out, err := json.Marshal(deviceFromProps(map[string]dbus.Variant{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(out), "rssi") || strings.Contains(string(out), "tx_power") {
+		t.Errorf("out-of-range device JSON = %s, want rssi/tx_power omitted", out)
+	}
+}
+
+func TestRenameDeviceValidates(t *testing.T) {
+	b := &BluetoothBackend{}
+	if err := b.RenameDevice("nope", "Kitchen"); !errors.Is(err, ErrInvalidAddress) {
+		t.Errorf("bad address error = %v, want ErrInvalidAddress", err)
+	}
+	if err := b.RenameDevice("40:C1:F6:D4:67:88", strings.Repeat("a", maxAliasLength+1)); !errors.Is(err, ErrInvalidAlias) {
+		t.Errorf("long alias error = %v, want ErrInvalidAlias", err)
+	}
+	if d := deviceFromProps(map[string]dbus.Variant{BT_PROP_ALIAS: dbus.MakeVariant("Kitchen")}); d.Alias != "Kitchen" {
+		t.Errorf("Alias = %q, want Kitchen", d.Alias)
+	}
+}
@@ -99,23 +99,14 @@ func (b *BluetoothBackend) handleDiscoveredDevice(path dbus.ObjectPath, props ma
 			return
 		}
 	}
-	address := extractString(props, BT_PROP_ADDRESS)
-	if address == "" {
-		address = addressFromPath(path)
+	device := deviceFromProps(props)
+	if device.Address == "" {
+		device.Address = addressFromPath(path)
 	}
-	if address == "" {
+	if device.Address == "" {
 		return
 	}
 
-	device := BluetoothDevice{
-		Address:   address,
-		Name:      extractString(props, BT_PROP_NAME),
-		Paired:    extractBoolProp(props, BT_STATE_PAIRED),
-		Bonded:    extractBoolProp(props, BT_STATE_BONDED),
-		Trusted:   extractBoolProp(props, BT_STATE_TRUSTED),
-		Connected: extractBoolProp(props, BT_STATE_CONNECTED),
-	}
-
 	// Hold scanMu across the check and the updates so a concurrent StopScan can't
 	// slip a device in after the scan is reported stopped.
 	b.scanMu.Lock()
@@ -129,6 +120,67 @@ func (b *BluetoothBackend) handleDiscoveredDevice(path dbus.ObjectPath, props ma
 	b.notifyDiscovered(device)
 }
 
+// autoPowerUpFor powers the adapter up when bluetooth.auto_powerup is set and
+// BlueZ announces a trusted device, either flagged in the signal or already
+// known as trusted. BlueZ only announces devices it registers anew (discovery,
+// or bluetoothd re-registering its devices on restart): a powered-off adapter
+// does not scan, so this cannot see a speaker merely coming into range.
+func (b *BluetoothBackend) autoPowerUpFor(path dbus.ObjectPath, props map[string]dbus.Variant) {
+	if !b.autoPowerUp {
+		return
+	}
+	status := b.GetStatus()
+	if status.Powered {
+		return
+	}
+	device := deviceFromProps(props)
+	if device.Address == "" {
+		device.Address = addressFromPath(path)
+	}
+	if !device.Trusted && !knownTrusted(status.KnownDevices, device.Address) {
+		return
+	}
+	logger.Info("[bluetooth] trusted device %s announced, powering up", device.Address)
+	b.autoPowerUpOnce()
+}
+
+// autoPowerUpForAdapter powers the adapter up when bluetooth.auto_powerup is
+// set and BlueZ registers it (first seen, USB dongle re-plugged, bluetoothd
+// restart) unpowered: its trusted devices can only come back once it is up.
+func (b *BluetoothBackend) autoPowerUpForAdapter(props map[string]dbus.Variant) {
+	if !b.autoPowerUp {
+		return
+	}
+	if powered, ok := extractMapBool(props, BT_STATE_POWERED); ok && powered {
+		return
+	}
+	logger.Info("[bluetooth] adapter added, powering up")
+	b.autoPowerUpOnce()
+}
+
+// autoPowerUpOnce powers up in the background unless an automatic power-up
+// is already running: a burst of InterfacesAdded signals starts a single one.
+func (b *BluetoothBackend) autoPowerUpOnce() {
+	if !b.autoPoweringUp.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer b.autoPoweringUp.Store(false)
+		if err := b.doPowerUp(); err != nil {
+			logger.Warn("[bluetooth] auto power-up failed: %v", err)
+		}
+	}()
+}
+
+func knownTrusted(devices []BluetoothDevice, address string) bool {
+	for _, d := range devices {
+		if d.Address == address {
+			return d.Trusted
+		}
+	}
+	return false
+}
+
 func (b *BluetoothBackend) notifyDiscovered(device BluetoothDevice) {
 	select {
 	case b.events <- events.Event{Type: events.TypeBluetoothDiscovered, Data: device}:
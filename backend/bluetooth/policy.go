@@ -0,0 +1,81 @@
+package bluetooth
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+
+	"github.com/b0bbywan/go-odio-api/logger"
+)
+
+// DevicePolicy restricts which devices may pair or connect. A non-empty
+// AllowList admits only its devices; BlockList devices are always refused.
+type DevicePolicy struct {
+	AllowList []string `json:"allowlist"`
+	BlockList []string `json:"blocklist"`
+}
+
+// newDevicePolicy validates the configured addresses and normalizes them to
+// upper case, as BlueZ reports them.
+func newDevicePolicy(allow, block []string) (DevicePolicy, error) {
+	p := DevicePolicy{AllowList: []string{}, BlockList: []string{}}
+	for _, list := range []struct {
+		key string
+		in  []string
+		out *[]string
+	}{
+		{"bluetooth.allowlist", allow, &p.AllowList},
+		{"bluetooth.blocklist", block, &p.BlockList},
+	} {
+		for _, address := range list.in {
+			address = strings.ToUpper(strings.TrimSpace(address))
+			if err := validateAddress(address); err != nil {
+				return DevicePolicy{}, fmt.Errorf("%s: %w", list.key, err)
+			}
+			if !slices.Contains(*list.out, address) {
+				*list.out = append(*list.out, address)
+			}
+		}
+	}
+	return p, nil
+}
+
+// Allows reports whether a device may pair and stay connected.
+func (p DevicePolicy) Allows(address string) bool {
+	address = strings.ToUpper(address)
+	if slices.Contains(p.BlockList, address) {
+		return false
+	}
+	return len(p.AllowList) == 0 || slices.Contains(p.AllowList, address)
+}
+
+// Policy returns the configured allow and block lists.
+func (b *BluetoothBackend) Policy() DevicePolicy {
+	return b.policy
+}
+
+// rejectDevice disconnects a device the policy refuses and reports whether it
+// did so; allowed devices are left alone.
+func (b *BluetoothBackend) rejectDevice(path dbus.ObjectPath) bool {
+	address := addressFromPath(path)
+	if b.policy.Allows(address) {
+		return false
+	}
+	logger.Warn("[bluetooth] %s is not allowed by the device policy, disconnecting", address)
+	if err := b.disconnectDevice(path); err != nil {
+		logger.Warn("[bluetooth] failed to disconnect refused device %s: %v", address, err)
+	}
+	return true
+}
+
+// agentAllows is the agent-side check: a refused device gets
+// org.bluez.Error.Rejected before any pairing or service authorization.
+func (a *bluezAgent) agentAllows(device dbus.ObjectPath) *dbus.Error {
+	if a.backend.policy.Allows(addressFromPath(device)) {
+		return nil
+	}
+	logger.Warn("[bluetooth] rejecting %s: not allowed by the device policy", addressFromPath(device))
+	return dbus.NewError(AGENT_REJECTED, nil)
+}
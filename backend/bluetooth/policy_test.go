@@ -0,0 +1,68 @@
+package bluetooth
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func TestNewDevicePolicy(t *testing.T) {
+	p, err := newDevicePolicy([]string{"aa:bb:cc:dd:ee:ff", " AA:BB:CC:DD:EE:FF "}, nil)
+	if err != nil {
+		t.Fatalf("newDevicePolicy() error = %v", err)
+	}
+	if !slices.Equal(p.AllowList, []string{"AA:BB:CC:DD:EE:FF"}) {
+		t.Errorf("AllowList = %v, want one upper-case address", p.AllowList)
+	}
+	if p.BlockList == nil {
+		t.Error("BlockList = nil, want an empty list for JSON")
+	}
+
+	if _, err := newDevicePolicy(nil, []string{"speaker"}); !errors.Is(err, ErrInvalidAddress) {
+		t.Errorf("invalid blocklist entry error = %v, want ErrInvalidAddress", err)
+	}
+}
+
+func TestDevicePolicyAllows(t *testing.T) {
+	const phone, speaker, stranger = "11:11:11:11:11:11", "22:22:22:22:22:22", "33:33:33:33:33:33"
+
+	open := DevicePolicy{}
+	if !open.Allows(stranger) {
+		t.Error("empty policy should allow every device")
+	}
+
+	blocked := DevicePolicy{BlockList: []string{stranger}}
+	if blocked.Allows(stranger) || !blocked.Allows(phone) {
+		t.Error("blocklist should refuse only its devices")
+	}
+
+	allow := DevicePolicy{AllowList: []string{phone, speaker}, BlockList: []string{speaker}}
+	if !allow.Allows("11:11:11:11:11:11") {
+		t.Error("allowlisted device refused")
+	}
+	if allow.Allows(stranger) {
+		t.Error("device outside a non-empty allowlist allowed")
+	}
+	if allow.Allows(speaker) {
+		t.Error("blocklist should win over the allowlist")
+	}
+}
+
+func TestAgentRejectsRefusedDevice(t *testing.T) {
+	b := newTestBackend()
+	b.policy = DevicePolicy{BlockList: []string{"40:C1:F6:D4:67:88"}}
+	agent := &bluezAgent{backend: b}
+
+	err := agent.AuthorizeService(dbus.ObjectPath("/org/bluez/hci0/dev_40_C1_F6_D4_67_88"), "0000110d-0000-1000-8000-00805f9b34fb")
+	if err == nil || err.Name != AGENT_REJECTED {
+		t.Errorf("AuthorizeService(blocked) = %v, want %s", err, AGENT_REJECTED)
+	}
+	if err := agent.RequestAuthorization(dbus.ObjectPath("/org/bluez/hci0/dev_11_11_11_11_11_11")); err != nil {
+		t.Errorf("RequestAuthorization(allowed) = %v, want nil", err)
+	}
+	if err := b.Connect("40:C1:F6:D4:67:88"); !errors.Is(err, ErrDeviceNotAllowed) {
+		t.Errorf("Connect(blocked) = %v, want ErrDeviceNotAllowed", err)
+	}
+}
@@ -0,0 +1,102 @@
+package bluetooth
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+
+	"github.com/b0bbywan/go-odio-api/logger"
+)
+
+// Audio profile names accepted by SetDeviceProfile, keyed to their BlueZ
+// service UUIDs. A2DP carries stereo music; HFP/HSP carry mono call audio.
+const (
+	ProfileA2DPSink   = "a2dp_sink"
+	ProfileA2DPSource = "a2dp_source"
+	ProfileHFPHF      = "hfp_hf"
+	ProfileHFPAG      = "hfp_ag"
+	ProfileHSPHS      = "hsp_hs"
+	ProfileHSPAG      = "hsp_ag"
+)
+
+var profileUUIDs = map[string]string{
+	ProfileA2DPSource: "0000110a-0000-1000-8000-00805f9b34fb",
+	ProfileA2DPSink:   "0000110b-0000-1000-8000-00805f9b34fb",
+	ProfileHSPHS:      "00001108-0000-1000-8000-00805f9b34fb",
+	ProfileHSPAG:      "00001112-0000-1000-8000-00805f9b34fb",
+	ProfileHFPHF:      "0000111e-0000-1000-8000-00805f9b34fb",
+	ProfileHFPAG:      "0000111f-0000-1000-8000-00805f9b34fb",
+}
+
+// profileUUID returns the BlueZ UUID of a profile name.
+func profileUUID(profile string) (string, error) {
+	uuid, ok := profileUUIDs[profile]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrInvalidProfile, profile)
+	}
+	return uuid, nil
+}
+
+// profileNames maps a device's UUIDs property to the audio profile names we
+// know, in the device's order; other services (AVRCP, PnP...) are skipped.
+func profileNames(props map[string]dbus.Variant) []string {
+	v, ok := props[BT_PROP_UUIDS]
+	if !ok {
+		return nil
+	}
+	uuids, ok := v.Value().([]string)
+	if !ok {
+		return nil
+	}
+	var names []string
+	for _, uuid := range uuids {
+		for name, known := range profileUUIDs {
+			if strings.EqualFold(uuid, known) {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	return names
+}
+
+// conflictingProfiles returns the profiles to drop before connecting profile:
+// a headset runs either A2DP or HFP/HSP, so switching means leaving the other.
+func conflictingProfiles(profile string) []string {
+	switch profile {
+	case ProfileA2DPSink, ProfileA2DPSource:
+		return []string{ProfileHFPHF, ProfileHFPAG, ProfileHSPHS, ProfileHSPAG}
+	default:
+		return []string{ProfileA2DPSink, ProfileA2DPSource}
+	}
+}
+
+// SetDeviceProfile switches a connected device to the given audio profile,
+// e.g. back to a2dp_sink when BlueZ negotiated HSP. Conflicting profiles are
+// disconnected first; failing to drop one the device never ran is expected
+// and only logged.
+func (b *BluetoothBackend) SetDeviceProfile(address, profile string) error {
+	if err := validateAddress(address); err != nil {
+		return err
+	}
+	uuid, err := profileUUID(profile)
+	if err != nil {
+		return err
+	}
+	obj := b.getObj(BLUETOOTH_PREFIX, string(devicePath(address)))
+
+	for _, other := range conflictingProfiles(profile) {
+		if err := b.callMethod(obj, DEVICE_DISCONNECT_PROFILE, profileUUIDs[other]); err != nil {
+			logger.Debug("[bluetooth] %s: disconnect %s: %v", address, other, err)
+		}
+	}
+
+	logger.Info("[bluetooth] switching %s to %s", address, profile)
+	if err := b.callMethod(obj, DEVICE_CONNECT_PROFILE, uuid); err != nil {
+		logger.Warn("[bluetooth] failed to connect %s profile on %s: %v", profile, address, err)
+		return fmt.Errorf("could not switch %s to %s: %w", address, profile, err)
+	}
+	b.refreshDevices()
+	return nil
+}
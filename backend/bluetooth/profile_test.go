@@ -0,0 +1,55 @@
+package bluetooth
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func TestProfileUUID(t *testing.T) {
+	uuid, err := profileUUID(ProfileA2DPSink)
+	if err != nil || uuid != "0000110b-0000-1000-8000-00805f9b34fb" {
+		t.Errorf("profileUUID(a2dp_sink) = %q, %v", uuid, err)
+	}
+	if _, err := profileUUID("stereo"); !errors.Is(err, ErrInvalidProfile) {
+		t.Errorf("profileUUID(stereo) error = %v, want ErrInvalidProfile", err)
+	}
+}
+
+func TestProfileNames(t *testing.T) {
+	props := map[string]dbus.Variant{
+		BT_PROP_UUIDS: dbus.MakeVariant([]string{
+			"0000110B-0000-1000-8000-00805F9B34FB",
+			"0000110e-0000-1000-8000-00805f9b34fb", // AVRCP, not an audio profile
+			"0000111e-0000-1000-8000-00805f9b34fb",
+		}),
+	}
+	want := []string{ProfileA2DPSink, ProfileHFPHF}
+	if got := profileNames(props); !slices.Equal(got, want) {
+		t.Errorf("profileNames() = %v, want %v", got, want)
+	}
+	if got := profileNames(map[string]dbus.Variant{}); got != nil {
+		t.Errorf("profileNames(no UUIDs) = %v, want nil", got)
+	}
+}
+
+func TestConflictingProfiles(t *testing.T) {
+	if got := conflictingProfiles(ProfileA2DPSink); !slices.Contains(got, ProfileHSPHS) || slices.Contains(got, ProfileA2DPSink) {
+		t.Errorf("conflictingProfiles(a2dp_sink) = %v", got)
+	}
+	if got := conflictingProfiles(ProfileHFPHF); !slices.Equal(got, []string{ProfileA2DPSink, ProfileA2DPSource}) {
+		t.Errorf("conflictingProfiles(hfp_hf) = %v", got)
+	}
+}
+
+func TestSetDeviceProfileValidates(t *testing.T) {
+	b := &BluetoothBackend{}
+	if err := b.SetDeviceProfile("nope", ProfileA2DPSink); !errors.Is(err, ErrInvalidAddress) {
+		t.Errorf("bad address error = %v, want ErrInvalidAddress", err)
+	}
+	if err := b.SetDeviceProfile("40:C1:F6:D4:67:88", "stereo"); !errors.Is(err, ErrInvalidProfile) {
+		t.Errorf("bad profile error = %v, want ErrInvalidProfile", err)
+	}
+}
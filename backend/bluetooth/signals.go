@@ -15,7 +15,11 @@ func (b *BluetoothBackend) onSignal(sig *dbus.Signal) bool {
 		return true // channel closed
 	}
 	if sig.Name == DBUS_OBJ_MANAGER+".InterfacesAdded" {
+		if props, ok := parseAdapterAdded(sig); ok {
+			b.autoPowerUpForAdapter(props)
+		}
 		if path, props, ok := parseInterfacesAdded(sig); ok {
+			b.autoPowerUpFor(path, props)
 			b.handleDiscoveredDevice(path, props)
 		}
 		return false
@@ -50,7 +54,12 @@ func (b *BluetoothBackend) onDevicePropertiesChanged(path dbus.ObjectPath, chang
 
 	if connected, ok := extractMapBool(changed, BT_STATE_CONNECTED); ok {
 		logger.Info("[bluetooth] device %s Connected=%v", path, connected)
+		if connected && b.rejectDevice(path) {
+			return
+		}
+		b.notifyConnection(DeviceConnection{Address: addressFromPath(path), Connected: connected})
 		if connected {
+			b.wakeConnectWaiters(addressFromPath(path))
 			b.cancelIdleTimer()
 			refresh = true
 			return
@@ -62,6 +71,14 @@ func (b *BluetoothBackend) onDevicePropertiesChanged(path dbus.ObjectPath, chang
 
 	if paired, ok := extractMapBool(changed, BT_STATE_PAIRED); ok && paired {
 		logger.Info("[bluetooth] device %s paired successfully", path)
+		if b.rejectDevice(path) {
+			return
+		}
+		b.notifyConnection(DeviceConnection{
+			Address:   addressFromPath(path),
+			Connected: b.isDeviceConnected(path),
+			Paired:    true,
+		})
 		if ok = b.trustDevice(path); !ok {
 			logger.Warn("[bluetooth] failed to trust device %s", path)
 			return
@@ -82,8 +99,14 @@ func (b *BluetoothBackend) onAdapterPropertiesChanged(changed map[string]dbus.Va
 
 	if discoverable, ok := extractMapBool(changed, BT_STATE_DISCOVERABLE); ok {
 		logger.Debug("[bluetooth] adapter Discoverable=%v", discoverable)
+		if !discoverable {
+			b.discoverableTimer.Cancel()
+		}
 		b.updateStatus(func(s *BluetoothStatus) {
 			s.Discoverable = discoverable
+			if !discoverable {
+				s.DiscoverableUntil = nil
+			}
 		})
 	}
 
@@ -113,6 +136,7 @@ func (b *BluetoothBackend) onAdapterPoweredChanged(powered bool) {
 	}
 	logger.Info("[bluetooth] adapter powered off")
 	b.cancelIdleTimer()
+	b.discoverableTimer.Cancel()
 	b.cleanupPoweredState()
 }
 
@@ -159,6 +183,23 @@ func parseInterfacesAdded(sig *dbus.Signal) (dbus.ObjectPath, map[string]dbus.Va
 	return path, dev, true
 }
 
+// parseAdapterAdded extracts the Adapter1 properties from an InterfacesAdded
+// signal about our adapter (BLUETOOTH_PATH).
+func parseAdapterAdded(sig *dbus.Signal) (map[string]dbus.Variant, bool) {
+	if sig == nil || len(sig.Body) < 2 {
+		return nil, false
+	}
+	if path, ok := sig.Body[0].(dbus.ObjectPath); !ok || path != BLUETOOTH_PATH {
+		return nil, false
+	}
+	ifaces, ok := sig.Body[1].(map[string]map[string]dbus.Variant)
+	if !ok {
+		return nil, false
+	}
+	adapter, ok := ifaces[BLUETOOTH_ADAPTER]
+	return adapter, ok
+}
+
 func extractMapBool(v map[string]dbus.Variant, value BluetoothState) (bool, bool) {
 	if extractVar, ok := v[value.String()]; ok {
 		return extractBool(extractVar)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/godbus/dbus/v5"
@@ -15,8 +16,23 @@ import (
 // ErrInvalidAddress is returned when a Bluetooth address is malformed.
 var ErrInvalidAddress = errors.New("invalid bluetooth address")
 
-// managedTimer is a self-locking one-shot timer handle shared by the idle and
-// scan auto-stop timers.
+// ErrDeviceNotAllowed is returned when the device policy refuses an address.
+var ErrDeviceNotAllowed = errors.New("bluetooth device not allowed")
+
+// ErrInvalidAlias is returned when a device alias exceeds BlueZ's limit.
+var ErrInvalidAlias = errors.New("invalid bluetooth alias")
+
+// ErrInvalidProfile is returned when a profile name is not a known audio profile.
+var ErrInvalidProfile = errors.New("invalid bluetooth profile")
+
+// ErrNoPendingConfirmation is returned when no pairing awaits confirmation.
+var ErrNoPendingConfirmation = errors.New("no pairing awaiting confirmation")
+
+// ErrInvalidDuration is returned when a discoverable window is not positive.
+var ErrInvalidDuration = errors.New("invalid bluetooth duration")
+
+// managedTimer is a self-locking one-shot timer handle shared by the idle,
+// scan and discoverable auto-stop timers.
 type managedTimer struct {
 	mu    sync.Mutex
 	timer *time.Timer
@@ -55,10 +71,31 @@ type BluetoothBackend struct {
 	timeout        time.Duration
 	pairingTimeout time.Duration
 	idleTimeout    time.Duration
-	scanTimeout    time.Duration
-	powerOnStart   bool
-	agent          *bluezAgent
+	// power down when idleTimeout expires (bluetooth.idle.poweroff)
+	idlePowerOff bool
+	scanTimeout  time.Duration
+	powerOnStart bool
+	// power up when BlueZ announces a trusted device or the adapter
+	// (bluetooth.auto_powerup); autoPoweringUp single-flights it
+	autoPowerUp    bool
+	autoPoweringUp atomic.Bool
+	// powers the adapter up at Start; nil means PowerUp, replaced in tests
+	powerUp func() error
+	// autoconnect: reconnect trusted devices after a power-up
+	autoConnect            bool
+	autoConnectTimeout     time.Duration
+	autoConnectConcurrency int
+	policy                 DevicePolicy
+	agent                  *bluezAgent
+	// BlueZ agent IO capability; "" means AGENT_CAPABILITY
+	agentCapability string
+	// numeric comparison awaiting POST /bluetooth/pairing/confirm
+	// (DisplayYesNo agent only), guarded by confirmMu
+	confirmMu      sync.Mutex
+	pendingConfirm *pendingConfirmation
 	idleTimer      managedTimer
+	// ends a SetDiscoverableOnly window; pairing mode relies on BlueZ timeouts
+	discoverableTimer managedTimer
 	// Permanent (New → Close): watches adapter + device PropertiesChanged and
 	// BlueZ InterfacesAdded (scan discovery).
 	listener *DBusListener
@@ -69,6 +106,9 @@ type BluetoothBackend struct {
 	// permanent cache (no expiration) for status tracking
 	statusCache *cache.Cache[BluetoothStatus]
 	events      chan events.Event
+	// requests blocked in WaitConnected, keyed by upper-case address
+	waitersMu      sync.Mutex
+	connectWaiters map[string][]chan struct{}
 }
 
 type dbusTimeoutError struct{}
@@ -85,23 +125,49 @@ func (e *bluetoothUnsupportedError) Error() string {
 
 // BluetoothDevice represents a Bluetooth device, known or freshly scanned.
 // Bonded tells them apart: a bonded device reconnects without needing the
-// adapter or the target speaker to be pairable.
+// adapter or the target speaker to be pairable. Alias is the user-set name
+// BlueZ persists (the advertised Name until renamed). ConnectedProfiles lists the
+// audio profiles (a2dp_sink, hfp_hf...) the device exposes, from its UUIDs.
+// RSSI and TxPower (dBm) are only known while BlueZ has recently seen the
+// device, so they are omitted otherwise.
 type BluetoothDevice struct {
+	Address           string   `json:"address"`
+	Name              string   `json:"name"`
+	Alias             string   `json:"alias,omitempty"`
+	Paired            bool     `json:"paired"`
+	Bonded            bool     `json:"bonded"`
+	Trusted           bool     `json:"trusted"`
+	Connected         bool     `json:"connected"`
+	ConnectedProfiles []string `json:"connected_profiles,omitempty"`
+	RSSI              *int16   `json:"rssi,omitempty"`
+	TxPower           *int16   `json:"tx_power,omitempty"`
+}
+
+// PendingConfirmation is a numeric-comparison pairing waiting for the user
+// to check that Passkey matches the one the device shows.
+type PendingConfirmation struct {
+	Address   string    `json:"address"`
+	Passkey   string    `json:"passkey"` // six digits, zero-padded; empty to authorize a pairing without one
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// DeviceConnection is the payload of bluetooth.connection events, sent as soon
+// as BlueZ reports a device connecting, disconnecting or finishing pairing.
+type DeviceConnection struct {
 	Address   string `json:"address"`
-	Name      string `json:"name"`
-	Paired    bool   `json:"paired"`
-	Bonded    bool   `json:"bonded"`
-	Trusted   bool   `json:"trusted"`
 	Connected bool   `json:"connected"`
+	Paired    bool   `json:"paired,omitempty"`
 }
 
 // BluetoothStatus represents the current Bluetooth state
 type BluetoothStatus struct {
-	Powered       bool              `json:"powered"`
-	Discoverable  bool              `json:"discoverable"`
-	Pairable      bool              `json:"pairable"`
-	PairingActive bool              `json:"pairing_active"`
-	PairingUntil  *time.Time        `json:"pairing_until,omitempty"`
-	Scanning      bool              `json:"scanning"`
-	KnownDevices  []BluetoothDevice `json:"known_devices,omitempty"`
+	Powered       bool       `json:"powered"`
+	Discoverable  bool       `json:"discoverable"`
+	Pairable      bool       `json:"pairable"`
+	PairingActive bool       `json:"pairing_active"`
+	PairingUntil  *time.Time `json:"pairing_until,omitempty"`
+	// DiscoverableUntil is set while the adapter is visible without pairing.
+	DiscoverableUntil *time.Time        `json:"discoverable_until,omitempty"`
+	Scanning          bool              `json:"scanning"`
+	KnownDevices      []BluetoothDevice `json:"known_devices,omitempty"`
 }
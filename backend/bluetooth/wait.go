@@ -0,0 +1,80 @@
+package bluetooth
+
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
+// WaitConnected blocks until the device at address reports Connected=true,
+// or ctx ends. It returns at once when the device is already connected.
+// Wake-ups come from the permanent PropertiesChanged listener, so no D-Bus
+// call is made while waiting.
+func (b *BluetoothBackend) WaitConnected(ctx context.Context, address string) error {
+	if err := validateAddress(address); err != nil {
+		return err
+	}
+	address = strings.ToUpper(address)
+
+	// Subscribe before reading the cache so a connection landing in between
+	// is not missed.
+	ch := b.addConnectWaiter(address)
+	defer b.removeConnectWaiter(address, ch)
+
+	if b.isDeviceConnectedCached(address) {
+		return nil
+	}
+
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// isDeviceConnectedCached reads Connected from the cached device list.
+func (b *BluetoothBackend) isDeviceConnectedCached(address string) bool {
+	for _, d := range b.GetStatus().KnownDevices {
+		if strings.EqualFold(d.Address, address) {
+			return d.Connected
+		}
+	}
+	return false
+}
+
+func (b *BluetoothBackend) addConnectWaiter(address string) chan struct{} {
+	ch := make(chan struct{})
+	b.waitersMu.Lock()
+	defer b.waitersMu.Unlock()
+	if b.connectWaiters == nil {
+		b.connectWaiters = make(map[string][]chan struct{})
+	}
+	b.connectWaiters[address] = append(b.connectWaiters[address], ch)
+	return ch
+}
+
+func (b *BluetoothBackend) removeConnectWaiter(address string, ch chan struct{}) {
+	b.waitersMu.Lock()
+	defer b.waitersMu.Unlock()
+	waiters := slices.DeleteFunc(b.connectWaiters[address], func(c chan struct{}) bool {
+		return c == ch
+	})
+	if len(waiters) == 0 {
+		delete(b.connectWaiters, address)
+		return
+	}
+	b.connectWaiters[address] = waiters
+}
+
+// wakeConnectWaiters releases every request waiting on address. Each channel
+// is closed once and dropped, so a later disconnect/reconnect cannot close it
+// twice.
+func (b *BluetoothBackend) wakeConnectWaiters(address string) {
+	b.waitersMu.Lock()
+	defer b.waitersMu.Unlock()
+	for _, ch := range b.connectWaiters[address] {
+		close(ch)
+	}
+	delete(b.connectWaiters, address)
+}
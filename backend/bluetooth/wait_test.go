@@ -0,0 +1,95 @@
+package bluetooth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func connectedSignal(path dbus.ObjectPath, connected bool) *dbus.Signal {
+	return &dbus.Signal{
+		Path: path,
+		Body: []interface{}{
+			"org.bluez.Device1",
+			map[string]dbus.Variant{"Connected": dbus.MakeVariant(connected)},
+		},
+	}
+}
+
+func TestWaitConnected(t *testing.T) {
+	const address = "40:C1:F6:D4:67:88"
+	path := devicePath(address)
+
+	t.Run("returns when the device connects", func(t *testing.T) {
+		b := newTestBackend()
+		done := make(chan error, 1)
+		go func() { done <- b.WaitConnected(context.Background(), "40:c1:f6:d4:67:88") }()
+
+		// Wait for the request to subscribe before the signal fires.
+		deadline := time.Now().Add(time.Second)
+		for {
+			b.waitersMu.Lock()
+			n := len(b.connectWaiters[address])
+			b.waitersMu.Unlock()
+			if n == 1 {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("WaitConnected never subscribed")
+			}
+			time.Sleep(time.Millisecond)
+		}
+
+		b.onSignal(connectedSignal(path, false))
+		select {
+		case err := <-done:
+			t.Fatalf("WaitConnected returned %v on a disconnect", err)
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		b.onSignal(connectedSignal(path, true))
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("WaitConnected() = %v, want nil", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("WaitConnected did not return after Connected=true")
+		}
+		if len(b.connectWaiters) != 0 {
+			t.Errorf("connectWaiters = %v, want empty", b.connectWaiters)
+		}
+	})
+
+	t.Run("already connected returns at once", func(t *testing.T) {
+		b := newTestBackend()
+		b.seedStatus(BluetoothStatus{KnownDevices: []BluetoothDevice{{Address: address, Connected: true}}})
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := b.WaitConnected(ctx, address); err != nil {
+			t.Errorf("WaitConnected() = %v, want nil", err)
+		}
+	})
+
+	t.Run("times out", func(t *testing.T) {
+		b := newTestBackend()
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if err := b.WaitConnected(ctx, address); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("WaitConnected() = %v, want %v", err, context.DeadlineExceeded)
+		}
+		if len(b.connectWaiters) != 0 {
+			t.Errorf("connectWaiters = %v, want empty after timeout", b.connectWaiters)
+		}
+	})
+
+	t.Run("invalid address", func(t *testing.T) {
+		b := newTestBackend()
+		if err := b.WaitConnected(context.Background(), "nope"); !errors.Is(err, ErrInvalidAddress) {
+			t.Errorf("WaitConnected() = %v, want %v", err, ErrInvalidAddress)
+		}
+	})
+}
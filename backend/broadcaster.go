@@ -3,22 +3,37 @@ package backend
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/b0bbywan/go-odio-api/events"
 	"github.com/b0bbywan/go-odio-api/logger"
 )
 
+// historySize is how many past external events Broadcaster.History keeps.
+const historySize = 200
+
 // Broadcaster fans out events from a single upstream channel to all subscribers.
 type Broadcaster struct {
 	mu      sync.RWMutex
 	clients map[chan events.Event]func(events.Event) bool
+	dedup   *events.Deduper // nil = no deduplication
+
+	historyMu sync.Mutex
+	history   []events.Event // ring buffer, next holds the oldest once full
+	next      int
 }
 
 // NewBroadcaster starts a broadcaster that reads from upstream and fans out to
 // all subscribers. It stops when ctx is cancelled or upstream is closed.
 func NewBroadcaster(ctx context.Context, upstream <-chan events.Event) *Broadcaster {
+	return newBroadcaster(ctx, upstream, nil)
+}
+
+// newBroadcaster is NewBroadcaster with repeated events dropped by dedup.
+func newBroadcaster(ctx context.Context, upstream <-chan events.Event, dedup *events.Deduper) *Broadcaster {
 	b := &Broadcaster{
 		clients: make(map[chan events.Event]func(events.Event) bool),
+		dedup:   dedup,
 	}
 	go b.run(ctx, upstream)
 	return b
@@ -49,6 +64,30 @@ func (b *Broadcaster) Unsubscribe(ch chan events.Event) {
 	close(ch)
 }
 
+// History returns the last broadcast events, oldest first. Internal events
+// are not recorded.
+func (b *Broadcaster) History() []events.Event {
+	b.historyMu.Lock()
+	defer b.historyMu.Unlock()
+	out := make([]events.Event, 0, len(b.history))
+	out = append(out, b.history[b.next:]...)
+	return append(out, b.history[:b.next]...)
+}
+
+func (b *Broadcaster) record(e events.Event) {
+	if e.Internal {
+		return
+	}
+	b.historyMu.Lock()
+	defer b.historyMu.Unlock()
+	if len(b.history) < historySize {
+		b.history = append(b.history, e)
+		return
+	}
+	b.history[b.next] = e
+	b.next = (b.next + 1) % historySize
+}
+
 func (b *Broadcaster) broadcast(e events.Event) {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
@@ -73,6 +112,11 @@ func (b *Broadcaster) run(ctx context.Context, upstream <-chan events.Event) {
 			if !ok {
 				return
 			}
+			if !b.dedup.Allow(e) {
+				logger.Debug("[sse] dropping duplicate %s event", e.Type)
+				continue
+			}
+			b.record(e)
 			b.broadcast(e)
 		}
 	}
@@ -80,7 +124,7 @@ func (b *Broadcaster) run(ctx context.Context, upstream <-chan events.Event) {
 
 // newBroadcasterFromBackend wires all enabled sub-backend event channels into
 // a single Broadcaster. Called once by Backend.New().
-func newBroadcasterFromBackend(ctx context.Context, b *Backend) *Broadcaster {
+func newBroadcasterFromBackend(ctx context.Context, b *Backend, dedupWindow time.Duration) *Broadcaster {
 	var srcs []<-chan events.Event
 	if b.Bluetooth != nil {
 		srcs = append(srcs, b.Bluetooth.Events())
@@ -100,7 +144,10 @@ func newBroadcasterFromBackend(ctx context.Context, b *Backend) *Broadcaster {
 	if b.Upgrade != nil {
 		srcs = append(srcs, b.Upgrade.Events())
 	}
-	return NewBroadcaster(ctx, fanIn(ctx, srcs...))
+	for _, p := range b.Plugins {
+		srcs = append(srcs, p.Events())
+	}
+	return newBroadcaster(ctx, fanIn(ctx, srcs...), events.NewDeduper(dedupWindow))
 }
 
 // fanIn merges multiple event channels into one.
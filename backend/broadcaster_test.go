@@ -7,6 +7,7 @@ import (
 
 	"github.com/b0bbywan/go-odio-api/backend/login1"
 	"github.com/b0bbywan/go-odio-api/events"
+	"github.com/b0bbywan/go-odio-api/plugin"
 )
 
 func TestBroadcaster_Subscribe_ReceivesAll(t *testing.T) {
@@ -109,7 +110,7 @@ func TestBroadcaster_PowerActionEventFlowsThrough(t *testing.T) {
 
 func TestNewBroadcasterFromBackend_Login1Nil_NoPanic(t *testing.T) {
 	b := &Backend{Login1: nil}
-	broadcaster := newBroadcasterFromBackend(context.Background(), b)
+	broadcaster := newBroadcasterFromBackend(context.Background(), b, 0)
 	ch := broadcaster.Subscribe()
 	defer broadcaster.Unsubscribe(ch)
 	// No events expected, just verify no panic and channel is usable.
@@ -163,3 +164,77 @@ func TestBroadcaster_MultipleSubscribersIndependentFilters(t *testing.T) {
 		// expected: nothing
 	}
 }
+
+func TestBroadcaster_DropsDuplicates(t *testing.T) {
+	upstream := make(chan events.Event, 4)
+	b := newBroadcaster(context.Background(), upstream, events.NewDeduper(time.Second))
+
+	ch := b.Subscribe()
+	defer b.Unsubscribe(ch)
+
+	connected := events.Event{Type: events.TypeBluetoothUpdated, Data: map[string]bool{"connected": true}}
+	upstream <- connected
+	upstream <- connected
+	upstream <- connected
+	upstream <- events.Event{Type: events.TypePlayerUpdated}
+
+	for _, want := range []string{events.TypeBluetoothUpdated, events.TypePlayerUpdated} {
+		select {
+		case got := <-ch:
+			if got.Type != want {
+				t.Errorf("got %s, want %s", got.Type, want)
+			}
+		case <-time.After(100 * time.Millisecond):
+			t.Fatalf("timed out waiting for event %s", want)
+		}
+	}
+}
+
+func TestBroadcaster_HistoryKeepsLastEvents(t *testing.T) {
+	upstream := make(chan events.Event)
+	b := NewBroadcaster(context.Background(), upstream)
+
+	upstream <- events.Event{Type: events.TypeServiceUpdated, Data: "internal", Internal: true}
+	for i := range historySize + 5 {
+		upstream <- events.Event{Type: events.TypePlayerUpdated, Data: i}
+	}
+	// An unbuffered send only proves the previous event was picked up.
+	upstream <- events.Event{Type: events.TypeAudioUpdated}
+	time.Sleep(10 * time.Millisecond)
+
+	history := b.History()
+	if len(history) != historySize {
+		t.Fatalf("len(History()) = %d, want %d", len(history), historySize)
+	}
+	if got := history[0].Data; got != 6 {
+		t.Errorf("oldest event = %v, want 6", got)
+	}
+	if got := history[historySize-1].Type; got != events.TypeAudioUpdated {
+		t.Errorf("newest event = %s, want %s", got, events.TypeAudioUpdated)
+	}
+}
+
+type eventPlugin struct{ ch chan events.Event }
+
+func (p *eventPlugin) Start() error                { return nil }
+func (p *eventPlugin) Close()                      {}
+func (p *eventPlugin) Routes() []plugin.Route      { return nil }
+func (p *eventPlugin) Events() <-chan events.Event { return p.ch }
+
+func TestBroadcasterFromBackend_ForwardsPluginEvents(t *testing.T) {
+	p := &eventPlugin{ch: make(chan events.Event, 1)}
+	b := newBroadcasterFromBackend(context.Background(), &Backend{Plugins: []plugin.Backend{p}}, 0)
+
+	ch := b.Subscribe()
+	defer b.Unsubscribe(ch)
+
+	p.ch <- events.Event{Type: "squeezebox.updated"}
+	select {
+	case got := <-ch:
+		if got.Type != "squeezebox.updated" {
+			t.Errorf("got %s, want squeezebox.updated", got.Type)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("timed out waiting for the plugin event")
+	}
+}
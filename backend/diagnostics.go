@@ -0,0 +1,94 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/b0bbywan/go-odio-api/config"
+)
+
+// notStarted is the reason recorded for a backend enabled in the config that
+// its constructor left nil without saying why.
+const notStarted = "enabled but not started, see logs"
+
+// BackendStatus is what New decided for one backend.
+type BackendStatus struct {
+	Enabled     bool   `json:"enabled"`         // requested in the config
+	Initialized bool   `json:"initialized"`     // created and running
+	Error       string `json:"error,omitempty"` // why an enabled backend is not running
+}
+
+// SystemdDiagnostics counts the units the systemd backend was asked to watch.
+type SystemdDiagnostics struct {
+	SystemUnits int `json:"system_units"`
+	UserUnits   int `json:"user_units"`
+}
+
+// BluetoothDiagnostics holds the configured Bluetooth timeouts.
+type BluetoothDiagnostics struct {
+	Timeout        string `json:"timeout"`
+	PairingTimeout string `json:"pairing_timeout"`
+	IdleTimeout    string `json:"idle_timeout"`
+	ScanTimeout    string `json:"scan_timeout"`
+	StartupTimeout string `json:"startup_timeout"`
+}
+
+// Diagnostics is a snapshot of the startup decisions of New, so a backend that
+// failed to come up can be told apart from one that was never enabled.
+// Listens is filled in by the API server.
+type Diagnostics struct {
+	Listens   []string                 `json:"listens"`
+	Backends  map[string]BackendStatus `json:"backends"`
+	Systemd   *SystemdDiagnostics      `json:"systemd,omitempty"`
+	Bluetooth *BluetoothDiagnostics    `json:"bluetooth,omitempty"`
+}
+
+func newDiagnostics(btcfg *config.BluetoothConfig, syscfg *config.SystemdConfig) *Diagnostics {
+	d := &Diagnostics{Backends: make(map[string]BackendStatus)}
+	if syscfg != nil && syscfg.Enabled {
+		d.Systemd = &SystemdDiagnostics{
+			SystemUnits: len(syscfg.SystemServices),
+			UserUnits:   len(syscfg.UserServices),
+		}
+	}
+	if btcfg != nil && btcfg.Enabled {
+		d.Bluetooth = &BluetoothDiagnostics{
+			Timeout:        btcfg.Timeout.String(),
+			PairingTimeout: btcfg.PairingTimeout.String(),
+			IdleTimeout:    btcfg.IdleTimeout.String(),
+			ScanTimeout:    btcfg.ScanTimeout.String(),
+			StartupTimeout: btcfg.StartupTimeout.String(),
+		}
+	}
+	return d
+}
+
+// record stores the outcome of a backend constructor. A startup timeout or a
+// config.ErrBackendDisabled only disables the backend: it is recorded as its
+// error, prefixed with the backend name, and swallowed. Any other error is
+// returned for New to fail on.
+func (d *Diagnostics) record(name string, enabled, initialized bool, err error) error {
+	status := BackendStatus{Enabled: enabled, Initialized: initialized}
+	var timeoutErr *startupTimeoutError
+	switch {
+	case errors.As(err, &timeoutErr):
+		status.Error = err.Error()
+	case errors.Is(err, config.ErrBackendDisabled):
+		status.Error = fmt.Sprintf("%s: %v", name, err)
+	case err != nil:
+		return err
+	case enabled && !initialized:
+		status.Error = fmt.Sprintf("%s: %s", name, notStarted)
+	}
+	d.Backends[name] = status
+	return nil
+}
+
+// skip records an enabled backend that New chose not to create.
+func (d *Diagnostics) skip(name string, enabled bool, reason string) {
+	status := BackendStatus{Enabled: enabled}
+	if enabled {
+		status.Error = fmt.Sprintf("%s: %s", name, reason)
+	}
+	d.Backends[name] = status
+}
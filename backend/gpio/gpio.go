@@ -0,0 +1,114 @@
+package gpio
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/warthog618/go-gpiocdev"
+
+	"github.com/b0bbywan/go-odio-api/config"
+	"github.com/b0bbywan/go-odio-api/logger"
+)
+
+// New creates a GPIO button backend. actions holds the operations of the
+// enabled backends, keyed by action name; mappings to a known action whose
+// backend is disabled are skipped with a warning.
+func New(ctx context.Context, cfg *config.GPIOConfig, actions map[string]Action) (*GPIOBackend, error) {
+	if cfg == nil || !cfg.Enabled {
+		return nil, nil
+	}
+
+	mappings := make([]config.GPIOMapping, 0, len(cfg.Mappings))
+	for _, m := range cfg.Mappings {
+		if !slices.Contains(SupportedActions, m.Action) {
+			return nil, fmt.Errorf("gpio: pin %d: unknown action %q", m.Pin, m.Action)
+		}
+		if _, ok := actions[m.Action]; !ok {
+			logger.Warn("[gpio] pin %d: backend for %s is disabled, skipping", m.Pin, m.Action)
+			continue
+		}
+		mappings = append(mappings, m)
+	}
+	if len(mappings) == 0 {
+		logger.Info("[gpio] no usable button mapping, backend disabled")
+		return nil, fmt.Errorf("%w: no usable button mapping", config.ErrBackendDisabled)
+	}
+
+	return &GPIOBackend{
+		ctx:       ctx,
+		chip:      cfg.Chip,
+		debounce:  cfg.Debounce,
+		mappings:  mappings,
+		actions:   actions,
+		lastPress: make(map[int]time.Duration),
+	}, nil
+}
+
+// Start requests one input line per mapping, pulled up and watched for falling
+// edges: a button wired to ground pulls the line low when pressed.
+func (g *GPIOBackend) Start() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, m := range g.mappings {
+		line, err := gpiocdev.RequestLine(g.chip, m.Pin,
+			gpiocdev.AsInput,
+			gpiocdev.WithPullUp,
+			gpiocdev.WithFallingEdge,
+			gpiocdev.WithEventHandler(func(evt gpiocdev.LineEvent) {
+				g.handlePress(m.Pin, m.Action, evt.Timestamp)
+			}),
+		)
+		if err != nil {
+			g.closeLines()
+			return fmt.Errorf("gpio: request %s line %d: %w", g.chip, m.Pin, err)
+		}
+		g.lines = append(g.lines, line)
+		logger.Debug("[gpio] %s line %d mapped to %s", g.chip, m.Pin, m.Action)
+	}
+
+	logger.Info("[gpio] backend started, %d button(s) on %s", len(g.lines), g.chip)
+	return nil
+}
+
+// handlePress runs the action bound to pin unless the edge falls within the
+// debounce window of the previous accepted one. ts is the kernel event
+// timestamp, so the window is immune to handler scheduling delays.
+func (g *GPIOBackend) handlePress(pin int, action string, ts time.Duration) {
+	g.pressMu.Lock()
+	last, seen := g.lastPress[pin]
+	if seen && ts-last < g.debounce {
+		g.pressMu.Unlock()
+		logger.Debug("[gpio] line %d bounce ignored", pin)
+		return
+	}
+	g.lastPress[pin] = ts
+	g.pressMu.Unlock()
+
+	if g.ctx.Err() != nil {
+		return
+	}
+
+	logger.Debug("[gpio] line %d pressed, running %s", pin, action)
+	if err := g.actions[action](); err != nil {
+		logger.Warn("[gpio] %s failed: %v", action, err)
+	}
+}
+
+// closeLines releases the requested lines. Caller holds mu.
+func (g *GPIOBackend) closeLines() {
+	for _, line := range g.lines {
+		if err := line.Close(); err != nil {
+			logger.Warn("[gpio] failed to close line: %v", err)
+		}
+	}
+	g.lines = nil
+}
+
+func (g *GPIOBackend) Close() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.closeLines()
+}
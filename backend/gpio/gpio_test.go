@@ -0,0 +1,123 @@
+package gpio
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/b0bbywan/go-odio-api/config"
+)
+
+func TestNew(t *testing.T) {
+	noop := func() error { return nil }
+
+	tests := []struct {
+		name      string
+		cfg       *config.GPIOConfig
+		actions   map[string]Action
+		wantNil   bool
+		wantErr   bool
+		wantLines int
+	}{
+		{
+			name:    "nil config",
+			cfg:     nil,
+			wantNil: true,
+		},
+		{
+			name:    "disabled",
+			cfg:     &config.GPIOConfig{Enabled: false, Mappings: []config.GPIOMapping{{Pin: 17, Action: ActionMPRISPlayPause}}},
+			wantNil: true,
+		},
+		{
+			name:    "unknown action",
+			cfg:     &config.GPIOConfig{Enabled: true, Mappings: []config.GPIOMapping{{Pin: 17, Action: "mpris.eject"}}},
+			wantErr: true,
+		},
+		{
+			name:    "action of a disabled backend is skipped",
+			cfg:     &config.GPIOConfig{Enabled: true, Mappings: []config.GPIOMapping{{Pin: 17, Action: ActionAudioMute}}},
+			actions: map[string]Action{ActionMPRISPlayPause: noop},
+			wantErr: true,
+		},
+		{
+			name: "usable mappings are kept",
+			cfg: &config.GPIOConfig{Enabled: true, Mappings: []config.GPIOMapping{
+				{Pin: 17, Action: ActionMPRISPlayPause},
+				{Pin: 27, Action: ActionAudioMute},
+			}},
+			actions:   map[string]Action{ActionMPRISPlayPause: noop},
+			wantLines: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := New(context.Background(), tt.cfg, tt.actions)
+			if tt.wantErr {
+				if err == nil || g != nil {
+					t.Fatalf("New() = %v, %v, want nil and an error", g, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New() error: %v", err)
+			}
+			if tt.wantNil {
+				if g != nil {
+					t.Fatal("New() should return nil")
+				}
+				return
+			}
+			if len(g.mappings) != tt.wantLines {
+				t.Errorf("mappings = %d, want %d", len(g.mappings), tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestHandlePressDebounce(t *testing.T) {
+	calls := 0
+	g := &GPIOBackend{
+		ctx:       context.Background(),
+		debounce:  50 * time.Millisecond,
+		actions:   map[string]Action{ActionMPRISPlayPause: func() error { calls++; return nil }},
+		lastPress: make(map[int]time.Duration),
+	}
+
+	presses := []time.Duration{
+		1 * time.Second,
+		1*time.Second + 10*time.Millisecond, // bounce
+		1*time.Second + 40*time.Millisecond, // bounce
+		1*time.Second + 60*time.Millisecond, // new press
+		2 * time.Second,                     // new press
+	}
+	for _, ts := range presses {
+		g.handlePress(17, ActionMPRISPlayPause, ts)
+	}
+	if calls != 3 {
+		t.Errorf("action ran %d times, want 3", calls)
+	}
+
+	// Lines debounce independently
+	g.handlePress(27, ActionMPRISPlayPause, 2*time.Second+time.Millisecond)
+	if calls != 4 {
+		t.Errorf("action ran %d times after another line's press, want 4", calls)
+	}
+}
+
+func TestHandlePressAfterShutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	called := false
+	g := &GPIOBackend{
+		ctx:       ctx,
+		actions:   map[string]Action{ActionMPRISNext: func() error { called = true; return nil }},
+		lastPress: make(map[int]time.Duration),
+	}
+	g.handlePress(17, ActionMPRISNext, time.Second)
+	if called {
+		t.Error("action should not run once the context is cancelled")
+	}
+}
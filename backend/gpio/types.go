@@ -0,0 +1,54 @@
+package gpio
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/warthog618/go-gpiocdev"
+
+	"github.com/b0bbywan/go-odio-api/config"
+)
+
+// Action is a backend operation bound to a button press.
+type Action func() error
+
+// Action names accepted in gpio.mappings.
+const (
+	ActionMPRISPlay      = "mpris.play"
+	ActionMPRISPause     = "mpris.pause"
+	ActionMPRISPlayPause = "mpris.play_pause"
+	ActionMPRISStop      = "mpris.stop"
+	ActionMPRISNext      = "mpris.next"
+	ActionMPRISPrevious  = "mpris.previous"
+	ActionAudioMute      = "audio.mute"
+	ActionBluetoothPair  = "bluetooth.pairing_mode"
+)
+
+// SupportedActions lists every action name a mapping may use.
+var SupportedActions = []string{
+	ActionMPRISPlay,
+	ActionMPRISPause,
+	ActionMPRISPlayPause,
+	ActionMPRISStop,
+	ActionMPRISNext,
+	ActionMPRISPrevious,
+	ActionAudioMute,
+	ActionBluetoothPair,
+}
+
+// GPIOBackend maps hardware buttons on GPIO lines to backend actions.
+type GPIOBackend struct {
+	ctx      context.Context
+	chip     string
+	debounce time.Duration
+	mappings []config.GPIOMapping
+	actions  map[string]Action
+
+	mu    sync.Mutex
+	lines []*gpiocdev.Line
+
+	// Debounce: kernel timestamp of the last accepted press per line
+	lastPress map[int]time.Duration
+	pressMu   sync.Mutex
+}
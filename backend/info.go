@@ -6,6 +6,7 @@ import (
 	"os"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/b0bbywan/go-odio-api/config"
 	"github.com/b0bbywan/go-odio-api/logger"
@@ -25,13 +26,20 @@ type ServerDeviceInfo struct {
 	APISW      string   `json:"api_sw"`
 	APIVersion string   `json:"api_version"`
 	Backends   Backends `json:"backends"`
+	ReadOnly   bool     `json:"readonly"`
+	// CacheAges holds, per cached backend, when its cache was last written;
+	// backends that never loaded are left out.
+	CacheAges map[string]time.Time `json:"cache_ages"`
 }
 
 type Backends struct {
+	ALSA       bool `json:"alsa"`
 	Bluetooth  bool `json:"bluetooth"`
+	GPIO       bool `json:"gpio"`
 	MPRIS      bool `json:"mpris"`
 	Power      bool `json:"power"`
 	PulseAudio bool `json:"pulseaudio"`
+	Snapcast   bool `json:"snapcast"`
 	Systemd    bool `json:"systemd"`
 	Upgrade    bool `json:"upgrade"`
 	Zeroconf   bool `json:"zeroconf"`
@@ -99,13 +107,80 @@ func (b *Backend) GetServerDeviceInfo() (ServerDeviceInfo, error) {
 		APISW:      config.AppName,
 		APIVersion: config.AppVersion,
 		Backends: Backends{
+			ALSA:       b.ALSA != nil,
 			Bluetooth:  b.Bluetooth != nil,
+			GPIO:       b.GPIO != nil,
 			MPRIS:      b.MPRIS != nil,
 			Power:      b.Login1 != nil,
 			PulseAudio: b.Pulse != nil,
+			Snapcast:   b.Snapcast != nil,
 			Systemd:    b.Systemd != nil,
 			Upgrade:    b.Upgrade != nil,
 			Zeroconf:   b.Zeroconf != nil,
 		},
+		CacheAges: b.cacheAges(),
 	}, nil
 }
+
+// NetworkInfo lists where the API can be reached: the addresses it listens on
+// and the interfaces zeroconf announces it on.
+type NetworkInfo struct {
+	Listens  []string     `json:"listens"`
+	Zeroconf ZeroconfInfo `json:"zeroconf"`
+}
+
+type ZeroconfInfo struct {
+	Active      bool     `json:"active"`
+	ServiceType string   `json:"service_type,omitempty"`
+	Port        int      `json:"port,omitempty"`
+	Interfaces  []string `json:"interfaces"`
+	TxtRecords  []string `json:"txt_records,omitempty"`
+}
+
+// GetNetworkInfo reports the zeroconf side of NetworkInfo; the API fills in
+// Listens from its own configuration.
+func (b *Backend) GetNetworkInfo() NetworkInfo {
+	info := NetworkInfo{Listens: []string{}, Zeroconf: ZeroconfInfo{Interfaces: []string{}}}
+	if b.Zeroconf == nil {
+		return info
+	}
+	cfg := b.Zeroconf.Config
+	info.Zeroconf = ZeroconfInfo{
+		Active:      true,
+		ServiceType: cfg.ServiceType,
+		Port:        cfg.Port,
+		Interfaces:  make([]string, 0, len(cfg.Listen)),
+		TxtRecords:  b.Zeroconf.TxtRecords(),
+	}
+	for _, iface := range cfg.Listen {
+		info.Zeroconf.Interfaces = append(info.Zeroconf.Interfaces, iface.Name)
+	}
+	return info
+}
+
+func (b *Backend) cacheAges() map[string]time.Time {
+	ages := make(map[string]time.Time)
+	set := func(name string, at time.Time) {
+		if !at.IsZero() {
+			ages[name] = at
+		}
+	}
+	if b.MPRIS != nil {
+		set("mpris", b.MPRIS.CacheUpdatedAt())
+	}
+	if b.Pulse != nil {
+		// clients and outputs are cached apart; report the fresher one
+		at := b.Pulse.CacheUpdatedAt()
+		if outputs := b.Pulse.OutputCacheUpdatedAt(); outputs.After(at) {
+			at = outputs
+		}
+		set("pulseaudio", at)
+	}
+	if b.Systemd != nil {
+		set("systemd", b.Systemd.CacheUpdatedAt())
+	}
+	if b.Bluetooth != nil {
+		set("bluetooth", b.Bluetooth.CacheUpdatedAt())
+	}
+	return ages
+}
@@ -0,0 +1,107 @@
+package login1
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/b0bbywan/go-odio-api/logger"
+)
+
+var (
+	ErrNoPendingAction = errors.New("no pending power action")
+	ErrActionPending   = errors.New("a power action is already pending")
+)
+
+// PendingAction is a reboot or poweroff waiting out power.graceperiod; Token
+// cancels it until ExecuteAt.
+type PendingAction struct {
+	Action    string    `json:"action"`
+	Token     string    `json:"token"`
+	ExecuteAt time.Time `json:"execute_at"`
+}
+
+type pendingAction struct {
+	PendingAction
+	timer *time.Timer
+}
+
+// request runs the action now without a grace period, else schedules it. A
+// single action may be pending: another request is an ErrActionPending.
+func (l *Login1Backend) request(action, method string) (*PendingAction, error) {
+	if l.grace <= 0 {
+		return nil, l.execute(action, method)
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	l.pendingMu.Lock()
+	defer l.pendingMu.Unlock()
+	if l.pending != nil {
+		return nil, ErrActionPending
+	}
+	p := &pendingAction{PendingAction: PendingAction{
+		Action:    action,
+		Token:     token,
+		ExecuteAt: time.Now().Add(l.grace),
+	}}
+	p.timer = time.AfterFunc(l.grace, func() { l.fire(p, method) })
+	l.pending = p
+	logger.Info("[login1] %s scheduled in %s", action, l.grace)
+
+	pending := p.PendingAction
+	return &pending, nil
+}
+
+// fire runs a pending action whose grace period ran out, unless it was
+// cancelled in the meantime.
+func (l *Login1Backend) fire(p *pendingAction, method string) {
+	l.pendingMu.Lock()
+	if l.pending != p {
+		l.pendingMu.Unlock()
+		return
+	}
+	l.pending = nil
+	l.pendingMu.Unlock()
+
+	if err := l.execute(p.Action, method); err != nil {
+		logger.Error("[login1] scheduled %s failed: %v", p.Action, err)
+	}
+}
+
+// Pending returns the action waiting out its grace period, if any.
+func (l *Login1Backend) Pending() (*PendingAction, bool) {
+	l.pendingMu.Lock()
+	defer l.pendingMu.Unlock()
+	if l.pending == nil {
+		return nil, false
+	}
+	pending := l.pending.PendingAction
+	return &pending, true
+}
+
+// CancelPending cancels the pending action token was issued for.
+func (l *Login1Backend) CancelPending(token string) error {
+	l.pendingMu.Lock()
+	defer l.pendingMu.Unlock()
+	if l.pending == nil || subtle.ConstantTimeCompare([]byte(token), []byte(l.pending.Token)) != 1 {
+		return ErrNoPendingAction
+	}
+	l.pending.timer.Stop()
+	logger.Info("[login1] pending %s cancelled", l.pending.Action)
+	l.pending = nil
+	return nil
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,115 @@
+package login1
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// graceBackend returns a backend with both actions allowed whose executed
+// actions are sent on the returned channel.
+func graceBackend(grace time.Duration) (*Login1Backend, chan string) {
+	ran := make(chan string, 4)
+	b := &Login1Backend{CanReboot: true, CanPoweroff: true, grace: grace}
+	b.execute = func(action, method string) error {
+		ran <- action
+		return nil
+	}
+	return b, ran
+}
+
+func TestRequest_NoGracePeriodRunsImmediately(t *testing.T) {
+	b, ran := graceBackend(0)
+
+	pending, err := b.RequestReboot()
+	if err != nil {
+		t.Fatalf("RequestReboot() error: %v", err)
+	}
+	if pending != nil {
+		t.Errorf("RequestReboot() = %+v, want nil without grace period", pending)
+	}
+	select {
+	case got := <-ran:
+		if got != "reboot" {
+			t.Errorf("executed %q, want reboot", got)
+		}
+	default:
+		t.Error("reboot was not executed")
+	}
+}
+
+func TestRequest_GracePeriodFires(t *testing.T) {
+	b, ran := graceBackend(10 * time.Millisecond)
+
+	pending, err := b.RequestPowerOff()
+	if err != nil {
+		t.Fatalf("RequestPowerOff() error: %v", err)
+	}
+	if pending == nil || pending.Action != "poweroff" || pending.Token == "" {
+		t.Fatalf("RequestPowerOff() = %+v, want a poweroff with a token", pending)
+	}
+	if got, ok := b.Pending(); !ok || got.Token != pending.Token {
+		t.Errorf("Pending() = %+v, %v, want the scheduled action", got, ok)
+	}
+
+	select {
+	case got := <-ran:
+		if got != "poweroff" {
+			t.Errorf("executed %q, want poweroff", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("poweroff was not executed after the grace period")
+	}
+	if _, ok := b.Pending(); ok {
+		t.Error("Pending() should be empty once the action ran")
+	}
+}
+
+func TestRequest_SecondRequestConflicts(t *testing.T) {
+	b, _ := graceBackend(time.Hour)
+	defer b.Close()
+
+	if _, err := b.RequestReboot(); err != nil {
+		t.Fatalf("RequestReboot() error: %v", err)
+	}
+	if _, err := b.RequestPowerOff(); !errors.Is(err, ErrActionPending) {
+		t.Errorf("second request error = %v, want ErrActionPending", err)
+	}
+}
+
+func TestCancelPending(t *testing.T) {
+	b, ran := graceBackend(20 * time.Millisecond)
+
+	pending, err := b.RequestReboot()
+	if err != nil {
+		t.Fatalf("RequestReboot() error: %v", err)
+	}
+	if err := b.CancelPending("wrong"); !errors.Is(err, ErrNoPendingAction) {
+		t.Errorf("CancelPending(wrong) = %v, want ErrNoPendingAction", err)
+	}
+	if err := b.CancelPending(pending.Token); err != nil {
+		t.Fatalf("CancelPending() error: %v", err)
+	}
+	if err := b.CancelPending(pending.Token); !errors.Is(err, ErrNoPendingAction) {
+		t.Errorf("second CancelPending() = %v, want ErrNoPendingAction", err)
+	}
+
+	select {
+	case got := <-ran:
+		t.Errorf("cancelled action %q was executed", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestRequest_CapabilityCheckedBeforeScheduling(t *testing.T) {
+	b, _ := graceBackend(time.Hour)
+	b.CanReboot = false
+
+	var capErr *CapabilityError
+	if _, err := b.RequestReboot(); !errors.As(err, &capErr) {
+		t.Errorf("RequestReboot() error = %v, want CapabilityError", err)
+	}
+	if _, ok := b.Pending(); ok {
+		t.Error("a disabled action must not be scheduled")
+	}
+}
@@ -27,13 +27,27 @@ func New(ctx context.Context, cfg *config.Login1Config) (*Login1Backend, error)
 		conn:    conn,
 		ctx:     ctx,
 		timeout: 10 * time.Second,
+		grace:   cfg.GracePeriod,
 		eventsC: make(chan events.Event, 4),
 	}
+	backend.execute = backend.callAction
 
-	if cfg.Capabilities != nil {
+	if cfg.AutoDetect {
+		if err := backend.detectCapabilities(cfg.Capabilities, backend.capabilityState); err != nil {
+			logger.Error("[login1] failed to detect capabilities: %v", err)
+			backend.Close()
+			return nil, err
+		}
+		if !backend.CanReboot && !backend.CanPoweroff {
+			logger.Warn("[login1] no capability allowed by logind, disabling backend")
+			backend.Close()
+			return nil, fmt.Errorf("%w: no capability allowed by logind", config.ErrBackendDisabled)
+		}
+	} else if cfg.Capabilities != nil {
 		if !cfg.Capabilities.CanReboot && !cfg.Capabilities.CanPoweroff {
 			logger.Warn("[login1] no capability enabled, disabling backend")
-			return nil, nil
+			backend.Close()
+			return nil, fmt.Errorf("%w: no capability enabled", config.ErrBackendDisabled)
 		}
 		if err := backend.validateCapabilities(*cfg.Capabilities); err != nil {
 			logger.Error("[login1] failed to validate capabilities: %v", err)
@@ -48,6 +62,13 @@ func New(ctx context.Context, cfg *config.Login1Config) (*Login1Backend, error)
 
 // Close cleanly closes connections and stops the listener
 func (l *Login1Backend) Close() {
+	l.pendingMu.Lock()
+	if l.pending != nil {
+		l.pending.timer.Stop()
+		l.pending = nil
+	}
+	l.pendingMu.Unlock()
+
 	if l.conn != nil {
 		if err := l.conn.Close(); err != nil {
 			logger.Error("Failed to close D-Bus connection: %v", err)
@@ -70,21 +91,37 @@ func (l *Login1Backend) notify(action string) {
 }
 
 func (l *Login1Backend) Reboot() error {
+	_, err := l.RequestReboot()
+	return err
+}
+
+func (l *Login1Backend) PowerOff() error {
+	_, err := l.RequestPowerOff()
+	return err
+}
+
+// RequestReboot reboots, after power.graceperiod when set: the returned
+// PendingAction then carries the token cancelling it, nil otherwise.
+func (l *Login1Backend) RequestReboot() (*PendingAction, error) {
 	if !l.CanReboot {
-		return &CapabilityError{Required: "reboot capability disabled"}
+		return nil, &CapabilityError{Required: "reboot capability disabled"}
 	}
-	logger.Info("[login1] Reboot requested")
-	l.notify("reboot")
-	return l.callMethod(LOGIN1_PREFIX, LOGIN1_METHOD_REBOOT, true)
+	return l.request("reboot", LOGIN1_METHOD_REBOOT)
 }
 
-func (l *Login1Backend) PowerOff() error {
+// RequestPowerOff is RequestReboot for poweroff.
+func (l *Login1Backend) RequestPowerOff() (*PendingAction, error) {
 	if !l.CanPoweroff {
-		return &CapabilityError{Required: "poweroff capability disabled"}
+		return nil, &CapabilityError{Required: "poweroff capability disabled"}
 	}
-	logger.Info("[login1] PowerOff requested")
-	l.notify("poweroff")
-	return l.callMethod(LOGIN1_PREFIX, LOGIN1_METHOD_POWEROFF, true)
+	return l.request("poweroff", LOGIN1_METHOD_POWEROFF)
+}
+
+// callAction announces the action and asks logind to run it.
+func (l *Login1Backend) callAction(action, method string) error {
+	logger.Info("[login1] %s requested", action)
+	l.notify(action)
+	return l.callMethod(LOGIN1_PREFIX, method, true)
 }
 
 func (l *Login1Backend) validateCapabilities(capabilities config.Login1Capabilities) error {
@@ -106,13 +143,95 @@ func (l *Login1Backend) validateCapabilities(capabilities config.Login1Capabilit
 	return nil
 }
 
-func (l *Login1Backend) checkCapability(method string) (bool, error) {
+// detectCapabilities enables each action logind allows, whatever the config
+// flags say: "yes" and "challenge" (polkit will ask) both count as allowed.
+// A flag set for an action logind refuses is only logged.
+func (l *Login1Backend) detectCapabilities(flags *config.Login1Capabilities, query func(string) (string, error)) error {
+	if flags == nil {
+		flags = &config.Login1Capabilities{}
+	}
+
+	reboot, err := detectCapability(LOGIN1_CAPABILITY_REBOOT, flags.CanReboot, query)
+	if err != nil {
+		return err
+	}
+	poweroff, err := detectCapability(LOGIN1_CAPABILITY_POWEROFF, flags.CanPoweroff, query)
+	if err != nil {
+		return err
+	}
+
+	l.CanReboot = reboot
+	l.CanPoweroff = poweroff
+	return nil
+}
+
+func detectCapability(method string, configured bool, query func(string) (string, error)) (bool, error) {
+	state, err := query(method)
+	if err != nil {
+		return false, fmt.Errorf("%s check failed: %w", method, err)
+	}
+
+	allowed := capabilityAllowed(state)
+	logger.Info("[login1] %s reports %q (configured: %v, enabled: %v)", method, state, configured, allowed)
+	if configured && !allowed {
+		logger.Warn("[login1] %s is configured but logind does not allow it", method)
+	}
+	return allowed, nil
+}
+
+// QueryCapabilities asks logind on demand whether each action is currently
+// allowed, whatever was enabled at startup.
+func (l *Login1Backend) QueryCapabilities() (reboot, poweroff bool, err error) {
+	return queryCapabilities(l.capabilityState)
+}
+
+func queryCapabilities(query func(string) (string, error)) (reboot, poweroff bool, err error) {
+	state, err := query(LOGIN1_CAPABILITY_REBOOT)
+	if err != nil {
+		return false, false, fmt.Errorf("%s check failed: %w", LOGIN1_CAPABILITY_REBOOT, err)
+	}
+	reboot = capabilityAllowed(state)
+
+	if state, err = query(LOGIN1_CAPABILITY_POWEROFF); err != nil {
+		return false, false, fmt.Errorf("%s check failed: %w", LOGIN1_CAPABILITY_POWEROFF, err)
+	}
+	return reboot, capabilityAllowed(state), nil
+}
+
+// Capabilities reports the enabled actions along with logind's live answer.
+// A failed query only leaves Live out: the enabled flags are still valid.
+func (l *Login1Backend) Capabilities() PowerCapabilities {
+	return l.capabilities(l.QueryCapabilities)
+}
+
+func (l *Login1Backend) capabilities(query func() (bool, bool, error)) PowerCapabilities {
+	caps := PowerCapabilities{Reboot: l.CanReboot, PowerOff: l.CanPoweroff}
+	reboot, poweroff, err := query()
+	if err != nil {
+		logger.Warn("[login1] live capability query failed: %v", err)
+		caps.LiveError = err.Error()
+		return caps
+	}
+	caps.Live = &LiveCapabilities{Reboot: reboot, PowerOff: poweroff}
+	return caps
+}
+
+// capabilityAllowed reports whether a logind Can* answer permits the action.
+func capabilityAllowed(state string) bool {
+	return state == "yes" || state == "challenge"
+}
+
+// capabilityState returns the raw logind answer: "yes", "no", "challenge" or "na".
+func (l *Login1Backend) capabilityState(method string) (string, error) {
 	call, err := l.callDBusMethod(method)
 	if err != nil {
-		return false, err
+		return "", err
 	}
+	return extractString(call)
+}
 
-	result, err := extractString(call)
+func (l *Login1Backend) checkCapability(method string) (bool, error) {
+	result, err := l.capabilityState(method)
 	if err != nil {
 		return false, err
 	}
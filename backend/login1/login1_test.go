@@ -344,3 +344,94 @@ func TestLogin1Backend_PowerOffError_IsCapabilityError(t *testing.T) {
 		t.Errorf("CapabilityError.Required = %q, want %q", capErr.Required, "poweroff capability disabled")
 	}
 }
+
+// --- Tests pour detectCapabilities() ---
+
+func TestDetectCapabilities(t *testing.T) {
+	tests := []struct {
+		name         string
+		states       map[string]string
+		flags        *config.Login1Capabilities
+		wantReboot   bool
+		wantPoweroff bool
+	}{
+		{
+			name:         "yes enables without flags",
+			states:       map[string]string{LOGIN1_CAPABILITY_REBOOT: "yes", LOGIN1_CAPABILITY_POWEROFF: "yes"},
+			flags:        &config.Login1Capabilities{},
+			wantReboot:   true,
+			wantPoweroff: true,
+		},
+		{
+			name:         "challenge counts as allowed",
+			states:       map[string]string{LOGIN1_CAPABILITY_REBOOT: "challenge", LOGIN1_CAPABILITY_POWEROFF: "no"},
+			flags:        nil,
+			wantReboot:   true,
+			wantPoweroff: false,
+		},
+		{
+			name:         "flag does not override a refusal",
+			states:       map[string]string{LOGIN1_CAPABILITY_REBOOT: "na", LOGIN1_CAPABILITY_POWEROFF: "no"},
+			flags:        &config.Login1Capabilities{CanReboot: true, CanPoweroff: true},
+			wantReboot:   false,
+			wantPoweroff: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &Login1Backend{}
+			query := func(method string) (string, error) { return tt.states[method], nil }
+			if err := b.detectCapabilities(tt.flags, query); err != nil {
+				t.Fatalf("detectCapabilities() error = %v", err)
+			}
+			if b.CanReboot != tt.wantReboot {
+				t.Errorf("CanReboot = %v, want %v", b.CanReboot, tt.wantReboot)
+			}
+			if b.CanPoweroff != tt.wantPoweroff {
+				t.Errorf("CanPoweroff = %v, want %v", b.CanPoweroff, tt.wantPoweroff)
+			}
+		})
+	}
+}
+
+func TestDetectCapabilities_QueryError(t *testing.T) {
+	b := &Login1Backend{}
+	query := func(string) (string, error) { return "", &dbusTimeoutError{} }
+	if err := b.detectCapabilities(nil, query); err == nil {
+		t.Fatal("detectCapabilities() should fail when logind cannot be queried")
+	}
+	if b.CanReboot || b.CanPoweroff {
+		t.Error("no capability should be enabled after a failed query")
+	}
+}
+
+func TestQueryCapabilities(t *testing.T) {
+	states := map[string]string{LOGIN1_CAPABILITY_REBOOT: "challenge", LOGIN1_CAPABILITY_POWEROFF: "no"}
+	reboot, poweroff, err := queryCapabilities(func(method string) (string, error) { return states[method], nil })
+	if err != nil {
+		t.Fatalf("queryCapabilities() error = %v", err)
+	}
+	if !reboot || poweroff {
+		t.Errorf("queryCapabilities() = %v, %v; want true, false", reboot, poweroff)
+	}
+
+	if _, _, err := queryCapabilities(func(string) (string, error) { return "", &dbusTimeoutError{} }); err == nil {
+		t.Error("queryCapabilities() should fail when logind cannot be queried")
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	b := &Login1Backend{CanReboot: true, CanPoweroff: true}
+
+	caps := b.capabilities(func() (bool, bool, error) { return true, false, nil })
+	want := LiveCapabilities{Reboot: true, PowerOff: false}
+	if !caps.Reboot || !caps.PowerOff || caps.Live == nil || *caps.Live != want || caps.LiveError != "" {
+		t.Errorf("capabilities() = %+v, want both enabled with live %+v", caps, want)
+	}
+
+	caps = b.capabilities(func() (bool, bool, error) { return false, false, &dbusTimeoutError{} })
+	if !caps.Reboot || !caps.PowerOff || caps.Live != nil || caps.LiveError == "" {
+		t.Errorf("capabilities() after a failed query = %+v, want enabled flags, no live, an error", caps)
+	}
+}
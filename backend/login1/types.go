@@ -2,6 +2,7 @@ package login1
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/godbus/dbus/v5"
@@ -18,9 +19,32 @@ type Login1Backend struct {
 	CanReboot   bool
 	CanPoweroff bool
 
+	// power.graceperiod: requested actions wait this long, cancellable.
+	grace     time.Duration
+	pending   *pendingAction
+	pendingMu sync.Mutex
+	// execute runs an action on logind; replaced in tests.
+	execute func(action, method string) error
+
 	eventsC chan events.Event
 }
 
+// PowerCapabilities is the GET /power payload: the actions enabled at
+// startup, and what logind answers right now since polkit policy may have
+// changed since. Live is nil when the query failed, LiveError then says why.
+type PowerCapabilities struct {
+	Reboot    bool              `json:"reboot"`
+	PowerOff  bool              `json:"power_off"`
+	Live      *LiveCapabilities `json:"live,omitempty"`
+	LiveError string            `json:"live_error,omitempty"`
+}
+
+// LiveCapabilities is logind's current CanReboot/CanPowerOff answer.
+type LiveCapabilities struct {
+	Reboot   bool `json:"reboot"`
+	PowerOff bool `json:"power_off"`
+}
+
 // PowerActionData is the payload of a power.action event.
 type PowerActionData struct {
 	Action string `json:"action"`
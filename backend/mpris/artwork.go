@@ -0,0 +1,154 @@
+package mpris
+
+import (
+	"container/list"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/b0bbywan/go-odio-api/logger"
+)
+
+const (
+	// maxArtworkEntries bounds the artwork cache; the least recently used
+	// cover is evicted first.
+	maxArtworkEntries = 16
+	// maxArtworkBytes skips files too large to be worth holding in memory.
+	maxArtworkBytes = 8 << 20
+)
+
+// artworkKey identifies one version of a cover file: players rewriting a
+// fixed path (e.g. /tmp/cover.jpg) change its mtime or size, never its URL.
+type artworkKey struct {
+	path    string
+	modTime int64 // UnixNano
+	size    int64
+}
+
+type artworkEntry struct {
+	key  artworkKey
+	data []byte
+}
+
+// artworkCache is an LRU of cover bytes keyed by artworkKey.
+type artworkCache struct {
+	mu      sync.Mutex
+	max     int
+	order   *list.List // of *artworkEntry, most recently used first
+	entries map[artworkKey]*list.Element
+}
+
+func newArtworkCache(max int) *artworkCache {
+	return &artworkCache{max: max, order: list.New(), entries: make(map[artworkKey]*list.Element)}
+}
+
+func (c *artworkCache) get(key artworkKey) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*artworkEntry).data, true
+}
+
+func (c *artworkCache) set(key artworkKey, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*artworkEntry).data = data
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&artworkEntry{key: key, data: data})
+	for c.order.Len() > c.max {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*artworkEntry).key)
+	}
+}
+
+// statArtwork returns the cache key of the file at path as it is now.
+func statArtwork(path string) (artworkKey, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return artworkKey{}, err
+	}
+	return artworkKey{path: path, modTime: info.ModTime().UnixNano(), size: info.Size()}, nil
+}
+
+// ArtworkPath returns the filesystem path of a file:// artUrl. Standards-
+// compliant MPRIS daemons percent-encode reserved characters in file:// URIs
+// (RFC 3986), so the URL is parsed to recover the decoded path.
+func ArtworkPath(artURL string) (string, bool) {
+	if !strings.HasPrefix(artURL, "file://") {
+		return "", false
+	}
+	u, err := url.Parse(artURL)
+	if err != nil || u.Path == "" {
+		return "", false
+	}
+	return u.Path, true
+}
+
+// Artwork returns the prefetched bytes of a file:// artUrl, if still cached
+// for the file as it is on disk now.
+func (m *MPRISBackend) Artwork(artURL string) ([]byte, bool) {
+	if m.artworkCache == nil {
+		return nil, false
+	}
+	path, ok := ArtworkPath(artURL)
+	if !ok {
+		return nil, false
+	}
+	key, err := statArtwork(path)
+	if err != nil {
+		return nil, false
+	}
+	return m.artworkCache.get(key)
+}
+
+// prefetchArtwork reads local artwork into the artwork cache on a track
+// change, so the cover request a UI sends right after is served from memory.
+// Failures are only logged: the cover endpoint falls back to the file.
+func (m *MPRISBackend) prefetchArtwork(artURL string) {
+	path, ok := ArtworkPath(artURL)
+	if !ok {
+		return
+	}
+	key, err := statArtwork(path)
+	if err != nil {
+		logger.Debug("[mpris] artwork prefetch skipped for %s: %v", path, err)
+		return
+	}
+	if _, ok := m.artworkCache.get(key); ok {
+		return
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		logger.Debug("[mpris] artwork prefetch skipped for %s: %v", path, err)
+		return
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			logger.Debug("[mpris] failed to close artwork %s: %v", path, err)
+		}
+	}()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxArtworkBytes+1))
+	if err != nil {
+		logger.Debug("[mpris] artwork prefetch failed for %s: %v", path, err)
+		return
+	}
+	if len(data) > maxArtworkBytes {
+		logger.Debug("[mpris] artwork %s larger than %d bytes, not cached", path, maxArtworkBytes)
+		return
+	}
+
+	m.artworkCache.set(key, data)
+	logger.Debug("[mpris] prefetched artwork %s (%d bytes)", path, len(data))
+}
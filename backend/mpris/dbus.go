@@ -1,6 +1,7 @@
 package mpris
 
 import (
+	"errors"
 	"strings"
 	"time"
 
@@ -38,26 +39,33 @@ func callWithTimeout(call *dbus.Call, timeout time.Duration) error {
 	}
 }
 
-// callWithTimeout receiver method for MPRISBackend
+// callWithTimeout receiver method for MPRISBackend, bounded by
+// mpris.timeouts.default
 func (m *MPRISBackend) callWithTimeout(call *dbus.Call) error {
-	return callWithTimeout(call, m.timeout)
+	return callWithTimeout(call, m.timeouts.Default)
 }
 
 // callMethod calls an MPRIS method on a player with timeout
 func (m *MPRISBackend) callMethod(busName, method string, args ...interface{}) error {
-	obj := m.conn.Object(busName, MPRIS_PATH)
-	return m.callWithTimeout(obj.Call(method, 0, args...))
+	obj := m.busConn().Object(busName, MPRIS_PATH)
+	return callWithTimeout(obj.Call(method, 0, args...), m.timeouts.Control)
 }
 
 // setProperty sets a property on a player
 func (m *MPRISBackend) setProperty(busName, property string, value interface{}) error {
-	obj := m.conn.Object(busName, MPRIS_PATH)
-	return m.callWithTimeout(obj.Call(DBUS_PROP_SET, 0, MPRIS_PLAYER_IFACE, property, dbus.MakeVariant(value)))
+	return m.setIfaceProperty(busName, MPRIS_PLAYER_IFACE, property, value)
+}
+
+// setIfaceProperty sets a property on another MPRIS interface, e.g. the root
+// interface's Fullscreen.
+func (m *MPRISBackend) setIfaceProperty(busName, iface, property string, value interface{}) error {
+	obj := m.busConn().Object(busName, MPRIS_PATH)
+	return callWithTimeout(obj.Call(DBUS_PROP_SET, 0, iface, property, dbus.MakeVariant(value)), m.timeouts.Control)
 }
 
 // getProperty retrieves a property from D-Bus for a given busName
 func (m *MPRISBackend) getProperty(busName, iface, prop string) (dbus.Variant, error) {
-	obj := m.conn.Object(busName, MPRIS_PATH)
+	obj := m.busConn().Object(busName, MPRIS_PATH)
 	var v dbus.Variant
 	call := obj.Call(DBUS_PROP_GET, 0, iface, prop)
 	if err := m.callWithTimeout(call); err != nil {
@@ -72,8 +80,8 @@ func (m *MPRISBackend) getProperty(busName, iface, prop string) (dbus.Variant, e
 // listDBusNames retrieves the list of all bus names on D-Bus
 func (m *MPRISBackend) listDBusNames() ([]string, error) {
 	var names []string
-	call := m.conn.BusObject().Call(DBUS_LIST_NAMES_METHOD, 0)
-	if err := m.callWithTimeout(call); err != nil {
+	call := m.busConn().BusObject().Call(DBUS_LIST_NAMES_METHOD, 0)
+	if err := callWithTimeout(call, m.timeouts.List); err != nil {
 		return nil, err
 	}
 	if err := call.Store(&names); err != nil {
@@ -84,7 +92,7 @@ func (m *MPRISBackend) listDBusNames() ([]string, error) {
 
 // addMatchRule subscribes to a D-Bus signal via a match rule
 func (m *MPRISBackend) addMatchRule(rule string) error {
-	call := m.conn.BusObject().Call(DBUS_ADD_MATCH_METHOD, 0, rule)
+	call := m.busConn().BusObject().Call(DBUS_ADD_MATCH_METHOD, 0, rule)
 	return m.callWithTimeout(call)
 }
 
@@ -114,7 +122,7 @@ func (m *MPRISBackend) addListenMatchRules() error {
 
 func (m *MPRISBackend) getNameOwner(busName string) (string, error) {
 	var owner string
-	call := m.conn.BusObject().Call(DBUS_GET_NAME_OWNER, 0, busName)
+	call := m.busConn().BusObject().Call(DBUS_GET_NAME_OWNER, 0, busName)
 	if err := m.callWithTimeout(call); err != nil {
 		return "", err
 	}
@@ -124,6 +132,16 @@ func (m *MPRISBackend) getNameOwner(busName string) (string, error) {
 	return owner, nil
 }
 
+// isNoOwner reports whether err is D-Bus saying nothing owns the bus name.
+func isNoOwner(err error) bool {
+	var dbusErr dbus.Error
+	if !errors.As(err, &dbusErr) {
+		return false
+	}
+	return dbusErr.Name == "org.freedesktop.DBus.Error.NameHasNoOwner" ||
+		dbusErr.Name == "org.freedesktop.DBus.Error.ServiceUnknown"
+}
+
 // arg extracts sig.Body[i] as T, false if absent or mistyped.
 func arg[T any](sig *dbus.Signal, i int) (T, bool) {
 	if i >= len(sig.Body) {
@@ -141,7 +159,8 @@ func extract[T any](v dbus.Variant) (T, bool) {
 	return val, ok
 }
 
-// callWithTimeout receiver method for Player
+// callWithTimeout receiver method for Player, bounded by mpris.timeouts.list
+// since player calls load properties and tracks
 func (p *Player) callWithTimeout(call *dbus.Call) error {
 	return callWithTimeout(call, p.timeout)
 }
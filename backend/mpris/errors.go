@@ -1,5 +1,10 @@
 package mpris
 
+import (
+	"strings"
+	"time"
+)
+
 // CapabilityError indicates that an action is not supported by the player
 type CapabilityError struct {
 	Required string
@@ -18,6 +23,27 @@ func (e *PlayerNotFoundError) Error() string {
 	return "player not found: " + e.BusName
 }
 
+// RefreshRateLimitedError indicates that a player was refreshed too recently
+type RefreshRateLimitedError struct {
+	BusName    string
+	RetryAfter time.Duration
+}
+
+func (e *RefreshRateLimitedError) Error() string {
+	return "player " + e.BusName + " refreshed too recently, retry in " + e.RetryAfter.Round(time.Second).String()
+}
+
+// AmbiguousPlayerError indicates that a short player name matches several
+// player instances
+type AmbiguousPlayerError struct {
+	Name       string
+	Candidates []string
+}
+
+func (e *AmbiguousPlayerError) Error() string {
+	return "ambiguous player name " + e.Name + ", candidates: " + strings.Join(e.Candidates, ", ")
+}
+
 // InvalidBusNameError indicates that a busName is invalid
 type InvalidBusNameError struct {
 	BusName string
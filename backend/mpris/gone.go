@@ -0,0 +1,45 @@
+package mpris
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// goneTTL bounds how long a removed player stays listed as a tombstone.
+const goneTTL = time.Hour
+
+// bury keeps a removed player as a tombstone, flagged Gone with the LastSeen
+// it had when it left the bus. Expired tombstones are purged on the way, so
+// players coming and going with ever new bus names do not pile up.
+func (m *MPRISBackend) bury(p Player) {
+	if m.gone == nil {
+		return
+	}
+	m.gone.CleanExpired()
+	p.Gone = true
+	m.gone.Set(p.BusName, p)
+}
+
+// unbury drops the tombstone of a player that came back.
+func (m *MPRISBackend) unbury(busName string) {
+	if m.gone != nil {
+		m.gone.Delete(busName)
+	}
+}
+
+// GonePlayers returns the players removed within goneTTL, sorted by bus name.
+// A bus name back in the cache (e.g. after a full reload) is not listed.
+func (m *MPRISBackend) GonePlayers() []Player {
+	if m.gone == nil {
+		return []Player{}
+	}
+	live := m.players.Load()
+	players := slices.DeleteFunc(m.gone.Values(), func(p Player) bool {
+		return slices.ContainsFunc(live, func(l Player) bool { return l.BusName == p.BusName })
+	})
+	slices.SortFunc(players, func(a, b Player) int {
+		return strings.Compare(a.BusName, b.BusName)
+	})
+	return players
+}
@@ -9,15 +9,17 @@ import (
 )
 
 // Heartbeat manages periodic position updates for playing players.
-// It starts automatically when at least one player is Playing and stops
-// automatically when no player is Playing anymore.
+// It runs while at least one player is Playing: the listener (re)starts it on
+// every transition into Playing and stops it once nothing plays anymore, and
+// the loop itself stops when a tick finds no Playing player.
 type Heartbeat struct {
 	backend *MPRISBackend
 	ctx     context.Context
 	cancel  context.CancelFunc
 
-	mu     sync.Mutex
-	active bool
+	mu      sync.Mutex
+	stopRun context.CancelFunc // non-nil while a run is active
+	runID   uint64             // identifies the current run, see run
 }
 
 // NewHeartbeat creates a new heartbeat manager
@@ -27,7 +29,6 @@ func NewHeartbeat(backend *MPRISBackend) *Heartbeat {
 		backend: backend,
 		ctx:     ctx,
 		cancel:  cancel,
-		active:  false,
 	}
 }
 
@@ -37,31 +38,61 @@ func (h *Heartbeat) Start() {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	if h.active {
-		return // Already active
+	if h.stopRun != nil || h.ctx.Err() != nil {
+		return // Already active, or stopped for good
 	}
 
-	h.active = true
-	go h.run()
+	runCtx, cancel := context.WithCancel(h.ctx)
+	h.stopRun = cancel
+	h.runID++
+	go h.run(runCtx, h.runID)
 }
 
-// Stop stops the heartbeat
+// Sync starts the heartbeat when a player is Playing and stops it otherwise,
+// from the cached playback states.
+func (h *Heartbeat) Sync() {
+	if h.backend.hasPlaying() {
+		h.Start()
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.stopRunLocked()
+}
+
+// Stop stops the heartbeat for good; Start is a no-op afterwards.
 func (h *Heartbeat) Stop() {
 	h.cancel()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.stopRunLocked()
 }
 
 // IsRunning returns true if the heartbeat is active
 func (h *Heartbeat) IsRunning() bool {
 	h.mu.Lock()
 	defer h.mu.Unlock()
-	return h.active
+	return h.stopRun != nil
+}
+
+// stopRunLocked cancels the current run, if any. Caller holds mu.
+func (h *Heartbeat) stopRunLocked() {
+	if h.stopRun != nil {
+		h.stopRun()
+		h.stopRun = nil
+	}
 }
 
-// run is the main heartbeat loop
-func (h *Heartbeat) run() {
+// run is the main heartbeat loop. On exit it only clears the state if it is
+// still the current run: a Sync may already have stopped it and a Start
+// launched a newer one.
+func (h *Heartbeat) run(ctx context.Context, id uint64) {
 	defer func() {
 		h.mu.Lock()
-		h.active = false
+		if h.runID == id {
+			h.stopRunLocked()
+		}
 		h.mu.Unlock()
 		logger.Debug("[mpris] position heartbeat stopped")
 	}()
@@ -73,7 +104,7 @@ func (h *Heartbeat) run() {
 
 	for {
 		select {
-		case <-h.ctx.Done():
+		case <-ctx.Done():
 			return
 		case <-ticker.C:
 			hasPlaying := h.updatePlayingPositions()
@@ -25,7 +25,7 @@ func NewListener(backend *MPRISBackend) *Listener {
 // Start starts listening to MPRIS D-Bus signals
 func (l *Listener) Start() error {
 	// Use the backend connection
-	conn := l.backend.conn
+	conn := l.backend.busConn()
 
 	if err := l.backend.addListenMatchRules(); err != nil {
 		return err
@@ -118,11 +118,27 @@ func (l *Listener) handlePropertiesChanged(sig *dbus.Signal) {
 		}
 		return
 	}
+	if iface == MPRIS_INTERFACE {
+		// Only the fullscreen state is tracked from the root interface.
+		rootChanged := make(map[string]dbus.Variant)
+		for _, key := range []string{"Fullscreen", "CanSetFullscreen"} {
+			if v, ok := changed[key]; ok {
+				rootChanged[key] = v
+			}
+		}
+		if len(rootChanged) > 0 {
+			if err := l.backend.UpdatePlayerProperties(busName, rootChanged); err != nil {
+				logger.Error("[mpris] failed to update fullscreen state for %s: %v", busName, err)
+			}
+		}
+		return
+	}
 	if iface != MPRIS_PLAYER_IFACE {
 		return
 	}
 
 	// Check if PlaybackStatus changed for deduplication
+	statusChanged := false
 	if statusVar, hasStatus := changed["PlaybackStatus"]; hasStatus {
 		if status, ok := extract[string](statusVar); ok {
 			newStatus := PlaybackStatus(status)
@@ -150,10 +166,7 @@ func (l *Listener) handlePropertiesChanged(sig *dbus.Signal) {
 					}
 				}
 
-				// If player switches to Playing, ensure heartbeat is running
-				if newStatus == StatusPlaying {
-					l.backend.heartbeat.Start()
-				}
+				statusChanged = true
 			}
 		}
 	}
@@ -169,6 +182,12 @@ func (l *Listener) handlePropertiesChanged(sig *dbus.Signal) {
 	if err := l.backend.UpdatePlayerProperties(busName, changed); err != nil {
 		logger.Error("[mpris] failed to update player %s properties: %v", busName, err)
 	}
+
+	// Evaluated once the cache holds the new status: a player switching to
+	// Playing (re)starts the heartbeat, the last one leaving it stops it.
+	if statusChanged {
+		l.backend.syncHeartbeat()
+	}
 }
 
 // handleNameOwnerChanged detects when a player appears or disappears
@@ -201,7 +220,12 @@ func (l *Listener) handleNameOwnerChanged(sig *dbus.Signal) {
 		if err := l.backend.RemovePlayer(busName); err != nil {
 			logger.Error("[mpris] failed to remove player %s: %v", busName, err)
 		}
+	} else {
+		return
 	}
+
+	// A player may appear already Playing, or the last Playing one may leave.
+	l.backend.syncHeartbeat()
 }
 
 // resolveSender maps a signal's unique-name sender to a cached busName.
@@ -221,7 +245,7 @@ func (l *Listener) refreshTracklist(busName string, ids []dbus.ObjectPath) {
 	tracks := tracksFromIDs(ids)
 	if len(ids) > 0 {
 		if metas, err := newPlayer(l.backend, busName).getTracksMetadata(ids); err == nil {
-			tracks = tracksFromMetadata(ids, metas)
+			tracks = tracksFromMetadata(ids, metas, l.backend.metadataKeyList())
 		} else {
 			logger.Debug("[mpris] GetTracksMetadata failed for %s, keeping IDs only: %v", busName, err)
 		}
@@ -287,7 +311,7 @@ func (l *Listener) handleTrackAdded(busName string, sig *dbus.Signal) {
 		return
 	}
 
-	if err := l.backend.AddTrackToCache(busName, trackFromSignalMetadata(meta), string(afterTrack)); err != nil {
+	if err := l.backend.AddTrackToCache(busName, trackFromSignalMetadata(meta, l.backend.metadataKeyList()), string(afterTrack)); err != nil {
 		logger.Error("[mpris] failed to add track for %s: %v", busName, err)
 	}
 }
@@ -317,7 +341,7 @@ func (l *Listener) handleTrackMetadataChanged(busName string, sig *dbus.Signal)
 		return
 	}
 
-	if err := l.backend.UpdateTrackMetadataInCache(busName, string(trackID), trackFromSignalMetadata(meta)); err != nil {
+	if err := l.backend.UpdateTrackMetadataInCache(busName, string(trackID), trackFromSignalMetadata(meta, l.backend.metadataKeyList())); err != nil {
 		logger.Error("[mpris] failed to update track metadata for %s: %v", busName, err)
 	}
 }
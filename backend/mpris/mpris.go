@@ -2,34 +2,105 @@ package mpris
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/godbus/dbus/v5"
 
+	"github.com/b0bbywan/go-odio-api/cache"
 	"github.com/b0bbywan/go-odio-api/config"
 	"github.com/b0bbywan/go-odio-api/events"
 	"github.com/b0bbywan/go-odio-api/logger"
 )
 
-// New creates a new MPRIS backend
-func New(ctx context.Context, cfg *config.MPRISConfig) (*MPRISBackend, error) {
+// New creates a new MPRIS backend. ctx bounds its lifetime, startCtx the
+// session bus dial.
+func New(ctx, startCtx context.Context, cfg *config.MPRISConfig) (*MPRISBackend, error) {
 	if cfg == nil || !cfg.Enabled {
 		return nil, nil
 	}
 
-	conn, err := dbus.ConnectSessionBus()
+	allowed, err := allowedActions(cfg.AllowedActions)
 	if err != nil {
 		return nil, err
 	}
 
-	return &MPRISBackend{
-		conn:    conn,
-		ctx:     ctx,
-		timeout: cfg.Timeout,
-		events:  make(chan events.Event, 64),
-	}, nil
+	conn, err := dialBus(startCtx, dbus.ConnectSessionBus)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &MPRISBackend{
+		conn:         conn,
+		connect:      dbus.ConnectSessionBus,
+		ctx:          ctx,
+		timeouts:     cfg.Timeouts,
+		events:       make(chan events.Event, 64),
+		priorityFile: cfg.PriorityFile,
+
+		allowedActions:      allowed,
+		metadataKeys:        metadataKeys(cfg.MetadataKeys),
+		metadataPassthrough: cfg.MetadataPassthrough,
+		artworkCache:        newArtworkCache(maxArtworkEntries),
+		gone:                cache.New[Player](goneTTL),
+	}
+	m.loadPriority()
+	return m, nil
+}
+
+// metadataKeyList returns the metadata keys to keep, nil for all of them with
+// mpris.metadata_passthrough; backends built without New (tests) get the
+// defaults.
+func (m *MPRISBackend) metadataKeyList() []string {
+	if m != nil && m.metadataPassthrough {
+		return nil
+	}
+	if m == nil || m.metadataKeys == nil {
+		return defaultMetadataKeys
+	}
+	return m.metadataKeys
+}
+
+// allowedActions validates mpris.allowedactions into a set; an empty list
+// yields nil, which allows every action.
+func allowedActions(names []string) (map[string]bool, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	allowed := make(map[string]bool, len(names))
+	for _, name := range names {
+		if !slices.Contains(SupportedActions, name) {
+			return nil, fmt.Errorf("mpris: unknown allowed action %q", name)
+		}
+		allowed[name] = true
+	}
+	return allowed, nil
+}
+
+// ActionAllowed reports whether the API may trigger the control action. This
+// is a deployment policy on top of the player's own capabilities.
+func (m *MPRISBackend) ActionAllowed(action string) bool {
+	return m.allowedActions == nil || m.allowedActions[action]
+}
+
+// ImportPlayers replaces the player cache with players, e.g. loaded from a
+// debug export, without asking D-Bus. The players are bound to this backend
+// so their controls still target the real bus names; the position heartbeat
+// is left alone so nothing polls the imported players.
+func (m *MPRISBackend) ImportPlayers(players []Player) {
+	imported := make([]Player, len(players))
+	for i, p := range players {
+		p.backend, p.conn, p.timeout = m, m.busConn(), m.timeouts.List
+		imported[i] = p
+	}
+
+	m.playersMu.Lock()
+	defer m.playersMu.Unlock()
+	m.players.Store(imported)
 }
 
 // updatePlayers hands fn a private copy of the cached players and stores fn's
@@ -58,27 +129,49 @@ func (m *MPRISBackend) updatePlayers(fn func([]Player) []Player) bool {
 // Start loads the initial cache and starts the listener
 func (m *MPRISBackend) Start() error {
 	logger.Debug("[mpris] starting backend")
+	if err := m.start(); err != nil {
+		return err
+	}
+
+	m.startWatch()
+
+	logger.Info("[mpris] backend started successfully")
+	return nil
+}
 
+// start loads the player cache and starts the heartbeat and the listener on
+// the current connection. Shared by Start and Reconnect.
+func (m *MPRISBackend) start() error {
 	// Load cache at startup
 	_, err := m.ListPlayers()
 	if err != nil {
 		return err
 	}
 
+	// The heartbeat must exist before the listener: status signals drive it.
+	heartbeat := NewHeartbeat(m)
+	m.heartbeat.Store(heartbeat)
+
 	// Start the listener for MPRIS changes
-	m.listener = NewListener(m)
-	if err := m.listener.Start(); err != nil {
+	listener := NewListener(m)
+	m.listener.Store(listener)
+	if err := listener.Start(); err != nil {
 		return err
 	}
 
-	// Start the heartbeat (will auto-stop if no player is Playing)
-	m.heartbeat = NewHeartbeat(m)
-	m.heartbeat.Start()
-
-	logger.Info("[mpris] backend started successfully")
+	// Start the heartbeat if a player is already Playing
+	heartbeat.Sync()
 	return nil
 }
 
+// syncHeartbeat syncs the current heartbeat with the playback states, if one
+// is set.
+func (m *MPRISBackend) syncHeartbeat() {
+	if h := m.heartbeat.Load(); h != nil {
+		h.Sync()
+	}
+}
+
 // ListPlayers lists all available MPRIS players.
 // This function uses the cache as priority. If the cache is empty,
 // it performs a D-Bus call to list players and updates the cache.
@@ -87,11 +180,39 @@ func (m *MPRISBackend) ListPlayers() ([]Player, error) {
 	// Check cache first
 	if players := m.players.Load(); players != nil {
 		logger.Debug("[mpris] returning %d players from cache", len(players))
-		return players, nil
+		return m.sortByPriority(players), nil
 	}
 
 	// Cache miss, load from D-Bus
 	logger.Debug("[mpris] cache miss, loading players")
+	players, err := m.loadPlayers()
+	if err != nil {
+		return nil, err
+	}
+	m.players.Store(players)
+
+	return m.sortByPriority(players), nil
+}
+
+// ReloadPlayers rebuilds the player cache from D-Bus. It holds playersMu
+// throughout, unlike InvalidateCache then ListPlayers: a listener update
+// cannot land between the reset and the reload and be overwritten, it waits
+// and applies on top of the fresh list. On error the cache is kept.
+func (m *MPRISBackend) ReloadPlayers() ([]Player, error) {
+	m.playersMu.Lock()
+	defer m.playersMu.Unlock()
+
+	players, err := m.loadPlayers()
+	if err != nil {
+		return nil, err
+	}
+	m.players.Store(players)
+	return m.sortByPriority(players), nil
+}
+
+// loadPlayers reads every MPRIS player from D-Bus, skipping those failing to
+// answer.
+func (m *MPRISBackend) loadPlayers() ([]Player, error) {
 	start := time.Now()
 
 	// List all bus names
@@ -115,11 +236,38 @@ func (m *MPRISBackend) ListPlayers() ([]Player, error) {
 
 	elapsed := time.Since(start)
 	logger.Debug("[mpris] loaded %d players in %s", len(players), elapsed)
+	return players, nil
+}
 
-	// Update cache
-	m.players.Store(players)
+// ActivePlayer picks the player a context-free control (e.g. a hardware
+// button) should drive: the first Playing player, else the first Paused one,
+// else the first listed. ListPlayers' priority order breaks ties.
+func (m *MPRISBackend) ActivePlayer() (string, error) {
+	players, err := m.ListPlayers()
+	if err != nil {
+		return "", err
+	}
+	if len(players) == 0 {
+		return "", &PlayerNotFoundError{}
+	}
+	for _, status := range []PlaybackStatus{StatusPlaying, StatusPaused} {
+		for _, p := range players {
+			if p.PlaybackStatus == status {
+				return p.BusName, nil
+			}
+		}
+	}
+	return players[0].BusName, nil
+}
 
-	return players, nil
+// hasPlaying reports whether any cached player is Playing.
+func (m *MPRISBackend) hasPlaying() bool {
+	for _, p := range m.players.Load() {
+		if p.PlaybackStatus == StatusPlaying {
+			return true
+		}
+	}
+	return false
 }
 
 // GetPlayerFromCache retrieves a specific player from cache only.
@@ -143,10 +291,88 @@ func (m *MPRISBackend) GetPlayerFromCache(busName string) (*Player, error) {
 	return nil, &PlayerNotFoundError{BusName: busName}
 }
 
+// ResolveBusName maps a short player name, the bus name without its
+// org.mpris.MediaPlayer2. prefix (e.g. "spotify"), to the full bus name of a
+// cached player; full bus names are returned unchanged. A name matching no
+// player exactly but several instances ("vlc" for vlc.instance1234 and
+// vlc.instance5678) is an AmbiguousPlayerError listing them.
+func (m *MPRISBackend) ResolveBusName(name string) (string, error) {
+	if name == "" || strings.HasPrefix(name, MPRIS_PREFIX+".") {
+		return name, nil
+	}
+
+	var candidates []string
+	for _, player := range m.players.Load() {
+		short := strings.TrimPrefix(player.BusName, MPRIS_PREFIX+".")
+		if short == name {
+			return player.BusName, nil
+		}
+		if strings.HasPrefix(short, name+".") {
+			candidates = append(candidates, player.BusName)
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return "", &PlayerNotFoundError{BusName: name}
+	case 1:
+		return candidates[0], nil
+	default:
+		return "", &AmbiguousPlayerError{Name: name, Candidates: candidates}
+	}
+}
+
+// GetPosition returns a player's playback progress from the cache, without
+// any D-Bus call so it is cheap to poll. While Playing, the cached position is extrapolated from
+// PositionUpdatedAt at the current rate, capped at the track length.
+func (m *MPRISBackend) GetPosition(busName string) (*PositionInfo, error) {
+	player, err := m.GetPlayerFromCache(busName)
+	if err != nil {
+		return nil, err
+	}
+	return positionInfo(player, time.Now()), nil
+}
+
+// GetPlayerStatus returns a player's playback status, volume, position and
+// loop status from the cache, without a D-Bus call.
+func (m *MPRISBackend) GetPlayerStatus(busName string) (*PlayerStatus, error) {
+	player, err := m.GetPlayerFromCache(busName)
+	if err != nil {
+		return nil, err
+	}
+	return &PlayerStatus{
+		PlaybackStatus: player.PlaybackStatus,
+		Volume:         player.Volume,
+		Position:       positionInfo(player, time.Now()).Position,
+		LoopStatus:     player.LoopStatus,
+	}, nil
+}
+
+func positionInfo(p *Player, now time.Time) *PositionInfo {
+	length, _ := strconv.ParseInt(p.Metadata["mpris:length"], 10, 64)
+	rate := p.Rate
+	if rate == 0 {
+		rate = 1 // Rate is optional in MPRIS; absent means normal speed
+	}
+	pos := p.Position
+	if p.PlaybackStatus == StatusPlaying && !p.PositionUpdatedAt.IsZero() {
+		pos += int64(float64(now.Sub(p.PositionUpdatedAt).Microseconds()) * rate)
+		if length > 0 && pos > length {
+			pos = length
+		}
+	}
+	return &PositionInfo{
+		Position: max(pos, 0),
+		Length:   length,
+		Rate:     rate,
+		Status:   p.PlaybackStatus,
+	}
+}
+
 // UpdatePlayer updates a specific player in the cache.
 // If the player exists, it is replaced. Otherwise, it is added to the cache.
 // WARNING: If the cache is empty, this function reloads ALL players via ListPlayers.
 func (m *MPRISBackend) UpdatePlayer(updated Player) error {
+	updated.LastSeen = time.Now()
 	found := false
 	ok := m.updatePlayers(func(players []Player) []Player {
 		for i, player := range players {
@@ -168,8 +394,10 @@ func (m *MPRISBackend) UpdatePlayer(updated Player) error {
 	eventType := events.TypePlayerUpdated
 	if !found {
 		eventType = events.TypePlayerAdded
+		m.unbury(updated.BusName)
 	}
 	m.notify(events.Event{Type: eventType, Data: playerEnvelope(updated)})
+	m.publishPlayer(updated)
 	return nil
 }
 
@@ -203,6 +431,7 @@ func shouldAcceptPosition(p *Player, pos int64) bool {
 // D-Bus PropertiesChanged signals. Does NOT make D-Bus calls.
 func (m *MPRISBackend) UpdatePlayerProperties(busName string, changed map[string]dbus.Variant) error {
 	var updated Player
+	var newArtURL string
 	found := false
 	ok := m.updatePlayers(func(players []Player) []Player {
 		i := -1
@@ -232,6 +461,10 @@ func (m *MPRISBackend) UpdatePlayerProperties(busName string, changed map[string
 				if val, ok := extract[bool](variant); ok {
 					players[i].Shuffle = val
 				}
+			case "Fullscreen":
+				if val, ok := extract[bool](variant); ok {
+					players[i].Fullscreen = val
+				}
 			case "Volume":
 				if val, ok := extract[float64](variant); ok {
 					players[i].Volume = &val
@@ -239,9 +472,10 @@ func (m *MPRISBackend) UpdatePlayerProperties(busName string, changed map[string
 			case "Metadata":
 				if metaMap, ok := extract[map[string]dbus.Variant](variant); ok {
 					oldTrackID := players[i].Metadata["mpris:trackid"]
-					players[i].Metadata = make(map[string]string)
-					for k, v := range metaMap {
-						players[i].Metadata[k] = formatMetadataValue(v.Value())
+					oldArtURL := players[i].Metadata["mpris:artUrl"]
+					players[i].Metadata = extractMetadata(metaMap, m.metadataKeyList())
+					if artURL := players[i].Metadata["mpris:artUrl"]; artURL != oldArtURL {
+						newArtURL = artURL
 					}
 					// Track changed — reset stale position from previous track
 					newTrackID := players[i].Metadata["mpris:trackid"]
@@ -260,11 +494,12 @@ func (m *MPRISBackend) UpdatePlayerProperties(busName string, changed map[string
 					players[i].Position = val
 					players[i].PositionUpdatedAt = time.Now()
 				}
-			case "CanPlay", "CanPause", "CanGoNext", "CanGoPrevious", "CanSeek", "CanControl":
+			case "CanPlay", "CanPause", "CanGoNext", "CanGoPrevious", "CanSeek", "CanControl", "CanSetFullscreen":
 				players[i].Capabilities.setFromProp(key, variant)
 			}
 		}
 
+		players[i].LastSeen = time.Now()
 		updated = players[i]
 		return players
 	})
@@ -272,7 +507,12 @@ func (m *MPRISBackend) UpdatePlayerProperties(busName string, changed map[string
 		return &PlayerNotFoundError{BusName: busName}
 	}
 
+	if m.artworkCache != nil && strings.HasPrefix(newArtURL, "file://") {
+		go m.prefetchArtwork(newArtURL)
+	}
+
 	m.notify(events.Event{Type: events.TypePlayerUpdated, Data: playerEnvelope(updated)})
+	m.publishPlayer(updated)
 	logger.Debug("[mpris] updated %d properties for player %s", len(changed), busName)
 	return nil
 }
@@ -332,17 +572,66 @@ func (m *MPRISBackend) ReloadPlayerFromDBus(busName string) (*Player, error) {
 	return &player, nil
 }
 
+// refreshInterval is the minimum delay between two RefreshPlayer calls on the
+// same player.
+const refreshInterval = 5 * time.Second
+
+// RefreshPlayer is ReloadPlayerFromDBus for API clients suspecting a stale
+// cache: at most once per refreshInterval per player, and a bus name with no
+// owner on D-Bus is a PlayerNotFoundError.
+func (m *MPRISBackend) RefreshPlayer(busName string) (*Player, error) {
+	if err := validateBusName(busName); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if wait := m.claimRefresh(busName, now); wait > 0 {
+		return nil, &RefreshRateLimitedError{BusName: busName, RetryAfter: wait}
+	}
+
+	player, err := m.ReloadPlayerFromDBus(busName)
+	if isNoOwner(err) {
+		// Drop the claim, unless a newer one replaced it: refreshing made-up
+		// bus names must not grow m.refreshed.
+		m.refreshed.CompareAndDelete(busName, now)
+		return nil, &PlayerNotFoundError{BusName: busName}
+	}
+	return player, err
+}
+
+// claimRefresh records now as the last refresh of busName and returns 0, or
+// returns how long to wait when busName was refreshed less than refreshInterval
+// ago. The check and the update are one atomic step, so of two concurrent
+// callers only one claims the refresh.
+func (m *MPRISBackend) claimRefresh(busName string, now time.Time) time.Duration {
+	for {
+		last, loaded := m.refreshed.LoadOrStore(busName, now)
+		if !loaded {
+			return 0
+		}
+		if wait := refreshInterval - now.Sub(last.(time.Time)); wait > 0 {
+			return wait
+		}
+		if m.refreshed.CompareAndSwap(busName, last, now) {
+			return 0
+		}
+	}
+}
+
 // RemovePlayer removes a player from cache (when it closes)
 func (m *MPRISBackend) RemovePlayer(busName string) error {
 	if err := validateBusName(busName); err != nil {
 		return err
 	}
 
+	var removed *Player
 	ok := m.updatePlayers(func(players []Player) []Player {
 		filtered := make([]Player, 0, len(players))
-		for _, player := range players {
+		for i, player := range players {
 			if player.BusName != busName {
 				filtered = append(filtered, player)
+			} else {
+				removed = &players[i]
 			}
 		}
 		return filtered
@@ -350,6 +639,11 @@ func (m *MPRISBackend) RemovePlayer(busName string) error {
 	if !ok {
 		return nil
 	}
+	if removed != nil {
+		m.bury(*removed)
+	}
+	m.refreshed.Delete(busName)
+	m.closeSubscribers(busName)
 
 	m.notify(events.Event{
 		Type: events.TypePlayerRemoved,
@@ -383,6 +677,7 @@ func (m *MPRISBackend) getPlayerFromDBus(busName string) (Player, error) {
 	if err := player.loadFromDBus(); err != nil {
 		return Player{}, err
 	}
+	player.LastSeen = time.Now()
 	return *player, nil
 }
 
@@ -460,16 +755,46 @@ func (m *MPRISBackend) Previous(busName string) error {
 	return m.callMethod(busName, MPRIS_METHOD_PREVIOUS)
 }
 
-// Seek moves the playback position
+// Seek moves the playback position by offset microseconds. When the track
+// length is known and the seek would overshoot either end, it becomes an
+// absolute SetPosition clamped to [0, length], since players disagree on what
+// an out-of-range relative Seek does. Streams without a length seek as is.
 func (m *MPRISBackend) Seek(busName string, offset int64) error {
-	if err := m.requireCapability(busName, "CanSeek", (*Player).CanSeek); err != nil {
+	player, err := m.GetPlayerFromCache(busName)
+	if err != nil {
 		return err
 	}
+	if !player.CanSeek() {
+		return &CapabilityError{Required: "CanSeek"}
+	}
+
+	info := positionInfo(player, time.Now())
+	if target, clamped := clampSeek(info.Position, info.Length, offset); clamped {
+		logger.Debug("[mpris] seek %d overshoots for %s, clamping to %d", offset, busName, target)
+		return m.SetPosition(busName, "", target)
+	}
 
 	logger.Debug("[mpris] seeking %d for %s", offset, busName)
 	return m.callMethod(busName, MPRIS_METHOD_SEEK, offset)
 }
 
+// clampSeek returns the position a relative seek from pos lands on, bounded
+// to [0, length], and whether it had to be bounded. An unknown length (0)
+// never clamps.
+func clampSeek(pos, length, offset int64) (int64, bool) {
+	if length <= 0 {
+		return 0, false
+	}
+	target := pos + offset
+	switch {
+	case target < 0:
+		return 0, true
+	case target > length:
+		return length, true
+	}
+	return target, false
+}
+
 // SetPosition seeks to an absolute position in microseconds.
 // trackID may be empty; if so it is resolved from the cached player metadata.
 // Falls back to a relative Seek when no valid track ID is available.
@@ -500,8 +825,8 @@ func (m *MPRISBackend) SetPosition(busName, trackID string, position int64) erro
 
 // SetVolume sets the volume
 func (m *MPRISBackend) SetVolume(busName string, volume float64) error {
-	if volume < 0 || volume > 1 {
-		return &ValidationError{Field: "volume", Message: "must be between 0 and 1"}
+	if err := validateVolume(volume); err != nil {
+		return err
 	}
 
 	if err := m.requireCapability(busName, "CanControl", (*Player).CanControl); err != nil {
@@ -514,11 +839,8 @@ func (m *MPRISBackend) SetVolume(busName string, volume float64) error {
 
 // SetLoopStatus sets the loop status
 func (m *MPRISBackend) SetLoopStatus(busName string, status LoopStatus) error {
-	switch status {
-	case LoopNone, LoopTrack, LoopPlaylist:
-		// Valid
-	default:
-		return &ValidationError{Field: "loop", Message: "must be None, Track, or Playlist"}
+	if err := validateLoopStatus(status); err != nil {
+		return err
 	}
 
 	if err := m.requireCapability(busName, "CanControl", (*Player).CanControl); err != nil {
@@ -529,6 +851,22 @@ func (m *MPRISBackend) SetLoopStatus(busName string, status LoopStatus) error {
 	return m.setProperty(busName, "LoopStatus", string(status))
 }
 
+func validateVolume(volume float64) error {
+	if volume < 0 || volume > 1 {
+		return &ValidationError{Field: "volume", Message: "must be between 0 and 1"}
+	}
+	return nil
+}
+
+func validateLoopStatus(status LoopStatus) error {
+	switch status {
+	case LoopNone, LoopTrack, LoopPlaylist:
+		return nil
+	default:
+		return &ValidationError{Field: "loop", Message: "must be None, Track, or Playlist"}
+	}
+}
+
 // SetShuffle enables/disables shuffle mode
 func (m *MPRISBackend) SetShuffle(busName string, shuffle bool) error {
 	if err := m.requireCapability(busName, "CanControl", (*Player).CanControl); err != nil {
@@ -539,6 +877,94 @@ func (m *MPRISBackend) SetShuffle(busName string, shuffle bool) error {
 	return m.setProperty(busName, "Shuffle", shuffle)
 }
 
+// ApplyState sets the provided fields of a partial state in one call, e.g. to
+// restore a saved playback profile. Every field is validated before any is
+// sent, so a bad value changes nothing, and all invalid fields are reported
+// together as joined ValidationErrors; after that each field is applied on
+// its own and reported in Fields. Player is reloaded from D-Bus so it reflects
+// the outcome rather than signals still in flight.
+func (m *MPRISBackend) ApplyState(busName string, req StateRequest) (*StateResult, error) {
+	if _, err := m.GetPlayerFromCache(busName); err != nil {
+		return nil, err
+	}
+	if req.Volume == nil && req.Loop == nil && req.Shuffle == nil {
+		return nil, &ValidationError{Message: "no field to apply (volume, loop, shuffle)"}
+	}
+	var invalid []error
+	if req.Volume != nil {
+		if err := validateVolume(*req.Volume); err != nil {
+			invalid = append(invalid, err)
+		}
+	}
+	if req.Loop != nil {
+		if err := validateLoopStatus(LoopStatus(*req.Loop)); err != nil {
+			invalid = append(invalid, err)
+		}
+	}
+	if err := errors.Join(invalid...); err != nil {
+		return nil, err
+	}
+
+	result := &StateResult{Fields: make(map[string]FieldResult)}
+	record := func(field string, err error) {
+		if err != nil {
+			result.Fields[field] = FieldResult{Error: err.Error()}
+			return
+		}
+		result.Fields[field] = FieldResult{OK: true}
+	}
+	if req.Volume != nil {
+		record("volume", m.SetVolume(busName, *req.Volume))
+	}
+	if req.Loop != nil {
+		record("loop", m.SetLoopStatus(busName, LoopStatus(*req.Loop)))
+	}
+	if req.Shuffle != nil {
+		record("shuffle", m.SetShuffle(busName, *req.Shuffle))
+	}
+
+	player, err := m.ReloadPlayerFromDBus(busName)
+	if err != nil {
+		logger.Debug("[mpris] reload after state update failed for %s, serving cache: %v", busName, err)
+		if player, err = m.GetPlayerFromCache(busName); err != nil {
+			return nil, err
+		}
+	}
+	result.Player = player
+	return result, nil
+}
+
+// SetFullscreen switches a media center frontend (Kodi, VLC...) in or out of
+// fullscreen through the root interface's Fullscreen property.
+func (m *MPRISBackend) SetFullscreen(busName string, on bool) error {
+	if err := m.requireCapability(busName, "CanSetFullscreen", (*Player).CanSetFullscreen); err != nil {
+		return err
+	}
+
+	logger.Debug("[mpris] setting fullscreen to %v for %s", on, busName)
+	return m.setIfaceProperty(busName, MPRIS_INTERFACE, "Fullscreen", on)
+}
+
+// SetRating rates the current track, 0 to 1. Rating is not part of MPRIS:
+// players supporting it (Rhythmbox, Banshee) publish xesam:userRating in their
+// metadata and accept a Rating property, so only those are let through.
+func (m *MPRISBackend) SetRating(busName string, rating float64) error {
+	if rating < 0 || rating > 1 {
+		return &ValidationError{Field: "rating", Message: "must be between 0 and 1"}
+	}
+
+	player, err := m.GetPlayerFromCache(busName)
+	if err != nil {
+		return err
+	}
+	if _, ok := player.Metadata["xesam:userRating"]; !ok {
+		return &ValidationError{Field: "rating", Message: "not supported by this player"}
+	}
+
+	logger.Debug("[mpris] setting rating to %.2f for %s", rating, busName)
+	return m.setProperty(busName, "Rating", rating)
+}
+
 // CacheUpdatedAt returns the last time the player cache was written to.
 func (m *MPRISBackend) CacheUpdatedAt() time.Time {
 	return m.players.UpdatedAt()
@@ -551,20 +977,26 @@ func (m *MPRISBackend) InvalidateCache() {
 
 // Close cleanly closes connections and stops the listener
 func (m *MPRISBackend) Close() {
-	if m.heartbeat != nil {
-		m.heartbeat.Stop()
-		m.heartbeat = nil
+	// No reconnect may restart the listener from here on.
+	if m.stopWatch != nil {
+		m.stopWatch()
+		<-m.watchDone
 	}
-	if m.listener != nil {
-		m.listener.Stop()
-		m.listener = nil
+	if h := m.heartbeat.Swap(nil); h != nil {
+		h.Stop()
 	}
+	if l := m.listener.Swap(nil); l != nil {
+		l.Stop()
+	}
+	m.connMu.Lock()
 	if m.conn != nil {
 		if err := m.conn.Close(); err != nil {
 			logger.Info("Failed to close D-Bus connection: %v", err)
 		}
 		m.conn = nil
 	}
+	m.connMu.Unlock()
+	m.closeAllSubscribers()
 	close(m.events)
 }
 
@@ -575,6 +1007,32 @@ func playerEnvelope(p Player) map[string]any {
 	}
 }
 
+// PlayerEvent narrows an MPRIS event to the player busName. ok is false for
+// events of other players or backends; a batched player.position event keeps
+// only that player's entry.
+func PlayerEvent(e events.Event, busName string) (events.Event, bool) {
+	if !slices.Contains(events.BackendTypes["mpris"], e.Type) {
+		return e, false
+	}
+	switch data := e.Data.(type) {
+	case map[string]any:
+		if p, ok := data["data"].(Player); ok {
+			return e, p.BusName == busName
+		}
+		return e, data["bus_name"] == busName
+	case map[string]string:
+		return e, data["bus_name"] == busName
+	case []map[string]any:
+		for _, u := range data {
+			if u["bus_name"] == busName {
+				e.Data = []map[string]any{u}
+				return e, true
+			}
+		}
+	}
+	return e, false
+}
+
 func (m *MPRISBackend) notify(e events.Event) {
 	select {
 	case m.events <- e:
@@ -585,3 +1043,28 @@ func (m *MPRISBackend) notify(e events.Event) {
 
 // Events returns the read-only event channel for this backend.
 func (m *MPRISBackend) Events() <-chan events.Event { return m.events }
+
+// FilterPlayers returns the players matching f, in their original order.
+// The input is a cache snapshot and is never modified.
+func FilterPlayers(players []Player, f PlayerFilter) []Player {
+	if f == (PlayerFilter{}) {
+		return players
+	}
+
+	out := make([]Player, 0, len(players))
+	for _, p := range players {
+		if f.Status != "" && !strings.EqualFold(string(p.PlaybackStatus), f.Status) {
+			continue
+		}
+		if !containsFold(p.Metadata["xesam:artist"], f.Artist) ||
+			!containsFold(p.Metadata["xesam:album"], f.Album) {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
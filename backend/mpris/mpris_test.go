@@ -1,14 +1,27 @@
 package mpris
 
 import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
 	"reflect"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/godbus/dbus/v5"
 
+	"github.com/b0bbywan/go-odio-api/cache"
+	"github.com/b0bbywan/go-odio-api/config"
 	"github.com/b0bbywan/go-odio-api/events"
 )
 
@@ -72,6 +85,48 @@ func TestConcurrentReadersWriters(t *testing.T) {
 	<-drained
 }
 
+// Concurrent UpdatePlayer calls for different players must all land.
+func TestUpdatePlayerConcurrent(t *testing.T) {
+	b := &MPRISBackend{events: make(chan events.Event, 64)}
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		for range b.events {
+		}
+	}()
+	b.players.Store([]Player{})
+
+	const n = 20
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			busName := fmt.Sprintf("org.mpris.MediaPlayer2.p%d", i)
+			for j := range 10 {
+				p := Player{BusName: busName, Position: int64(j)}
+				if err := b.UpdatePlayer(p); err != nil {
+					t.Errorf("UpdatePlayer(%s): %v", busName, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(b.events)
+	<-drained
+
+	players := b.players.Load()
+	if len(players) != n {
+		t.Fatalf("cache holds %d players, want %d", len(players), n)
+	}
+	for _, p := range players {
+		if p.Position != 9 {
+			t.Errorf("%s position = %d, want the last update (9)", p.BusName, p.Position)
+		}
+	}
+}
+
 func TestGetPlayerFromCache(t *testing.T) {
 	backend := &MPRISBackend{}
 
@@ -290,6 +345,45 @@ func TestUpdatePlayerAddNew(t *testing.T) {
 	}
 }
 
+// TestPlayerSubscribers checks a subscriber gets its player after each
+// property change, not other players', and is closed on removal.
+func TestPlayerSubscribers(t *testing.T) {
+	const spotify, vlc = "org.mpris.MediaPlayer2.spotify", "org.mpris.MediaPlayer2.vlc"
+	m := &MPRISBackend{}
+	m.players.Store([]Player{{BusName: spotify}, {BusName: vlc}})
+
+	ch := m.SubscribePlayer(spotify)
+	if err := m.UpdatePlayerProperties(vlc, map[string]dbus.Variant{"PlaybackStatus": dbus.MakeVariant("Playing")}); err != nil {
+		t.Fatalf("UpdatePlayerProperties(vlc) error = %v", err)
+	}
+	if err := m.UpdatePlayerProperties(spotify, map[string]dbus.Variant{"PlaybackStatus": dbus.MakeVariant("Playing")}); err != nil {
+		t.Fatalf("UpdatePlayerProperties(spotify) error = %v", err)
+	}
+
+	select {
+	case p := <-ch:
+		if p.BusName != spotify || p.PlaybackStatus != StatusPlaying {
+			t.Errorf("received %s %s, want %s Playing", p.BusName, p.PlaybackStatus, spotify)
+		}
+	default:
+		t.Fatal("subscriber received no update")
+	}
+	select {
+	case p := <-ch:
+		t.Fatalf("unexpected second update for %s", p.BusName)
+	default:
+	}
+
+	if err := m.RemovePlayer(spotify); err != nil {
+		t.Fatalf("RemovePlayer() error = %v", err)
+	}
+	if _, ok := <-ch; ok {
+		t.Error("subscriber channel should be closed once the player is removed")
+	}
+	// Unsubscribing after the removal closed it must not panic.
+	m.UnsubscribePlayer(spotify, ch)
+}
+
 func TestRemovePlayer(t *testing.T) {
 	backend := &MPRISBackend{}
 
@@ -334,6 +428,58 @@ func TestRemovePlayer(t *testing.T) {
 	}
 }
 
+// TestBuryPurgesExpiredTombstones: an expired tombstone is dropped from the
+// cache, not only hidden from GonePlayers.
+func TestBuryPurgesExpiredTombstones(t *testing.T) {
+	backend := &MPRISBackend{gone: cache.New[Player](10 * time.Millisecond)}
+	backend.bury(Player{BusName: "org.mpris.MediaPlayer2.chromium.instance1"})
+	time.Sleep(20 * time.Millisecond)
+	backend.bury(Player{BusName: "org.mpris.MediaPlayer2.chromium.instance2"})
+
+	if n := backend.gone.Len(); n != 1 {
+		t.Errorf("tombstones stored = %d, want the expired one purged", n)
+	}
+	if gone := backend.GonePlayers(); len(gone) != 1 || gone[0].BusName != "org.mpris.MediaPlayer2.chromium.instance2" {
+		t.Errorf("GonePlayers() = %+v, want instance2 only", gone)
+	}
+}
+
+func TestRemovePlayerKeepsTombstone(t *testing.T) {
+	backend := &MPRISBackend{gone: cache.New[Player](goneTTL)}
+	seen := time.Now().Add(-time.Minute)
+	backend.players.Store([]Player{
+		{BusName: "org.mpris.MediaPlayer2.spotify", Identity: "Spotify", LastSeen: seen},
+		{BusName: "org.mpris.MediaPlayer2.vlc", Identity: "VLC"},
+	})
+
+	if err := backend.RemovePlayer("org.mpris.MediaPlayer2.spotify"); err != nil {
+		t.Fatalf("RemovePlayer failed: %v", err)
+	}
+
+	gone := backend.GonePlayers()
+	if len(gone) != 1 {
+		t.Fatalf("GonePlayers() = %+v, want the removed player only", gone)
+	}
+	if g := gone[0]; g.BusName != "org.mpris.MediaPlayer2.spotify" || !g.Gone || !g.LastSeen.Equal(seen) {
+		t.Errorf("tombstone = %+v, want spotify flagged gone with its last_seen", g)
+	}
+
+	// The player comes back: its tombstone goes away.
+	if err := backend.UpdatePlayer(Player{BusName: "org.mpris.MediaPlayer2.spotify"}); err != nil {
+		t.Fatalf("UpdatePlayer failed: %v", err)
+	}
+	if gone := backend.GonePlayers(); len(gone) != 0 {
+		t.Errorf("GonePlayers() after return = %+v, want none", gone)
+	}
+	p, err := backend.GetPlayerFromCache("org.mpris.MediaPlayer2.spotify")
+	if err != nil {
+		t.Fatalf("GetPlayerFromCache: %v", err)
+	}
+	if !p.LastSeen.After(seen) {
+		t.Errorf("LastSeen = %v, want refreshed by UpdatePlayer", p.LastSeen)
+	}
+}
+
 func TestInvalidateCache(t *testing.T) {
 	backend := &MPRISBackend{}
 
@@ -361,13 +507,13 @@ func TestInvalidateCache(t *testing.T) {
 
 func TestExtractMetadata(t *testing.T) {
 	// Test with empty metadata
-	emptyMetadata := extractMetadata(nil)
+	emptyMetadata := extractMetadata(nil, defaultMetadataKeys)
 	if len(emptyMetadata) != 0 {
 		t.Error("Empty metadata should return empty map")
 	}
 
 	// Test with non-map type
-	invalidMetadata := extractMetadata("invalid")
+	invalidMetadata := extractMetadata("invalid", defaultMetadataKeys)
 	if len(invalidMetadata) != 0 {
 		t.Error("Invalid metadata type should return empty map")
 	}
@@ -391,6 +537,124 @@ func TestPlayerNotFoundError(t *testing.T) {
 	}
 }
 
+func TestRefreshPlayerRateLimited(t *testing.T) {
+	m := &MPRISBackend{}
+	busName := "org.mpris.MediaPlayer2.spotify"
+	m.refreshed.Store(busName, time.Now().Add(-2*time.Second))
+
+	_, err := m.RefreshPlayer(busName)
+	var rateErr *RefreshRateLimitedError
+	if !errors.As(err, &rateErr) {
+		t.Fatalf("RefreshPlayer() = %v, want RefreshRateLimitedError", err)
+	}
+	if rateErr.RetryAfter <= 0 || rateErr.RetryAfter > 3*time.Second {
+		t.Errorf("RetryAfter = %v, want about 3s", rateErr.RetryAfter)
+	}
+}
+
+func TestClaimRefreshConcurrent(t *testing.T) {
+	m := &MPRISBackend{}
+	busName := "org.mpris.MediaPlayer2.spotify"
+	m.refreshed.Store(busName, time.Now().Add(-2*refreshInterval))
+
+	var claimed atomic.Int32
+	var wg sync.WaitGroup
+	now := time.Now()
+	for range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if m.claimRefresh(busName, now) == 0 {
+				claimed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := claimed.Load(); got != 1 {
+		t.Errorf("%d concurrent refreshes claimed, want 1", got)
+	}
+}
+
+// noOwnerConn returns a connection whose every method call fails with
+// NameHasNoOwner, as for a bus name nobody owns. The peer speaks just enough
+// of the auth handshake and wire protocol for that.
+func noOwnerConn(t *testing.T) *dbus.Conn {
+	t.Helper()
+	client, server := net.Pipe()
+	t.Cleanup(func() { _ = server.Close() })
+	go func() {
+		in := bufio.NewReader(server)
+		for _, answer := range []string{"REJECTED ANONYMOUS", "OK 0123456789abcdef0123456789abcdef", ""} {
+			if _, err := in.ReadString('\n'); err != nil { // NUL+AUTH, AUTH ANONYMOUS, BEGIN
+				return
+			}
+			if answer != "" {
+				if _, err := io.WriteString(server, answer+"\r\n"); err != nil {
+					return
+				}
+			}
+		}
+		for {
+			call, err := dbus.DecodeMessage(in)
+			if err != nil {
+				return
+			}
+			reply := &dbus.Message{
+				Type: dbus.TypeError,
+				Headers: map[dbus.HeaderField]dbus.Variant{
+					dbus.FieldReplySerial: dbus.MakeVariant(call.Serial()),
+					dbus.FieldErrorName:   dbus.MakeVariant("org.freedesktop.DBus.Error.NameHasNoOwner"),
+				},
+			}
+			if err := reply.EncodeTo(server, binary.LittleEndian); err != nil {
+				return
+			}
+		}
+	}()
+
+	conn, err := dbus.NewConn(client)
+	if err != nil {
+		t.Fatalf("NewConn: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	if err := conn.Auth([]dbus.Auth{dbus.AuthAnonymous()}); err != nil {
+		t.Fatalf("Auth: %v", err)
+	}
+	return conn
+}
+
+// TestRefreshUnknownPlayerForgetsClaim: refreshing a bus name with no owner
+// leaves no rate-limit entry behind.
+func TestRefreshUnknownPlayerForgetsClaim(t *testing.T) {
+	m := &MPRISBackend{
+		ctx:      context.Background(),
+		conn:     noOwnerConn(t),
+		timeouts: config.MPRISTimeoutConfig{Default: time.Second, List: time.Second},
+	}
+	busName := "org.mpris.MediaPlayer2.made_up"
+
+	var notFound *PlayerNotFoundError
+	if _, err := m.RefreshPlayer(busName); !errors.As(err, &notFound) {
+		t.Fatalf("RefreshPlayer() = %v, want PlayerNotFoundError", err)
+	}
+	if _, ok := m.refreshed.Load(busName); ok {
+		t.Error("refresh claim kept for a player that does not exist")
+	}
+}
+
+func TestIsNoOwner(t *testing.T) {
+	if !isNoOwner(dbus.Error{Name: "org.freedesktop.DBus.Error.NameHasNoOwner"}) {
+		t.Error("isNoOwner(NameHasNoOwner) = false, want true")
+	}
+	if isNoOwner(dbus.Error{Name: "org.freedesktop.DBus.Error.AccessDenied"}) {
+		t.Error("isNoOwner(AccessDenied) = true, want false")
+	}
+	if isNoOwner(nil) {
+		t.Error("isNoOwner(nil) = true, want false")
+	}
+}
+
 func TestInvalidBusNameError(t *testing.T) {
 	err := &InvalidBusNameError{
 		BusName: "invalid",
@@ -926,7 +1190,7 @@ func TestExtractMetadataWithRealData(t *testing.T) {
 		"mpris:length":      dbus.MakeVariant(int64(240000000)),
 	}
 
-	result := extractMetadata(metadata)
+	result := extractMetadata(metadata, defaultMetadataKeys)
 
 	expectedKeys := []string{
 		"xesam:title",
@@ -970,6 +1234,7 @@ func TestPlayerStructTags(t *testing.T) {
 		"PlaybackStatus":      {dbusTag: "PlaybackStatus", ifaceTag: "org.mpris.MediaPlayer2.Player"},
 		"LoopStatus":          {dbusTag: "LoopStatus", ifaceTag: "org.mpris.MediaPlayer2.Player"},
 		"Shuffle":             {dbusTag: "Shuffle", ifaceTag: "org.mpris.MediaPlayer2.Player"},
+		"Fullscreen":          {dbusTag: "Fullscreen", ifaceTag: "org.mpris.MediaPlayer2"},
 		"Volume":              {dbusTag: "Volume", ifaceTag: "org.mpris.MediaPlayer2.Player"},
 		"Position":            {dbusTag: "Position", ifaceTag: "org.mpris.MediaPlayer2.Player"},
 		"Rate":                {dbusTag: "Rate", ifaceTag: "org.mpris.MediaPlayer2.Player"},
@@ -1017,12 +1282,13 @@ func TestCapabilitiesStructTags(t *testing.T) {
 	capsType := reflect.TypeOf(Capabilities{})
 
 	expectedTags := map[string]string{
-		"CanPlay":       "CanPlay",
-		"CanPause":      "CanPause",
-		"CanGoNext":     "CanGoNext",
-		"CanGoPrevious": "CanGoPrevious",
-		"CanSeek":       "CanSeek",
-		"CanControl":    "CanControl",
+		"CanPlay":          "CanPlay",
+		"CanPause":         "CanPause",
+		"CanGoNext":        "CanGoNext",
+		"CanGoPrevious":    "CanGoPrevious",
+		"CanSeek":          "CanSeek",
+		"CanControl":       "CanControl",
+		"CanSetFullscreen": "CanSetFullscreen",
 	}
 
 	for i := 0; i < capsType.NumField(); i++ {
@@ -1289,6 +1555,119 @@ func TestShouldAcceptPosition(t *testing.T) {
 	}
 }
 
+func TestPositionInfo(t *testing.T) {
+	now := time.Now()
+	meta := map[string]string{"mpris:length": "100000000"}
+
+	tests := []struct {
+		name   string
+		player Player
+		want   PositionInfo
+	}{
+		{
+			name:   "paused returns the cached position",
+			player: Player{PlaybackStatus: StatusPaused, Position: 10_000_000, PositionUpdatedAt: now.Add(-5 * time.Second), Metadata: meta},
+			want:   PositionInfo{Position: 10_000_000, Length: 100_000_000, Rate: 1, Status: StatusPaused},
+		},
+		{
+			name:   "playing extrapolates from the update time",
+			player: Player{PlaybackStatus: StatusPlaying, Rate: 1, Position: 10_000_000, PositionUpdatedAt: now.Add(-5 * time.Second), Metadata: meta},
+			want:   PositionInfo{Position: 15_000_000, Length: 100_000_000, Rate: 1, Status: StatusPlaying},
+		},
+		{
+			name:   "playing honors the rate",
+			player: Player{PlaybackStatus: StatusPlaying, Rate: 2, Position: 10_000_000, PositionUpdatedAt: now.Add(-5 * time.Second), Metadata: meta},
+			want:   PositionInfo{Position: 20_000_000, Length: 100_000_000, Rate: 2, Status: StatusPlaying},
+		},
+		{
+			name:   "playing is capped at the length",
+			player: Player{PlaybackStatus: StatusPlaying, Rate: 1, Position: 99_000_000, PositionUpdatedAt: now.Add(-5 * time.Second), Metadata: meta},
+			want:   PositionInfo{Position: 100_000_000, Length: 100_000_000, Rate: 1, Status: StatusPlaying},
+		},
+		{
+			name:   "missing rate defaults to normal speed",
+			player: Player{PlaybackStatus: StatusPlaying, Position: 10_000_000, PositionUpdatedAt: now.Add(-5 * time.Second), Metadata: meta},
+			want:   PositionInfo{Position: 15_000_000, Length: 100_000_000, Rate: 1, Status: StatusPlaying},
+		},
+		{
+			name:   "stopped reports its status",
+			player: Player{PlaybackStatus: StatusStopped, Rate: 1, Metadata: meta},
+			want:   PositionInfo{Length: 100_000_000, Rate: 1, Status: StatusStopped},
+		},
+		{
+			name:   "missing length reports zero",
+			player: Player{PlaybackStatus: StatusPaused, Position: 10_000_000},
+			want:   PositionInfo{Position: 10_000_000, Rate: 1, Status: StatusPaused},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := positionInfo(&tt.player, now)
+			if *got != tt.want {
+				t.Errorf("positionInfo() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetPlayerStatus(t *testing.T) {
+	vol := 0.7
+	m := &MPRISBackend{}
+	m.players.Store([]Player{{
+		BusName:        "org.mpris.MediaPlayer2.spotify",
+		PlaybackStatus: StatusPaused,
+		LoopStatus:     "Track",
+		Volume:         &vol,
+		Position:       10_000_000,
+	}})
+
+	got, err := m.GetPlayerStatus("org.mpris.MediaPlayer2.spotify")
+	if err != nil {
+		t.Fatalf("GetPlayerStatus() error = %v", err)
+	}
+	want := PlayerStatus{PlaybackStatus: StatusPaused, Volume: &vol, Position: 10_000_000, LoopStatus: "Track"}
+	if *got != want {
+		t.Errorf("GetPlayerStatus() = %+v, want %+v", *got, want)
+	}
+
+	var notFound *PlayerNotFoundError
+	if _, err := m.GetPlayerStatus("org.mpris.MediaPlayer2.vlc"); !errors.As(err, &notFound) {
+		t.Errorf("GetPlayerStatus(unknown) error = %v, want PlayerNotFoundError", err)
+	}
+}
+
+func TestClampSeek(t *testing.T) {
+	const length = 100_000_000
+
+	tests := []struct {
+		name        string
+		pos, length int64
+		offset      int64
+		want        int64
+		wantClamped bool
+	}{
+		{name: "within bounds", pos: 50_000_000, length: length, offset: 10_000_000, want: 60_000_000},
+		{name: "back to exactly zero", pos: 10_000_000, length: length, offset: -10_000_000, want: 0},
+		{name: "back past the start", pos: 5_000_000, length: length, offset: -10_000_000, want: 0, wantClamped: true},
+		{name: "forward to exactly the end", pos: 90_000_000, length: length, offset: 10_000_000, want: length},
+		{name: "forward past the end", pos: 95_000_000, length: length, offset: 10_000_000, want: length, wantClamped: true},
+		{name: "unknown length never clamps", pos: 5_000_000, length: 0, offset: -10_000_000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, clamped := clampSeek(tt.pos, tt.length, tt.offset)
+			if clamped != tt.wantClamped {
+				t.Fatalf("clampSeek() clamped = %v, want %v", clamped, tt.wantClamped)
+			}
+			if got != tt.want {
+				t.Errorf("clampSeek() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestUpdatePlayerPropertiesPosition(t *testing.T) {
 	const busName = "org.mpris.MediaPlayer2.test"
 
@@ -1495,3 +1874,772 @@ func TestUpdatePlayerPropertiesCapabilities(t *testing.T) {
 		})
 	}
 }
+
+func TestListPlayersSortsByPriority(t *testing.T) {
+	b := &MPRISBackend{priority: map[string]int{
+		"org.mpris.MediaPlayer2.spotify": 10,
+		"org.mpris.MediaPlayer2.mpd":     5,
+	}}
+	b.players.Store([]Player{
+		{BusName: "org.mpris.MediaPlayer2.vlc"},
+		{BusName: "org.mpris.MediaPlayer2.mpd"},
+		{BusName: "org.mpris.MediaPlayer2.firefox"},
+		{BusName: "org.mpris.MediaPlayer2.spotify"},
+	})
+
+	players, err := b.ListPlayers()
+	if err != nil {
+		t.Fatalf("ListPlayers: %v", err)
+	}
+	got := make([]string, len(players))
+	for i, p := range players {
+		got[i] = p.BusName
+	}
+	want := []string{
+		"org.mpris.MediaPlayer2.spotify",
+		"org.mpris.MediaPlayer2.mpd",
+		"org.mpris.MediaPlayer2.vlc",
+		"org.mpris.MediaPlayer2.firefox",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+
+	// The cached snapshot keeps the D-Bus order
+	if cached := b.players.Load(); cached[0].BusName != "org.mpris.MediaPlayer2.vlc" {
+		t.Errorf("cache was reordered: first = %s", cached[0].BusName)
+	}
+}
+
+func TestSetPriorityPersists(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "odio-api", "player-priority.json")
+
+	b := &MPRISBackend{priorityFile: file}
+	b.loadPriority()
+	if err := b.SetPriority("org.mpris.MediaPlayer2.spotify", 10); err != nil {
+		t.Fatalf("SetPriority: %v", err)
+	}
+	if err := b.SetPriority("org.mpris.MediaPlayer2.mpd", 3); err != nil {
+		t.Fatalf("SetPriority: %v", err)
+	}
+	if err := b.SetPriority("org.mpris.MediaPlayer2.mpd", 0); err != nil {
+		t.Fatalf("SetPriority: %v", err)
+	}
+
+	if _, err := os.Stat(file); err != nil {
+		t.Fatalf("priority file not written: %v", err)
+	}
+
+	restored := &MPRISBackend{priorityFile: file}
+	restored.loadPriority()
+	want := map[string]int{"org.mpris.MediaPlayer2.spotify": 10}
+	if !reflect.DeepEqual(restored.priority, want) {
+		t.Errorf("restored priority = %v, want %v", restored.priority, want)
+	}
+
+	if err := b.SetPriority("invalid", 1); err == nil {
+		t.Error("SetPriority with invalid bus name should fail")
+	}
+}
+
+func TestFilterPlayers(t *testing.T) {
+	players := []Player{
+		{BusName: "org.mpris.MediaPlayer2.spotify", PlaybackStatus: StatusPlaying,
+			Metadata: map[string]string{"xesam:artist": "Daft Punk", "xesam:album": "Discovery"}},
+		{BusName: "org.mpris.MediaPlayer2.mpd", PlaybackStatus: StatusPaused,
+			Metadata: map[string]string{"xesam:artist": "Air", "xesam:album": "Moon Safari"}},
+		{BusName: "org.mpris.MediaPlayer2.vlc", PlaybackStatus: StatusPlaying},
+	}
+
+	tests := []struct {
+		name   string
+		filter PlayerFilter
+		want   []string
+	}{
+		{"empty filter returns all", PlayerFilter{}, []string{"org.mpris.MediaPlayer2.spotify", "org.mpris.MediaPlayer2.mpd", "org.mpris.MediaPlayer2.vlc"}},
+		{"status", PlayerFilter{Status: "Playing"}, []string{"org.mpris.MediaPlayer2.spotify", "org.mpris.MediaPlayer2.vlc"}},
+		{"status is case-insensitive", PlayerFilter{Status: "paused"}, []string{"org.mpris.MediaPlayer2.mpd"}},
+		{"artist substring", PlayerFilter{Artist: "daft"}, []string{"org.mpris.MediaPlayer2.spotify"}},
+		{"album substring", PlayerFilter{Album: "SAFARI"}, []string{"org.mpris.MediaPlayer2.mpd"}},
+		{"combined filters", PlayerFilter{Status: "Playing", Artist: "air"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, p := range FilterPlayers(players, tt.filter) {
+				got = append(got, p.BusName)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FilterPlayers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestActivePlayer(t *testing.T) {
+	tests := []struct {
+		name    string
+		players []Player
+		want    string
+		wantErr bool
+	}{
+		{"no players", []Player{}, "", true},
+		{"playing wins", []Player{
+			{BusName: "org.mpris.MediaPlayer2.mpd", PlaybackStatus: StatusPaused},
+			{BusName: "org.mpris.MediaPlayer2.spotify", PlaybackStatus: StatusPlaying},
+		}, "org.mpris.MediaPlayer2.spotify", false},
+		{"paused over stopped", []Player{
+			{BusName: "org.mpris.MediaPlayer2.vlc", PlaybackStatus: StatusStopped},
+			{BusName: "org.mpris.MediaPlayer2.mpd", PlaybackStatus: StatusPaused},
+		}, "org.mpris.MediaPlayer2.mpd", false},
+		{"first listed otherwise", []Player{
+			{BusName: "org.mpris.MediaPlayer2.vlc", PlaybackStatus: StatusStopped},
+			{BusName: "org.mpris.MediaPlayer2.mpd", PlaybackStatus: StatusStopped},
+		}, "org.mpris.MediaPlayer2.vlc", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &MPRISBackend{}
+			b.players.Store(tt.players)
+
+			got, err := b.ActivePlayer()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("ActivePlayer() should fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ActivePlayer() error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ActivePlayer() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHeartbeatFollowsPlaybackStatus(t *testing.T) {
+	const busName = "org.mpris.MediaPlayer2.test"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b := &MPRISBackend{ctx: ctx}
+	b.players.Store([]Player{{BusName: busName, PlaybackStatus: StatusPaused}})
+	heartbeat := NewHeartbeat(b)
+	b.heartbeat.Store(heartbeat)
+	defer heartbeat.Stop()
+
+	heartbeat.Sync()
+	if heartbeat.IsRunning() {
+		t.Fatal("heartbeat running with no Playing player")
+	}
+
+	setStatus := func(status PlaybackStatus) {
+		changed := map[string]dbus.Variant{"PlaybackStatus": dbus.MakeVariant(string(status))}
+		if err := b.UpdatePlayerProperties(busName, changed); err != nil {
+			t.Fatalf("UpdatePlayerProperties: %v", err)
+		}
+		heartbeat.Sync()
+	}
+
+	setStatus(StatusPlaying)
+	if !heartbeat.IsRunning() {
+		t.Fatal("heartbeat not started on Paused -> Playing")
+	}
+
+	setStatus(StatusPaused)
+	if heartbeat.IsRunning() {
+		t.Fatal("heartbeat still running once nothing plays")
+	}
+
+	// A later transition restarts it: stopping on idle is not final
+	setStatus(StatusPlaying)
+	if !heartbeat.IsRunning() {
+		t.Fatal("heartbeat not restarted on second Paused -> Playing")
+	}
+
+	heartbeat.Stop()
+	heartbeat.Start()
+	if heartbeat.IsRunning() {
+		t.Error("Start after Stop should be a no-op")
+	}
+}
+
+func TestActionAllowed(t *testing.T) {
+	m := &MPRISBackend{}
+	if !m.ActionAllowed(ActionStop) {
+		t.Error("no allowed list should allow every action")
+	}
+
+	allowed, err := allowedActions([]string{ActionPlay, ActionNext})
+	if err != nil {
+		t.Fatalf("allowedActions() error = %v", err)
+	}
+	m.allowedActions = allowed
+	if !m.ActionAllowed(ActionPlay) || !m.ActionAllowed(ActionNext) {
+		t.Error("listed actions should be allowed")
+	}
+	if m.ActionAllowed(ActionStop) || m.ActionAllowed(ActionVolume) {
+		t.Error("unlisted actions should be forbidden")
+	}
+
+	if allowed, err := allowedActions(nil); err != nil || allowed != nil {
+		t.Errorf("allowedActions(nil) = %v, %v; want nil, nil", allowed, err)
+	}
+	if _, err := allowedActions([]string{"eject"}); err == nil {
+		t.Error("allowedActions() should reject an unknown action")
+	}
+}
+
+func TestImportPlayers(t *testing.T) {
+	m := &MPRISBackend{timeouts: config.MPRISTimeoutConfig{List: time.Second}}
+	m.players.Store([]Player{{BusName: "org.mpris.MediaPlayer2.stale"}})
+
+	m.ImportPlayers([]Player{{BusName: "org.mpris.MediaPlayer2.spotify", PlaybackStatus: StatusPlaying}})
+
+	players, err := m.ListPlayers()
+	if err != nil {
+		t.Fatalf("ListPlayers() error = %v", err)
+	}
+	if len(players) != 1 || players[0].BusName != "org.mpris.MediaPlayer2.spotify" {
+		t.Fatalf("ListPlayers() = %v, want the imported player only", players)
+	}
+	if players[0].backend != m || players[0].timeout != time.Second {
+		t.Error("imported player is not bound to the backend")
+	}
+	if m.heartbeat.Load() != nil {
+		t.Error("import should not start the heartbeat")
+	}
+}
+
+// closedConn stands in for a session bus connection that went away.
+func closedConn(t *testing.T) *dbus.Conn {
+	t.Helper()
+	client, server := net.Pipe()
+	t.Cleanup(func() { _ = server.Close() })
+	conn, err := dbus.NewConn(client)
+	if err != nil {
+		t.Fatalf("NewConn: %v", err)
+	}
+	if err := conn.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return conn
+}
+
+// TestDialBusGivesUpOnContext: a session bus dial that hangs does not outlive
+// its context.
+func TestDialBusGivesUpOnContext(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	conn, err := dialBus(ctx, func(...dbus.ConnOption) (*dbus.Conn, error) {
+		<-release
+		return nil, errors.New("too late")
+	})
+	if conn != nil || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("dialBus() = %v, %v, want nil, %v", conn, err, context.DeadlineExceeded)
+	}
+}
+
+func TestReconnectFailureKeepsState(t *testing.T) {
+	dead := closedConn(t)
+	attempts := 0
+	m := &MPRISBackend{
+		ctx:  context.Background(),
+		conn: dead,
+		connect: func(...dbus.ConnOption) (*dbus.Conn, error) {
+			attempts++
+			return nil, errors.New("no session bus")
+		},
+	}
+	m.players.Store([]Player{{BusName: "org.mpris.MediaPlayer2.spotify"}})
+	heartbeat, listener := NewHeartbeat(m), NewListener(m)
+	m.heartbeat.Store(heartbeat)
+	m.listener.Store(listener)
+
+	if m.Connected() {
+		t.Fatal("Connected() = true on a closed connection")
+	}
+	if err := m.Reconnect(); err == nil {
+		t.Fatal("Reconnect() error = nil, want the connect error")
+	}
+	if attempts != 1 {
+		t.Errorf("connect attempts = %d, want 1", attempts)
+	}
+	if m.busConn() != dead {
+		t.Error("failed reconnect replaced the connection")
+	}
+	if players := m.players.Load(); len(players) != 1 {
+		t.Errorf("players = %v, want the last known player kept", players)
+	}
+	if listener.ctx.Err() == nil {
+		t.Error("listener still running on the dead connection")
+	}
+	heartbeat.Start()
+	if heartbeat.IsRunning() {
+		t.Error("heartbeat restarted on the dead connection")
+	}
+}
+
+func TestReconnectSwapsConnectionSafely(t *testing.T) {
+	replacement := closedConn(t)
+	m := &MPRISBackend{
+		ctx:  context.Background(),
+		conn: closedConn(t),
+		connect: func(...dbus.ConnOption) (*dbus.Conn, error) {
+			return replacement, nil
+		},
+	}
+	m.heartbeat.Store(NewHeartbeat(m))
+	m.listener.Store(NewListener(m))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 100 {
+			m.Connected() // concurrent reader, flagged by -race without connMu
+		}
+	}()
+	_ = m.Reconnect() // start fails on the closed replacement, the swap is kept
+	<-done
+
+	if m.busConn() != replacement {
+		t.Error("Reconnect did not install the new connection")
+	}
+}
+
+// TestCloseWaitsForReconnect: Close waits for a reconnect in flight and stops
+// what it started, instead of closing events under a restarted listener.
+func TestCloseWaitsForReconnect(t *testing.T) {
+	defer func(d time.Duration) { connCheckInterval = d }(connCheckInterval)
+	connCheckInterval = time.Millisecond
+
+	replacement := closedConn(t)
+	dialing := make(chan struct{})
+	release := make(chan struct{})
+	var dials atomic.Int32
+	m := &MPRISBackend{
+		ctx:    context.Background(),
+		conn:   closedConn(t),
+		events: make(chan events.Event, 1),
+		connect: func(...dbus.ConnOption) (*dbus.Conn, error) {
+			if dials.Add(1) == 1 {
+				close(dialing)
+			}
+			<-release
+			return replacement, nil
+		},
+	}
+	m.startWatch()
+	<-dialing
+
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		m.Close()
+	}()
+	select {
+	case <-closed:
+		t.Fatal("Close returned with a reconnect in flight")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	<-closed
+
+	if n := dials.Load(); n != 1 {
+		t.Errorf("connect attempts = %d, want no retry after Close", n)
+	}
+	if m.listener.Load() != nil || m.heartbeat.Load() != nil {
+		t.Error("Close left the listener or the heartbeat set")
+	}
+}
+
+func TestReloadPlayersFailureKeepsCache(t *testing.T) {
+	m := &MPRISBackend{ctx: context.Background(), conn: closedConn(t)}
+	m.players.Store([]Player{{BusName: "org.mpris.MediaPlayer2.spotify"}})
+
+	if _, err := m.ReloadPlayers(); err == nil {
+		t.Fatal("ReloadPlayers() error = nil on a closed connection")
+	}
+	if players := m.players.Load(); len(players) != 1 {
+		t.Errorf("players = %v, want the cache kept on a failed reload", players)
+	}
+	// playersMu is released: writers go on
+	if !m.updatePlayers(func(p []Player) []Player { return p }) {
+		t.Error("updatePlayers() = false after a failed reload")
+	}
+}
+
+func TestRetryWithBackoff(t *testing.T) {
+	calls := 0
+	ok := retryWithBackoff(context.Background(), time.Millisecond, 2*time.Millisecond, func() error {
+		calls++
+		if calls < 3 {
+			return errors.New("down")
+		}
+		return nil
+	})
+	if !ok || calls != 3 {
+		t.Errorf("retryWithBackoff() = %v after %d calls, want true after 3", ok, calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if retryWithBackoff(ctx, time.Hour, time.Hour, func() error { return errors.New("down") }) {
+		t.Error("retryWithBackoff() = true with a cancelled context and a failing fn")
+	}
+}
+
+func TestFullscreen(t *testing.T) {
+	const busName = "org.mpris.MediaPlayer2.kodi"
+
+	t.Run("capability loaded from the root interface", func(t *testing.T) {
+		p := &Player{}
+		caps := p.loadCapabilitiesFromProps(
+			map[string]dbus.Variant{"CanPlay": dbus.MakeVariant(true)},
+			map[string]dbus.Variant{"CanSetFullscreen": dbus.MakeVariant(true), "CanQuit": dbus.MakeVariant(true)},
+		)
+		if !caps.CanPlay || !caps.CanSetFullscreen {
+			t.Errorf("capabilities = %+v, want CanPlay and CanSetFullscreen", caps)
+		}
+	})
+
+	t.Run("SetFullscreen without the capability", func(t *testing.T) {
+		b := &MPRISBackend{}
+		b.players.Store([]Player{{BusName: busName}})
+		var capErr *CapabilityError
+		if err := b.SetFullscreen(busName, true); !errors.As(err, &capErr) || capErr.Required != "CanSetFullscreen" {
+			t.Errorf("SetFullscreen() error = %v, want CapabilityError{CanSetFullscreen}", err)
+		}
+	})
+
+	t.Run("root interface changes update the cache", func(t *testing.T) {
+		b := &MPRISBackend{events: make(chan events.Event, 4)}
+		b.players.Store([]Player{{BusName: busName}})
+		err := b.UpdatePlayerProperties(busName, map[string]dbus.Variant{
+			"Fullscreen":       dbus.MakeVariant(true),
+			"CanSetFullscreen": dbus.MakeVariant(true),
+		})
+		if err != nil {
+			t.Fatalf("UpdatePlayerProperties() error = %v", err)
+		}
+		p, _ := b.GetPlayerFromCache(busName)
+		if !p.Fullscreen || !p.Capabilities.CanSetFullscreen {
+			t.Errorf("player = fullscreen %v, can_set_fullscreen %v, want both true", p.Fullscreen, p.Capabilities.CanSetFullscreen)
+		}
+	})
+}
+
+func TestSetRatingValidation(t *testing.T) {
+	const rated, unrated = "org.mpris.MediaPlayer2.rhythmbox", "org.mpris.MediaPlayer2.vlc"
+	b := &MPRISBackend{}
+	b.players.Store([]Player{
+		{BusName: rated, Metadata: map[string]string{"xesam:userRating": "0.6"}},
+		{BusName: unrated, Metadata: map[string]string{"xesam:title": "Song"}},
+	})
+
+	tests := []struct {
+		name    string
+		busName string
+		rating  float64
+	}{
+		{"above range", rated, 1.5},
+		{"below range", rated, -0.1},
+		{"player without userRating", unrated, 0.8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var validErr *ValidationError
+			if err := b.SetRating(tt.busName, tt.rating); !errors.As(err, &validErr) || validErr.Field != "rating" {
+				t.Errorf("SetRating(%s, %v) error = %v, want rating ValidationError", tt.busName, tt.rating, err)
+			}
+		})
+	}
+}
+
+func TestExtractMetadataUserRating(t *testing.T) {
+	got := extractMetadata(map[string]dbus.Variant{"xesam:userRating": dbus.MakeVariant(0.8)}, defaultMetadataKeys)
+	if got["xesam:userRating"] != "0.8" {
+		t.Errorf("xesam:userRating = %q, want %q", got["xesam:userRating"], "0.8")
+	}
+}
+
+func TestExtractMetadataPassthrough(t *testing.T) {
+	m := &MPRISBackend{metadataPassthrough: true, metadataKeys: []string{"xesam:title"}}
+	raw := map[string]dbus.Variant{
+		"xesam:title":          dbus.MakeVariant("Song"),
+		"xesam:comment":        dbus.MakeVariant([]string{"live", "remastered"}),
+		"xesam:contentCreated": dbus.MakeVariant("2019-05-01"),
+	}
+
+	got := extractMetadata(raw, m.metadataKeyList())
+	want := map[string]string{
+		"xesam:title":          "Song",
+		"xesam:comment":        "live, remastered",
+		"xesam:contentCreated": "2019-05-01",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("extractMetadata() with passthrough = %v, want %v", got, want)
+	}
+}
+
+func TestMetadataKeys(t *testing.T) {
+	if got := metadataKeys(nil); !slices.Equal(got, defaultMetadataKeys) {
+		t.Errorf("metadataKeys(nil) = %v, want the defaults", got)
+	}
+	got := metadataKeys([]string{"xesam:title", "mpris:length"})
+	want := []string{"xesam:title", "mpris:length", "xesam:userRating", "mpris:trackid", "mpris:artUrl"}
+	if !slices.Equal(got, want) {
+		t.Errorf("metadataKeys() = %v, want %v", got, want)
+	}
+	for _, key := range requiredMetadataKeys {
+		if !slices.Contains(defaultMetadataKeys, key) {
+			t.Errorf("required key %s missing from the defaults", key)
+		}
+	}
+}
+
+// TestConfiguredMetadataKeyReachesPlayer: a key added through
+// mpris.metadatakeys is kept on the cached player, and dropped otherwise.
+func TestConfiguredMetadataKeyReachesPlayer(t *testing.T) {
+	const busName = "org.mpris.MediaPlayer2.mpd"
+	changed := map[string]dbus.Variant{
+		"Metadata": dbus.MakeVariant(map[string]dbus.Variant{
+			"xesam:title":       dbus.MakeVariant("Song"),
+			"xesam:url":         dbus.MakeVariant("file:///music/song.flac"),
+			"xesam:trackNumber": dbus.MakeVariant(int32(3)),
+		}),
+	}
+
+	tests := []struct {
+		name    string
+		keys    []string
+		wantURL string
+	}{
+		{"defaults drop xesam:url", nil, ""},
+		{"configured keeps xesam:url", metadataKeys([]string{"xesam:title", "xesam:url"}), "file:///music/song.flac"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &MPRISBackend{events: make(chan events.Event, 4), metadataKeys: tt.keys}
+			b.players.Store([]Player{{BusName: busName}})
+			if err := b.UpdatePlayerProperties(busName, changed); err != nil {
+				t.Fatalf("UpdatePlayerProperties() error = %v", err)
+			}
+			p, _ := b.GetPlayerFromCache(busName)
+			if p.Metadata["xesam:url"] != tt.wantURL {
+				t.Errorf("Metadata[xesam:url] = %q, want %q", p.Metadata["xesam:url"], tt.wantURL)
+			}
+			if _, ok := p.Metadata["xesam:trackNumber"]; ok {
+				t.Error("unconfigured xesam:trackNumber should be dropped")
+			}
+		})
+	}
+}
+
+// TestApplyStateValidatesFirst: every provided field is checked before any is
+// sent, so these all fail without reaching D-Bus, each invalid field reported.
+func TestApplyStateValidatesFirst(t *testing.T) {
+	const busName = "org.mpris.MediaPlayer2.mpd"
+	b := &MPRISBackend{}
+	b.players.Store([]Player{{BusName: busName}})
+
+	volume, badVolume := 0.4, 1.4
+	loop, badLoop := "Playlist", "Forever"
+	shuffle := true
+
+	tests := []struct {
+		name       string
+		busName    string
+		req        StateRequest
+		wantFields []string
+	}{
+		{"empty body", busName, StateRequest{}, []string{""}},
+		{"volume out of range", busName, StateRequest{Volume: &badVolume, Loop: &loop, Shuffle: &shuffle}, []string{"volume"}},
+		{"unknown loop", busName, StateRequest{Volume: &volume, Loop: &badLoop}, []string{"loop"}},
+		{"both invalid", busName, StateRequest{Volume: &badVolume, Loop: &badLoop, Shuffle: &shuffle}, []string{"volume", "loop"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := b.ApplyState(tt.busName, tt.req)
+			errs := []error{err}
+			if joined, ok := err.(interface{ Unwrap() []error }); ok {
+				errs = joined.Unwrap()
+			}
+			var fields []string
+			for _, e := range errs {
+				var validErr *ValidationError
+				if !errors.As(e, &validErr) {
+					t.Fatalf("ApplyState() error = %v, want ValidationErrors", err)
+				}
+				fields = append(fields, validErr.Field)
+			}
+			if !slices.Equal(fields, tt.wantFields) {
+				t.Errorf("ApplyState() invalid fields = %q, want %q", fields, tt.wantFields)
+			}
+		})
+	}
+
+	var notFound *PlayerNotFoundError
+	if _, err := b.ApplyState("org.mpris.MediaPlayer2.gone", StateRequest{Shuffle: &shuffle}); !errors.As(err, &notFound) {
+		t.Errorf("ApplyState() on unknown player error = %v, want PlayerNotFoundError", err)
+	}
+}
+
+// TestArtworkPrefetchedOnTrackChange: a new file:// artUrl is read into the
+// artwork cache in the background, percent-encoding decoded.
+func TestArtworkPrefetchedOnTrackChange(t *testing.T) {
+	const busName = "org.mpris.MediaPlayer2.test"
+	dir := filepath.Join(t.TempDir(), "My Album")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "cover.jpg"), []byte("image-bytes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	artURL := "file://" + filepath.ToSlash(dir) + "/cover.jpg"
+	artURL = strings.ReplaceAll(artURL, " ", "%20")
+
+	b := &MPRISBackend{artworkCache: newArtworkCache(maxArtworkEntries)}
+	b.players.Store([]Player{{BusName: busName, Metadata: map[string]string{}}})
+
+	err := b.UpdatePlayerProperties(busName, map[string]dbus.Variant{
+		"Metadata": dbus.MakeVariant(map[string]dbus.Variant{
+			"mpris:trackid": dbus.MakeVariant(dbus.ObjectPath("/track/1")),
+			"mpris:artUrl":  dbus.MakeVariant(artURL),
+		}),
+	})
+	if err != nil {
+		t.Fatalf("UpdatePlayerProperties() error = %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if data, ok := b.Artwork(artURL); ok {
+			if string(data) != "image-bytes" {
+				t.Errorf("Artwork() = %q, want %q", data, "image-bytes")
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("artwork was not prefetched")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestArtworkPath(t *testing.T) {
+	tests := []struct {
+		artURL string
+		want   string
+		wantOK bool
+	}{
+		{"file:///music/cover.jpg", "/music/cover.jpg", true},
+		{"file:///music/My%20Album/cover.jpg", "/music/My Album/cover.jpg", true},
+		{"https://example.com/cover.jpg", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := ArtworkPath(tt.artURL)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ArtworkPath(%q) = %q, %v; want %q, %v", tt.artURL, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestResolveBusName(t *testing.T) {
+	b := &MPRISBackend{}
+	b.players.Store([]Player{
+		{BusName: "org.mpris.MediaPlayer2.spotify"},
+		{BusName: "org.mpris.MediaPlayer2.vlc.instance1"},
+		{BusName: "org.mpris.MediaPlayer2.vlc.instance2"},
+		{BusName: "org.mpris.MediaPlayer2.mpv.instance9"},
+	})
+
+	tests := []struct {
+		name    string
+		want    string
+		wantErr any
+	}{
+		{name: "spotify", want: "org.mpris.MediaPlayer2.spotify"},
+		{name: "org.mpris.MediaPlayer2.kodi", want: "org.mpris.MediaPlayer2.kodi"},
+		{name: "vlc.instance2", want: "org.mpris.MediaPlayer2.vlc.instance2"},
+		{name: "mpv", want: "org.mpris.MediaPlayer2.mpv.instance9"},
+		{name: "vlc", wantErr: &AmbiguousPlayerError{}},
+		{name: "kodi", wantErr: &PlayerNotFoundError{}},
+	}
+	for _, tt := range tests {
+		got, err := b.ResolveBusName(tt.name)
+		switch want := tt.wantErr.(type) {
+		case *AmbiguousPlayerError:
+			if !errors.As(err, &want) || len(want.Candidates) != 2 {
+				t.Errorf("ResolveBusName(%q) error = %v, want ambiguous with 2 candidates", tt.name, err)
+			}
+		case *PlayerNotFoundError:
+			if !errors.As(err, &want) {
+				t.Errorf("ResolveBusName(%q) error = %v, want PlayerNotFoundError", tt.name, err)
+			}
+		default:
+			if err != nil || got != tt.want {
+				t.Errorf("ResolveBusName(%q) = %q, %v; want %q", tt.name, got, err, tt.want)
+			}
+		}
+	}
+}
+
+func TestArtworkRewrittenFileIsReadAgain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cover.jpg")
+	if err := os.WriteFile(path, []byte("first"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	artURL := "file://" + filepath.ToSlash(path)
+	b := &MPRISBackend{artworkCache: newArtworkCache(maxArtworkEntries)}
+
+	b.prefetchArtwork(artURL)
+	if data, ok := b.Artwork(artURL); !ok || string(data) != "first" {
+		t.Fatalf("Artwork() = %q, %v; want first", data, ok)
+	}
+
+	// same path, new content: a player rewriting a fixed cover file
+	if err := os.WriteFile(path, []byte("second!"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if data, ok := b.Artwork(artURL); ok {
+		t.Errorf("Artwork() = %q after a rewrite, want a miss", data)
+	}
+	b.prefetchArtwork(artURL)
+	if data, ok := b.Artwork(artURL); !ok || string(data) != "second!" {
+		t.Errorf("Artwork() = %q, %v; want second!", data, ok)
+	}
+}
+
+func TestArtworkCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newArtworkCache(2)
+	a, b, d := artworkKey{path: "/a"}, artworkKey{path: "/b"}, artworkKey{path: "/d"}
+	c.set(a, []byte("a"))
+	c.set(b, []byte("b"))
+	c.get(a) // a is now the most recently used
+	c.set(d, []byte("d"))
+
+	if _, ok := c.get(b); ok {
+		t.Error("least recently used entry was not evicted")
+	}
+	for _, key := range []artworkKey{a, d} {
+		if _, ok := c.get(key); !ok {
+			t.Errorf("entry %s evicted, want it kept", key.path)
+		}
+	}
+	if c.order.Len() != 2 || len(c.entries) != 2 {
+		t.Errorf("cache holds %d/%d entries, want 2", c.order.Len(), len(c.entries))
+	}
+}
@@ -3,6 +3,7 @@ package mpris
 import (
 	"fmt"
 	"reflect"
+	"slices"
 	"time"
 
 	"github.com/godbus/dbus/v5"
@@ -14,8 +15,8 @@ import (
 func newPlayer(backend *MPRISBackend, busName string) *Player {
 	return &Player{
 		backend: backend,
-		conn:    backend.conn,
-		timeout: backend.timeout,
+		conn:    backend.busConn(),
+		timeout: backend.timeouts.List,
 		BusName: busName,
 	}
 }
@@ -52,6 +53,11 @@ func (p *Player) CanControl() bool {
 	return p.Capabilities.CanControl
 }
 
+// CanSetFullscreen checks if the player can toggle fullscreen
+func (p *Player) CanSetFullscreen() bool {
+	return p.Capabilities.CanSetFullscreen
+}
+
 // loadFromDBus loads all player properties from D-Bus.
 // This private function performs the necessary D-Bus calls to fill all Player fields
 // using GetAll (2 calls) instead of individual Get calls (~15 calls).
@@ -150,13 +156,13 @@ func (p *Player) loadFromDBus() error {
 		case reflect.Map:
 			// Special case for Metadata
 			if dbusTag == "Metadata" {
-				field.Set(reflect.ValueOf(extractMetadata(variant.Value())))
+				field.Set(reflect.ValueOf(extractMetadata(variant.Value(), p.backend.metadataKeyList())))
 			}
 		}
 	}
 
 	// Load capabilities from already retrieved properties
-	p.Capabilities = p.loadCapabilitiesFromProps(propsPlayer)
+	p.Capabilities = p.loadCapabilitiesFromProps(propsPlayer, propsMediaPlayer2)
 
 	p.loadTracklist()
 
@@ -197,17 +203,17 @@ func (p *Player) loadTracklist() {
 		p.Tracklist = tracksFromIDs(ids)
 		return
 	}
-	p.Tracklist = tracksFromMetadata(ids, metas)
+	p.Tracklist = tracksFromMetadata(ids, metas, p.backend.metadataKeyList())
 }
 
 // tracksFromMetadata zips ordered track IDs with their metadata.
 // TrackID always comes from ids so players omitting mpris:trackid still work.
-func tracksFromMetadata(ids []dbus.ObjectPath, metas []map[string]dbus.Variant) []Track {
+func tracksFromMetadata(ids []dbus.ObjectPath, metas []map[string]dbus.Variant, keys []string) []Track {
 	tracks := make([]Track, len(ids))
 	for i, id := range ids {
 		tracks[i] = Track{TrackID: string(id)}
 		if i < len(metas) {
-			tracks[i].Metadata = extractMetadata(metas[i])
+			tracks[i].Metadata = extractMetadata(metas[i], keys)
 		}
 	}
 	return tracks
@@ -223,8 +229,8 @@ func tracksFromIDs(ids []dbus.ObjectPath) []Track {
 
 // trackFromSignalMetadata builds a Track from signal-provided metadata,
 // where the ID can only come from mpris:trackid.
-func trackFromSignalMetadata(meta map[string]dbus.Variant) Track {
-	track := Track{Metadata: extractMetadata(meta)}
+func trackFromSignalMetadata(meta map[string]dbus.Variant, keys []string) Track {
+	track := Track{Metadata: extractMetadata(meta, keys)}
 	if v, ok := meta["mpris:trackid"]; ok {
 		if id, ok := v.Value().(dbus.ObjectPath); ok {
 			track.TrackID = string(id)
@@ -251,17 +257,58 @@ func (c *Capabilities) setFromProp(name string, variant dbus.Variant) {
 }
 
 // loadCapabilitiesFromProps loads capabilities from already retrieved
-// properties, avoiding additional D-Bus calls.
-func (p *Player) loadCapabilitiesFromProps(props map[string]dbus.Variant) Capabilities {
+// properties of one or more interfaces, avoiding additional D-Bus calls.
+func (p *Player) loadCapabilitiesFromProps(ifaceProps ...map[string]dbus.Variant) Capabilities {
 	var caps Capabilities
-	for name, variant := range props {
-		caps.setFromProp(name, variant)
+	for _, props := range ifaceProps {
+		for name, variant := range props {
+			caps.setFromProp(name, variant)
+		}
 	}
 	return caps
 }
 
-// extractMetadata extracts relevant metadata
-func extractMetadata(raw interface{}) map[string]string {
+// defaultMetadataKeys are the metadata entries kept when mpris.metadatakeys
+// is not set.
+var defaultMetadataKeys = []string{
+	"xesam:title",
+	"xesam:artist",
+	"xesam:album",
+	"xesam:albumArtist",
+	"xesam:genre",
+	"xesam:userRating",
+	"mpris:trackid",
+	"mpris:artUrl",
+	"mpris:length",
+}
+
+// requiredMetadataKeys are always kept: position tracking, track change
+// detection, artwork and SetRating read them.
+var requiredMetadataKeys = []string{
+	"xesam:userRating",
+	"mpris:trackid",
+	"mpris:artUrl",
+	"mpris:length",
+}
+
+// metadataKeys returns the configured keys plus the required ones, or the
+// defaults when none are configured.
+func metadataKeys(configured []string) []string {
+	if len(configured) == 0 {
+		return defaultMetadataKeys
+	}
+	keys := slices.Clone(configured)
+	for _, key := range requiredMetadataKeys {
+		if !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// extractMetadata keeps the given metadata keys, formatted as strings; nil
+// keys keep every entry (mpris.metadata_passthrough).
+func extractMetadata(raw interface{}, keys []string) map[string]string {
 	metadata := make(map[string]string)
 
 	m, ok := raw.(map[string]dbus.Variant)
@@ -269,19 +316,13 @@ func extractMetadata(raw interface{}) map[string]string {
 		return metadata
 	}
 
-	// Extract common metadata
-	metadataKeys := []string{
-		"xesam:title",
-		"xesam:artist",
-		"xesam:album",
-		"xesam:albumArtist",
-		"xesam:genre",
-		"mpris:trackid",
-		"mpris:artUrl",
-		"mpris:length",
+	if keys == nil {
+		for key, v := range m {
+			metadata[key] = formatMetadataValue(v.Value())
+		}
+		return metadata
 	}
-
-	for _, key := range metadataKeys {
+	for _, key := range keys {
 		if v, ok := m[key]; ok {
 			metadata[key] = formatMetadataValue(v.Value())
 		}
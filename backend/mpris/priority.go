@@ -0,0 +1,95 @@
+package mpris
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/b0bbywan/go-odio-api/logger"
+)
+
+// loadPriority restores the persisted priorities. A missing file is the
+// normal first-run case; an unreadable one starts from no priorities.
+func (m *MPRISBackend) loadPriority() {
+	m.priority = make(map[string]int)
+	if m.priorityFile == "" {
+		return
+	}
+	data, err := os.ReadFile(m.priorityFile)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			logger.Warn("[mpris] cannot read player priorities %s: %v", m.priorityFile, err)
+		}
+		return
+	}
+	var priority map[string]int
+	if err := json.Unmarshal(data, &priority); err != nil {
+		logger.Warn("[mpris] player priorities %s invalid, ignoring: %v", m.priorityFile, err)
+		return
+	}
+	if priority != nil {
+		m.priority = priority
+	}
+	logger.Debug("[mpris] restored %d player priorities from %s", len(m.priority), m.priorityFile)
+}
+
+// savePriority writes the priorities atomically. Caller holds priorityMu.
+func (m *MPRISBackend) savePriority() error {
+	if m.priorityFile == "" {
+		return nil
+	}
+	data, err := json.Marshal(m.priority)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(m.priorityFile), 0o700); err != nil {
+		return err
+	}
+	tmp := m.priorityFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, m.priorityFile)
+}
+
+// SetPriority assigns a sort priority to a player (higher = earlier in
+// ListPlayers). The player need not be running: priorities outlive players.
+// A zero priority drops the entry.
+func (m *MPRISBackend) SetPriority(busName string, priority int) error {
+	if err := validateBusName(busName); err != nil {
+		return err
+	}
+
+	m.priorityMu.Lock()
+	defer m.priorityMu.Unlock()
+	if m.priority == nil {
+		m.priority = make(map[string]int)
+	}
+	if priority == 0 {
+		delete(m.priority, busName)
+	} else {
+		m.priority[busName] = priority
+	}
+	logger.Debug("[mpris] priority of %s set to %d", busName, priority)
+	return m.savePriority()
+}
+
+// sortByPriority returns players ordered by priority descending, keeping the
+// D-Bus order among equals. The cached snapshot is immutable, so it sorts a copy.
+func (m *MPRISBackend) sortByPriority(players []Player) []Player {
+	m.priorityMu.RLock()
+	defer m.priorityMu.RUnlock()
+	if len(m.priority) == 0 {
+		return players
+	}
+
+	sorted := slices.Clone(players)
+	slices.SortStableFunc(sorted, func(a, b Player) int {
+		return cmp.Compare(m.priority[b.BusName], m.priority[a.BusName])
+	})
+	return sorted
+}
@@ -0,0 +1,144 @@
+package mpris
+
+import (
+	"context"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+
+	"github.com/b0bbywan/go-odio-api/logger"
+)
+
+const (
+	reconnectBackoff    = time.Second
+	reconnectMaxBackoff = 30 * time.Second
+)
+
+// connCheckInterval is how often the connection watcher polls the bus; a
+// variable so tests can shorten it.
+var connCheckInterval = 2 * time.Second
+
+// Connected reports whether the session bus connection is still alive. It
+// drops when the bus goes away, e.g. when the user session restarts.
+func (m *MPRISBackend) Connected() bool {
+	conn := m.busConn()
+	return conn != nil && conn.Connected()
+}
+
+// busConn returns the current session bus connection; Reconnect replaces it
+// while requests and the listener use it.
+func (m *MPRISBackend) busConn() *dbus.Conn {
+	m.connMu.RLock()
+	defer m.connMu.RUnlock()
+	return m.conn
+}
+
+// startWatch runs watchConnection until Close stops it.
+func (m *MPRISBackend) startWatch() {
+	ctx, cancel := context.WithCancel(m.ctx)
+	done := make(chan struct{})
+	m.stopWatch, m.watchDone = cancel, done
+	go func() {
+		defer close(done)
+		m.watchConnection(ctx)
+	}()
+}
+
+// watchConnection polls the session bus connection and reconnects, with
+// capped backoff, once it is lost, until ctx ends.
+func (m *MPRISBackend) watchConnection(ctx context.Context) {
+	ticker := time.NewTicker(connCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if m.Connected() {
+				continue
+			}
+			logger.Warn("[mpris] session bus connection lost, reconnecting")
+			if !retryWithBackoff(ctx, reconnectBackoff, reconnectMaxBackoff, m.Reconnect) {
+				return // stopped: a ready tick must not start another round
+			}
+			logger.Info("[mpris] reconnected")
+		}
+	}
+}
+
+// Reconnect opens a new session bus connection, then restarts the listener
+// and the heartbeat on it and reloads the player cache. On failure the dead
+// connection and the last known players are kept: calls keep failing with an
+// error instead of dereferencing a nil connection.
+func (m *MPRISBackend) Reconnect() error {
+	// Stopped but not cleared: a signal still being handled may reach the
+	// heartbeat, whose Start is a no-op once stopped. start replaces both.
+	if l := m.listener.Load(); l != nil {
+		l.Stop()
+	}
+	if h := m.heartbeat.Load(); h != nil {
+		h.Stop()
+	}
+
+	conn, err := m.connect()
+	if err != nil {
+		return err
+	}
+	m.connMu.Lock()
+	dead := m.conn
+	m.conn = conn
+	m.connMu.Unlock()
+	if dead != nil {
+		if err := dead.Close(); err != nil {
+			logger.Debug("[mpris] failed to close dead D-Bus connection: %v", err)
+		}
+	}
+	m.players.Reset()
+	return m.start()
+}
+
+// dialBus opens a session bus connection with connect, giving up when ctx
+// ends: the dial itself takes no context, so it runs aside and a connection
+// it completes too late is closed.
+func dialBus(ctx context.Context, connect func(...dbus.ConnOption) (*dbus.Conn, error)) (*dbus.Conn, error) {
+	type result struct {
+		conn *dbus.Conn
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		conn, err := connect()
+		done <- result{conn, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.conn, r.err
+	case <-ctx.Done():
+		go func() {
+			if r := <-done; r.conn != nil {
+				_ = r.conn.Close()
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
+
+// retryWithBackoff calls fn until it succeeds, doubling the wait between
+// attempts up to maxBackoff. Returns false if ctx ends first.
+func retryWithBackoff(ctx context.Context, backoff, maxBackoff time.Duration, fn func() error) bool {
+	for {
+		err := fn()
+		if err == nil {
+			return true
+		}
+		logger.Warn("[mpris] reconnect failed: %v, retry in %s", err, backoff)
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
@@ -0,0 +1,76 @@
+package mpris
+
+import (
+	"slices"
+
+	"github.com/b0bbywan/go-odio-api/logger"
+)
+
+// SubscribePlayer registers a subscriber to busName's property changes and
+// returns its dedicated channel (buffered, size 8). It receives the player
+// after every cache update and is closed when the player leaves the cache.
+func (m *MPRISBackend) SubscribePlayer(busName string) chan Player {
+	ch := make(chan Player, 8)
+	m.subscribersMu.Lock()
+	if m.subscribers == nil {
+		m.subscribers = make(map[string][]chan Player)
+	}
+	m.subscribers[busName] = append(m.subscribers[busName], ch)
+	m.subscribersMu.Unlock()
+	return ch
+}
+
+// UnsubscribePlayer removes a subscriber and closes its channel, unless the
+// player's removal already did.
+func (m *MPRISBackend) UnsubscribePlayer(busName string, ch chan Player) {
+	m.subscribersMu.Lock()
+	defer m.subscribersMu.Unlock()
+	subs := m.subscribers[busName]
+	i := slices.Index(subs, ch)
+	if i < 0 {
+		return
+	}
+	if subs = slices.Delete(subs, i, i+1); len(subs) == 0 {
+		delete(m.subscribers, busName)
+	} else {
+		m.subscribers[busName] = subs
+	}
+	close(ch)
+}
+
+// publishPlayer hands p to its subscribers without blocking: a subscriber
+// whose buffer is full misses this update, the next one carries the full
+// state anyway.
+func (m *MPRISBackend) publishPlayer(p Player) {
+	m.subscribersMu.Lock()
+	defer m.subscribersMu.Unlock()
+	for _, ch := range m.subscribers[p.BusName] {
+		select {
+		case ch <- p:
+		default:
+			logger.Debug("[mpris] subscriber of %s is full, dropping update", p.BusName)
+		}
+	}
+}
+
+// closeSubscribers closes and forgets every subscriber of busName.
+func (m *MPRISBackend) closeSubscribers(busName string) {
+	m.subscribersMu.Lock()
+	defer m.subscribersMu.Unlock()
+	for _, ch := range m.subscribers[busName] {
+		close(ch)
+	}
+	delete(m.subscribers, busName)
+}
+
+// closeAllSubscribers closes every subscriber, on backend shutdown.
+func (m *MPRISBackend) closeAllSubscribers() {
+	m.subscribersMu.Lock()
+	defer m.subscribersMu.Unlock()
+	for _, subs := range m.subscribers {
+		for _, ch := range subs {
+			close(ch)
+		}
+	}
+	m.subscribers = nil
+}
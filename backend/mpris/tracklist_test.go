@@ -25,7 +25,7 @@ func TestTracksFromMetadata(t *testing.T) {
 		},
 	}
 
-	tracks := tracksFromMetadata(ids, metas)
+	tracks := tracksFromMetadata(ids, metas, defaultMetadataKeys)
 
 	if len(tracks) != 3 {
 		t.Fatalf("len(tracks) = %d, want 3", len(tracks))
@@ -108,7 +108,7 @@ func TestTrackFromSignalMetadata(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			track := trackFromSignalMetadata(tt.meta)
+			track := trackFromSignalMetadata(tt.meta, defaultMetadataKeys)
 			if track.TrackID != tt.wantTrackID {
 				t.Errorf("TrackID = %q, want %q", track.TrackID, tt.wantTrackID)
 			}
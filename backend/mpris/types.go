@@ -3,14 +3,52 @@ package mpris
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/godbus/dbus/v5"
 
 	"github.com/b0bbywan/go-odio-api/cache"
+	"github.com/b0bbywan/go-odio-api/config"
 	"github.com/b0bbywan/go-odio-api/events"
 )
 
+// Control action names accepted in mpris.allowedactions.
+const (
+	ActionPlay       = "play"
+	ActionPause      = "pause"
+	ActionPlayPause  = "play_pause"
+	ActionStop       = "stop"
+	ActionNext       = "next"
+	ActionPrevious   = "previous"
+	ActionSeek       = "seek"
+	ActionPosition   = "position"
+	ActionVolume     = "volume"
+	ActionLoop       = "loop"
+	ActionShuffle    = "shuffle"
+	ActionFullscreen = "fullscreen"
+	ActionRating     = "rating"
+	ActionTracklist  = "tracklist" // goto, add and remove
+)
+
+// SupportedActions lists every action name mpris.allowedactions may use.
+var SupportedActions = []string{
+	ActionPlay,
+	ActionPause,
+	ActionPlayPause,
+	ActionStop,
+	ActionNext,
+	ActionPrevious,
+	ActionSeek,
+	ActionPosition,
+	ActionVolume,
+	ActionLoop,
+	ActionShuffle,
+	ActionFullscreen,
+	ActionRating,
+	ActionTracklist,
+}
+
 // PlaybackStatus represents the current playback state
 type PlaybackStatus string
 
@@ -19,22 +57,60 @@ type LoopStatus string
 
 // MPRISBackend manages connections to media players via MPRIS
 type MPRISBackend struct {
-	conn    *dbus.Conn
-	ctx     context.Context
-	timeout time.Duration
+	conn     *dbus.Conn
+	connMu   sync.RWMutex                                 // guards conn, replaced on Reconnect
+	connect  func(...dbus.ConnOption) (*dbus.Conn, error) // opens the session bus, replaced in tests
+	ctx      context.Context
+	timeouts config.MPRISTimeoutConfig
 
 	// Players cache: readers take lock-free immutable snapshots (nil = never
 	// loaded); writers copy-on-write, serialized through updatePlayers.
 	players   cache.Value[[]Player]
 	playersMu sync.Mutex
 
-	// listener for MPRIS changes
-	listener *Listener
+	// listener for MPRIS changes, replaced on Reconnect
+	listener atomic.Pointer[Listener]
+
+	// Per-player subscribers keyed by bus name, fed after each cache update
+	// and closed when the player is removed.
+	subscribers   map[string][]chan Player
+	subscribersMu sync.Mutex
 
-	// heartbeat to update Position of playing players
-	heartbeat *Heartbeat
+	// heartbeat to update Position of playing players, replaced on Reconnect
+	heartbeat atomic.Pointer[Heartbeat]
+
+	// Connection watcher started by Start, stopped and awaited by Close
+	// before events is closed: a reconnect in flight would restart the
+	// listener on it.
+	stopWatch context.CancelFunc
+	watchDone chan struct{}
 
 	events chan events.Event
+
+	// User-assigned sort priorities keyed by bus name (higher = earlier in
+	// ListPlayers), persisted to priorityFile.
+	priority     map[string]int
+	priorityMu   sync.RWMutex
+	priorityFile string
+
+	// Control actions the API may trigger; nil allows all of them.
+	allowedActions map[string]bool
+
+	// Metadata entries kept on players and tracks (mpris.metadatakeys).
+	metadataKeys []string
+	// Keep every metadata entry instead (mpris.metadata_passthrough).
+	metadataPassthrough bool
+
+	// Last RefreshPlayer call per bus name (time.Time), for rate limiting.
+	refreshed sync.Map
+
+	// Local artwork bytes, prefetched on track change; keyed by path, mtime
+	// and size so rewritten cover files are read again.
+	artworkCache *artworkCache
+
+	// Removed players keyed by bus name, kept goneTTL as tombstones for
+	// GET /players?include_gone=true.
+	gone *cache.Cache[Player]
 }
 
 // Listener listens to MPRIS changes via D-Bus signals
@@ -52,7 +128,7 @@ type Listener struct {
 type Player struct {
 	backend    *MPRISBackend // Parent backend (not exported)
 	conn       *dbus.Conn    // D-Bus connection (not exported)
-	timeout    time.Duration // Timeout for D-Bus calls (not exported)
+	timeout    time.Duration // Timeout for D-Bus calls, mpris.timeouts.list (not exported)
 	uniqueName string        // Unique D-Bus connection name (e.g., :1.107)
 
 	BusName string `json:"bus_name"`
@@ -62,6 +138,7 @@ type Player struct {
 	PlaybackStatus      PlaybackStatus    `json:"playback_status" dbus:"PlaybackStatus" iface:"org.mpris.MediaPlayer2.Player"`
 	LoopStatus          LoopStatus        `json:"loop_status,omitempty" dbus:"LoopStatus" iface:"org.mpris.MediaPlayer2.Player"`
 	Shuffle             bool              `json:"shuffle,omitempty" dbus:"Shuffle" iface:"org.mpris.MediaPlayer2.Player"`
+	Fullscreen          bool              `json:"fullscreen,omitempty" dbus:"Fullscreen" iface:"org.mpris.MediaPlayer2"`
 	Volume              *float64          `json:"volume,omitempty" dbus:"Volume" iface:"org.mpris.MediaPlayer2.Player"`
 	Position            int64             `json:"position,omitempty" dbus:"Position" iface:"org.mpris.MediaPlayer2.Player"`
 	PositionUpdatedAt   time.Time         `json:"position_updated_at"`
@@ -74,6 +151,11 @@ type Player struct {
 	TracklistSupported bool    `json:"tracklist_supported"`
 	CanEditTracks      bool    `json:"-"`
 	Tracklist          []Track `json:"-"` // served by the dedicated /tracklist endpoint
+
+	// LastSeen is when the player last reported live data; Gone marks a
+	// removed player served from the tombstones.
+	LastSeen time.Time `json:"last_seen"`
+	Gone     bool      `json:"gone,omitempty"`
 }
 
 // Track represents an entry in a player's tracklist
@@ -90,6 +172,8 @@ type Capabilities struct {
 	CanGoPrevious bool `json:"can_go_previous" dbus:"CanGoPrevious"`
 	CanSeek       bool `json:"can_seek" dbus:"CanSeek"`
 	CanControl    bool `json:"can_control" dbus:"CanControl"`
+	// From the root interface, unlike the others
+	CanSetFullscreen bool `json:"can_set_fullscreen" dbus:"CanSetFullscreen"`
 }
 
 type positionUpdate struct {
@@ -98,6 +182,33 @@ type positionUpdate struct {
 	emittedAt int64
 }
 
+// PositionInfo is a player's playback progress. Position and Length are in
+// microseconds (Length is 0 when the track has no mpris:length); Rate and
+// Status let pollers extrapolate between calls and stop once playback ends.
+type PositionInfo struct {
+	Position int64          `json:"position"`
+	Length   int64          `json:"length"`
+	Rate     float64        `json:"rate"`
+	Status   PlaybackStatus `json:"status"`
+}
+
+// PlayerStatus is the playback state subset of a player, for clients that
+// only render transport controls. Position is extrapolated like PositionInfo.
+type PlayerStatus struct {
+	PlaybackStatus PlaybackStatus `json:"playback_status"`
+	Volume         *float64       `json:"volume,omitempty"`
+	Position       int64          `json:"position"`
+	LoopStatus     LoopStatus     `json:"loop_status,omitempty"`
+}
+
+// PlayerFilter narrows ListPlayers results; empty fields match everything.
+// Artist and Album are case-insensitive substring matches on metadata.
+type PlayerFilter struct {
+	Status string
+	Artist string
+	Album  string
+}
+
 // Request types for the API
 
 type SeekRequest struct {
@@ -113,6 +224,25 @@ type VolumeRequest struct {
 	Volume float64 `json:"volume"`
 }
 
+// StateRequest is a partial player state for ApplyState; nil fields are left
+// untouched.
+type StateRequest struct {
+	Volume  *float64 `json:"volume,omitempty"`
+	Loop    *string  `json:"loop,omitempty"`
+	Shuffle *bool    `json:"shuffle,omitempty"`
+}
+
+// FieldResult is the outcome of applying one StateRequest field.
+type FieldResult struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+type StateResult struct {
+	Player *Player                `json:"player"`
+	Fields map[string]FieldResult `json:"fields"`
+}
+
 type LoopRequest struct {
 	Loop string `json:"loop"`
 }
@@ -121,6 +251,18 @@ type ShuffleRequest struct {
 	Shuffle bool `json:"shuffle"`
 }
 
+type FullscreenRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+type RatingRequest struct {
+	Rating float64 `json:"rating"`
+}
+
+type PriorityRequest struct {
+	Priority int `json:"priority"`
+}
+
 type TracklistResponse struct {
 	CanEditTracks bool    `json:"can_edit_tracks"`
 	Tracks        []Track `json:"tracks"`
@@ -27,3 +27,11 @@ type DisabledError struct {
 func (e *DisabledError) Error() string {
 	return e.Feature + " is disabled"
 }
+
+// BackendUnavailableError indicates that the audio server connection is down,
+// e.g. while the backend is reconnecting after a server restart.
+type BackendUnavailableError struct{}
+
+func (e *BackendUnavailableError) Error() string {
+	return "audio server unavailable, reconnecting"
+}
@@ -27,8 +27,12 @@ func NewListener(backend *PulseAudioBackend) *Listener {
 
 // Start starts listening for pulseaudio events
 func (l *Listener) Start() error {
+	client, err := l.backend.currentClient()
+	if err != nil {
+		return err
+	}
 	// Subscribe to sink, sink input and server changes
-	updates, err := l.backend.client.UpdatesByType(pulseaudio.SUBSCRIPTION_MASK_SINK | pulseaudio.SUBSCRIPTION_MASK_SINK_INPUT | pulseaudio.SUBSCRIPTION_MASK_SERVER)
+	updates, err := client.UpdatesByType(pulseaudio.SUBSCRIPTION_MASK_SINK | pulseaudio.SUBSCRIPTION_MASK_SINK_INPUT | pulseaudio.SUBSCRIPTION_MASK_SERVER)
 	if err != nil {
 		return err
 	}
@@ -69,6 +73,7 @@ func (l *Listener) listen(updates <-chan struct{}) {
 			logger.Debug("[pulseaudio] client diff: %d changed, %d removed", len(changed), len(removed))
 			if len(changed) > 0 {
 				l.backend.notify(events.Event{Type: events.TypeAudioUpdated, Data: changed})
+				l.backend.normalizeNewClients(oldClients, changed)
 			}
 			if len(removed) > 0 {
 				l.backend.notify(events.Event{Type: events.TypeAudioRemoved, Data: removed})
@@ -0,0 +1,56 @@
+package pulseaudio
+
+import (
+	"fmt"
+
+	"github.com/b0bbywan/go-odio-api/logger"
+)
+
+const loopbackModule = "module-loopback"
+
+// CreateLoopback routes a source into a sink through module-loopback, e.g. a
+// bluetooth microphone to a speaker, and returns the loaded module index.
+// Both must exist: an unknown name returns a NotFoundError rather than letting
+// the server fall back to its defaults.
+func (pa *PulseAudioBackend) CreateLoopback(source, sink string) (uint32, error) {
+	client, err := pa.awaitConnected()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := findSourceByName(client, source); err != nil {
+		return 0, err
+	}
+	if _, err := findSinkByName(client, sink); err != nil {
+		return 0, err
+	}
+
+	index, err := client.LoadModule(loopbackModule, loopbackArgument(source, sink))
+	if err != nil {
+		return 0, fmt.Errorf("failed to load %s: %w", loopbackModule, err)
+	}
+	logger.Info("[pulseaudio] loopback %s -> %s loaded as module %d", source, sink, index)
+	return index, nil
+}
+
+// RemoveLoopback unloads a loopback module. Only module-loopback indexes are
+// accepted, so the API cannot be used to unload arbitrary server modules.
+func (pa *PulseAudioBackend) RemoveLoopback(moduleIndex uint32) error {
+	client, err := pa.awaitConnected()
+	if err != nil {
+		return err
+	}
+	if _, err := findModule(client, moduleIndex, loopbackModule); err != nil {
+		return err
+	}
+	if err := client.UnloadModule(moduleIndex); err != nil {
+		return fmt.Errorf("failed to unload module %d: %w", moduleIndex, err)
+	}
+	logger.Info("[pulseaudio] loopback module %d unloaded", moduleIndex)
+	return nil
+}
+
+// loopbackArgument builds the module-loopback arguments; the values are
+// quoted, which extractModuleSource strips when reading them back.
+func loopbackArgument(source, sink string) string {
+	return fmt.Sprintf(`source="%s" sink="%s"`, source, sink)
+}
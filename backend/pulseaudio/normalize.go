@@ -0,0 +1,73 @@
+package pulseaudio
+
+import (
+	"fmt"
+
+	"github.com/b0bbywan/go-odio-api/logger"
+)
+
+// NormalizeClientVolume brings a client's effective level to target. Without
+// peak metering the level is approximated as the client volume scaled by its
+// sink volume, so the client volume is set to target divided by the sink
+// volume (capped at 100%): a notification on a loud sink ends up quieter than
+// the same target on a quiet one.
+func (pa *PulseAudioBackend) NormalizeClientVolume(name string, target float32) error {
+	if target <= 0 || target > 1 {
+		return fmt.Errorf("normalize target must be between 0 (excluded) and 1, got %.2f", target)
+	}
+	client, err := pa.awaitConnected()
+	if err != nil {
+		return err
+	}
+	input, err := findSinkInput(client, name)
+	if err != nil {
+		return err
+	}
+
+	sinks, err := client.Sinks()
+	if err != nil {
+		return fmt.Errorf("failed to list sinks: %w", err)
+	}
+	sinkVolume := float32(1)
+	for _, s := range sinks {
+		if s.Index == input.Sink {
+			sinkVolume = s.GetVolume()
+			break
+		}
+	}
+
+	vol := normalizedVolume(target, sinkVolume)
+	logger.Debug("[pulseaudio] normalizing client %q to %.2f (target %.2f, sink at %.2f)", name, vol, target, sinkVolume)
+	return input.SetVolume(vol)
+}
+
+// normalizedVolume is the client volume whose product with sinkVolume is
+// target. A muted-to-zero sink leaves nothing to compensate, so target is
+// used as is.
+func normalizedVolume(target, sinkVolume float32) float32 {
+	if sinkVolume <= 0 {
+		return target
+	}
+	return min(target/sinkVolume, 1)
+}
+
+// normalizeNewClients normalizes the clients of changed missing from old,
+// i.e. streams that just appeared, when pulseaudio.auto_normalize is on.
+// Failures are logged: a stream may be gone again before it is reached.
+func (pa *PulseAudioBackend) normalizeNewClients(old, changed []AudioClient) {
+	if !pa.autoNormalize {
+		return
+	}
+	known := make(map[string]struct{}, len(old))
+	for _, c := range old {
+		known[c.Name] = struct{}{}
+	}
+	for _, c := range changed {
+		if _, ok := known[c.Name]; ok {
+			continue
+		}
+		if err := pa.NormalizeClientVolume(c.Name, pa.normalizeTarget); err != nil {
+			logger.Warn("[pulseaudio] failed to auto-normalize client %q: %v", c.Name, err)
+		}
+	}
+}
@@ -2,9 +2,11 @@ package pulseaudio
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -26,11 +28,24 @@ func New(ctx context.Context, cfg *config.PulseAudioConfig) (*PulseAudioBackend,
 	}
 
 	address := fmt.Sprintf("%s/pulse/native", cfg.XDGRuntimeDir)
+	if cfg.Socket != "" {
+		if err := checkSocket(cfg.Socket); err != nil {
+			return nil, fmt.Errorf("pulseaudio.socket: %w", err)
+		}
+		address = cfg.Socket
+	}
 
 	backend := &PulseAudioBackend{
 		address:     address,
 		serveCookie: cfg.ServeCookie,
 		ctx:         ctx,
+
+		startupTimeout: cfg.StartupTimeout,
+
+		autoNormalize:   cfg.AutoNormalize,
+		normalizeTarget: cfg.NormalizeTarget,
+		startupVolume:   cfg.StartupVolume,
+
 		cache:       cache.New[[]AudioClient](0),
 		outputCache: cache.New[[]AudioOutput](0),
 		events:      make(chan events.Event, 32),
@@ -39,17 +54,31 @@ func New(ctx context.Context, cfg *config.PulseAudioConfig) (*PulseAudioBackend,
 	return backend, nil
 }
 
+// checkSocket fails unless path is an existing unix socket, so a wrong
+// pulseaudio.socket is reported at startup rather than as a connect error.
+func checkSocket(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s is not a unix socket", path)
+	}
+	return nil
+}
+
 // Start loads the initial cache and starts the listener
 func (pa *PulseAudioBackend) Start() error {
 	logger.Debug("[pulseaudio] starting backend")
 	pa.mu.Lock()
 	defer pa.mu.Unlock()
-	var err error
-	if pa.client, err = pulseaudio.NewClient(pa.address); err != nil {
+	client, err := pa.dial()
+	if err != nil {
 		return err
 	}
+	pa.client.Store(client)
 
-	if pa.server, err = pa.client.ServerInfo(); err != nil {
+	if pa.server, err = client.ServerInfo(); err != nil {
 		return err
 	}
 	pa.kind = detectServerKind(pa.server)
@@ -71,15 +100,118 @@ func (pa *PulseAudioBackend) Start() error {
 
 	go pa.heartbeat()
 
+	pa.applyStartupVolume()
+
 	logger.Info("[pulseaudio] backend started successfully")
 	return nil
 }
 
+// dial connects to the audio server. The first dial is bounded by
+// pulseaudio.startup_timeout, so a stalled server cannot block startup;
+// reconnects wait, their loop already retries.
+func (pa *PulseAudioBackend) dial() (*pulseaudio.Client, error) {
+	if pa.dialed || pa.startupTimeout <= 0 {
+		return pulseaudio.NewClient(pa.address)
+	}
+
+	type result struct {
+		client *pulseaudio.Client
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		client, err := pulseaudio.NewClient(pa.address)
+		done <- result{client, err}
+	}()
+
+	timer := time.NewTimer(pa.startupTimeout)
+	defer timer.Stop()
+	var err error
+	select {
+	case r := <-done:
+		pa.dialed = r.err == nil
+		return r.client, r.err
+	case <-timer.C:
+		err = fmt.Errorf("audio server did not answer within %v", pa.startupTimeout)
+	case <-pa.ctx.Done():
+		err = pa.ctx.Err()
+	}
+	go func() {
+		if r := <-done; r.client != nil {
+			r.client.Close()
+		}
+	}()
+	return nil, err
+}
+
+// applyStartupVolume sets pulseaudio.startupvolume on the master sink, once.
+// A failure is only logged: a box at its previous volume beats no audio.
+func (pa *PulseAudioBackend) applyStartupVolume() {
+	if pa.startupVolume == nil || pa.startupVolumeApplied {
+		return
+	}
+	volume := min(max(*pa.startupVolume, 0), 1)
+	set := pa.setVolumeMaster
+	if set == nil {
+		set = pa.SetVolumeMaster
+	}
+	if err := set(volume); err != nil {
+		logger.Warn("[pulseaudio] failed to apply startup volume %.2f: %v", volume, err)
+		return
+	}
+	pa.startupVolumeApplied = true
+	logger.Info("[pulseaudio] startup volume set to %.2f", volume)
+}
+
 func (pa *PulseAudioBackend) Reconnect() error {
 	pa.closeConnections()
 	return pa.Start()
 }
 
+// Connected reports whether the audio server connection is still alive.
+func (pa *PulseAudioBackend) Connected() bool {
+	client := pa.client.Load()
+	return client != nil && client.Connected()
+}
+
+// reconnectWait bounds how long an action waits for a dropped connection to
+// come back before giving up with a BackendUnavailableError.
+var reconnectWait = 2 * time.Second
+
+// awaitConnected returns the audio server client once its connection is
+// alive, giving the reconnect loop up to reconnectWait, so actions in that
+// window fail with a BackendUnavailableError instead of calling into a dead
+// client. Callers use the returned client only: a reconnect may replace
+// pa.client meanwhile.
+func (pa *PulseAudioBackend) awaitConnected() (*pulseaudio.Client, error) {
+	deadline := time.NewTimer(reconnectWait)
+	defer deadline.Stop()
+	poll := time.NewTicker(100 * time.Millisecond)
+	defer poll.Stop()
+	for {
+		if client := pa.client.Load(); client != nil && client.Connected() {
+			return client, nil
+		}
+		select {
+		case <-poll.C:
+		case <-deadline.C:
+			return nil, &BackendUnavailableError{}
+		case <-pa.ctx.Done():
+			return nil, &BackendUnavailableError{}
+		}
+	}
+}
+
+// currentClient returns the audio server client without waiting, for the
+// cache refreshes driven by Start and the listener.
+func (pa *PulseAudioBackend) currentClient() (*pulseaudio.Client, error) {
+	client := pa.client.Load()
+	if client == nil {
+		return nil, &BackendUnavailableError{}
+	}
+	return client, nil
+}
+
 func (pa *PulseAudioBackend) heartbeat() {
 	ticker := time.NewTicker(2 * time.Second)
 	defer ticker.Stop()
@@ -88,7 +220,7 @@ func (pa *PulseAudioBackend) heartbeat() {
 		case <-pa.ctx.Done():
 			return
 		case <-ticker.C:
-			if pa.client == nil || !pa.client.Connected() {
+			if !pa.Connected() {
 				pa.reconnectWithBackoff()
 				return
 			}
@@ -129,10 +261,15 @@ func (pa *PulseAudioBackend) ServerInfo() (*ServerInfo, error) {
 		return nil, &NotReadyError{Message: "output cache not ready"}
 	}
 
+	var apiVersion string
+	if pa.server != nil {
+		apiVersion = protocolVersion
+	}
 	for _, o := range outputs {
 		if o.Default {
 			return &ServerInfo{
 				Kind:        pa.kind,
+				APIVersion:  apiVersion,
 				DefaultSink: o.Name,
 				Volume:      o.Volume,
 				Muted:       o.Muted,
@@ -143,6 +280,22 @@ func (pa *PulseAudioBackend) ServerInfo() (*ServerInfo, error) {
 	return nil, &NotFoundError{Resource: "sink", Name: "default"}
 }
 
+// ImportCache replaces the client and output caches, e.g. with a debug
+// export, without asking the audio server. ServerInfo follows the imported
+// default output.
+func (pa *PulseAudioBackend) ImportCache(clients []AudioClient, outputs []AudioOutput) {
+	if clients == nil {
+		clients = []AudioClient{}
+	}
+	if outputs == nil {
+		outputs = []AudioOutput{}
+	}
+	pa.cache.Clear()
+	pa.cache.Set(cacheKey, clients)
+	pa.outputCache.Clear()
+	pa.outputCache.Set(outputCacheKey, outputs)
+}
+
 func (pa *PulseAudioBackend) ListClients() ([]AudioClient, error) {
 	// Check the cache
 	if cached, ok := pa.cache.Get(cacheKey); ok {
@@ -154,9 +307,50 @@ func (pa *PulseAudioBackend) ListClients() ([]AudioClient, error) {
 	return pa.refreshCache()
 }
 
+// ReloadClients rebuilds the client cache from the audio server. Unlike
+// InvalidateCache then ListClients, the cached clients stay readable until
+// the new list replaces them, and a failure keeps them.
+func (pa *PulseAudioBackend) ReloadClients() ([]AudioClient, error) {
+	return pa.refreshCache()
+}
+
+// FilterClients returns the cached clients matching f. It only reads the
+// cache, never the audio server: before the first load it is a NotReadyError.
+func (pa *PulseAudioBackend) FilterClients(f AudioClientFilter) ([]AudioClient, error) {
+	cached, ok := pa.cache.Get(cacheKey)
+	if !ok {
+		return nil, &NotReadyError{Message: "audio clients not loaded yet"}
+	}
+	return filterClients(cached, f), nil
+}
+
+// ListCorkedClients returns the cached clients whose stream is corked (paused).
+func (pa *PulseAudioBackend) ListCorkedClients() ([]AudioClient, error) {
+	corked := true
+	return pa.FilterClients(AudioClientFilter{Corked: &corked})
+}
+
+func filterClients(clients []AudioClient, f AudioClientFilter) []AudioClient {
+	out := make([]AudioClient, 0, len(clients))
+	for _, c := range clients {
+		if f.Corked != nil && c.Corked != *f.Corked {
+			continue
+		}
+		if f.Muted != nil && c.Muted != *f.Muted {
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
 // refreshCache reloads from pulseaudio and updates the cache
 func (pa *PulseAudioBackend) refreshCache() ([]AudioClient, error) {
-	sinks, err := pa.client.SinkInputs()
+	client, err := pa.currentClient()
+	if err != nil {
+		return nil, err
+	}
+	sinks, err := client.SinkInputs()
 	if err != nil {
 		return nil, err
 	}
@@ -167,7 +361,7 @@ func (pa *PulseAudioBackend) refreshCache() ([]AudioClient, error) {
 	oldClients, _ := pa.cache.Get(cacheKey)
 
 	// generate the new cache with updates/additions
-	updatedClients := pa.mergeClients(oldClients, sinks)
+	updatedClients := pa.mergeClients(client, oldClients, sinks)
 
 	// Cache it
 	pa.cache.Set(cacheKey, updatedClients)
@@ -175,7 +369,7 @@ func (pa *PulseAudioBackend) refreshCache() ([]AudioClient, error) {
 	return updatedClients, nil
 }
 
-func (pa *PulseAudioBackend) mergeClients(oldClients []AudioClient, sinks []pulseaudio.SinkInput) []AudioClient {
+func (pa *PulseAudioBackend) mergeClients(client *pulseaudio.Client, oldClients []AudioClient, sinks []pulseaudio.SinkInput) []AudioClient {
 	// temporary map for lookup by Name
 	oldMap := make(map[string]AudioClient, len(oldClients))
 	for _, c := range oldClients {
@@ -184,10 +378,11 @@ func (pa *PulseAudioBackend) mergeClients(oldClients []AudioClient, sinks []puls
 
 	// create the new slice and update / add clients
 	newClients := make([]AudioClient, 0, len(sinks))
+	now := time.Now()
 	for _, s := range sinks {
-		client := pa.parseSinkInput(s)
-		client = pa.updateOrAddClient(oldMap, client)
-		newClients = append(newClients, client)
+		c := pa.updateOrAddClient(oldMap, pa.parseSinkInput(client, s))
+		c.LastSeen = now
+		newClients = append(newClients, c)
 	}
 
 	// remove missing clients
@@ -240,50 +435,71 @@ func (pa *PulseAudioBackend) GetClient(name string) (*AudioClient, bool) {
 
 // UpdateClient updates a specific client in the cache
 func (pa *PulseAudioBackend) UpdateClient(updated AudioClient) error {
-	clients, ok := pa.cache.Get(cacheKey)
+	updated.LastSeen = time.Now()
+	ok := pa.cache.Modify(cacheKey, func(clients []AudioClient) []AudioClient {
+		clients = slices.Clone(clients)
+		for i, client := range clients {
+			if client.Name == updated.Name {
+				clients[i] = updated
+				return clients
+			}
+		}
+		// Client not in cache, add it
+		return append(clients, updated)
+	})
 	if !ok {
 		// If no cache, reload everything
 		_, err := pa.ListClients()
 		return err
 	}
+	return nil
+}
 
-	found := false
-	for i, client := range clients {
-		if client.Name == updated.Name {
-			clients[i] = updated
-			found = true
-			break
-		}
-	}
-
-	if !found {
-		// Client not in cache, add it
-		clients = append(clients, updated)
+// RemoveClient removes a specific client from the cache, if loaded, and emits
+// TypeAudioRemoved for it: the listener diffs against the cache, so it would
+// never report a client removed here.
+func (pa *PulseAudioBackend) RemoveClient(name string) {
+	var removed []AudioClient
+	pa.cache.Modify(cacheKey, func(clients []AudioClient) []AudioClient {
+		removed = nil
+		return slices.DeleteFunc(slices.Clone(clients), func(c AudioClient) bool {
+			if strings.EqualFold(c.Name, name) {
+				removed = append(removed, c)
+				return true
+			}
+			return false
+		})
+	})
+	if len(removed) > 0 {
+		pa.notify(events.Event{Type: events.TypeAudioRemoved, Data: removed})
 	}
-
-	pa.cache.Set(cacheKey, clients)
-	return nil
 }
 
 // RefreshClient reloads a specific client from pulseaudio and updates the cache
 func (pa *PulseAudioBackend) RefreshClient(name string) (*AudioClient, error) {
-	sink, err := pa.client.GetSinkInputByName(name)
+	client, err := pa.awaitConnected()
+	if err != nil {
+		return nil, err
+	}
+	sink, err := findSinkInput(client, name)
 	if err != nil {
-		// Client no longer exists, reload everything
-		if _, err := pa.ListClients(); err != nil {
-			return nil, err
+		// Client no longer exists: drop just that entry instead of reloading
+		// everything, which churns the cache on rapid bluetooth (dis)connects.
+		var notFound *NotFoundError
+		if errors.As(err, &notFound) {
+			pa.RemoveClient(name)
 		}
-		return nil, &NotFoundError{Resource: "client", Name: name}
+		return nil, err
 	}
 
-	client := pa.parseSinkInput(sink)
+	c := pa.parseSinkInput(client, sink)
 
 	// Update in the cache
-	if err := pa.UpdateClient(client); err != nil {
+	if err := pa.UpdateClient(c); err != nil {
 		return nil, err
 	}
 
-	return &client, nil
+	return &c, nil
 }
 
 // CacheUpdatedAt returns the last time the client cache was written to.
@@ -303,9 +519,8 @@ func (pa *PulseAudioBackend) closeConnections() {
 		pa.listener.Stop()
 		pa.listener = nil
 	}
-	if pa.client != nil {
-		pa.client.Close()
-		pa.client = nil
+	if client := pa.client.Swap(nil); client != nil {
+		client.Close()
 	}
 }
 
@@ -348,19 +563,31 @@ func (pa *PulseAudioBackend) cookie() ([]byte, error) {
 }
 
 func (pa *PulseAudioBackend) ToggleMuteMaster() error {
-	if _, err := pa.client.ToggleMute(); err != nil {
+	client, err := pa.awaitConnected()
+	if err != nil {
+		return err
+	}
+	if _, err := client.ToggleMute(); err != nil {
 		return fmt.Errorf("failed to get default sink: %w", err)
 	}
 	return nil
 }
 
 func (pa *PulseAudioBackend) SetVolumeMaster(volume float32) error {
-	return pa.client.SetVolume(volume)
+	client, err := pa.awaitConnected()
+	if err != nil {
+		return err
+	}
+	return client.SetVolume(volume)
 }
 
 func (pa *PulseAudioBackend) ToggleMute(name string) error {
 	logger.Debug("[pulseaudio] toggling mute for client %q", name)
-	sink, err := pa.findSinkInput(name)
+	client, err := pa.awaitConnected()
+	if err != nil {
+		return err
+	}
+	sink, err := findSinkInput(client, name)
 	if err != nil {
 		return err
 	}
@@ -373,7 +600,11 @@ func (pa *PulseAudioBackend) ToggleMute(name string) error {
 
 func (pa *PulseAudioBackend) SetVolume(name string, vol float32) error {
 	logger.Debug("[pulseaudio] setting volume for client %q to %.2f", name, vol)
-	sink, err := pa.findSinkInput(name)
+	client, err := pa.awaitConnected()
+	if err != nil {
+		return err
+	}
+	sink, err := findSinkInput(client, name)
 	if err != nil {
 		return err
 	}
@@ -387,8 +618,8 @@ func (pa *PulseAudioBackend) SetVolume(name string, vol float32) error {
 
 // findSinkInput matches a sink input by the same derived name the parsers
 // expose, so clients registering empty names stay addressable.
-func (pa *PulseAudioBackend) findSinkInput(name string) (pulseaudio.SinkInput, error) {
-	inputs, err := pa.client.SinkInputs()
+func findSinkInput(client *pulseaudio.Client, name string) (pulseaudio.SinkInput, error) {
+	inputs, err := client.SinkInputs()
 	if err != nil {
 		return pulseaudio.SinkInput{}, fmt.Errorf("failed to list sink inputs: %w", err)
 	}
@@ -400,21 +631,23 @@ func (pa *PulseAudioBackend) findSinkInput(name string) (pulseaudio.SinkInput, e
 	return pulseaudio.SinkInput{}, &NotFoundError{Resource: "client", Name: name}
 }
 
-func (pa *PulseAudioBackend) parseSinkInput(s pulseaudio.SinkInput) AudioClient {
+// parseSinkInput builds the client of a sink input; client resolves the
+// loopback module and source of PulseAudio bluetooth streams.
+func (pa *PulseAudioBackend) parseSinkInput(client *pulseaudio.Client, s pulseaudio.SinkInput) AudioClient {
 	switch pa.kind {
 	case ServerPipeWire:
 		return pa.parsePipeWireSinkInput(s)
 	default:
-		return pa.parsePulseSinkInput(s)
+		return parsePulseSinkInput(client, s)
 	}
 }
 
-func (pa *PulseAudioBackend) parsePulseSinkInput(s pulseaudio.SinkInput) AudioClient {
+func parsePulseSinkInput(client *pulseaudio.Client, s pulseaudio.SinkInput) AudioClient {
 	props := cloneProps(s.PropList)
 
 	if props["media.icon_name"] == "audio-card-bluetooth" && strings.HasPrefix(props["media.name"], "Loopback from") {
-		if client, ok := pa.parsePulseBluetoothSink(s, props); ok {
-			return client
+		if c, ok := parsePulseBluetoothSink(client, s, props); ok {
+			return c
 		}
 		logger.Warn("[pulseaudio] failed to resolve bluetooth sink %s", s.Name)
 	}
@@ -464,9 +697,9 @@ func cloneProps(in map[string]string) map[string]string {
 	return out
 }
 
-func (pa *PulseAudioBackend) parsePulseBluetoothSink(s pulseaudio.SinkInput, props map[string]string) (AudioClient, bool) {
+func parsePulseBluetoothSink(client *pulseaudio.Client, s pulseaudio.SinkInput, props map[string]string) (AudioClient, bool) {
 	// retrieve the module-loopback
-	mod, err := pa.findModule(s.OwnerModule, "module-loopback")
+	mod, err := findModule(client, s.OwnerModule, "module-loopback")
 	if err != nil {
 		return AudioClient{}, false
 	}
@@ -478,40 +711,70 @@ func (pa *PulseAudioBackend) parsePulseBluetoothSink(s pulseaudio.SinkInput, pro
 	}
 
 	// source lookup
-	src, err := pa.findSourceByName(sourceName)
+	src, err := findSourceByName(client, sourceName)
 	if err != nil {
 		return AudioClient{}, false
 	}
 
+	return bluetoothClient(s, props, sourceName, src.PropList), true
+}
+
+// bluetoothClient builds the client of a bluetooth loopback sink input from
+// its own props and those of the bluez source it plays.
+func bluetoothClient(s pulseaudio.SinkInput, props map[string]string, sourceName string, srcProps map[string]string) AudioClient {
 	btProps := cloneProps(props)
-	for k, v := range src.PropList {
+	if btProps == nil {
+		btProps = make(map[string]string, len(srcProps))
+	}
+	for k, v := range srcProps {
 		btProps[k] = v
 	}
 
 	// enrich props
-	name := src.PropList["device.description"]
+	name := srcProps["device.description"]
 	if name == "" {
 		name = strings.TrimPrefix(props["media.name"], "Loopback from ")
 	}
 
 	return AudioClient{
-		ID:      s.Index,
-		Name:    name,
-		App:     "bluetooth",
-		Muted:   s.IsMute(),
-		Volume:  s.GetVolume(),
-		Corked:  s.Corked,
-		Backend: ServerPulse,
-		Binary:  "bluez",
-		User:    "",
-		Host:    name,
-		Props:   btProps,
-	}, true
-
+		ID:            s.Index,
+		Name:          name,
+		App:           "bluetooth",
+		Muted:         s.IsMute(),
+		Volume:        s.GetVolume(),
+		Corked:        s.Corked,
+		Backend:       ServerPulse,
+		Binary:        "bluez",
+		User:          "",
+		Host:          name,
+		IsBluetooth:   true,
+		DeviceAddress: bluezSourceAddress(sourceName),
+		Props:         btProps,
+	}
+}
+
+// bluezSourceAddress extracts the device MAC from a bluez source name, e.g.
+// "bluez_source.C8_2A_DD_A7_D5_0D.a2dp_source" → "C8:2A:DD:A7:D5:0D".
+// Returns "" when the name does not carry one.
+func bluezSourceAddress(sourceName string) string {
+	parts := strings.Split(sourceName, ".")
+	if len(parts) < 2 || parts[0] != "bluez_source" {
+		return ""
+	}
+	octets := strings.Split(parts[1], "_")
+	if len(octets) != 6 {
+		return ""
+	}
+	for _, o := range octets {
+		if len(o) != 2 {
+			return ""
+		}
+	}
+	return strings.Join(octets, ":")
 }
 
-func (pa *PulseAudioBackend) findModule(index uint32, name string) (*pulseaudio.Module, error) {
-	mods, err := pa.client.ModuleList()
+func findModule(client *pulseaudio.Client, index uint32, name string) (*pulseaudio.Module, error) {
+	mods, err := client.ModuleList()
 	if err != nil {
 		return nil, err
 	}
@@ -523,8 +786,8 @@ func (pa *PulseAudioBackend) findModule(index uint32, name string) (*pulseaudio.
 	return nil, &NotFoundError{Resource: "module", Name: fmt.Sprintf("%s %d", name, index)}
 }
 
-func (pa *PulseAudioBackend) findSourceByName(name string) (*pulseaudio.Source, error) {
-	sources, err := pa.client.Sources()
+func findSourceByName(client *pulseaudio.Client, name string) (*pulseaudio.Source, error) {
+	sources, err := client.Sources()
 	if err != nil {
 		return nil, err
 	}
@@ -547,12 +810,16 @@ func (pa *PulseAudioBackend) ListOutputs() ([]AudioOutput, error) {
 }
 
 func (pa *PulseAudioBackend) refreshOutputCache() ([]AudioOutput, error) {
-	srv, err := pa.client.ServerInfo()
+	client, err := pa.currentClient()
+	if err != nil {
+		return nil, err
+	}
+	srv, err := client.ServerInfo()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get server info: %w", err)
 	}
 
-	sinks, err := pa.client.Sinks()
+	sinks, err := client.Sinks()
 	if err != nil {
 		return nil, err
 	}
@@ -610,12 +877,20 @@ func (pa *PulseAudioBackend) OutputCacheUpdatedAt() time.Time {
 
 func (pa *PulseAudioBackend) SetDefaultOutput(name string) error {
 	logger.Debug("[pulseaudio] setting default output to %q", name)
-	return pa.client.SetDefaultSink(name)
+	client, err := pa.awaitConnected()
+	if err != nil {
+		return err
+	}
+	return client.SetDefaultSink(name)
 }
 
 func (pa *PulseAudioBackend) ToggleMuteOutput(name string) error {
 	logger.Debug("[pulseaudio] toggling mute for output %q", name)
-	sink, err := pa.findSinkByName(name)
+	client, err := pa.awaitConnected()
+	if err != nil {
+		return err
+	}
+	sink, err := findSinkByName(client, name)
 	if err != nil {
 		return err
 	}
@@ -624,15 +899,19 @@ func (pa *PulseAudioBackend) ToggleMuteOutput(name string) error {
 
 func (pa *PulseAudioBackend) SetVolumeOutput(name string, vol float32) error {
 	logger.Debug("[pulseaudio] setting volume for output %q to %.2f", name, vol)
-	sink, err := pa.findSinkByName(name)
+	client, err := pa.awaitConnected()
+	if err != nil {
+		return err
+	}
+	sink, err := findSinkByName(client, name)
 	if err != nil {
 		return err
 	}
 	return sink.SetVolume(vol)
 }
 
-func (pa *PulseAudioBackend) findSinkByName(name string) (*pulseaudio.Sink, error) {
-	sinks, err := pa.client.Sinks()
+func findSinkByName(client *pulseaudio.Client, name string) (*pulseaudio.Sink, error) {
+	sinks, err := client.Sinks()
 	if err != nil {
 		return nil, err
 	}
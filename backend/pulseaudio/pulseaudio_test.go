@@ -1,11 +1,20 @@
 package pulseaudio
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"net"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/b0bbywan/go-odio-api/cache"
+	"github.com/b0bbywan/go-odio-api/config"
+	"github.com/b0bbywan/go-odio-api/events"
 	"github.com/the-jonsey/pulseaudio"
 )
 
@@ -175,6 +184,61 @@ func TestExtractModuleSource(t *testing.T) {
 	}
 }
 
+func TestBluezSourceAddress(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected string
+	}{
+		{"bluez_source.C8_2A_DD_A7_D5_0D.a2dp_source", "C8:2A:DD:A7:D5:0D"},
+		{"bluez_source.C8_2A_DD_A7_D5_0D", "C8:2A:DD:A7:D5:0D"},
+		{"bluez_source.test", ""},
+		{"bluez_source.C8_2A_DD_A7_D5.a2dp_source", ""},
+		{"alsa_input.C8_2A_DD_A7_D5_0D.analog", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := bluezSourceAddress(tt.source); got != tt.expected {
+			t.Errorf("bluezSourceAddress(%q) = %q, want %q", tt.source, got, tt.expected)
+		}
+	}
+}
+
+func TestBluetoothClient(t *testing.T) {
+	s := pulseaudio.SinkInput{Index: 7, Cvolume: []uint32{0xffff}}
+	props := map[string]string{"media.name": "Loopback from Phone"}
+	src := map[string]string{"device.description": "My Phone"}
+
+	c := bluetoothClient(s, props, "bluez_source.C8_2A_DD_A7_D5_0D.a2dp_source", src)
+	if !c.IsBluetooth {
+		t.Error("IsBluetooth = false, want true")
+	}
+	if c.DeviceAddress != "C8:2A:DD:A7:D5:0D" {
+		t.Errorf("DeviceAddress = %q, want C8:2A:DD:A7:D5:0D", c.DeviceAddress)
+	}
+	if c.Name != "My Phone" || c.Binary != "bluez" || c.App != "bluetooth" {
+		t.Errorf("got name=%q binary=%q app=%q", c.Name, c.Binary, c.App)
+	}
+	if c.Props["device.description"] != "My Phone" || c.Props["media.name"] != "Loopback from Phone" {
+		t.Errorf("props not merged: %v", c.Props)
+	}
+
+	c = bluetoothClient(s, props, "bluez_source.test", nil)
+	if c.Name != "Phone" || c.DeviceAddress != "" || !c.IsBluetooth {
+		t.Errorf("fallback: name=%q address=%q bluetooth=%v", c.Name, c.DeviceAddress, c.IsBluetooth)
+	}
+}
+
+func TestParseSinkInputNotBluetooth(t *testing.T) {
+	s := pulseaudio.SinkInput{Index: 1, Cvolume: []uint32{0xffff}, PropList: map[string]string{"media.name": "Spotify"}}
+	for _, kind := range []AudioServerKind{ServerPulse, ServerPipeWire} {
+		pa := &PulseAudioBackend{kind: kind}
+		if c := pa.parseSinkInput(nil, s); c.IsBluetooth || c.DeviceAddress != "" {
+			t.Errorf("%s: IsBluetooth=%v DeviceAddress=%q, want false and empty", kind, c.IsBluetooth, c.DeviceAddress)
+		}
+	}
+}
+
 func TestClientChanged(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -472,6 +536,110 @@ func TestDiffOutputs(t *testing.T) {
 // the lib's cvolume type is an unexported slice that requires protocol
 // deserialization to be valid.
 
+// TestReloadClientsFailureKeepsCache: a reload that cannot reach the audio
+// server leaves the cached clients in place instead of emptying them.
+func TestReloadClientsFailureKeepsCache(t *testing.T) {
+	pa := &PulseAudioBackend{cache: cache.New[[]AudioClient](0)}
+	pa.cache.Set(cacheKey, []AudioClient{{Name: "Spotify"}})
+
+	var unavailable *BackendUnavailableError
+	if _, err := pa.ReloadClients(); !errors.As(err, &unavailable) {
+		t.Fatalf("ReloadClients() error = %v, want BackendUnavailableError", err)
+	}
+	if clients, ok := pa.cache.Get(cacheKey); !ok || len(clients) != 1 {
+		t.Errorf("cache = %v, %v after a failed reload, want Spotify kept", clients, ok)
+	}
+}
+
+func TestImportCache(t *testing.T) {
+	pa := &PulseAudioBackend{
+		cache:       cache.New[[]AudioClient](0),
+		outputCache: newOutputCache(),
+	}
+	pa.cache.Set(cacheKey, []AudioClient{{Name: "stale"}})
+
+	pa.ImportCache(
+		[]AudioClient{{Name: "Spotify", Volume: 0.5}},
+		[]AudioOutput{{Name: "hdmi", Default: true, Volume: 0.8}},
+	)
+
+	clients, err := pa.ListClients()
+	if err != nil || len(clients) != 1 || clients[0].Name != "Spotify" {
+		t.Errorf("ListClients() = %v, %v; want the imported client only", clients, err)
+	}
+	info, err := pa.ServerInfo()
+	if err != nil || info.DefaultSink != "hdmi" {
+		t.Errorf("ServerInfo() = %+v, %v; want default sink hdmi", info, err)
+	}
+
+	pa.ImportCache(nil, nil)
+	if clients, _ := pa.ListClients(); clients == nil || len(clients) != 0 {
+		t.Errorf("ListClients() after empty import = %v, want empty cached list", clients)
+	}
+}
+
+func TestUpdateClientSetsLastSeen(t *testing.T) {
+	pa := &PulseAudioBackend{cache: cache.New[[]AudioClient](0)}
+	pa.cache.Set(cacheKey, []AudioClient{{Name: "Spotify"}})
+
+	before := time.Now()
+	if err := pa.UpdateClient(AudioClient{Name: "Spotify", Volume: 0.5}); err != nil {
+		t.Fatalf("UpdateClient() error = %v", err)
+	}
+
+	client, ok := pa.GetClient("Spotify")
+	if !ok {
+		t.Fatal("GetClient(Spotify) not found after UpdateClient")
+	}
+	if client.LastSeen.Before(before) {
+		t.Errorf("LastSeen = %v, want at or after %v", client.LastSeen, before)
+	}
+}
+
+func TestRemoveClient(t *testing.T) {
+	pa := &PulseAudioBackend{cache: cache.New[[]AudioClient](0), events: make(chan events.Event, 4)}
+	original := []AudioClient{{Name: "Spotify"}, {Name: "VLC"}}
+	pa.cache.Set(cacheKey, original)
+
+	pa.RemoveClient("spotify") // matched case-insensitively, like findSinkInput
+	select {
+	case e := <-pa.events:
+		removed, ok := e.Data.([]AudioClient)
+		if e.Type != events.TypeAudioRemoved || !ok || len(removed) != 1 || removed[0].Name != "Spotify" {
+			t.Errorf("event = %+v, want %s for Spotify", e, events.TypeAudioRemoved)
+		}
+	default:
+		t.Error("RemoveClient emitted no event")
+	}
+	if _, ok := pa.GetClient("Spotify"); ok {
+		t.Error("GetClient(Spotify) still found after RemoveClient")
+	}
+	if _, ok := pa.GetClient("VLC"); !ok {
+		t.Error("RemoveClient dropped an unrelated client")
+	}
+	if original[0].Name != "Spotify" {
+		t.Error("RemoveClient modified the previously cached slice in place")
+	}
+
+	pa.RemoveClient("unknown") // no-op
+	if clients, _ := pa.cache.Get(cacheKey); len(clients) != 1 {
+		t.Errorf("cache = %v, want only VLC", clients)
+	}
+	select {
+	case e := <-pa.events:
+		t.Errorf("removing an unknown client emitted %+v", e)
+	default:
+	}
+}
+
+func TestRemoveClientWithoutCache(t *testing.T) {
+	pa := &PulseAudioBackend{cache: cache.New[[]AudioClient](0), events: make(chan events.Event, 1)}
+	pa.RemoveClient("Spotify")
+	if _, ok := pa.cache.Get(cacheKey); ok {
+		t.Error("RemoveClient must not create a cache entry")
+	}
+}
+
 func TestServerInfoFromCache(t *testing.T) {
 	t.Run("cache miss returns error", func(t *testing.T) {
 		pa := &PulseAudioBackend{
@@ -525,6 +693,32 @@ func TestServerInfoFromCache(t *testing.T) {
 			t.Error("Muted = false, want true")
 		}
 	})
+
+	t.Run("serializes kind and api version as strings", func(t *testing.T) {
+		pa := &PulseAudioBackend{
+			kind:        ServerPipeWire,
+			server:      &pulseaudio.Server{PackageVersion: "15.0.0"},
+			outputCache: newOutputCache(),
+		}
+		pa.outputCache.Set(outputCacheKey, []AudioOutput{{Name: "sink", Default: true}})
+		info, err := pa.ServerInfo()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		data, err := json.Marshal(info)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{`"kind":"pipewire"`, `"api_version":"32"`} {
+			if !strings.Contains(string(data), want) {
+				t.Errorf("ServerInfo JSON = %s, want it to contain %s", data, want)
+			}
+		}
+		// The protocol version, not the server's package version.
+		if strings.Contains(string(data), "15.0.0") {
+			t.Errorf("ServerInfo JSON = %s, want no package version", data)
+		}
+	})
 }
 
 func newOutputCache() *cache.Cache[[]AudioOutput] {
@@ -703,3 +897,262 @@ func TestClientName(t *testing.T) {
 		})
 	}
 }
+
+// TestLoopbackArgumentRoundTrip: loopbacks created through the API are read
+// back by the bluetooth client detection like any other module-loopback.
+func TestLoopbackArgumentRoundTrip(t *testing.T) {
+	source := "bluez_source.C8_2A_DD_A7_D5_0D.a2dp_source"
+	arg := loopbackArgument(source, "alsa_output.default")
+	if got := extractModuleSource(arg); got != source {
+		t.Errorf("extractModuleSource(%q) = %q, want %q", arg, got, source)
+	}
+}
+
+func TestNormalizedVolume(t *testing.T) {
+	tests := []struct {
+		name               string
+		target, sinkVolume float32
+		want               float32
+	}{
+		{"full sink keeps target", 0.3, 1, 0.3},
+		{"half sink doubles the client", 0.3, 0.5, 0.6},
+		{"quiet sink caps at 100%", 0.3, 0.2, 1},
+		{"zero sink uses target", 0.3, 0, 0.3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizedVolume(tt.target, tt.sinkVolume); got != tt.want {
+				t.Errorf("normalizedVolume(%v, %v) = %v, want %v", tt.target, tt.sinkVolume, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeClientVolumeRejectsTarget(t *testing.T) {
+	pa := &PulseAudioBackend{}
+	for _, target := range []float32{0, -0.1, 1.5} {
+		if err := pa.NormalizeClientVolume("spotify", target); err == nil {
+			t.Errorf("NormalizeClientVolume(target=%v) = nil, want error", target)
+		}
+	}
+}
+
+func TestFilterClients(t *testing.T) {
+	pa := &PulseAudioBackend{cache: cache.New[[]AudioClient](0)}
+	if _, err := pa.FilterClients(AudioClientFilter{}); err == nil {
+		t.Fatal("FilterClients() before the first load: error = nil, want NotReadyError")
+	}
+
+	pa.cache.Set(cacheKey, []AudioClient{
+		{Name: "Spotify", Corked: true},
+		{Name: "Firefox", Muted: true},
+		{Name: "mpd", Corked: true, Muted: true},
+	})
+	yes, no := true, false
+
+	tests := []struct {
+		name   string
+		filter AudioClientFilter
+		want   []string
+	}{
+		{"no filter", AudioClientFilter{}, []string{"Spotify", "Firefox", "mpd"}},
+		{"corked", AudioClientFilter{Corked: &yes}, []string{"Spotify", "mpd"}},
+		{"muted", AudioClientFilter{Muted: &yes}, []string{"Firefox", "mpd"}},
+		{"corked and unmuted", AudioClientFilter{Corked: &yes, Muted: &no}, []string{"Spotify"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pa.FilterClients(tt.filter)
+			if err != nil {
+				t.Fatalf("FilterClients() error = %v", err)
+			}
+			var names []string
+			for _, c := range got {
+				names = append(names, c.Name)
+			}
+			if !slices.Equal(names, tt.want) {
+				t.Errorf("FilterClients() = %v, want %v", names, tt.want)
+			}
+		})
+	}
+
+	corked, err := pa.ListCorkedClients()
+	if err != nil || len(corked) != 2 {
+		t.Errorf("ListCorkedClients() = %v, %v; want 2 clients", corked, err)
+	}
+}
+
+// TestStartBoundedByStartupTimeout: a server that accepts but never answers
+// the handshake fails the first Start once pulseaudio.startup_timeout passes.
+func TestStartBoundedByStartupTimeout(t *testing.T) {
+	dir := t.TempDir()
+	cookie := filepath.Join(dir, "cookie")
+	if err := os.WriteFile(cookie, make([]byte, 256), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PULSE_COOKIE", cookie)
+	sock := filepath.Join(dir, "native")
+	ln, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer func() { _ = conn.Close() }()
+		}
+	}()
+
+	pa, err := New(context.Background(), &config.PulseAudioConfig{Enabled: true, Socket: sock, StartupTimeout: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- pa.Start() }()
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "did not answer") {
+			t.Errorf("Start() = %v, want a startup timeout", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Start() still blocked past the startup timeout")
+	}
+}
+
+func TestNewSocketOverride(t *testing.T) {
+	dir := t.TempDir()
+	sock := filepath.Join(dir, "native")
+	ln, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+
+	pa, err := New(context.Background(), &config.PulseAudioConfig{Enabled: true, XDGRuntimeDir: "/run/user/1000", Socket: sock})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if pa.address != sock {
+		t.Errorf("address = %q, want %q", pa.address, sock)
+	}
+
+	pa, err = New(context.Background(), &config.PulseAudioConfig{Enabled: true, XDGRuntimeDir: "/run/user/1000"})
+	if err != nil || pa.address != "/run/user/1000/pulse/native" {
+		t.Errorf("New() without socket = %q, %v; want the runtime dir socket", pa.address, err)
+	}
+
+	regular := filepath.Join(dir, "file")
+	if err := os.WriteFile(regular, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{filepath.Join(dir, "missing"), regular} {
+		_, err := New(context.Background(), &config.PulseAudioConfig{Enabled: true, Socket: path})
+		if err == nil || !strings.Contains(err.Error(), "pulseaudio.socket") {
+			t.Errorf("New(socket=%s) error = %v, want one naming pulseaudio.socket", path, err)
+		}
+	}
+}
+
+// TestActionsUnavailableWhileDisconnected: with no live client (e.g. mid
+// reconnect) actions fail with BackendUnavailableError instead of panicking.
+func TestActionsUnavailableWhileDisconnected(t *testing.T) {
+	saved := reconnectWait
+	reconnectWait = 0
+	t.Cleanup(func() { reconnectWait = saved })
+
+	pa := &PulseAudioBackend{ctx: context.Background()}
+	actions := map[string]func() error{
+		"ToggleMuteMaster": pa.ToggleMuteMaster,
+		"SetVolumeMaster":  func() error { return pa.SetVolumeMaster(0.5) },
+		"ToggleMute":       func() error { return pa.ToggleMute("mpd") },
+		"SetVolume":        func() error { return pa.SetVolume("mpd", 0.5) },
+		"SetDefaultOutput": func() error { return pa.SetDefaultOutput("hdmi") },
+		"ToggleMuteOutput": func() error { return pa.ToggleMuteOutput("hdmi") },
+		"SetVolumeOutput":  func() error { return pa.SetVolumeOutput("hdmi", 0.5) },
+		"NormalizeClient":  func() error { return pa.NormalizeClientVolume("mpd", 0.3) },
+		"RemoveLoopback":   func() error { return pa.RemoveLoopback(1) },
+		"CreateLoopback": func() error {
+			_, err := pa.CreateLoopback("mic", "hdmi")
+			return err
+		},
+	}
+	for name, action := range actions {
+		var unavailable *BackendUnavailableError
+		if err := action(); !errors.As(err, &unavailable) {
+			t.Errorf("%s() = %v, want BackendUnavailableError", name, err)
+		}
+	}
+}
+
+// TestAwaitConnectedStopsWithBackend: a shutdown ends the wait for a
+// reconnect right away, and dropping the client concurrently is race free.
+func TestAwaitConnectedStopsWithBackend(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	pa := &PulseAudioBackend{ctx: ctx}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 100 {
+			pa.closeConnections() // the heartbeat goroutine dropping the client
+		}
+	}()
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := pa.awaitConnected()
+	<-done
+	var unavailable *BackendUnavailableError
+	if !errors.As(err, &unavailable) {
+		t.Fatalf("awaitConnected() error = %v, want BackendUnavailableError", err)
+	}
+	if elapsed := time.Since(start); elapsed >= reconnectWait {
+		t.Errorf("awaitConnected() waited %v after shutdown, want it to stop early", elapsed)
+	}
+}
+
+func TestApplyStartupVolume(t *testing.T) {
+	ptr := func(v float32) *float32 { return &v }
+	tests := []struct {
+		name   string
+		volume *float32
+		err    error
+		want   []float32
+		done   bool
+	}{
+		{name: "unset leaves volume alone", volume: nil},
+		{name: "configured value", volume: ptr(0.25), want: []float32{0.25}, done: true},
+		{name: "clamped above 1", volume: ptr(1.5), want: []float32{1}, done: true},
+		{name: "failure retried next start", volume: ptr(0.4), err: errors.New("no sink"), want: []float32{0.4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []float32
+			pa := &PulseAudioBackend{
+				startupVolume: tt.volume,
+				setVolumeMaster: func(v float32) error {
+					got = append(got, v)
+					return tt.err
+				},
+			}
+			pa.applyStartupVolume()
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("setVolumeMaster calls = %v, want %v", got, tt.want)
+			}
+			if pa.startupVolumeApplied != tt.done {
+				t.Errorf("startupVolumeApplied = %v, want %v", pa.startupVolumeApplied, tt.done)
+			}
+
+			// A reconnect runs Start again: an applied volume is not reapplied.
+			got = nil
+			pa.applyStartupVolume()
+			if tt.done && len(got) != 0 {
+				t.Errorf("second start set volume %v, want no call", got)
+			}
+		})
+	}
+}
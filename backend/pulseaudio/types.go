@@ -3,6 +3,8 @@ package pulseaudio
 import (
 	"context"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/the-jonsey/pulseaudio"
 
@@ -23,18 +25,41 @@ type PulseAudioBackend struct {
 
 	address     string
 	serveCookie bool
-	client      *pulseaudio.Client
+	client      atomic.Pointer[pulseaudio.Client] // nil between a dropped connection and its reconnect
 	server      *pulseaudio.Server
 	kind        AudioServerKind
 
+	startupTimeout time.Duration // bounds the first dial only
+	dialed         bool          // a dial succeeded once
+
+	autoNormalize   bool // normalize new clients to normalizeTarget
+	normalizeTarget float32
+
+	// startupVolume is set on the master sink by the first Start only, so a
+	// reconnect does not undo a volume changed since boot.
+	startupVolume        *float32
+	startupVolumeApplied bool
+	// sets the master volume; nil means SetVolumeMaster, replaced in tests
+	setVolumeMaster func(float32) error
+
 	cache       *cache.Cache[[]AudioClient]
 	outputCache *cache.Cache[[]AudioOutput]
 	listener    *Listener
 	events      chan events.Event
 }
 
+// protocolVersion is the PulseAudio native protocol version spoken with the
+// audio server: the client library requires at least this version at the
+// handshake. Bump it only when the library's protocol changes.
+const protocolVersion = "32"
+
+// ServerInfo describes the audio server. APIVersion is the PulseAudio native
+// protocol version spoken with it (protocolVersion), to debug client
+// compatibility with PulseAudio and pipewire-pulse; it is empty for an
+// imported cache, when no server was reached.
 type ServerInfo struct {
 	Kind        AudioServerKind `json:"kind"`
+	APIVersion  string          `json:"api_version,omitempty"`
 	DefaultSink string          `json:"default_sink"`
 	Volume      float32         `json:"volume"`
 	Muted       bool            `json:"muted"`
@@ -56,15 +81,27 @@ type AudioOutput struct {
 }
 
 type AudioClient struct {
-	ID      uint32            `json:"id"`
-	Name    string            `json:"name"` // media.name
-	App     string            `json:"app"`  // application.name
-	Muted   bool              `json:"muted"`
-	Volume  float32           `json:"volume"`
-	Corked  bool              `json:"corked"`
-	Backend AudioServerKind   `json:"backend"` // pulse | pipewire
-	Binary  string            `json:"binary,omitempty"`
-	User    string            `json:"user,omitempty"`
-	Host    string            `json:"host,omitempty"`
-	Props   map[string]string `json:"props,omitempty"`
+	ID      uint32          `json:"id"`
+	Name    string          `json:"name"` // media.name
+	App     string          `json:"app"`  // application.name
+	Muted   bool            `json:"muted"`
+	Volume  float32         `json:"volume"`
+	Corked  bool            `json:"corked"`
+	Backend AudioServerKind `json:"backend"` // pulse | pipewire
+	Binary  string          `json:"binary,omitempty"`
+	User    string          `json:"user,omitempty"`
+	Host    string          `json:"host,omitempty"`
+	// IsBluetooth marks the loopback stream of a connected bluetooth source;
+	// DeviceAddress is then that device's MAC, matching BluetoothDevice.Address.
+	IsBluetooth   bool              `json:"is_bluetooth"`
+	DeviceAddress string            `json:"device_address,omitempty"`
+	Props         map[string]string `json:"props,omitempty"`
+	// LastSeen is when the audio server last reported the stream.
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// AudioClientFilter narrows FilterClients; a nil field matches any value.
+type AudioClientFilter struct {
+	Corked *bool
+	Muted  *bool
 }
@@ -0,0 +1,43 @@
+package backend
+
+// CacheRefresh is the outcome of rebuilding one backend's cache.
+type CacheRefresh struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// RefreshCaches reloads the cache of every running backend from its source,
+// returning the outcome per backend name. Each new list replaces its cache
+// only once built: readers never see it empty and a failure keeps it.
+// Refreshes are serialized; listeners keep running and simply apply their
+// next change on top of the reloaded cache, so calling it again is harmless.
+func (b *Backend) RefreshCaches() map[string]CacheRefresh {
+	b.refreshMu.Lock()
+	defer b.refreshMu.Unlock()
+
+	results := make(map[string]CacheRefresh)
+	record := func(name string, err error) {
+		if err != nil {
+			results[name] = CacheRefresh{Error: err.Error()}
+			return
+		}
+		results[name] = CacheRefresh{OK: true}
+	}
+
+	if b.MPRIS != nil {
+		_, err := b.MPRIS.ReloadPlayers()
+		record("mpris", err)
+	}
+	if b.Pulse != nil {
+		_, err := b.Pulse.ReloadClients()
+		record("pulseaudio", err)
+	}
+	if b.Systemd != nil {
+		_, err := b.Systemd.ReloadServices()
+		record("systemd", err)
+	}
+	if b.Bluetooth != nil {
+		record("bluetooth", b.Bluetooth.RefreshStatus())
+	}
+	return results
+}
@@ -0,0 +1,32 @@
+package snapcast
+
+import "strconv"
+
+// ClientNotFoundError indicates that no Snapcast client has the given ID.
+type ClientNotFoundError struct {
+	ID string
+}
+
+func (e *ClientNotFoundError) Error() string {
+	return "snapcast client not found: " + e.ID
+}
+
+// ValidationError indicates that a parameter is invalid
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// RPCError is an error returned by the Snapcast server itself.
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RPCError) Error() string {
+	return "snapcast error " + strconv.Itoa(e.Code) + ": " + e.Message
+}
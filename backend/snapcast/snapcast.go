@@ -0,0 +1,176 @@
+package snapcast
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/b0bbywan/go-odio-api/config"
+	"github.com/b0bbywan/go-odio-api/logger"
+)
+
+const (
+	METHOD_GET_STATUS        = "Server.GetStatus"
+	METHOD_CLIENT_SET_VOLUME = "Client.SetVolume"
+)
+
+// New creates a Snapcast backend. No connection is opened here: every call
+// dials the server, so a snapserver restart needs no reconnection logic.
+func New(ctx context.Context, cfg *config.SnapcastConfig) (*SnapcastBackend, error) {
+	if cfg == nil || !cfg.Enabled {
+		return nil, nil
+	}
+
+	return &SnapcastBackend{
+		ctx:     ctx,
+		addr:    net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		timeout: cfg.Timeout,
+	}, nil
+}
+
+// Start probes the server. An unreachable snapserver is not fatal: it may be
+// on another host or start later, and each request retries the connection.
+func (s *SnapcastBackend) Start() error {
+	clients, err := s.ListClients()
+	if err != nil {
+		logger.Warn("[snapcast] server %s unreachable at startup: %v", s.addr, err)
+		return nil
+	}
+	logger.Info("[snapcast] backend started, %d client(s) on %s", len(clients), s.addr)
+	return nil
+}
+
+// ListClients returns every client of every group. Snapcast state is fetched
+// live: without a notification listener a cache could not be kept fresh.
+func (s *SnapcastBackend) ListClients() ([]SnapcastClient, error) {
+	var status rpcStatus
+	if err := s.call(METHOD_GET_STATUS, nil, &status); err != nil {
+		return nil, err
+	}
+
+	clients := make([]SnapcastClient, 0)
+	for _, g := range status.Server.Groups {
+		for _, c := range g.Clients {
+			clients = append(clients, clientFromRPC(c))
+		}
+	}
+	return clients, nil
+}
+
+func clientFromRPC(c rpcClient) SnapcastClient {
+	name := c.Config.Name
+	if name == "" {
+		name = c.Host.Name
+	}
+	return SnapcastClient{
+		ID:        c.ID,
+		Name:      name,
+		Host:      c.Host.Name,
+		Volume:    c.Config.Volume.Percent,
+		Muted:     c.Config.Volume.Muted,
+		Latency:   c.Config.Latency,
+		Connected: c.Connected,
+	}
+}
+
+// GetClient returns a single client by ID.
+func (s *SnapcastBackend) GetClient(id string) (*SnapcastClient, error) {
+	clients, err := s.ListClients()
+	if err != nil {
+		return nil, err
+	}
+	for i := range clients {
+		if clients[i].ID == id {
+			return &clients[i], nil
+		}
+	}
+	return nil, &ClientNotFoundError{ID: id}
+}
+
+// SetVolume sets a client's volume (percent, 0-100), keeping its mute state.
+func (s *SnapcastBackend) SetVolume(clientID string, vol int) error {
+	if vol < 0 || vol > 100 {
+		return &ValidationError{Field: "volume", Message: "must be between 0 and 100"}
+	}
+	client, err := s.GetClient(clientID)
+	if err != nil {
+		return err
+	}
+	logger.Debug("[snapcast] setting volume of %s to %d", clientID, vol)
+	return s.setClientVolume(clientID, rpcVolume{Percent: vol, Muted: client.Muted})
+}
+
+// SetMuted mutes or unmutes a client, keeping its volume.
+func (s *SnapcastBackend) SetMuted(clientID string, muted bool) error {
+	client, err := s.GetClient(clientID)
+	if err != nil {
+		return err
+	}
+	logger.Debug("[snapcast] setting mute of %s to %v", clientID, muted)
+	return s.setClientVolume(clientID, rpcVolume{Percent: client.Volume, Muted: muted})
+}
+
+// setClientVolume sends both fields: older snapservers reject a partial
+// volume object.
+func (s *SnapcastBackend) setClientVolume(clientID string, vol rpcVolume) error {
+	params := map[string]any{"id": clientID, "volume": vol}
+	return s.call(METHOD_CLIENT_SET_VOLUME, params, nil)
+}
+
+// call performs one JSON-RPC request on a fresh connection and decodes the
+// result into dest (when non-nil). Notifications the server pushes on the
+// same socket are skipped until the matching response arrives.
+func (s *SnapcastBackend) call(method string, params any, dest any) error {
+	dialer := net.Dialer{Timeout: s.timeout}
+	conn, err := dialer.DialContext(s.ctx, "tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			logger.Debug("[snapcast] failed to close connection: %v", err)
+		}
+	}()
+	if err := conn.SetDeadline(time.Now().Add(s.timeout)); err != nil {
+		return err
+	}
+
+	id := s.nextID.Add(1)
+	req, err := json.Marshal(rpcRequest{ID: id, JSONRPC: "2.0", Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	if _, err := conn.Write(append(req, '\n')); err != nil {
+		return fmt.Errorf("snapcast %s: %w", method, err)
+	}
+
+	scanner := bufio.NewScanner(conn)
+	// Server.GetStatus grows with the number of clients and streams
+	scanner.Buffer(make([]byte, 0, 64*1024), 4<<20)
+	for scanner.Scan() {
+		var resp rpcResponse
+		if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+			return fmt.Errorf("snapcast %s: invalid response: %w", method, err)
+		}
+		if resp.ID == nil || *resp.ID != id {
+			continue
+		}
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if dest == nil {
+			return nil
+		}
+		return json.Unmarshal(resp.Result, dest)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("snapcast %s: %w", method, err)
+	}
+	return fmt.Errorf("snapcast %s: connection closed before response", method)
+}
+
+func (s *SnapcastBackend) Close() {}
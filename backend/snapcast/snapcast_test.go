@@ -0,0 +1,243 @@
+package snapcast
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/b0bbywan/go-odio-api/config"
+)
+
+// fakeServer is a minimal snapserver speaking newline-delimited JSON-RPC.
+type fakeServer struct {
+	ln net.Listener
+
+	mu      sync.Mutex
+	clients []rpcClient
+	calls   []string
+}
+
+func newFakeServer(t *testing.T, clients ...rpcClient) *fakeServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	f := &fakeServer{ln: ln, clients: clients}
+	t.Cleanup(func() { _ = ln.Close() })
+	go f.serve()
+	return f
+}
+
+func (f *fakeServer) serve() {
+	for {
+		conn, err := f.ln.Accept()
+		if err != nil {
+			return
+		}
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeServer) handle(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		var req struct {
+			ID     uint64          `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			return
+		}
+
+		// A notification pushed before the response must be skipped
+		_, _ = conn.Write([]byte(`{"jsonrpc":"2.0","method":"Client.OnConnect","params":{}}` + "\n"))
+
+		resp := map[string]any{"jsonrpc": "2.0", "id": req.ID}
+		f.mu.Lock()
+		f.calls = append(f.calls, req.Method)
+		switch req.Method {
+		case METHOD_GET_STATUS:
+			status := rpcStatus{}
+			status.Server.Groups = append(status.Server.Groups, struct {
+				Clients []rpcClient `json:"clients"`
+			}{Clients: f.clients})
+			resp["result"] = status
+		case METHOD_CLIENT_SET_VOLUME:
+			var p struct {
+				ID     string    `json:"id"`
+				Volume rpcVolume `json:"volume"`
+			}
+			_ = json.Unmarshal(req.Params, &p)
+			for i := range f.clients {
+				if f.clients[i].ID == p.ID {
+					f.clients[i].Config.Volume = p.Volume
+				}
+			}
+			resp["result"] = map[string]any{"volume": p.Volume}
+		default:
+			resp["error"] = RPCError{Code: -32601, Message: "Method not found"}
+		}
+		f.mu.Unlock()
+
+		data, _ := json.Marshal(resp)
+		_, _ = conn.Write(append(data, '\n'))
+	}
+}
+
+func (f *fakeServer) backend(t *testing.T) *SnapcastBackend {
+	t.Helper()
+	host, port, _ := net.SplitHostPort(f.ln.Addr().String())
+	p, _ := strconv.Atoi(port)
+	s, err := New(context.Background(), &config.SnapcastConfig{
+		Enabled: true,
+		Host:    host,
+		Port:    p,
+		Timeout: 2 * time.Second,
+	})
+	if err != nil || s == nil {
+		t.Fatalf("New() = %v, %v", s, err)
+	}
+	return s
+}
+
+func testClient(id, name string, percent int, muted bool) rpcClient {
+	c := rpcClient{ID: id, Connected: true}
+	c.Host.Name = id + "-host"
+	c.Config.Name = name
+	c.Config.Volume = rpcVolume{Percent: percent, Muted: muted}
+	return c
+}
+
+func TestNewDisabled(t *testing.T) {
+	for _, cfg := range []*config.SnapcastConfig{nil, {Enabled: false}} {
+		s, err := New(context.Background(), cfg)
+		if s != nil || err != nil {
+			t.Errorf("New(%v) = %v, %v, want nil, nil", cfg, s, err)
+		}
+	}
+}
+
+func TestListClients(t *testing.T) {
+	f := newFakeServer(t,
+		testClient("kitchen", "Kitchen", 40, false),
+		testClient("living", "", 80, true),
+	)
+	s := f.backend(t)
+
+	clients, err := s.ListClients()
+	if err != nil {
+		t.Fatalf("ListClients() error = %v", err)
+	}
+	if len(clients) != 2 {
+		t.Fatalf("ListClients() = %d clients, want 2", len(clients))
+	}
+	if clients[0].Name != "Kitchen" || clients[0].Volume != 40 {
+		t.Errorf("clients[0] = %+v", clients[0])
+	}
+	// Unnamed clients fall back to their host name
+	if clients[1].Name != "living-host" || !clients[1].Muted {
+		t.Errorf("clients[1] = %+v", clients[1])
+	}
+}
+
+func TestSetVolumeKeepsMute(t *testing.T) {
+	f := newFakeServer(t, testClient("kitchen", "Kitchen", 40, true))
+	s := f.backend(t)
+
+	if err := s.SetVolume("kitchen", 65); err != nil {
+		t.Fatalf("SetVolume() error = %v", err)
+	}
+	c, err := s.GetClient("kitchen")
+	if err != nil {
+		t.Fatalf("GetClient() error = %v", err)
+	}
+	if c.Volume != 65 || !c.Muted {
+		t.Errorf("client = %+v, want volume 65 and still muted", c)
+	}
+}
+
+func TestSetMutedKeepsVolume(t *testing.T) {
+	f := newFakeServer(t, testClient("kitchen", "Kitchen", 40, false))
+	s := f.backend(t)
+
+	if err := s.SetMuted("kitchen", true); err != nil {
+		t.Fatalf("SetMuted() error = %v", err)
+	}
+	c, err := s.GetClient("kitchen")
+	if err != nil {
+		t.Fatalf("GetClient() error = %v", err)
+	}
+	if c.Volume != 40 || !c.Muted {
+		t.Errorf("client = %+v, want volume 40 and muted", c)
+	}
+}
+
+func TestSetVolumeErrors(t *testing.T) {
+	f := newFakeServer(t, testClient("kitchen", "Kitchen", 40, false))
+	s := f.backend(t)
+
+	var validErr *ValidationError
+	for _, vol := range []int{-1, 101} {
+		if err := s.SetVolume("kitchen", vol); !errors.As(err, &validErr) {
+			t.Errorf("SetVolume(%d) error = %v, want ValidationError", vol, err)
+		}
+	}
+
+	var notFoundErr *ClientNotFoundError
+	if err := s.SetVolume("garage", 50); !errors.As(err, &notFoundErr) {
+		t.Errorf("SetVolume(unknown) error = %v, want ClientNotFoundError", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, m := range f.calls {
+		if m == METHOD_CLIENT_SET_VOLUME {
+			t.Errorf("invalid requests must not reach the server")
+		}
+	}
+}
+
+func TestRPCError(t *testing.T) {
+	f := newFakeServer(t)
+	s := f.backend(t)
+
+	var rpcErr *RPCError
+	if err := s.call("Server.Unknown", nil, nil); !errors.As(err, &rpcErr) {
+		t.Fatalf("call() error = %v, want RPCError", err)
+	}
+	if rpcErr.Code != -32601 {
+		t.Errorf("RPCError.Code = %d, want -32601", rpcErr.Code)
+	}
+}
+
+func TestUnreachableServer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := ln.Addr().(*net.TCPAddr)
+	_ = ln.Close()
+
+	s, _ := New(context.Background(), &config.SnapcastConfig{
+		Enabled: true,
+		Host:    "127.0.0.1",
+		Port:    addr.Port,
+		Timeout: time.Second,
+	})
+	if _, err := s.ListClients(); err == nil {
+		t.Error("ListClients() on a closed port should fail")
+	}
+	// Start tolerates an unreachable server
+	if err := s.Start(); err != nil {
+		t.Errorf("Start() error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,81 @@
+package snapcast
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"time"
+)
+
+// SnapcastBackend controls a Snapcast server over its JSON-RPC TCP API.
+type SnapcastBackend struct {
+	ctx     context.Context
+	addr    string
+	timeout time.Duration
+
+	// JSON-RPC request IDs, unique per backend
+	nextID atomic.Uint64
+}
+
+// SnapcastClient is a Snapcast playback client (one per room/speaker).
+type SnapcastClient struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Host      string `json:"host"`
+	Volume    int    `json:"volume"` // percent, 0-100
+	Muted     bool   `json:"muted"`
+	Latency   int    `json:"latency"` // ms
+	Connected bool   `json:"connected"`
+}
+
+// Request types for the API
+
+type VolumeRequest struct {
+	Volume int `json:"volume"`
+}
+
+type MuteRequest struct {
+	Muted bool `json:"muted"`
+}
+
+// JSON-RPC 2.0 wire types
+
+type rpcRequest struct {
+	ID      uint64 `json:"id"`
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	ID     *uint64         `json:"id"` // nil for server notifications
+	Result json.RawMessage `json:"result"`
+	Error  *RPCError       `json:"error"`
+}
+
+type rpcVolume struct {
+	Percent int  `json:"percent"`
+	Muted   bool `json:"muted"`
+}
+
+type rpcClient struct {
+	ID        string `json:"id"`
+	Connected bool   `json:"connected"`
+	Host      struct {
+		Name string `json:"name"`
+		IP   string `json:"ip"`
+	} `json:"host"`
+	Config struct {
+		Name    string    `json:"name"`
+		Latency int       `json:"latency"`
+		Volume  rpcVolume `json:"volume"`
+	} `json:"config"`
+}
+
+type rpcStatus struct {
+	Server struct {
+		Groups []struct {
+			Clients []rpcClient `json:"clients"`
+		} `json:"groups"`
+	} `json:"server"`
+}
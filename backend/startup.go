@@ -0,0 +1,51 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/b0bbywan/go-odio-api/logger"
+)
+
+// closer is a backend pointer type that can be released.
+type closer[T any] interface {
+	*T
+	Close()
+}
+
+// startupTimeoutError reports a backend whose constructor outlived its
+// startup timeout. New records it in the diagnostics and carries on.
+type startupTimeoutError struct {
+	name    string
+	timeout time.Duration
+}
+
+func (e *startupTimeoutError) Error() string {
+	return fmt.Sprintf("%s did not start within %v", e.name, e.timeout)
+}
+
+// withStartupTimeout runs a backend constructor with a context that expires
+// after timeout, so a slow D-Bus service cannot block the whole process. The
+// constructor must return once that context is done; a backend still built past
+// the deadline is closed, treated as disabled and reported with a
+// *startupTimeoutError. A zero timeout passes ctx through unchanged.
+func withStartupTimeout[T any, P closer[T]](ctx context.Context, name string, timeout time.Duration, newFn func(context.Context) (P, error)) (P, error) {
+	if timeout <= 0 {
+		return newFn(ctx)
+	}
+
+	startCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	b, err := newFn(startCtx)
+	if errors.Is(startCtx.Err(), context.DeadlineExceeded) {
+		logger.Warn("[backend] %s did not start within %v, disabling it", name, timeout)
+		if b != nil {
+			b.Close()
+		}
+		return nil, &startupTimeoutError{name: name, timeout: timeout}
+	}
+	return b, err
+}
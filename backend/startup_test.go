@@ -0,0 +1,76 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeBackend struct {
+	closed chan struct{}
+}
+
+func (f *fakeBackend) Close() { close(f.closed) }
+
+func TestWithStartupTimeout(t *testing.T) {
+	t.Run("fast constructor", func(t *testing.T) {
+		want := &fakeBackend{closed: make(chan struct{})}
+		got, err := withStartupTimeout(context.Background(), "fake", time.Second, func(context.Context) (*fakeBackend, error) {
+			return want, nil
+		})
+		if err != nil || got != want {
+			t.Errorf("withStartupTimeout() = %v, %v, want backend", got, err)
+		}
+	})
+
+	t.Run("constructor error", func(t *testing.T) {
+		wantErr := errors.New("boom")
+		_, err := withStartupTimeout(context.Background(), "fake", time.Second, func(context.Context) (*fakeBackend, error) {
+			return nil, wantErr
+		})
+		if !errors.Is(err, wantErr) {
+			t.Errorf("error = %v, want %v", err, wantErr)
+		}
+	})
+
+	t.Run("slow constructor is cancelled and closed", func(t *testing.T) {
+		late := &fakeBackend{closed: make(chan struct{})}
+		got, err := withStartupTimeout(context.Background(), "fake", 20*time.Millisecond, func(ctx context.Context) (*fakeBackend, error) {
+			<-ctx.Done()
+			return late, nil
+		})
+		var timeoutErr *startupTimeoutError
+		if got != nil || !errors.As(err, &timeoutErr) {
+			t.Fatalf("withStartupTimeout() = %v, %v, want nil, startup timeout", got, err)
+		}
+		// The constructor has returned, so the late backend is already closed.
+		select {
+		case <-late.closed:
+		default:
+			t.Error("late backend was not closed")
+		}
+	})
+
+	t.Run("parent cancellation is not a timeout", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := withStartupTimeout(ctx, "fake", time.Second, func(ctx context.Context) (*fakeBackend, error) {
+			return nil, ctx.Err()
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("error = %v, want %v", err, context.Canceled)
+		}
+	})
+
+	t.Run("zero timeout waits", func(t *testing.T) {
+		want := &fakeBackend{closed: make(chan struct{})}
+		got, _ := withStartupTimeout(context.Background(), "fake", 0, func(context.Context) (*fakeBackend, error) {
+			time.Sleep(20 * time.Millisecond)
+			return want, nil
+		})
+		if got != want {
+			t.Errorf("withStartupTimeout() = %v, want backend", got)
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package backend
+
+import (
+	"github.com/b0bbywan/go-odio-api/backend/bluetooth"
+	"github.com/b0bbywan/go-odio-api/backend/mpris"
+	"github.com/b0bbywan/go-odio-api/backend/pulseaudio"
+	"github.com/b0bbywan/go-odio-api/backend/systemd"
+)
+
+// State is a snapshot of what the running backends currently hold, for bug
+// reports. Sections of disabled backends are omitted; a section that could
+// not be read is reported in Errors under its name.
+type State struct {
+	Players      []mpris.Player             `json:"players,omitempty"`
+	AudioServer  *pulseaudio.ServerInfo     `json:"audio_server,omitempty"`
+	AudioClients []pulseaudio.AudioClient   `json:"audio_clients,omitempty"`
+	AudioOutputs []pulseaudio.AudioOutput   `json:"audio_outputs,omitempty"`
+	Services     []systemd.Service          `json:"services,omitempty"`
+	Bluetooth    *bluetooth.BluetoothStatus `json:"bluetooth,omitempty"`
+	Errors       map[string]string          `json:"errors,omitempty"`
+}
+
+// State collects the cached state of every running backend. Unlike the
+// public listings it includes internal systemd units.
+func (b *Backend) State() State {
+	var st State
+	fail := func(section string, err error) {
+		if st.Errors == nil {
+			st.Errors = make(map[string]string)
+		}
+		st.Errors[section] = err.Error()
+	}
+
+	if b.MPRIS != nil {
+		if players, err := b.MPRIS.ListPlayers(); err != nil {
+			fail("players", err)
+		} else {
+			st.Players = players
+		}
+	}
+	if b.Pulse != nil {
+		if info, err := b.Pulse.ServerInfo(); err != nil {
+			fail("audio_server", err)
+		} else {
+			st.AudioServer = info
+		}
+		if clients, err := b.Pulse.ListClients(); err != nil {
+			fail("audio_clients", err)
+		} else {
+			st.AudioClients = clients
+		}
+		if outputs, err := b.Pulse.ListOutputs(); err != nil {
+			fail("audio_outputs", err)
+		} else {
+			st.AudioOutputs = outputs
+		}
+	}
+	if b.Systemd != nil {
+		if services, err := b.Systemd.ListServices(); err != nil {
+			fail("services", err)
+		} else {
+			st.Services = services
+		}
+	}
+	if b.Bluetooth != nil {
+		status := b.Bluetooth.GetStatus()
+		st.Bluetooth = &status
+	}
+	return st
+}
+
+// ImportState replaces the caches of every running backend with the sections
+// of st, a State as exported by State, without touching D-Bus or the audio
+// server. A missing section empties its cache; AudioServer is derived from
+// the outputs, Errors are ignored. Live signals keep updating the caches
+// afterwards.
+func (b *Backend) ImportState(st State) {
+	if b.MPRIS != nil {
+		b.MPRIS.ImportPlayers(st.Players)
+	}
+	if b.Pulse != nil {
+		b.Pulse.ImportCache(st.AudioClients, st.AudioOutputs)
+	}
+	if b.Systemd != nil {
+		b.Systemd.ImportServices(st.Services)
+	}
+	if b.Bluetooth != nil {
+		var status bluetooth.BluetoothStatus
+		if st.Bluetooth != nil {
+			status = *st.Bluetooth
+		}
+		b.Bluetooth.ImportStatus(status)
+	}
+}
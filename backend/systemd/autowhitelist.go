@@ -0,0 +1,76 @@
+package systemd
+
+import (
+	"context"
+	"path"
+	"slices"
+	"time"
+
+	"github.com/b0bbywan/go-odio-api/config"
+	"github.com/b0bbywan/go-odio-api/logger"
+)
+
+// autoWhitelisting reports whether user units matching
+// systemd.auto_whitelist_pattern are picked up once they start.
+func (s *SystemdBackend) autoWhitelisting() bool {
+	return s.config.WatchUnitsDir && len(s.config.AutoWhitelist) > 0
+}
+
+// userServices returns the configured user units followed by the
+// auto-whitelisted ones.
+func (s *SystemdBackend) userServices() []config.SystemdService {
+	s.autoUnitsMu.RLock()
+	defer s.autoUnitsMu.RUnlock()
+	if len(s.autoUnits) == 0 {
+		return s.config.UserServices
+	}
+	return append(slices.Clone(s.config.UserServices), s.autoUnits...)
+}
+
+// matchUnitPattern returns the first pattern matching the unit name.
+func matchUnitPattern(patterns []string, name string) (string, bool) {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// autoWhitelist adds a user unit that just started to the whitelist when it
+// matches systemd.auto_whitelist_pattern. Reports whether it was added.
+func (l *Listener) autoWhitelist(name string) bool {
+	b := l.backend
+	if !b.autoWhitelisting() || ValidateServiceName(name) != nil {
+		return false
+	}
+	pattern, ok := matchUnitPattern(b.config.AutoWhitelist, name)
+	if !ok {
+		return false
+	}
+
+	l.watchedMu.Lock()
+	if l.userWatched[name] {
+		l.watchedMu.Unlock()
+		return false
+	}
+	l.userWatched[name] = true
+	l.watchedMu.Unlock()
+
+	b.autoUnitsMu.Lock()
+	b.autoUnits = append(b.autoUnits, config.SystemdService{Name: name})
+	b.autoUnitsMu.Unlock()
+
+	logger.Info("[systemd] auto-whitelisted user unit %s (pattern %q)", name, pattern)
+	return true
+}
+
+// refreshUnit loads a user unit's current state into the cache and
+// publishes it.
+func (l *Listener) refreshUnit(name string) {
+	ctx, cancel := context.WithTimeout(l.backend.ctx, 5*time.Second)
+	defer cancel()
+	if unit, err := l.backend.RefreshService(ctx, name, ScopeUser); err == nil {
+		l.backend.notifyService(*unit)
+	}
+}
@@ -14,7 +14,7 @@ import (
 
 // StartFSNotifier starts listening for systemd events via fsnotify
 func (l *Listener) StartFSNotifier() error {
-	if len(l.userWatched) == 0 {
+	if len(l.userWatched) == 0 && !l.backend.autoWhitelisting() {
 		return nil
 	}
 
@@ -100,8 +100,17 @@ func (l *Listener) dispatchFSNotify(event fsnotify.Event) {
 
 	serviceName := basename[11:]
 
+	if event.Has(fsnotify.Create) && l.autoWhitelist(serviceName) && l.supportsUTMP {
+		// D-Bus signals follow it from now on: load its current state once
+		go l.refreshUnit(serviceName)
+	}
+	// With D-Bus tracking the user units, fsnotify only feeds the whitelist
+	if l.supportsUTMP {
+		return
+	}
+
 	// Filter only monitored services
-	if !l.userWatched[serviceName] {
+	if !l.Watched(serviceName, ScopeUser) {
 		return
 	}
 
@@ -1,6 +1,7 @@
 package systemd
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/b0bbywan/go-odio-api/cache"
@@ -94,3 +95,134 @@ func TestPublicServicesFiltersInternal(t *testing.T) {
 		}
 	}
 }
+
+func TestListServicesByScope(t *testing.T) {
+	backend := &SystemdBackend{cache: cache.New[[]Service](0)}
+	backend.cache.Set(cacheKey, []Service{
+		{Name: "bluetooth.service", Scope: ScopeSystem},
+		{Name: "mpd.service", Scope: ScopeUser},
+		{Name: "upgrade.service", Scope: ScopeUser, Internal: true},
+	})
+
+	tests := []struct {
+		scope UnitScope
+		want  []string
+	}{
+		{ScopeSystem, []string{"bluetooth.service"}},
+		{ScopeUser, []string{"mpd.service"}},
+	}
+	for _, tt := range tests {
+		services, err := backend.ListServicesByScope(tt.scope)
+		if err != nil {
+			t.Fatalf("ListServicesByScope(%s): %v", tt.scope, err)
+		}
+		var got []string
+		for _, svc := range services {
+			got = append(got, svc.Name)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("ListServicesByScope(%s) = %v, want %v", tt.scope, got, tt.want)
+		}
+	}
+}
+
+func TestWatchedUnits(t *testing.T) {
+	backend := &SystemdBackend{config: &config.SystemdConfig{
+		SystemServices: []config.SystemdService{{Name: "bluetooth.service"}},
+		UserServices: []config.SystemdService{
+			{Name: "mpd.service"},
+			{Name: "odio-upgrade.service", Internal: true},
+		},
+	}}
+
+	got := backend.WatchedUnits()
+	if !slices.Equal(got.System, []string{"bluetooth.service"}) {
+		t.Errorf("System = %v, want [bluetooth.service]", got.System)
+	}
+	if !slices.Equal(got.User, []string{"mpd.service"}) {
+		t.Errorf("User = %v, want [mpd.service] (internal units hidden)", got.User)
+	}
+}
+
+func TestWatchedUnitsEmpty(t *testing.T) {
+	backend := &SystemdBackend{config: &config.SystemdConfig{}}
+
+	got := backend.WatchedUnits()
+	if got.System == nil || got.User == nil {
+		t.Errorf("WatchedUnits() = %+v, want empty non-nil lists", got)
+	}
+}
+
+func TestScopeUnits(t *testing.T) {
+	backend := &SystemdBackend{
+		config: &config.SystemdConfig{
+			UserServices: []config.SystemdService{
+				{Name: "snapclient.service"},
+				{Name: "mpd.service"},
+				{Name: "odio-upgrade.service", Internal: true},
+			},
+		},
+		listener: &Listener{
+			sysWatched: map[string]bool{"bluetooth.service": true},
+			userWatched: map[string]bool{
+				"snapclient.service":   true,
+				"mpd.service":          true,
+				"odio-upgrade.service": true,
+			},
+		},
+	}
+
+	if got := backend.ScopeUnits(ScopeUser); !slices.Equal(got, []string{"mpd.service", "snapclient.service"}) {
+		t.Errorf("ScopeUnits(user) = %v, want [mpd.service snapclient.service] (sorted, internal hidden)", got)
+	}
+	if got := backend.ScopeUnits(ScopeSystem); !slices.Equal(got, []string{"bluetooth.service"}) {
+		t.Errorf("ScopeUnits(system) = %v, want [bluetooth.service]", got)
+	}
+}
+
+func TestAutoWhitelist(t *testing.T) {
+	backend := &SystemdBackend{config: &config.SystemdConfig{
+		UserServices:  []config.SystemdService{{Name: "mpd.service"}},
+		WatchUnitsDir: true,
+		AutoWhitelist: []string{"myapp-*.service"},
+	}}
+	l := &Listener{backend: backend, userWatched: map[string]bool{"mpd.service": true}}
+	backend.listener = l
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"myapp-web.service", true},
+		{"myapp-web.service", false}, // already whitelisted
+		{"other.service", false},
+		{"myapp-../x.service", false}, // not a valid unit name
+	}
+	for _, tt := range tests {
+		if got := l.autoWhitelist(tt.name); got != tt.want {
+			t.Errorf("autoWhitelist(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if err := backend.canExecute("myapp-web.service", ScopeUser); err != nil {
+		t.Errorf("canExecute(myapp-web.service) = %v, want allowed once whitelisted", err)
+	}
+	if got := backend.WatchedUnits().User; !slices.Equal(got, []string{"mpd.service", "myapp-web.service"}) {
+		t.Errorf("WatchedUnits().User = %v, want the configured then the auto-whitelisted unit", got)
+	}
+	if !slices.Equal(backend.config.UserServices, []config.SystemdService{{Name: "mpd.service"}}) {
+		t.Errorf("config.UserServices modified: %v", backend.config.UserServices)
+	}
+}
+
+func TestAutoWhitelistDisabled(t *testing.T) {
+	backend := &SystemdBackend{config: &config.SystemdConfig{
+		WatchUnitsDir: false,
+		AutoWhitelist: []string{"myapp-*.service"},
+	}}
+	l := &Listener{backend: backend, userWatched: map[string]bool{}}
+
+	if l.autoWhitelist("myapp-web.service") {
+		t.Error("autoWhitelist() without systemd.watch_units_dir should not add units")
+	}
+}
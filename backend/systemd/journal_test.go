@@ -0,0 +1,76 @@
+package systemd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/b0bbywan/go-odio-api/config"
+)
+
+func TestParseJournalLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want JournalEntry
+		ok   bool
+	}{
+		{
+			name: "text message",
+			line: `{"__REALTIME_TIMESTAMP":"1700000000123456","PRIORITY":"3","MESSAGE":"failed to open device"}`,
+			want: JournalEntry{Timestamp: time.UnixMicro(1700000000123456).UTC(), Priority: 3, Message: "failed to open device"},
+			ok:   true,
+		},
+		{
+			name: "binary message",
+			line: `{"__REALTIME_TIMESTAMP":"1700000000000000","MESSAGE":[104,105]}`,
+			want: JournalEntry{Timestamp: time.UnixMicro(1700000000000000).UTC(), Priority: 6, Message: "hi"},
+			ok:   true,
+		},
+		{name: "no message", line: `{"__REALTIME_TIMESTAMP":"1700000000000000"}`},
+		{name: "not json", line: `-- No entries --`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseJournalLine([]byte(tt.line))
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if ok && (got != tt.want) {
+				t.Errorf("parseJournalLine() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFollowJournalRejectsUnwatchedUnits(t *testing.T) {
+	backend := &SystemdBackend{config: &config.SystemdConfig{
+		SystemServices: []config.SystemdService{{Name: "bluetooth.service"}},
+		UserServices: []config.SystemdService{
+			{Name: "mpd.service"},
+			{Name: "odio-upgrade.service", Internal: true},
+		},
+	}}
+
+	tests := []struct {
+		unit  string
+		scope UnitScope
+	}{
+		{"sshd.service", ScopeSystem},
+		{"mpd.service", ScopeSystem}, // configured in the other scope
+		{"odio-upgrade.service", ScopeUser},
+	}
+	for _, tt := range tests {
+		_, err := backend.FollowJournal(context.Background(), tt.unit, tt.scope)
+		var notWatched *NotWatchedError
+		if !errors.As(err, &notWatched) {
+			t.Errorf("FollowJournal(%s, %s) error = %v, want NotWatchedError", tt.unit, tt.scope, err)
+		}
+	}
+
+	if !backend.isWatched("bluetooth.service", ScopeSystem) || !backend.isWatched("mpd.service", ScopeUser) {
+		t.Error("configured units should be watched")
+	}
+}
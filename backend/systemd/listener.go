@@ -2,12 +2,24 @@ package systemd
 
 import (
 	"context"
+	"maps"
+	"slices"
+	"time"
 
 	"github.com/godbus/dbus/v5"
 
 	"github.com/b0bbywan/go-odio-api/logger"
 )
 
+// Backoff bounds between signal connection attempts after the bus drops us.
+var (
+	reconnectBackoff    = time.Second
+	maxReconnectBackoff = 30 * time.Second
+)
+
+// signalConnector opens a bus connection subscribed to systemd signals.
+type signalConnector func(UnitScope) (*dbus.Conn, <-chan *dbus.Signal, error)
+
 func NewListener(backend *SystemdBackend) *Listener {
 	ctx, cancel := context.WithCancel(backend.ctx)
 
@@ -17,12 +29,13 @@ func NewListener(backend *SystemdBackend) *Listener {
 		sysWatched[svc.Name] = true
 	}
 
-	userWatched := make(map[string]bool, len(backend.config.UserServices))
-	for _, svc := range backend.config.UserServices {
+	userServices := backend.userServices()
+	userWatched := make(map[string]bool, len(userServices))
+	for _, svc := range userServices {
 		userWatched[svc.Name] = true
 	}
 
-	return &Listener{
+	l := &Listener{
 		backend:      backend,
 		ctx:          ctx,
 		cancel:       cancel,
@@ -31,12 +44,14 @@ func NewListener(backend *SystemdBackend) *Listener {
 		supportsUTMP: backend.config.SupportsUTMP,
 		lastState:    make(map[string]string),
 	}
+	l.connect = l.connectScope
+	return l
 }
 
 // Start starts listening for D-Bus signals directly via godbus
 func (l *Listener) Start() error {
 	// Raw D-Bus connections for signals
-	if err := l.startScope(ScopeSystem, l.sysWatched); err != nil {
+	if err := l.startScope(ScopeSystem, len(l.sysWatched) > 0); err != nil {
 		return err
 	}
 
@@ -46,22 +61,42 @@ func (l *Listener) Start() error {
 			l.Stop()
 			return err
 		}
-	} else {
-		if err := l.startScope(ScopeUser, l.userWatched); err != nil {
-			l.Stop()
-			return err
-		}
+		return nil
 	}
 
+	if err := l.startScope(ScopeUser, len(l.userWatched) > 0 || l.backend.autoWhitelisting()); err != nil {
+		l.Stop()
+		return err
+	}
+	// D-Bus tracks the user units; fsnotify only spots new ones to whitelist.
+	if l.backend.autoWhitelisting() {
+		if err := l.StartFSNotifier(); err != nil {
+			logger.Warn("[systemd] unit auto-whitelisting disabled: %v", err)
+		}
+	}
 	return nil
 }
 
-func (l *Listener) startScope(scope UnitScope, watched map[string]bool) error {
-	if len(watched) == 0 {
+func (l *Listener) startScope(scope UnitScope, enabled bool) error {
+	if !enabled {
 		logger.Debug("[systemd] no units configured for %s scope, skipping listener", scope)
 		return nil
 	}
 
+	conn, ch, err := l.connect(scope)
+	if err != nil {
+		return err
+	}
+
+	go l.listen(ch, conn, scope)
+
+	logger.Info("[systemd] %s listener started (D-Bus signal-based)", scope)
+	return nil
+}
+
+// connectScope opens a raw bus connection for a scope and subscribes it to
+// systemd signals.
+func (l *Listener) connectScope(scope UnitScope) (*dbus.Conn, <-chan *dbus.Signal, error) {
 	var conn *dbus.Conn
 	var err error
 	switch scope {
@@ -71,25 +106,28 @@ func (l *Listener) startScope(scope UnitScope, watched map[string]bool) error {
 		conn, err = dbus.ConnectSessionBus()
 	}
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	// Subscribe to systemd signals (path filters on systemd1)
 	matchRule := "type='signal',sender='org.freedesktop.systemd1'"
 
 	if err := conn.BusObject().Call("org.freedesktop.DBus.AddMatch", 0, matchRule).Err; err != nil {
-		if closeErr := conn.Close(); closeErr != nil {
-			logger.Info("Failed to close D-Bus connection: %v", closeErr)
-		}
-		return err
+		closeConn(conn)
+		return nil, nil, err
 	}
 	ch := make(chan *dbus.Signal, 10)
 	conn.Signal(ch)
+	return conn, ch, nil
+}
 
-	go l.listen(ch, conn, scope, watched)
-
-	logger.Info("[systemd] %s listener started (D-Bus signal-based)", scope)
-	return nil
+func closeConn(conn *dbus.Conn) {
+	if conn == nil {
+		return
+	}
+	if err := conn.Close(); err != nil {
+		logger.Info("Failed to close D-Bus connection: %v", err)
+	}
 }
 
 func (l *Listener) checkUnit(sig *dbus.Signal, scope UnitScope) (string, bool) {
@@ -146,24 +184,46 @@ func (l *Listener) Watched(unitName string, scope UnitScope) bool {
 	case ScopeSystem:
 		return l.sysWatched[unitName]
 	case ScopeUser:
+		l.watchedMu.RLock()
+		defer l.watchedMu.RUnlock()
 		return l.userWatched[unitName]
 	default:
 		return false
 	}
 }
 
-func (l *Listener) listen(
-	ch <-chan *dbus.Signal,
-	conn *dbus.Conn,
-	scope UnitScope,
-	watched map[string]bool,
-) {
-	defer func() {
-		if err := conn.Close(); err != nil {
-			logger.Info("Failed to close D-Bus connection: %v", err)
+// watchedNames returns the names of the units watched in scope.
+func (l *Listener) watchedNames(scope UnitScope) []string {
+	if scope == ScopeSystem {
+		return slices.Collect(maps.Keys(l.sysWatched))
+	}
+	l.watchedMu.RLock()
+	defer l.watchedMu.RUnlock()
+	return slices.Collect(maps.Keys(l.userWatched))
+}
+
+// listen handles signals until shutdown. godbus closes the signal channel when
+// the bus drops the connection (e.g. the session ends); the listener then
+// reconnects and resyncs instead of leaving service states frozen.
+func (l *Listener) listen(ch <-chan *dbus.Signal, conn *dbus.Conn, scope UnitScope) {
+	for {
+		l.consume(ch, scope)
+		closeConn(conn)
+		if l.ctx.Err() != nil {
+			return
+		}
+
+		logger.Warn("[systemd] %s bus connection lost, reconnecting", scope)
+		var ok bool
+		if conn, ch, ok = l.reconnect(scope); !ok {
+			return
 		}
-	}()
+		l.resync(scope)
+	}
+}
 
+// consume returns on shutdown or once the signal channel is closed.
+func (l *Listener) consume(ch <-chan *dbus.Signal, scope UnitScope) {
 	for {
 		select {
 		case <-l.ctx.Done():
@@ -183,6 +243,69 @@ func (l *Listener) listen(
 	}
 }
 
+// reconnect retries the signal connection with exponential backoff until it
+// succeeds or the listener is stopped.
+func (l *Listener) reconnect(scope UnitScope) (*dbus.Conn, <-chan *dbus.Signal, bool) {
+	backoff := reconnectBackoff
+	for {
+		select {
+		case <-l.ctx.Done():
+			return nil, nil, false
+		case <-time.After(backoff):
+		}
+
+		conn, ch, err := l.connect(scope)
+		if err == nil {
+			logger.Info("[systemd] %s listener reconnected", scope)
+			return conn, ch, true
+		}
+
+		backoff = min(backoff*2, maxReconnectBackoff)
+		logger.Warn("[systemd] %s listener reconnect failed, retry in %s: %v", scope, backoff, err)
+	}
+}
+
+// resync reloads every unit after a reconnect and publishes those whose state
+// changed while no signal could reach us.
+func (l *Listener) resync(scope UnitScope) {
+	if err := l.backend.redialScope(scope); err != nil {
+		logger.Warn("[systemd] failed to reconnect %s systemd API: %v", scope, err)
+	}
+
+	before := make(map[string]Service)
+	if services, ok := l.backend.cache.Get(cacheKey); ok {
+		for _, svc := range services {
+			if svc.Scope == scope {
+				before[svc.Name] = svc
+			}
+		}
+	}
+
+	l.backend.invalidateCache()
+	services, err := l.backend.ListServices()
+	if err != nil {
+		logger.Warn("[systemd] failed to reload %s units after reconnect: %v", scope, err)
+		return
+	}
+
+	// Missed signals leave the dedup state stale: start over for this scope.
+	l.lastStateMu.Lock()
+	for _, name := range l.watchedNames(scope) {
+		delete(l.lastState, stateKey(name, scope))
+	}
+	l.lastStateMu.Unlock()
+
+	for _, svc := range services {
+		if svc.Scope != scope {
+			continue
+		}
+		if old, ok := before[svc.Name]; ok && old == svc {
+			continue
+		}
+		l.backend.notifyService(svc)
+	}
+}
+
 // Stop stops the listener
 func (l *Listener) Stop() {
 	logger.Info("[systemd] stopping listener")
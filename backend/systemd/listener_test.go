@@ -0,0 +1,106 @@
+package systemd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+
+	"github.com/b0bbywan/go-odio-api/cache"
+	"github.com/b0bbywan/go-odio-api/config"
+	"github.com/b0bbywan/go-odio-api/events"
+)
+
+func newTestListener(t *testing.T) *Listener {
+	t.Helper()
+	saved := reconnectBackoff
+	reconnectBackoff = time.Millisecond
+	t.Cleanup(func() { reconnectBackoff = saved })
+
+	backend := &SystemdBackend{
+		ctx: context.Background(),
+		config: &config.SystemdConfig{
+			UserServices: []config.SystemdService{{Name: "mpd.service"}},
+		},
+		cache:  cache.New[[]Service](0),
+		events: make(chan events.Event, 4),
+	}
+	l := NewListener(backend)
+	t.Cleanup(l.Stop)
+	return l
+}
+
+func closedSignals() <-chan *dbus.Signal {
+	ch := make(chan *dbus.Signal)
+	close(ch)
+	return ch
+}
+
+func waitListen(t *testing.T, l *Listener, ch <-chan *dbus.Signal) {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		l.listen(ch, nil, ScopeUser)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("listen did not return")
+	}
+}
+
+// TestListenRetriesAfterClosedChannel: a closed signal channel (bus dropped
+// the connection) triggers reconnect attempts until the listener is stopped.
+func TestListenRetriesAfterClosedChannel(t *testing.T) {
+	l := newTestListener(t)
+	attempts := 0
+	l.connect = func(UnitScope) (*dbus.Conn, <-chan *dbus.Signal, error) {
+		attempts++
+		if attempts == 3 {
+			l.Stop()
+		}
+		return nil, nil, errors.New("session bus down")
+	}
+
+	waitListen(t, l, closedSignals())
+
+	if attempts != 3 {
+		t.Errorf("connect attempts = %d, want 3", attempts)
+	}
+}
+
+// TestListenResyncsAfterReconnect: once reconnected, the dedup state of the
+// scope is dropped so the next SubState signal is not mistaken for a repeat.
+func TestListenResyncsAfterReconnect(t *testing.T) {
+	l := newTestListener(t)
+	l.lastState[stateKey("mpd.service", ScopeUser)] = "running"
+
+	fresh := make(chan *dbus.Signal)
+	l.connect = func(UnitScope) (*dbus.Conn, <-chan *dbus.Signal, error) {
+		// Stopping here ends the listener right after the resync.
+		defer l.Stop()
+		return nil, fresh, nil
+	}
+
+	waitListen(t, l, closedSignals())
+
+	l.lastStateMu.RLock()
+	defer l.lastStateMu.RUnlock()
+	if state, ok := l.lastState[stateKey("mpd.service", ScopeUser)]; ok {
+		t.Errorf("lastState after resync = %q, want cleared", state)
+	}
+}
+
+func TestListenStopsWithoutReconnect(t *testing.T) {
+	l := newTestListener(t)
+	l.connect = func(UnitScope) (*dbus.Conn, <-chan *dbus.Signal, error) {
+		t.Error("connect called after Stop")
+		return nil, nil, errors.New("unexpected")
+	}
+	l.Stop()
+
+	waitListen(t, l, make(chan *dbus.Signal))
+}
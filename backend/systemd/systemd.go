@@ -2,6 +2,9 @@ package systemd
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
 	"time"
 
 	"github.com/coreos/go-systemd/v22/dbus"
@@ -12,29 +15,39 @@ import (
 	"github.com/b0bbywan/go-odio-api/logger"
 )
 
-// New now takes the services list from the config
-func New(ctx context.Context, config *config.SystemdConfig) (*SystemdBackend, error) {
-	if config == nil || !config.Enabled {
+// New now takes the services list from the config. ctx bounds the backend's
+// lifetime, startCtx its startup.
+func New(ctx, startCtx context.Context, cfg *config.SystemdConfig) (*SystemdBackend, error) {
+	if cfg == nil || !cfg.Enabled {
 		return nil, nil
 	}
 
-	if len(config.SystemServices) == 0 && len(config.UserServices) == 0 {
+	autoWhitelist := cfg.WatchUnitsDir && len(cfg.AutoWhitelist) > 0
+	if len(cfg.SystemServices) == 0 && len(cfg.UserServices) == 0 && !autoWhitelist {
 		logger.Debug("[systemd] no unit configured, disabling backend")
-		return nil, nil
+		return nil, fmt.Errorf("%w: no unit configured", config.ErrBackendDisabled)
 	}
 
+	// The connections are bound to ctx, their dial to startCtx.
 	var sysC, userC *dbus.Conn
 	var err error
-	if len(config.SystemServices) > 0 {
-		sysC, err = dbus.NewSystemConnectionContext(ctx)
+	if len(cfg.SystemServices) > 0 {
+		sysC, err = dialContext(startCtx, func() (*dbus.Conn, error) {
+			return dbus.NewSystemConnectionContext(ctx)
+		})
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	if len(config.UserServices) > 0 {
-		userC, err = dbus.NewUserConnectionContext(ctx)
+	if len(cfg.UserServices) > 0 || autoWhitelist {
+		userC, err = dialContext(startCtx, func() (*dbus.Conn, error) {
+			return dbus.NewUserConnectionContext(ctx)
+		})
 		if err != nil {
+			if sysC != nil {
+				sysC.Close()
+			}
 			return nil, err
 		}
 	}
@@ -43,12 +56,39 @@ func New(ctx context.Context, config *config.SystemdConfig) (*SystemdBackend, er
 		sysConn:  sysC,
 		userConn: userC,
 		ctx:      ctx,
-		config:   config,
+		config:   cfg,
 		cache:    cache.New[[]Service](0), // TTL=0 = no expiration
 		events:   make(chan events.Event, 32),
 	}, nil
 }
 
+// dialContext runs dial until it returns or ctx ends: go-systemd ties its
+// connections to the context they are dialed with, so the dial runs aside
+// and a connection it completes too late is closed.
+func dialContext(ctx context.Context, dial func() (*dbus.Conn, error)) (*dbus.Conn, error) {
+	type result struct {
+		conn *dbus.Conn
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		conn, err := dial()
+		done <- result{conn, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.conn, r.err
+	case <-ctx.Done():
+		go func() {
+			if r := <-done; r.conn != nil {
+				r.conn.Close()
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
+
 // AddInternalUserUnits registers extra user units as internal: triggerable and
 // state-tracked, but hidden from /services and service.updated events. Must be
 // called before Start, since the listener snapshots the unit list then.
@@ -92,6 +132,8 @@ func (s *SystemdBackend) Close() {
 		s.listener.Stop()
 		s.listener = nil
 	}
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
 	if s.sysConn != nil {
 		s.sysConn.Close()
 		s.sysConn = nil
@@ -122,11 +164,14 @@ func (s *SystemdBackend) notifyService(svc Service) {
 func (s *SystemdBackend) Events() <-chan events.Event { return s.events }
 
 func (b *SystemdBackend) canExecute(name string, scope UnitScope) error {
+	if err := ValidateServiceName(name); err != nil {
+		return err
+	}
 	switch scope {
 	case ScopeSystem:
 		return &PermissionSystemError{Unit: name}
 	case ScopeUser:
-		if !b.listener.userWatched[name] {
+		if !b.listener.Watched(name, ScopeUser) {
 			return &PermissionUserError{Unit: name}
 		}
 	}
@@ -150,7 +195,7 @@ func (s *SystemdBackend) Execute(
 		return err
 	}
 
-	if err := action(ctx, s.userConn, name); err != nil {
+	if err := action(ctx, s.connForScope(ScopeUser), name); err != nil {
 		return err
 	}
 
@@ -164,29 +209,62 @@ func (s *SystemdBackend) ListServices() ([]Service, error) {
 		return services, nil
 	}
 
-	// Cache miss, load from D-Bus
+	// Cache miss, load from D-Bus; a failed scope is left out
 	logger.Debug("[systemd] cache miss, loading units")
+	out, err := s.loadServices()
+	if err != nil {
+		logger.Warn("[systemd] %v", err)
+	}
+
+	// Update the cache
+	s.cache.Set(cacheKey, out)
+
+	return out, nil
+}
+
+// ReloadServices rebuilds the unit cache from D-Bus. Unlike InvalidateCache
+// then ListServices, the cached units stay readable until the new list
+// replaces them, and a failed scope keeps the whole cache.
+func (s *SystemdBackend) ReloadServices() ([]Service, error) {
+	out, err := s.loadServices()
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Set(cacheKey, out)
+	return out, nil
+}
+
+// loadServices lists the configured units of both scopes from D-Bus. It
+// returns those of the scopes that answered along with the errors of the
+// others.
+func (s *SystemdBackend) loadServices() ([]Service, error) {
 	out := make([]Service, 0, len(s.config.SystemServices)+len(s.config.UserServices))
 	start := time.Now()
 
-	sysSvcs, err := s.listServices(s.ctx, s.sysConn, ScopeSystem, s.config.SystemServices)
-	if err != nil {
-		logger.Warn("[systemd] failed to list system services: %v", err)
+	sysSvcs, sysErr := s.listServices(s.ctx, s.connForScope(ScopeSystem), ScopeSystem, s.config.SystemServices)
+	if sysErr != nil {
+		sysErr = fmt.Errorf("failed to list system services: %w", sysErr)
 	}
-	userSvcs, err := s.listServices(s.ctx, s.userConn, ScopeUser, s.config.UserServices)
-	if err != nil {
-		logger.Warn("[systemd] failed to list user services: %v", err)
+	userSvcs, userErr := s.listServices(s.ctx, s.connForScope(ScopeUser), ScopeUser, s.userServices())
+	if userErr != nil {
+		userErr = fmt.Errorf("failed to list user services: %w", userErr)
 	}
 	elapsed := time.Since(start)
 
 	out = append(out, sysSvcs...)
 	out = append(out, userSvcs...)
 	logger.Debug("[systemd] loaded %d units in %s", len(out), elapsed)
+	return out, errors.Join(sysErr, userErr)
+}
 
-	// Update the cache
-	s.cache.Set(cacheKey, out)
-
-	return out, nil
+// ImportServices replaces the unit cache, e.g. with a debug export, without
+// asking systemd.
+func (s *SystemdBackend) ImportServices(services []Service) {
+	if services == nil {
+		services = []Service{}
+	}
+	s.cache.Clear()
+	s.cache.Set(cacheKey, services)
 }
 
 // PublicServices returns the configured services minus internal ones, for the
@@ -205,6 +283,53 @@ func (s *SystemdBackend) PublicServices() ([]Service, error) {
 	return public, nil
 }
 
+// ListServicesByScope returns the public services of a single scope.
+func (s *SystemdBackend) ListServicesByScope(scope UnitScope) ([]Service, error) {
+	public, err := s.PublicServices()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Service, 0, len(public))
+	for _, svc := range public {
+		if svc.Scope == scope {
+			out = append(out, svc)
+		}
+	}
+	return out, nil
+}
+
+// WatchedUnits returns the names of the configured public units grouped by
+// scope, straight from the config: no D-Bus call nor cache read.
+func (s *SystemdBackend) WatchedUnits() WatchedUnits {
+	return WatchedUnits{
+		System: publicNames(s.config.SystemServices),
+		User:   publicNames(s.userServices()),
+	}
+}
+
+// ScopeUnits returns the sorted public units the listener watches in scope.
+// Only this whitelist is ever iterated for scope-wide actions, never input.
+func (s *SystemdBackend) ScopeUnits(scope UnitScope) []string {
+	if s.listener == nil {
+		return nil
+	}
+	names := slices.DeleteFunc(s.listener.watchedNames(scope), func(name string) bool {
+		return s.configuredInternal(name, scope)
+	})
+	slices.Sort(names)
+	return names
+}
+
+func publicNames(services []config.SystemdService) []string {
+	names := make([]string, 0, len(services))
+	for _, svc := range services {
+		if !svc.Internal {
+			names = append(names, svc.Name)
+		}
+	}
+	return names
+}
+
 // GetService retrieves a specific service from the cache
 func (s *SystemdBackend) GetService(name string, scope UnitScope) (*Service, bool) {
 	services, ok := s.cache.Get(cacheKey)
@@ -222,28 +347,22 @@ func (s *SystemdBackend) GetService(name string, scope UnitScope) (*Service, boo
 
 // UpdateService updates a specific service in the cache
 func (s *SystemdBackend) UpdateService(updated Service) error {
-	services, ok := s.cache.Get(cacheKey)
+	ok := s.cache.Modify(cacheKey, func(services []Service) []Service {
+		services = slices.Clone(services)
+		for i, svc := range services {
+			if svc.Name == updated.Name && svc.Scope == updated.Scope {
+				services[i] = updated
+				return services
+			}
+		}
+		// Service not in cache, add it
+		return append(services, updated)
+	})
 	if !ok {
 		// If no cache, reload everything
 		_, err := s.ListServices()
 		return err
 	}
-
-	found := false
-	for i, svc := range services {
-		if svc.Name == updated.Name && svc.Scope == updated.Scope {
-			services[i] = updated
-			found = true
-			break
-		}
-	}
-
-	if !found {
-		// Service not in cache, add it
-		services = append(services, updated)
-	}
-
-	s.cache.Set(cacheKey, services)
 	return nil
 }
 
@@ -364,6 +483,12 @@ func (s *SystemdBackend) listServices(
 			} else {
 				svc.Description = description.Value.Value().(string)
 			}
+			changed, err := conn.GetUnitPropertyContext(ctx, unit.Name, "StateChangeTimestamp")
+			if err != nil {
+				logger.Warn("[systemd] failed to get %s StateChangeTimestamp: %v", unit.Name, err)
+			} else {
+				svc.Since = usecTime(changed.Value.Value())
+			}
 
 			services = append(services, svc)
 		}
@@ -399,18 +524,57 @@ func (s *SystemdBackend) StopService(name string, scope UnitScope) error {
 	return s.Execute(s.ctx, name, scope, stopUnit)
 }
 
+// StopServiceAndWait stops a unit like StopService, then blocks until its
+// ActiveState is inactive or failed, or timeout expires (StopTimeoutError).
+// A timeout <= 0 uses systemd.timeout.
+func (s *SystemdBackend) StopServiceAndWait(name string, scope UnitScope, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = s.config.Timeout
+	}
+	logger.Debug("[systemd] stopping service %s/%s and waiting up to %s", scope, name, timeout)
+	ctx, cancel := context.WithTimeout(s.ctx, timeout)
+	defer cancel()
+	return s.Execute(ctx, name, scope, stopUnitAndWait)
+}
+
 func (s *SystemdBackend) RestartService(name string, scope UnitScope) error {
 	logger.Debug("[systemd] restarting service %s/%s", scope, name)
 	return s.Execute(s.ctx, name, scope, restartUnit)
 }
 
 func (s *SystemdBackend) connForScope(scope UnitScope) *dbus.Conn {
+	s.connMu.RLock()
+	defer s.connMu.RUnlock()
 	if scope == ScopeUser {
 		return s.userConn
 	}
 	return s.sysConn
 }
 
+// redialScope replaces a scope's systemd connection once the bus dropped it,
+// so the reload after a listener reconnect does not hit the dead one.
+func (s *SystemdBackend) redialScope(scope UnitScope) error {
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+
+	conn := &s.sysConn
+	dial := dbus.NewSystemConnectionContext
+	if scope == ScopeUser {
+		conn, dial = &s.userConn, dbus.NewUserConnectionContext
+	}
+	if *conn == nil || (*conn).Connected() {
+		return nil
+	}
+
+	fresh, err := dial(s.ctx)
+	if err != nil {
+		return err
+	}
+	(*conn).Close()
+	*conn = fresh
+	return nil
+}
+
 // CacheUpdatedAt returns the last time the service cache was written to.
 func (s *SystemdBackend) CacheUpdatedAt() time.Time {
 	return s.cache.UpdatedAt()
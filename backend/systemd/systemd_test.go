@@ -1,7 +1,15 @@
 package systemd
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
 
 	"github.com/b0bbywan/go-odio-api/cache"
 )
@@ -230,6 +238,34 @@ func TestUpdateServiceAddNew(t *testing.T) {
 	}
 }
 
+// Concurrent updates of different services must all land: none may write
+// back a stale list over another's change (-race enforces the rest).
+func TestUpdateServiceConcurrent(t *testing.T) {
+	backend := &SystemdBackend{
+		cache: cache.New[[]Service](0),
+	}
+	backend.cache.Set(cacheKey, []Service{})
+
+	const n = 20
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			name := fmt.Sprintf("svc%d.service", i)
+			if err := backend.UpdateService(Service{Name: name, Scope: ScopeUser}); err != nil {
+				t.Errorf("UpdateService(%s): %v", name, err)
+			}
+			_, _ = backend.cache.Get(cacheKey)
+		}()
+	}
+	wg.Wait()
+
+	if services, _ := backend.cache.Get(cacheKey); len(services) != n {
+		t.Errorf("cache holds %d services, want %d", len(services), n)
+	}
+}
+
 func TestInvalidateCache(t *testing.T) {
 	backend := &SystemdBackend{
 		cache: cache.New[[]Service](0),
@@ -445,6 +481,22 @@ func TestCanExecute_UserScope(t *testing.T) {
 	}
 }
 
+func TestCanExecute_InvalidUnitName(t *testing.T) {
+	backend := &SystemdBackend{
+		listener: &Listener{
+			sysWatched:  map[string]bool{},
+			userWatched: map[string]bool{"mympd": true},
+		},
+	}
+
+	for _, scope := range []UnitScope{ScopeUser, ScopeSystem} {
+		var invalidErr *InvalidUnitNameError
+		if err := backend.canExecute("mympd", scope); !errors.As(err, &invalidErr) {
+			t.Errorf("canExecute(mympd, %s) = %v, want InvalidUnitNameError", scope, err)
+		}
+	}
+}
+
 func TestCanExecute_WhitelistEnforcement(t *testing.T) {
 	// Test that ONLY explicitly whitelisted user units can be executed
 	backend := &SystemdBackend{
@@ -473,9 +525,34 @@ func TestCanExecute_WhitelistEnforcement(t *testing.T) {
 			if err == nil {
 				t.Errorf("canExecute(%q, ScopeUser) should block non-whitelisted service", service)
 			}
+			// Path traversal is rejected as a malformed name before the
+			// whitelist is even consulted.
+			if strings.Contains(service, "/") {
+				if _, ok := err.(*InvalidUnitNameError); !ok {
+					t.Errorf("canExecute should return InvalidUnitNameError, got: %T", err)
+				}
+				return
+			}
 			if _, ok := err.(*PermissionUserError); !ok {
 				t.Errorf("canExecute should return PermissionUserError, got: %T", err)
 			}
 		})
 	}
 }
+
+// TestDialContextGivesUpOnContext: a systemd dial that hangs does not outlive
+// its context.
+func TestDialContextGivesUpOnContext(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	conn, err := dialContext(ctx, func() (*dbus.Conn, error) {
+		<-release
+		return nil, errors.New("too late")
+	})
+	if conn != nil || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("dialContext() = %v, %v, want nil, %v", conn, err, context.DeadlineExceeded)
+	}
+}
@@ -2,7 +2,9 @@ package systemd
 
 import (
 	"context"
+	"fmt"
 	"sync"
+	"time"
 
 	"github.com/coreos/go-systemd/v22/dbus"
 
@@ -17,13 +19,17 @@ type Listener struct {
 	ctx          context.Context
 	cancel       context.CancelFunc
 	sysWatched   map[string]bool
-	userWatched  map[string]bool
+	userWatched  map[string]bool // grows with auto-whitelisted units
+	watchedMu    sync.RWMutex    // guards userWatched
 	supportsUTMP bool
 
 	// Deduplication: last known state per service/scope
 	lastState   map[string]string
 	lastStateMu sync.RWMutex
 	watcherMap  sync.Map
+
+	// connect opens a signal connection for a scope; swapped out in tests.
+	connect signalConnector
 }
 
 type UnitScope string
@@ -35,6 +41,8 @@ const (
 )
 
 type SystemdBackend struct {
+	// connMu guards the connections, replaced when the listener reconnects.
+	connMu   sync.RWMutex
 	sysConn  *dbus.Conn
 	userConn *dbus.Conn
 	ctx      context.Context
@@ -46,6 +54,11 @@ type SystemdBackend struct {
 	// listener for systemd changes
 	listener *Listener
 
+	// User units auto-whitelisted at runtime (systemd.auto_whitelist_pattern),
+	// listed along the configured ones.
+	autoUnits   []config.SystemdService
+	autoUnitsMu sync.RWMutex
+
 	events chan events.Event
 }
 
@@ -57,10 +70,23 @@ type Service struct {
 	Enabled     bool      `json:"enabled"`
 	Exists      bool      `json:"exists"`
 	Description string    `json:"description,omitempty"`
+	Since       time.Time `json:"since,omitzero"` // entered its current active state
 	URL         string    `json:"url,omitempty"`
 	Internal    bool      `json:"-"` // triggerable but hidden from listings/events
 }
 
+// WatchedUnits lists the unit names odio-api manages, by scope.
+type WatchedUnits struct {
+	System []string `json:"system"`
+	User   []string `json:"user"`
+}
+
+// BatchRequest applies one action to several units of the same scope.
+type BatchRequest struct {
+	Action string   `json:"action"`
+	Units  []string `json:"units"`
+}
+
 type unitActionFunc func(ctx context.Context, conn *dbus.Conn, name string) error
 
 type PermissionSystemError struct {
@@ -78,3 +104,36 @@ type PermissionUserError struct {
 func (e *PermissionUserError) Error() string {
 	return "cannot act on unmanaged user unit: " + e.Unit
 }
+
+// InvalidUnitNameError is returned for a malformed unit name, before any
+// permission check.
+type InvalidUnitNameError struct {
+	Unit   string
+	Reason string
+}
+
+func (e *InvalidUnitNameError) Error() string {
+	return "invalid unit name: " + e.Reason
+}
+
+// NotWatchedError is returned when reading a unit that is not in the
+// configured whitelist of its scope.
+type NotWatchedError struct {
+	Unit  string
+	Scope UnitScope
+}
+
+func (e *NotWatchedError) Error() string {
+	return fmt.Sprintf("unit %s is not watched in %s scope", e.Unit, e.Scope)
+}
+
+// StopTimeoutError is returned when a stopped unit is still not inactive
+// once the wait is over.
+type StopTimeoutError struct {
+	Unit  string
+	State string // last ActiveState seen
+}
+
+func (e *StopTimeoutError) Error() string {
+	return fmt.Sprintf("unit %s still %s after stop", e.Unit, e.State)
+}
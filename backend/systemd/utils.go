@@ -2,10 +2,14 @@ package systemd
 
 import (
 	"context"
+	"errors"
 	"strings"
+	"time"
 
 	sysdbus "github.com/coreos/go-systemd/v22/dbus"
 	"github.com/godbus/dbus/v5"
+
+	"github.com/b0bbywan/go-odio-api/config"
 )
 
 // unitNameFromPath extracts the unit name from the D-Bus path
@@ -67,6 +71,15 @@ func stateKey(name string, scope UnitScope) string {
 	return string(scope) + "/" + name
 }
 
+// ValidateServiceName checks a unit name against config.ValidateUnitName, the
+// rules systemd.system and systemd.user entries are held to.
+func ValidateServiceName(name string) error {
+	if err := config.ValidateUnitName(name); err != nil {
+		return &InvalidUnitNameError{Unit: name, Reason: err.Error()}
+	}
+	return nil
+}
+
 func serviceFromProps(name string, scope UnitScope, props map[string]interface{}) Service {
 	svc := Service{
 		Name:  name,
@@ -89,10 +102,38 @@ func serviceFromProps(name string, scope UnitScope, props map[string]interface{}
 	if desc, ok := props["Description"].(string); ok {
 		svc.Description = desc
 	}
+	svc.Since = stateSince(props)
 
 	return svc
 }
 
+// stateSince reads when the unit entered its current state from
+// StateChangeTimestamp, falling back to ActiveEnterTimestamp or
+// InactiveEnterTimestamp for the current state; zero when none is set.
+func stateSince(props map[string]interface{}) time.Time {
+	if t := usecTime(props["StateChangeTimestamp"]); !t.IsZero() {
+		return t
+	}
+	switch props["ActiveState"] {
+	case "active":
+		return usecTime(props["ActiveEnterTimestamp"])
+	case "inactive", "failed":
+		return usecTime(props["InactiveEnterTimestamp"])
+	}
+	return time.Time{}
+}
+
+// usecTime converts a systemd timestamp property (µs since the epoch, 0 when
+// the transition never happened) to a time, zero when unset. A value, not a
+// pointer, so that Service compares by value.
+func usecTime(v interface{}) time.Time {
+	usec, ok := v.(uint64)
+	if !ok || usec == 0 {
+		return time.Time{}
+	}
+	return time.UnixMicro(int64(usec))
+}
+
 func startUnit(ctx context.Context, conn *sysdbus.Conn, name string) error {
 	return doUnitJob(ctx, func(ch chan<- string) (int, error) {
 		return conn.StartUnitContext(ctx, name, "replace", ch)
@@ -112,6 +153,55 @@ func stopUnit(ctx context.Context, conn *sysdbus.Conn, name string) error {
 	})
 }
 
+// stopPollInterval is how often stopUnitAndWait reads ActiveState.
+var stopPollInterval = 200 * time.Millisecond
+
+// stopUnitAndWait stops a unit, then waits for it to actually be inactive (or
+// failed): the stop job completing does not mean ExecStop and the kill are
+// over. ctx bounds the wait.
+func stopUnitAndWait(ctx context.Context, conn *sysdbus.Conn, name string) error {
+	if err := stopUnit(ctx, conn, name); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return &StopTimeoutError{Unit: name, State: "stopping"}
+		}
+		return err
+	}
+	return waitInactive(ctx, name, func(ctx context.Context) (string, error) {
+		prop, err := conn.GetUnitPropertyContext(ctx, name, "ActiveState")
+		if err != nil {
+			return "", err
+		}
+		state, _ := prop.Value.Value().(string)
+		return state, nil
+	})
+}
+
+// waitInactive polls activeState until it reports inactive or failed. Past
+// ctx's deadline it is a StopTimeoutError carrying the last state seen.
+func waitInactive(ctx context.Context, name string, activeState func(context.Context) (string, error)) error {
+	ticker := time.NewTicker(stopPollInterval)
+	defer ticker.Stop()
+
+	last := "unknown"
+	for {
+		state, err := activeState(ctx)
+		switch {
+		case err == nil && (state == "inactive" || state == "failed"):
+			return nil
+		case err == nil:
+			last = state
+		case ctx.Err() == nil:
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return &StopTimeoutError{Unit: name, State: last}
+		case <-ticker.C:
+		}
+	}
+}
+
 func restartUnit(ctx context.Context, conn *sysdbus.Conn, name string) error {
 	return doUnitJob(ctx, func(ch chan<- string) (int, error) {
 		return conn.RestartUnitContext(ctx, name, "replace", ch)
@@ -148,8 +238,12 @@ func doUnitJob(
 		return err
 	}
 
-	<-ch
-	return nil
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 func ParseUnitScope(v string) (UnitScope, bool) {
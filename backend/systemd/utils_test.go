@@ -1,7 +1,10 @@
 package systemd
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/godbus/dbus/v5"
 )
@@ -285,6 +288,64 @@ func TestServiceFromProps(t *testing.T) {
 	}
 }
 
+func TestServiceFromPropsSince(t *testing.T) {
+	changed := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	entered := changed.Add(-time.Hour)
+
+	tests := []struct {
+		name  string
+		props map[string]interface{}
+		want  time.Time
+	}{
+		{
+			name: "StateChangeTimestamp wins",
+			props: map[string]interface{}{
+				"UnitFileState":        "enabled",
+				"ActiveState":          "active",
+				"SubState":             "running",
+				"StateChangeTimestamp": uint64(changed.UnixMicro()),
+				"ActiveEnterTimestamp": uint64(entered.UnixMicro()),
+			},
+			want: changed,
+		},
+		{
+			name: "failed unit falls back to InactiveEnterTimestamp",
+			props: map[string]interface{}{
+				"UnitFileState":          "enabled",
+				"ActiveState":            "failed",
+				"SubState":               "failed",
+				"StateChangeTimestamp":   uint64(0),
+				"ActiveEnterTimestamp":   uint64(entered.UnixMicro()),
+				"InactiveEnterTimestamp": uint64(changed.UnixMicro()),
+			},
+			want: changed,
+		},
+		{
+			name: "never started",
+			props: map[string]interface{}{
+				"UnitFileState":          "disabled",
+				"ActiveState":            "inactive",
+				"SubState":               "dead",
+				"StateChangeTimestamp":   uint64(0),
+				"InactiveEnterTimestamp": uint64(0),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := serviceFromProps("mpd.service", ScopeUser, tt.props).Since
+			if !got.Equal(tt.want) {
+				t.Errorf("Since = %v, want %v", got, tt.want)
+			}
+			// resync skips unchanged units with ==: Since must compare by value
+			if again := serviceFromProps("mpd.service", ScopeUser, tt.props); again != serviceFromProps("mpd.service", ScopeUser, tt.props) {
+				t.Errorf("services built from the same props differ: %+v", again)
+			}
+		})
+	}
+}
+
 func TestParseUnitScope(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -330,3 +391,49 @@ func TestParseUnitScope(t *testing.T) {
 		})
 	}
 }
+
+func TestWaitInactive(t *testing.T) {
+	saved := stopPollInterval
+	stopPollInterval = time.Millisecond
+	t.Cleanup(func() { stopPollInterval = saved })
+
+	t.Run("returns once inactive", func(t *testing.T) {
+		states := []string{"active", "deactivating", "inactive"}
+		calls := 0
+		err := waitInactive(context.Background(), "mpd.service", func(context.Context) (string, error) {
+			state := states[min(calls, len(states)-1)]
+			calls++
+			return state, nil
+		})
+		if err != nil {
+			t.Fatalf("waitInactive() = %v, want nil", err)
+		}
+		if calls != len(states) {
+			t.Errorf("polled %d times, want %d", calls, len(states))
+		}
+	})
+
+	t.Run("failed counts as stopped", func(t *testing.T) {
+		err := waitInactive(context.Background(), "mpd.service", func(context.Context) (string, error) {
+			return "failed", nil
+		})
+		if err != nil {
+			t.Fatalf("waitInactive() = %v, want nil", err)
+		}
+	})
+
+	t.Run("times out with last state", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		err := waitInactive(ctx, "mpd.service", func(context.Context) (string, error) {
+			return "deactivating", nil
+		})
+		var timeoutErr *StopTimeoutError
+		if !errors.As(err, &timeoutErr) {
+			t.Fatalf("waitInactive() = %v, want StopTimeoutError", err)
+		}
+		if timeoutErr.State != "deactivating" {
+			t.Errorf("State = %q, want deactivating", timeoutErr.State)
+		}
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"time"
@@ -15,14 +16,15 @@ import (
 	"github.com/b0bbywan/go-odio-api/logger"
 )
 
-// New returns nil when the backend is disabled or has no result file configured.
+// New returns nil when the backend is disabled, and wraps
+// config.ErrBackendDisabled when no result file is configured.
 func New(ctx context.Context, cfg *config.UpgradeConfig, sysd *systemd.SystemdBackend) (*UpgradeBackend, error) {
 	if cfg == nil || !cfg.Enabled {
 		return nil, nil
 	}
 	if cfg.ResultFile == "" {
 		logger.Warn("[upgrade] enabled but no resultFile configured, disabling backend")
-		return nil, nil
+		return nil, fmt.Errorf("%w: no resultFile configured", config.ErrBackendDisabled)
 	}
 
 	u := &UpgradeBackend{
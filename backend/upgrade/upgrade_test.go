@@ -3,6 +3,7 @@ package upgrade
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net"
 	"os"
 	"path/filepath"
@@ -90,7 +91,11 @@ func TestNewDisabled(t *testing.T) {
 	for name, cfg := range cases {
 		t.Run(name, func(t *testing.T) {
 			u, err := New(ctx, cfg, nil)
-			if err != nil {
+			if cfg != nil && cfg.Enabled {
+				if !errors.Is(err, config.ErrBackendDisabled) {
+					t.Fatalf("New error = %v, want %v", err, config.ErrBackendDisabled)
+				}
+			} else if err != nil {
 				t.Fatalf("New: %v", err)
 			}
 			if u != nil {
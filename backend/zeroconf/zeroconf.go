@@ -2,6 +2,9 @@ package zeroconf
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"strings"
 	"sync"
 
 	"github.com/grandcat/zeroconf"
@@ -14,24 +17,29 @@ type ZeroConfBackend struct {
 	Config *config.ZeroConfig
 
 	server *zeroconf.Server
+	txt    []string // announced TXT records, Config.TxtRecords until updated
 	ctx    context.Context
 	cancel context.CancelFunc
 	mu     sync.Mutex
 }
 
+// maxTxtRecordLen is the longest string a DNS TXT record can carry.
+const maxTxtRecordLen = 255
+
 func New(ctx context.Context, cfg *config.ZeroConfig) (*ZeroConfBackend, error) {
 	if !cfg.Enabled {
 		return nil, nil
 	}
 	if len(cfg.Listen) == 0 {
 		logger.Debug("[zeroconf] no interface selected, zeroconf disabled")
-		return nil, nil
+		return nil, fmt.Errorf("%w: no interface selected", config.ErrBackendDisabled)
 	}
 
 	subCtx, cancel := context.WithCancel(ctx)
 
 	return &ZeroConfBackend{
 		Config: cfg,
+		txt:    slices.Clone(cfg.TxtRecords),
 		ctx:    subCtx,
 		cancel: cancel,
 	}, nil
@@ -50,7 +58,7 @@ func (z *ZeroConfBackend) Start() error {
 		z.Config.ServiceType,
 		z.Config.Domain,
 		z.Config.Port,
-		z.Config.TxtRecords,
+		z.txt,
 		z.Config.Listen,
 	)
 	if err != nil {
@@ -69,6 +77,46 @@ func (z *ZeroConfBackend) Start() error {
 	return nil
 }
 
+// TxtRecords returns the TXT records currently announced.
+func (z *ZeroConfBackend) TxtRecords() []string {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	return slices.Clone(z.txt)
+}
+
+// UpdateTxtRecords replaces the announced TXT records. A published service
+// re-announces them in place, without unregistering; browsers that cached
+// the previous records may still serve them until their TTL expires. Before
+// Start, the records are only kept for the registration.
+func (z *ZeroConfBackend) UpdateTxtRecords(records []string) error {
+	for _, r := range records {
+		if err := validateTxtRecord(r); err != nil {
+			return err
+		}
+	}
+
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	z.txt = slices.Clone(records)
+	if z.server != nil {
+		z.server.SetText(slices.Clone(records))
+		logger.Debug("[zeroconf] '%s' TXT records updated: %v", z.Config.InstanceName, records)
+	}
+	return nil
+}
+
+// validateTxtRecord checks a key=value TXT string (RFC 6763 §6).
+func validateTxtRecord(r string) error {
+	if len(r) > maxTxtRecordLen {
+		return fmt.Errorf("TXT record %q exceeds %d bytes", r, maxTxtRecordLen)
+	}
+	key, _, _ := strings.Cut(r, "=")
+	if key == "" {
+		return fmt.Errorf("TXT record %q has an empty key", r)
+	}
+	return nil
+}
+
 func (z *ZeroConfBackend) Close() {
 	z.mu.Lock()
 	defer z.mu.Unlock()
@@ -3,7 +3,10 @@ package zeroconf
 
 import (
 	"context"
+	"errors"
 	"net"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/b0bbywan/go-odio-api/config"
@@ -28,8 +31,8 @@ func TestNew_NoInterfaces(t *testing.T) {
 	}
 	backend, err := New(context.Background(), cfg)
 
-	if err != nil {
-		t.Errorf("New() with no interfaces returned error: %v", err)
+	if !errors.Is(err, config.ErrBackendDisabled) {
+		t.Errorf("New() with no interfaces error = %v, want %v", err, config.ErrBackendDisabled)
 	}
 	if backend != nil {
 		t.Error("New() with no interfaces should return nil backend")
@@ -82,6 +85,31 @@ func TestNew_WithInterfaces(t *testing.T) {
 	backend.Close()
 }
 
+func TestUpdateTxtRecords(t *testing.T) {
+	cfg := &config.ZeroConfig{TxtRecords: []string{"version=test"}}
+	z := &ZeroConfBackend{Config: cfg, txt: cfg.TxtRecords}
+
+	// Not published yet: the records are kept for the registration.
+	if err := z.UpdateTxtRecords([]string{"version=test", "bt=1", "flag"}); err != nil {
+		t.Fatalf("UpdateTxtRecords() error = %v", err)
+	}
+	if got := z.TxtRecords(); !slices.Equal(got, []string{"version=test", "bt=1", "flag"}) {
+		t.Errorf("TxtRecords() = %v", got)
+	}
+	if !slices.Equal(cfg.TxtRecords, []string{"version=test"}) {
+		t.Errorf("config records modified: %v", cfg.TxtRecords)
+	}
+
+	for _, bad := range []string{"", "=1", "k=" + strings.Repeat("x", maxTxtRecordLen)} {
+		if err := z.UpdateTxtRecords([]string{bad}); err == nil {
+			t.Errorf("UpdateTxtRecords([%q]) error = nil, want an error", bad)
+		}
+	}
+	if got := z.TxtRecords(); len(got) != 3 {
+		t.Errorf("TxtRecords() after rejected updates = %v, want unchanged", got)
+	}
+}
+
 func TestClose_NilServer(t *testing.T) {
 	z := &ZeroConfBackend{}
 	// Should not panic
@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"reflect"
 	"sync"
 	"time"
 )
@@ -54,6 +55,44 @@ func (c *Cache[T]) Set(key string, value T) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	c.set(key, value)
+}
+
+// CompareAndSwap stores newVal under key only if the live value is still
+// deeply equal to expected, and reports whether it did. A missing or expired
+// key never matches.
+func (c *Cache[T]) CompareAndSwap(key string, expected, newVal T) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, exists := c.entries[key]
+	if !exists || entry.IsExpired() || !reflect.DeepEqual(entry.Value, expected) {
+		return false
+	}
+	c.set(key, newVal)
+	return true
+}
+
+// Modify replaces the value under key with fn's result, holding the write
+// lock throughout so concurrent read-modify-writes cannot drop each other.
+// fn must not call back into the cache. Values handed out by Get may share
+// memory with the one fn receives: fn should copy slices and maps before
+// changing them. Returns false without calling fn when the key is missing or
+// expired.
+func (c *Cache[T]) Modify(key string, fn func(T) T) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, exists := c.entries[key]
+	if !exists || entry.IsExpired() {
+		return false
+	}
+	c.set(key, fn(entry.Value))
+	return true
+}
+
+// set stores value under key; c.mu must be held.
+func (c *Cache[T]) set(key string, value T) {
 	var expiresAt time.Time
 	if c.ttl > 0 {
 		expiresAt = time.Now().Add(c.ttl)
@@ -67,6 +106,29 @@ func (c *Cache[T]) Set(key string, value T) {
 	c.updatedAt = time.Now()
 }
 
+// Values returns the unexpired values, in no particular order.
+func (c *Cache[T]) Values() []T {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	values := make([]T, 0, len(c.entries))
+	for _, entry := range c.entries {
+		if !entry.IsExpired() {
+			values = append(values, entry.Value)
+		}
+	}
+	return values
+}
+
+// Len returns the number of stored entries, expired ones included until
+// CleanExpired drops them.
+func (c *Cache[T]) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return len(c.entries)
+}
+
 func (c *Cache[T]) Delete(key string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"sync"
 	"testing"
 	"time"
 )
@@ -100,8 +101,16 @@ func TestCacheCleanExpired(t *testing.T) {
 
 	c.Set("key3", "value3") // This one should not expire
 
+	if n := c.Len(); n != 3 {
+		t.Fatalf("Len() = %d before cleaning, want expired entries counted", n)
+	}
+
 	c.CleanExpired()
 
+	if n := c.Len(); n != 1 {
+		t.Fatalf("Len() = %d after cleaning, want 1", n)
+	}
+
 	_, exists1 := c.Get("key1")
 	_, exists2 := c.Get("key2")
 	_, exists3 := c.Get("key3")
@@ -114,6 +123,20 @@ func TestCacheCleanExpired(t *testing.T) {
 	}
 }
 
+func TestCacheValues(t *testing.T) {
+	c := New[string](100 * time.Millisecond)
+	c.Set("key1", "value1")
+
+	time.Sleep(150 * time.Millisecond)
+
+	c.Set("key2", "value2")
+
+	got := c.Values()
+	if len(got) != 1 || got[0] != "value2" {
+		t.Fatalf("Values() = %v, want [value2] (expired entries skipped)", got)
+	}
+}
+
 func TestCacheUpdatedAt(t *testing.T) {
 	c := New[string](0)
 
@@ -170,3 +193,53 @@ func TestCacheThreadSafety(t *testing.T) {
 	// Should not panic from race conditions
 	_, _ = c.Get("key")
 }
+
+func TestCacheCompareAndSwap(t *testing.T) {
+	c := New[[]string](0)
+	if c.CompareAndSwap("key", nil, []string{"a"}) {
+		t.Error("CompareAndSwap() on a missing key = true, want false")
+	}
+
+	c.Set("key", []string{"a"})
+	if c.CompareAndSwap("key", []string{"b"}, []string{"c"}) {
+		t.Error("CompareAndSwap() with a stale expected value = true, want false")
+	}
+	if !c.CompareAndSwap("key", []string{"a"}, []string{"a", "b"}) {
+		t.Error("CompareAndSwap() with a deeply equal expected value = false, want true")
+	}
+	if got, _ := c.Get("key"); len(got) != 2 {
+		t.Errorf("Get() = %v, want [a b]", got)
+	}
+
+	expiring := New[int](10 * time.Millisecond)
+	expiring.Set("key", 1)
+	time.Sleep(20 * time.Millisecond)
+	if expiring.CompareAndSwap("key", 1, 2) {
+		t.Error("CompareAndSwap() on an expired key = true, want false")
+	}
+}
+
+func TestCacheModify(t *testing.T) {
+	c := New[int](0)
+	if c.Modify("key", func(v int) int { t.Error("fn called for a missing key"); return v }) {
+		t.Error("Modify() on a missing key = true, want false")
+	}
+
+	c.Set("key", 0)
+	const goroutines, increments = 10, 100
+	var wg sync.WaitGroup
+	for range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range increments {
+				c.Modify("key", func(v int) int { return v + 1 })
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got, _ := c.Get("key"); got != goroutines*increments {
+		t.Errorf("Get() = %d after concurrent Modify, want %d (an increment was lost)", got, goroutines*increments)
+	}
+}
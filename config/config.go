@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"os"
@@ -11,6 +12,7 @@ import (
 	"time"
 
 	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
 
 	"github.com/b0bbywan/go-odio-api/logger"
 )
@@ -24,12 +26,21 @@ const (
 // AppVersion is set at build time via -ldflags "-X github.com/b0bbywan/go-odio-api/config.AppVersion=x.y.z"
 var AppVersion = "dev"
 
+// ErrBackendDisabled is wrapped by a backend constructor that turns itself off
+// although enabled in the config; the wrapping error carries the reason.
+var ErrBackendDisabled = errors.New("backend disabled")
+
 type Config struct {
+	ALSA       *ALSAConfig
 	Api        *ApiConfig
 	Bluetooth  *BluetoothConfig
+	Events     *EventsConfig
+	GPIO       *GPIOConfig
 	Login1     *Login1Config
 	MPRIS      *MPRISConfig
+	Plugins    *PluginConfig
 	Pulseaudio *PulseAudioConfig
+	Snapcast   *SnapcastConfig
 	Systemd    *SystemdConfig
 	Upgrade    *UpgradeConfig
 	Zeroconf   *ZeroConfig
@@ -37,7 +48,15 @@ type Config struct {
 }
 
 type UIConfig struct {
-	Enabled bool
+	Enabled         bool
+	RefreshInterval time.Duration // dashboard polling cadence while the SSE stream is down
+	Locale          string        // UI translation, e.g. "en", "fr"; unknown ones fall back to "en"
+	BasePath        string        // public URL prefix of the UI behind a reverse proxy, default /ui
+	ClientTimeout   time.Duration // bound on each API call the dashboard makes while rendering
+}
+
+type EventsConfig struct {
+	DedupWindow time.Duration // identical consecutive events of a type within it are dropped; 0 = off
 }
 
 type SSEConfig struct {
@@ -48,14 +67,46 @@ type CORSConfig struct {
 	Origins []string // allowed origins; ["*"] for wildcard
 }
 
+// DebugConfig enables GET /debug/state.
+type DebugConfig struct {
+	Settings map[string]any // effective settings with sensitive values redacted
+}
+
 type ApiConfig struct {
 	Enabled bool
 	Listens []string
-	Port    int
+	Socket  string // unix socket path served alongside Listens; "" = none
+	// take the listening sockets from systemd socket activation instead of
+	// binding Listens and Socket
+	SystemdSocket bool
+	Port          int
+	MaxBodyBytes  int64 // request body cap, enforced by the API server
+	ReadOnly      bool  // reject every mutating method with 405
+	// wrap JSON responses as {"data", "meta"} and errors as {"error"}
+	Envelope bool
+	// let the server fetch http(s) covers itself (art colors); off, only
+	// local artwork is read
+	RemoteArtwork bool
+	// how long shutdown waits for in-flight requests before closing them
+	ShutdownTimeout time.Duration
+
+	UI    *UIConfig
+	SSE   *SSEConfig
+	CORS  *CORSConfig  // nil = CORS disabled
+	Debug *DebugConfig // nil = debug endpoints disabled
+}
+
+// GPIOMapping binds a button on a GPIO line to a backend action name.
+type GPIOMapping struct {
+	Pin    int    `mapstructure:"pin"`
+	Action string `mapstructure:"action"`
+}
 
-	UI   *UIConfig
-	SSE  *SSEConfig
-	CORS *CORSConfig // nil = CORS disabled
+type GPIOConfig struct {
+	Enabled  bool
+	Chip     string        // GPIO character device, e.g. gpiochip0
+	Debounce time.Duration // presses closer than this are dropped
+	Mappings []GPIOMapping
 }
 
 type Login1Capabilities struct {
@@ -66,17 +117,55 @@ type Login1Capabilities struct {
 type Login1Config struct {
 	Enabled      bool
 	Capabilities *Login1Capabilities
+	AutoDetect   bool // enable whatever logind reports as allowed, not only the flags above
+	// Delay before a requested reboot/poweroff runs, cancellable meanwhile;
+	// 0 runs it immediately.
+	GracePeriod time.Duration
+}
+
+// MPRISTimeoutConfig bounds D-Bus calls per kind of operation: player
+// controls should fail fast, while loading players may be slow on a busy bus.
+type MPRISTimeoutConfig struct {
+	Default time.Duration // match rules, name owners, single property reads
+	List    time.Duration // listing bus names and loading player properties
+	Control time.Duration // control methods and property writes (Play, Volume...)
 }
 
 type MPRISConfig struct {
-	Enabled bool
-	Timeout time.Duration
+	Enabled      bool
+	Timeouts     MPRISTimeoutConfig
+	PriorityFile string // persisted per-player sort priorities; empty = not persisted
+	// Control actions the API may trigger (play, stop, volume...); empty = all.
+	AllowedActions []string
+	// Metadata keys kept on players and tracks (xesam:url...); empty = built-in set.
+	MetadataKeys []string
+	// Keep every metadata key the player publishes, overriding MetadataKeys.
+	MetadataPassthrough bool
+
+	StartupTimeout time.Duration // backend disabled if New takes longer; 0 = wait forever
+}
+
+// PluginConfig lists the Go plugins (.so files) loaded after the built-in
+// backends.
+type PluginConfig struct {
+	Paths []string
 }
 
 type PulseAudioConfig struct {
 	Enabled       bool
 	XDGRuntimeDir string
+	Socket        string // overrides XDGRuntimeDir/pulse/native when set
 	ServeCookie   bool
+
+	// AutoNormalize sets every new client to NormalizeTarget (see
+	// PulseAudioBackend.NormalizeClientVolume).
+	AutoNormalize   bool
+	NormalizeTarget float32
+
+	StartupTimeout time.Duration
+	// StartupVolume is applied to the master sink once the backend starts;
+	// nil leaves the volume as the last session left it.
+	StartupVolume *float32
 }
 
 type SystemdService struct {
@@ -88,6 +177,18 @@ type SystemdService struct {
 	Internal bool
 }
 
+type ALSAConfig struct {
+	Enabled bool
+	Timeout time.Duration
+}
+
+type SnapcastConfig struct {
+	Enabled bool
+	Host    string
+	Port    int
+	Timeout time.Duration
+}
+
 type SystemdConfig struct {
 	Enabled        bool
 	SystemServices []SystemdService
@@ -95,6 +196,13 @@ type SystemdConfig struct {
 	SupportsUTMP   bool
 	XDGRuntimeDir  string
 	Timeout        time.Duration
+	LogStreamMax   time.Duration // live journal tails end after this; 0 = until the client leaves
+	// Watch $XDG_RUNTIME_DIR/systemd/units and whitelist the user units
+	// matching AutoWhitelist (globs like myapp-*.service) once they start.
+	WatchUnitsDir bool
+	AutoWhitelist []string
+
+	StartupTimeout time.Duration
 }
 
 // UpgradeConfig drives the agnostic upgrade backend: it reads a result file
@@ -111,10 +219,24 @@ type UpgradeConfig struct {
 type BluetoothConfig struct {
 	Enabled        bool
 	PowerOnStart   bool
+	AutoPowerUp    bool // power up when BlueZ announces a trusted device
 	PairingTimeout time.Duration
 	Timeout        time.Duration
 	IdleTimeout    time.Duration
+	IdlePowerOff   bool // power down once IdleTimeout expires; false only logs it
 	ScanTimeout    time.Duration
+	StartupTimeout time.Duration
+
+	AutoConnect            bool
+	AutoConnectTimeout     time.Duration
+	AutoConnectConcurrency int
+	// device policy: a non-empty AllowList admits only its addresses,
+	// BlockList addresses are always disconnected
+	AllowList []string
+	BlockList []string
+	// BlueZ agent IO capability: NoInputNoOutput accepts every pairing,
+	// DisplayYesNo holds numeric comparisons until confirmed over the API
+	AgentCapability string
 }
 
 type ZeroConfig struct {
@@ -148,8 +270,14 @@ func validateConfigPath(path string) error {
 	return nil
 }
 
+// mergeConfDir merges the conf.d snippets next to mainConfigPath.
 func mergeConfDir(mainConfigPath string) error {
-	confDir := filepath.Join(filepath.Dir(mainConfigPath), "conf.d")
+	return mergeSnippets(filepath.Join(filepath.Dir(mainConfigPath), "conf.d"))
+}
+
+// mergeSnippets merges every yaml file of confDir over the loaded config, in
+// lexical order so later files win. A missing directory is not an error.
+func mergeSnippets(confDir string) error {
 	entries, err := os.ReadDir(confDir)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -160,14 +288,14 @@ func mergeConfDir(mainConfigPath string) error {
 
 	var files []string
 	for _, e := range entries {
-		if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+		if strings.HasPrefix(e.Name(), ".") {
 			continue
 		}
-		ext := filepath.Ext(e.Name())
-		if ext != ".yaml" && ext != ".yml" {
+		path := filepath.Join(confDir, e.Name())
+		if validateConfigPath(path) != nil {
 			continue
 		}
-		files = append(files, filepath.Join(confDir, e.Name()))
+		files = append(files, path)
 	}
 	sort.Strings(files)
 
@@ -197,46 +325,118 @@ func readConfig(cfgFile *string) error {
 		return viper.ReadInConfig()
 	}
 
-	viper.SetConfigName("config")                       // name of config file (without extension)
-	viper.AddConfigPath(filepath.Join("/etc", AppName)) // Global configuration path
-	if home, err := os.UserHomeDir(); err == nil {
-		viper.AddConfigPath(filepath.Join(home, ".config", AppName)) // User config path
+	viper.SetConfigName("config") // name of config file (without extension)
+	for _, dir := range configDirs() {
+		viper.AddConfigPath(dir)
 	}
 	return viper.ReadInConfig()
 }
 
-func New(cfgFile *string) (*Config, error) {
+// configDirs lists the searched config directories: global, then user.
+func configDirs() []string {
+	dirs := []string{filepath.Join("/etc", AppName)}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".config", AppName))
+	}
+	return dirs
+}
+
+// mergeConfDirs merges the conf.d snippets over the main config. An explicit
+// config file only takes the conf.d next to it; otherwise the global then the
+// user conf.d both apply, whichever config.yaml was found (if any), so
+// package-managed snippets combine with per-machine overrides.
+func mergeConfDirs(cfgFile *string) error {
+	if cfgFile != nil && *cfgFile != "" {
+		return mergeConfDir(*cfgFile)
+	}
+	for _, dir := range configDirs() {
+		if err := mergeSnippets(filepath.Join(dir, "conf.d")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnvPrefix prefixes the environment variables overriding config keys, dots
+// becoming underscores: ODIO_API_PORT sets api.port.
+const EnvPrefix = "ODIO"
+
+// New loads the configuration. Each key resolves, highest priority first,
+// from overrides ("key=value", the --set flags), ODIO_* environment
+// variables, the config file and its conf.d snippets, then defaults.
+func New(cfgFile *string, overrides ...string) (*Config, error) {
 
 	viper.SetDefault("bind", "lo")
 	viper.SetDefault("LogLevel", "INFO")
 
 	viper.SetDefault("api.enabled", true)
 	viper.SetDefault("api.port", 8018)
+	viper.SetDefault("api.maxbodybytes", 64<<10)
+	viper.SetDefault("api.readonly", false)
+	viper.SetDefault("api.envelope", false)
+	viper.SetDefault("api.remote_artwork", false)
+	viper.SetDefault("api.shutdown_timeout", "5s")
+	viper.SetDefault("api.debug", false)
 	viper.SetDefault("api.cors.origins", []string{"https://odio-pwa.vercel.app", "https://pwa.odio.love"})
 	viper.SetDefault("api.ui.enabled", true)
+	viper.SetDefault("api.ui.refreshinterval", "5s")
+	viper.SetDefault("api.ui.locale", "en")
+	viper.SetDefault("api.ui.basepath", "/ui")
+	viper.SetDefault("api.ui.clienttimeout", "3s")
 	viper.SetDefault("api.sse.enabled", true)
 
 	viper.SetDefault("bluetooth.enabled", true)
 	viper.SetDefault("bluetooth.poweronstart", false)
+	viper.SetDefault("bluetooth.auto_powerup", false)
 	viper.SetDefault("bluetooth.timeout", "5s")
 	viper.SetDefault("bluetooth.pairingtimeout", "60s")
 	viper.SetDefault("bluetooth.idletimeout", "30m")
+	viper.SetDefault("bluetooth.idle.poweroff", true)
 	viper.SetDefault("bluetooth.scantimeout", "60s")
+	viper.SetDefault("bluetooth.startup_timeout", "10s")
+	viper.SetDefault("bluetooth.autoconnect", false)
+	viper.SetDefault("bluetooth.autoconnecttimeout", "15s")
+	viper.SetDefault("bluetooth.autoconnectconcurrency", 2)
+	viper.SetDefault("bluetooth.agent_capability", "NoInputNoOutput")
+
+	viper.SetDefault("events.dedup_window", "500ms")
+
+	viper.SetDefault("gpio.enabled", false)
+	viper.SetDefault("gpio.chip", "gpiochip0")
+	viper.SetDefault("gpio.debounce", "50ms")
 
 	viper.SetDefault("power.enabled", false)
 	viper.SetDefault("power.capabilities.reboot", false)
 	viper.SetDefault("power.capabilities.poweroff", false)
+	viper.SetDefault("power.auto_detect", true)
+	viper.SetDefault("power.graceperiod", "0s")
 
 	viper.SetDefault("mpris.enabled", true)
 	viper.SetDefault("mpris.timeout", "5s")
+	viper.SetDefault("mpris.metadata_passthrough", false)
+	viper.SetDefault("mpris.startup_timeout", "10s")
 
 	viper.SetDefault("pulseaudio.enabled", true)
 	viper.SetDefault("pulseaudio.serve_cookie", false)
+	viper.SetDefault("pulseaudio.auto_normalize", false)
+	viper.SetDefault("pulseaudio.normalize_target", 0.3)
+	viper.SetDefault("pulseaudio.startup_timeout", "10s")
+
+	viper.SetDefault("alsa.enabled", false)
+	viper.SetDefault("alsa.timeout", "5s")
+
+	viper.SetDefault("snapcast.enabled", false)
+	viper.SetDefault("snapcast.host", "127.0.0.1")
+	viper.SetDefault("snapcast.port", 1705)
+	viper.SetDefault("snapcast.timeout", "5s")
 
 	viper.SetDefault("systemd.enabled", false)
 	viper.SetDefault("systemd.system", []string{})
 	viper.SetDefault("systemd.user", []string{})
 	viper.SetDefault("systemd.timeout", "90s")
+	viper.SetDefault("systemd.startup_timeout", "10s")
+	viper.SetDefault("systemd.logstreammax", "10m")
+	viper.SetDefault("systemd.watch_units_dir", false)
 
 	viper.SetDefault("zeroconf.enabled", true)
 
@@ -249,10 +449,16 @@ func New(cfgFile *string) (*Config, error) {
 		}
 	}
 
-	if used := viper.ConfigFileUsed(); used != "" {
-		if err := mergeConfDir(used); err != nil {
-			return nil, err
-		}
+	if err := mergeConfDirs(cfgFile); err != nil {
+		return nil, err
+	}
+
+	viper.SetEnvPrefix(EnvPrefix)
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.AutomaticEnv()
+
+	if err := applyOverrides(overrides); err != nil {
+		return nil, err
 	}
 
 	xdgRuntimeDir := os.Getenv("XDG_RUNTIME_DIR")
@@ -265,16 +471,41 @@ func New(cfgFile *string) (*Config, error) {
 		return nil, fmt.Errorf("invalid port: %d", port)
 	}
 
-	// bind accepts a single interface name or a list: "enp2s0", ["enp2s0","wlan0"], "all"
+	// api.listens lists host:port addresses explicitly; otherwise bind accepts
+	// a single interface name or a list: "enp2s0", ["enp2s0","wlan0"], "all"
 	binds := viper.GetStringSlice("bind")
 	portStr := strconv.Itoa(port)
-	listens, err := resolveBindsToListens(binds, portStr)
+	var (
+		listens []string
+		err     error
+	)
+	if explicit := viper.GetStringSlice("api.listens"); len(explicit) > 0 {
+		// The listens replace api.port: the UI client and Zeroconf follow them.
+		if listens, err = parseListens(explicit); err == nil {
+			port = listensPort(listens)
+			portStr = strconv.Itoa(port)
+		}
+	} else {
+		listens, err = resolveBindsToListens(binds, portStr)
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	uiCfg := UIConfig{
-		Enabled: viper.GetBool("api.ui.enabled"),
+		Enabled:         viper.GetBool("api.ui.enabled"),
+		RefreshInterval: getDuration("api.ui.refreshinterval", 5*time.Second),
+		Locale:          strings.ToLower(strings.TrimSpace(viper.GetString("api.ui.locale"))),
+		ClientTimeout:   getDuration("api.ui.clienttimeout", 3*time.Second),
+	}
+	if uiCfg.RefreshInterval < time.Second {
+		return nil, fmt.Errorf("invalid api.ui.refreshinterval: %s (minimum 1s)", uiCfg.RefreshInterval)
+	}
+	if uiCfg.ClientTimeout <= 0 {
+		return nil, fmt.Errorf("invalid api.ui.clienttimeout: %s (must be positive)", uiCfg.ClientTimeout)
+	}
+	if uiCfg.BasePath, err = parseBasePath(viper.GetString("api.ui.basepath")); err != nil {
+		return nil, err
 	}
 
 	if uiCfg.Enabled && !hasLoopback(listens, portStr) {
@@ -286,18 +517,40 @@ func New(cfgFile *string) (*Config, error) {
 		Enabled: viper.GetBool("api.sse.enabled"),
 	}
 
+	socket := strings.TrimSpace(viper.GetString("api.socket"))
+	if socket != "" && !filepath.IsAbs(socket) {
+		return nil, fmt.Errorf("invalid api.socket: %s (must be an absolute path)", socket)
+	}
+
+	maxBodyBytes := viper.GetInt64("api.maxbodybytes")
+	if maxBodyBytes <= 0 {
+		return nil, fmt.Errorf("invalid api.maxbodybytes: %d", maxBodyBytes)
+	}
+
 	apiCfg := ApiConfig{
-		Enabled: viper.GetBool("api.enabled"),
-		Listens: listens,
-		Port:    port,
-		UI:      &uiCfg,
-		SSE:     &sseCfg,
+		Enabled:       viper.GetBool("api.enabled"),
+		Listens:       listens,
+		Socket:        socket,
+		SystemdSocket: viper.GetBool("api.systemd_socket"),
+		Port:          port,
+		MaxBodyBytes:  maxBodyBytes,
+		ReadOnly:      viper.GetBool("api.readonly"),
+		Envelope:      viper.GetBool("api.envelope"),
+		RemoteArtwork: viper.GetBool("api.remote_artwork"),
+		UI:            &uiCfg,
+		SSE:           &sseCfg,
+
+		ShutdownTimeout: getDuration("api.shutdown_timeout", 5*time.Second),
 	}
 
 	if origins := viper.GetStringSlice("api.cors.origins"); len(origins) > 0 {
 		apiCfg.CORS = &CORSConfig{Origins: origins}
 	}
 
+	if viper.GetBool("api.debug") {
+		apiCfg.Debug = &DebugConfig{Settings: redactSettings(viper.AllSettings())}
+	}
+
 	loginCapabilities := Login1Capabilities{
 		CanReboot:   viper.GetBool("power.capabilities.reboot"),
 		CanPoweroff: viper.GetBool("power.capabilities.poweroff"),
@@ -306,26 +559,99 @@ func New(cfgFile *string) (*Config, error) {
 	logincfg := Login1Config{
 		Enabled:      viper.GetBool("power.enabled"),
 		Capabilities: &loginCapabilities,
+		AutoDetect:   viper.GetBool("power.auto_detect"),
+		GracePeriod:  getDuration("power.graceperiod", 0),
 	}
 
+	// Player priorities are user settings that must survive a reboot, so they
+	// live in the cache dir alongside the upgrade run state.
+	priorityFile := viper.GetString("mpris.priorityFile")
+	if priorityFile == "" {
+		if cacheDir, err := os.UserCacheDir(); err == nil {
+			priorityFile = filepath.Join(cacheDir, "odio-api", "player-priority.json")
+		}
+	}
+	// mpris.timeout is the shorthand for all three per-operation timeouts;
+	// mpris.timeouts.read names the player property reads, an alias of list.
+	mprisTimeout := getDuration("mpris.timeout", 5*time.Second)
+	mprisDefault := getDurationIfSet("mpris.timeouts.default", mprisTimeout)
+	mprisRead := getDurationIfSet("mpris.timeouts.read", mprisDefault)
 	mpriscfg := MPRISConfig{
 		Enabled: viper.GetBool("mpris.enabled"),
-		Timeout: getDuration("mpris.timeout", 5*time.Second),
+		Timeouts: MPRISTimeoutConfig{
+			Default: mprisDefault,
+			List:    getDurationIfSet("mpris.timeouts.list", mprisRead),
+			Control: getDurationIfSet("mpris.timeouts.control", mprisDefault),
+		},
+		PriorityFile: priorityFile,
+
+		AllowedActions: normalizeList(viper.GetStringSlice("mpris.allowedactions")),
+		// keys are case-sensitive (xesam:albumArtist): trimmed, not lowercased
+		MetadataKeys:        trimList(viper.GetStringSlice("mpris.metadatakeys")),
+		MetadataPassthrough: viper.GetBool("mpris.metadata_passthrough"),
+
+		StartupTimeout: getDuration("mpris.startup_timeout", 10*time.Second),
+	}
+
+	eventscfg := EventsConfig{
+		DedupWindow: getDuration("events.dedup_window", 500*time.Millisecond),
+	}
+
+	var gpioMappings []GPIOMapping
+	if err := viper.UnmarshalKey("gpio.mappings", &gpioMappings); err != nil {
+		return nil, fmt.Errorf("invalid gpio.mappings: %w", err)
+	}
+	gpiocfg := GPIOConfig{
+		Enabled:  viper.GetBool("gpio.enabled"),
+		Chip:     viper.GetString("gpio.chip"),
+		Debounce: getDuration("gpio.debounce", 50*time.Millisecond),
+		Mappings: gpioMappings,
 	}
 
 	bluetoothcfg := BluetoothConfig{
 		Enabled:        viper.GetBool("bluetooth.enabled"),
 		PowerOnStart:   viper.GetBool("bluetooth.poweronstart"),
+		AutoPowerUp:    viper.GetBool("bluetooth.auto_powerup"),
 		Timeout:        getDuration("bluetooth.timeout", 5*time.Second),
 		PairingTimeout: getDuration("bluetooth.pairingtimeout", 60*time.Second),
 		IdleTimeout:    getDuration("bluetooth.idletimeout", 30*time.Minute),
+		IdlePowerOff:   viper.GetBool("bluetooth.idle.poweroff"),
 		ScanTimeout:    getDuration("bluetooth.scantimeout", 60*time.Second),
+		StartupTimeout: getDuration("bluetooth.startup_timeout", 10*time.Second),
+
+		AutoConnect:            viper.GetBool("bluetooth.autoconnect"),
+		AutoConnectTimeout:     getDuration("bluetooth.autoconnecttimeout", 15*time.Second),
+		AutoConnectConcurrency: max(viper.GetInt("bluetooth.autoconnectconcurrency"), 1),
+		AllowList:              viper.GetStringSlice("bluetooth.allowlist"),
+		BlockList:              viper.GetStringSlice("bluetooth.blocklist"),
+	}
+	if bluetoothcfg.AgentCapability, err = parseAgentCapability(viper.GetString("bluetooth.agent_capability")); err != nil {
+		return nil, err
 	}
 
 	pulsecfg := PulseAudioConfig{
 		Enabled:       viper.GetBool("pulseaudio.enabled"),
 		XDGRuntimeDir: xdgRuntimeDir,
+		Socket:        strings.TrimSpace(viper.GetString("pulseaudio.socket")),
 		ServeCookie:   viper.GetBool("pulseaudio.serve_cookie"),
+
+		AutoNormalize:   viper.GetBool("pulseaudio.auto_normalize"),
+		NormalizeTarget: float32(viper.GetFloat64("pulseaudio.normalize_target")),
+
+		StartupTimeout: getDuration("pulseaudio.startup_timeout", 10*time.Second),
+	}
+	if pulsecfg.Socket != "" && !filepath.IsAbs(pulsecfg.Socket) {
+		return nil, fmt.Errorf("invalid pulseaudio.socket: %s (must be an absolute path)", pulsecfg.Socket)
+	}
+	if pulsecfg.NormalizeTarget <= 0 || pulsecfg.NormalizeTarget > 1 {
+		return nil, fmt.Errorf("invalid pulseaudio.normalize_target: %v (must be in (0, 1])", pulsecfg.NormalizeTarget)
+	}
+	if viper.IsSet("pulseaudio.startupvolume") {
+		v := float32(viper.GetFloat64("pulseaudio.startupvolume"))
+		if v < 0 || v > 1 {
+			return nil, fmt.Errorf("invalid pulseaudio.startupvolume: %v (must be in [0, 1])", v)
+		}
+		pulsecfg.StartupVolume = &v
 	}
 
 	sysServices, err := parseSystemdServices(viper.Get("systemd.system"))
@@ -336,6 +662,10 @@ func New(cfgFile *string) (*Config, error) {
 	if err != nil {
 		return nil, fmt.Errorf("invalid systemd.user: %w", err)
 	}
+	autoWhitelist, err := parseUnitPatterns(viper.GetStringSlice("systemd.auto_whitelist_pattern"))
+	if err != nil {
+		return nil, err
+	}
 	syscfg := SystemdConfig{
 		Enabled:        viper.GetBool("systemd.enabled"),
 		SystemServices: sysServices,
@@ -343,6 +673,11 @@ func New(cfgFile *string) (*Config, error) {
 		SupportsUTMP:   systemdHasUTMP(),
 		XDGRuntimeDir:  xdgRuntimeDir,
 		Timeout:        getDuration("systemd.timeout", 90*time.Second),
+		LogStreamMax:   getDuration("systemd.logstreammax", 10*time.Minute),
+		WatchUnitsDir:  viper.GetBool("systemd.watch_units_dir"),
+		AutoWhitelist:  autoWhitelist,
+
+		StartupTimeout: getDuration("systemd.startup_timeout", 10*time.Second),
 	}
 
 	// Progress streams over a socket, not a file, to avoid SD-card writes; default
@@ -380,17 +715,107 @@ func New(cfgFile *string) (*Config, error) {
 		Listen:       interfaces,
 	}
 
+	alsacfg := ALSAConfig{
+		Enabled: viper.GetBool("alsa.enabled"),
+		Timeout: getDuration("alsa.timeout", 5*time.Second),
+	}
+
+	snapcastPort := viper.GetInt("snapcast.port")
+	if snapcastPort <= 0 || snapcastPort > 65535 {
+		return nil, fmt.Errorf("invalid snapcast.port: %d", snapcastPort)
+	}
+	snapcastcfg := SnapcastConfig{
+		Enabled: viper.GetBool("snapcast.enabled"),
+		Host:    viper.GetString("snapcast.host"),
+		Port:    snapcastPort,
+		Timeout: getDuration("snapcast.timeout", 5*time.Second),
+	}
+
+	plugincfg := PluginConfig{Paths: trimList(viper.GetStringSlice("plugins"))}
+	for _, path := range plugincfg.Paths {
+		if filepath.Ext(path) != ".so" {
+			return nil, fmt.Errorf("invalid plugins entry %q: must be a .so file", path)
+		}
+	}
+
 	cfg := Config{
+		ALSA:       &alsacfg,
 		Api:        &apiCfg,
 		Bluetooth:  &bluetoothcfg,
+		Events:     &eventscfg,
+		GPIO:       &gpiocfg,
 		Login1:     &logincfg,
 		MPRIS:      &mpriscfg,
+		Plugins:    &plugincfg,
 		Pulseaudio: &pulsecfg,
+		Snapcast:   &snapcastcfg,
 		Systemd:    &syscfg,
 		Upgrade:    &upgradecfg,
 		Zeroconf:   &zerocfg,
 		LogLevel:   parseLogLevel(viper.GetString("LogLevel")),
 	}
+	zerocfg.TxtRecords = append(zerocfg.TxtRecords, backendTxtRecords(&cfg)...)
 
 	return &cfg, nil
 }
+
+// Dump renders the settings New resolved from defaults, config files,
+// environment and --set flags as YAML, with sensitive values redacted. Keys
+// are the config file's own, so the output can be saved and loaded back as
+// a config file.
+func Dump() ([]byte, error) {
+	return yaml.Marshal(redactSettings(viper.AllSettings()))
+}
+
+// Effective renders the resolved configuration as one compact line for the
+// startup log: listeners, enabled backends, watched units and the timeouts
+// most often left at their defaults. It prints settings only, never file
+// contents or credentials.
+func (c *Config) Effective() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "log=%s", c.LogLevel)
+
+	if c.Api.Enabled {
+		fmt.Fprintf(&b, " api=%s", strings.Join(c.Api.Listens, ","))
+		if c.Api.Socket != "" {
+			fmt.Fprintf(&b, " socket=%s", c.Api.Socket)
+		}
+		if c.Api.SystemdSocket {
+			b.WriteString(" systemd_socket")
+		}
+		if c.Api.ReadOnly {
+			b.WriteString(" readonly")
+		}
+		fmt.Fprintf(&b, " ui=%v sse=%v", c.Api.UI.Enabled, c.Api.SSE.Enabled)
+	} else {
+		b.WriteString(" api=off")
+	}
+
+	var enabled []string
+	for _, r := range backendTxtRecords(c) {
+		if name, on := strings.CutSuffix(r, "=1"); on {
+			enabled = append(enabled, name)
+		}
+	}
+	if c.Zeroconf.Enabled {
+		enabled = append(enabled, "zeroconf")
+	}
+	fmt.Fprintf(&b, " backends=[%s]", strings.Join(enabled, ","))
+	if len(c.Plugins.Paths) > 0 {
+		fmt.Fprintf(&b, " plugins=%d", len(c.Plugins.Paths))
+	}
+
+	if c.Systemd.Enabled {
+		fmt.Fprintf(&b, " systemd(units=%d system/%d user, timeout=%s)",
+			len(c.Systemd.SystemServices), len(c.Systemd.UserServices), c.Systemd.Timeout)
+	}
+	if c.MPRIS.Enabled {
+		t := c.MPRIS.Timeouts
+		fmt.Fprintf(&b, " mpris(timeout=%s list=%s control=%s)", t.Default, t.List, t.Control)
+	}
+	if c.Bluetooth.Enabled {
+		fmt.Fprintf(&b, " bluetooth(timeout=%s pairing=%s idle=%s scan=%s)",
+			c.Bluetooth.Timeout, c.Bluetooth.PairingTimeout, c.Bluetooth.IdleTimeout, c.Bluetooth.ScanTimeout)
+	}
+	return b.String()
+}
@@ -1,12 +1,17 @@
 package config
 
 import (
+	"fmt"
 	"net"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
 
 	"github.com/b0bbywan/go-odio-api/logger"
 )
@@ -127,6 +132,580 @@ func TestNew_UIEnabledByDefault(t *testing.T) {
 	}
 }
 
+func TestNew_UIRefreshInterval(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   any
+		want    time.Duration
+		wantErr bool
+	}{
+		{"default", nil, 5 * time.Second, false},
+		{"custom", "10s", 10 * time.Second, false},
+		{"minimum", "1s", time.Second, false},
+		{"below minimum", "500ms", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			if tt.value != nil {
+				viper.Set("api.ui.refreshinterval", tt.value)
+			}
+			t.Setenv("HOME", t.TempDir())
+
+			cfg, err := New(nil)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("New(nil) should reject a refresh interval below 1s")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New(nil) returned error: %v", err)
+			}
+			if cfg.Api.UI.RefreshInterval != tt.want {
+				t.Errorf("Api.UI.RefreshInterval = %s, want %s", cfg.Api.UI.RefreshInterval, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew_UIClientTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   any
+		want    time.Duration
+		wantErr bool
+	}{
+		{"default", nil, 3 * time.Second, false},
+		{"custom", "750ms", 750 * time.Millisecond, false},
+		{"zero", "0s", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			if tt.value != nil {
+				viper.Set("api.ui.clienttimeout", tt.value)
+			}
+			t.Setenv("HOME", t.TempDir())
+
+			cfg, err := New(nil)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("New(nil) should reject a non-positive client timeout")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New(nil) returned error: %v", err)
+			}
+			if cfg.Api.UI.ClientTimeout != tt.want {
+				t.Errorf("Api.UI.ClientTimeout = %s, want %s", cfg.Api.UI.ClientTimeout, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew_UILocale(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{"default", nil, "en"},
+		{"custom", "fr", "fr"},
+		{"normalized", " FR ", "fr"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			if tt.value != nil {
+				viper.Set("api.ui.locale", tt.value)
+			}
+			t.Setenv("HOME", t.TempDir())
+
+			cfg, err := New(nil)
+			if err != nil {
+				t.Fatalf("New(nil) returned error: %v", err)
+			}
+			if cfg.Api.UI.Locale != tt.want {
+				t.Errorf("Api.UI.Locale = %q, want %q", cfg.Api.UI.Locale, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew_MPRISAllowedActions(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  []string
+	}{
+		{"default", nil, nil},
+		{"list", []string{"play", "next"}, []string{"play", "next"}},
+		{"normalized", []string{" Play ", "", "STOP"}, []string{"play", "stop"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			if tt.value != nil {
+				viper.Set("mpris.allowedactions", tt.value)
+			}
+			t.Setenv("HOME", t.TempDir())
+
+			cfg, err := New(nil)
+			if err != nil {
+				t.Fatalf("New(nil) returned error: %v", err)
+			}
+			if !slices.Equal(cfg.MPRIS.AllowedActions, tt.want) {
+				t.Errorf("MPRIS.AllowedActions = %v, want %v", cfg.MPRIS.AllowedActions, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew_MPRISMetadataKeys(t *testing.T) {
+	viper.Reset()
+	viper.Set("mpris.metadatakeys", []string{" xesam:url ", "", "xesam:trackNumber"})
+	t.Setenv("HOME", t.TempDir())
+
+	cfg, err := New(nil)
+	if err != nil {
+		t.Fatalf("New(nil) returned error: %v", err)
+	}
+	want := []string{"xesam:url", "xesam:trackNumber"}
+	if !slices.Equal(cfg.MPRIS.MetadataKeys, want) {
+		t.Errorf("MPRIS.MetadataKeys = %v, want %v (case kept)", cfg.MPRIS.MetadataKeys, want)
+	}
+}
+
+func TestNew_MPRISMetadataPassthrough(t *testing.T) {
+	viper.Reset()
+	t.Setenv("HOME", t.TempDir())
+	cfg, err := New(nil)
+	if err != nil {
+		t.Fatalf("New(nil) returned error: %v", err)
+	}
+	if cfg.MPRIS.MetadataPassthrough {
+		t.Error("MPRIS.MetadataPassthrough should be false by default")
+	}
+
+	viper.Reset()
+	viper.Set("mpris.metadata_passthrough", true)
+	if cfg, err = New(nil); err != nil {
+		t.Fatalf("New(nil) returned error: %v", err)
+	}
+	if !cfg.MPRIS.MetadataPassthrough {
+		t.Error("MPRIS.MetadataPassthrough = false, want true")
+	}
+}
+
+func TestNew_APIDebug(t *testing.T) {
+	viper.Reset()
+	t.Setenv("HOME", t.TempDir())
+
+	cfg, err := New(nil)
+	if err != nil {
+		t.Fatalf("New(nil) returned error: %v", err)
+	}
+	if cfg.Api.Debug != nil {
+		t.Errorf("Api.Debug = %+v, want nil by default", cfg.Api.Debug)
+	}
+
+	viper.Reset()
+	viper.Set("api.debug", true)
+	cfg, err = New(nil)
+	if err != nil {
+		t.Fatalf("New(nil) returned error: %v", err)
+	}
+	if cfg.Api.Debug == nil {
+		t.Fatal("Api.Debug = nil, want settings with api.debug")
+	}
+	mpris, _ := cfg.Api.Debug.Settings["mpris"].(map[string]any)
+	if mpris["timeout"] != "5s" {
+		t.Errorf("settings mpris.timeout = %v, want 5s", mpris["timeout"])
+	}
+}
+
+func TestDump(t *testing.T) {
+	viper.Reset()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ODIO_API_PORT", "9090")
+	viper.Set("mpris.timeout", "3s")
+	viper.Set("api.token", "abc")
+
+	if _, err := New(nil); err != nil {
+		t.Fatalf("New(nil) returned error: %v", err)
+	}
+	out, err := Dump()
+	if err != nil {
+		t.Fatalf("Dump() error = %v", err)
+	}
+	if strings.Contains(string(out), "abc") {
+		t.Errorf("Dump() leaked a secret:\n%s", out)
+	}
+	var dumped map[string]any
+	if err := yaml.Unmarshal(out, &dumped); err != nil {
+		t.Fatalf("Dump() is not valid YAML: %v", err)
+	}
+	api, _ := dumped["api"].(map[string]any)
+	if fmt.Sprint(api["port"]) != "9090" || api["token"] != "<redacted>" {
+		t.Errorf("dumped api = %v, want env port and redacted token", api)
+	}
+
+	// Loaded back as a config file, the dump describes the same settings.
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, out, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ODIO_API_PORT", "")
+	viper.Reset()
+	if _, err := New(&path); err != nil {
+		t.Fatalf("New(%s) returned error: %v", path, err)
+	}
+	again, err := Dump()
+	if err != nil {
+		t.Fatalf("Dump() error = %v", err)
+	}
+	if string(again) != string(out) {
+		t.Errorf("round trip changed the dump:\nfirst:\n%s\nsecond:\n%s", out, again)
+	}
+}
+
+func TestRedactSettings(t *testing.T) {
+	in := map[string]any{
+		"api":   map[string]any{"token": "abc", "port": 8018, "empty_secret": ""},
+		"bind":  "lo",
+		"users": map[string]any{"db_password": "hunter2"},
+	}
+	out := redactSettings(in)
+
+	api := out["api"].(map[string]any)
+	if api["token"] != "<redacted>" || api["port"] != 8018 || api["empty_secret"] != "" {
+		t.Errorf("api = %v", api)
+	}
+	if out["users"].(map[string]any)["db_password"] != "<redacted>" {
+		t.Errorf("users = %v", out["users"])
+	}
+	if out["bind"] != "lo" {
+		t.Errorf("bind = %v, want lo", out["bind"])
+	}
+	if in["api"].(map[string]any)["token"] != "abc" {
+		t.Error("redactSettings modified its input")
+	}
+}
+
+func TestParseListens(t *testing.T) {
+	got, err := parseListens([]string{"127.0.0.1:8018", " 192.168.1.10:8018 ", "127.0.0.1:8018", ":9000", "[::1]:8018"})
+	if err != nil {
+		t.Fatalf("parseListens() error = %v", err)
+	}
+	want := []string{"127.0.0.1:8018", "192.168.1.10:8018", ":9000", "[::1]:8018"}
+	if !slices.Equal(got, want) {
+		t.Errorf("parseListens() = %v, want %v", got, want)
+	}
+
+	for _, bad := range []string{"127.0.0.1", "localhost:8018", "127.0.0.1:0", "127.0.0.1:http", "127.0.0.1:70000"} {
+		if _, err := parseListens([]string{bad}); err == nil {
+			t.Errorf("parseListens(%q) error = nil, want an error", bad)
+		}
+	}
+}
+
+func TestListensPort(t *testing.T) {
+	tests := []struct {
+		listens []string
+		want    int
+	}{
+		{[]string{"127.0.0.1:8018"}, 8018},
+		{[]string{"10.0.0.2:9001", "0.0.0.0:9000"}, 9000},
+		{[]string{"10.0.0.2:9001", ":9002"}, 9002},
+		{[]string{"10.0.0.2:9001", "[::1]:9003"}, 9001},
+	}
+	for _, tt := range tests {
+		if got := listensPort(tt.listens); got != tt.want {
+			t.Errorf("listensPort(%v) = %d, want %d", tt.listens, got, tt.want)
+		}
+	}
+}
+
+func TestParseAgentCapability(t *testing.T) {
+	tests := map[string]string{
+		"NoInputNoOutput": "NoInputNoOutput",
+		"displayyesno":    "DisplayYesNo",
+		" DisplayYesNo ":  "DisplayYesNo",
+	}
+	for in, want := range tests {
+		got, err := parseAgentCapability(in)
+		if err != nil {
+			t.Errorf("parseAgentCapability(%q) error = %v", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("parseAgentCapability(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	for _, bad := range []string{"KeyboardOnly", "DisplayOnly", "yes"} {
+		if _, err := parseAgentCapability(bad); err == nil {
+			t.Errorf("parseAgentCapability(%q) error = nil, want an error", bad)
+		}
+	}
+}
+
+func TestParseUnitPatterns(t *testing.T) {
+	got, err := parseUnitPatterns([]string{" myapp-*.service ", "", "snap?.service"})
+	if err != nil {
+		t.Fatalf("parseUnitPatterns() error = %v", err)
+	}
+	if want := []string{"myapp-*.service", "snap?.service"}; !slices.Equal(got, want) {
+		t.Errorf("parseUnitPatterns() = %v, want %v", got, want)
+	}
+
+	if _, err := parseUnitPatterns([]string{"myapp-[.service"}); err == nil {
+		t.Error("parseUnitPatterns() with a malformed glob should fail")
+	}
+}
+
+func TestParseBasePath(t *testing.T) {
+	tests := map[string]string{
+		"/ui":        "/ui",
+		" /odio/ui/": "/odio/ui",
+		"/a//b/../c": "/a/c",
+	}
+	for in, want := range tests {
+		if got, err := parseBasePath(in); err != nil || got != want {
+			t.Errorf("parseBasePath(%q) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"", "ui", "/", "/ui?x=1", "/ui#top"} {
+		if _, err := parseBasePath(bad); err == nil {
+			t.Errorf("parseBasePath(%q) error = nil, want an error", bad)
+		}
+	}
+}
+
+func TestNew_APIListens(t *testing.T) {
+	t.Run("explicit list overrides bind", func(t *testing.T) {
+		viper.Reset()
+		viper.Set("bind", "all")
+		viper.Set("api.listens", []string{"127.0.0.1:8018", "10.0.0.2:8018"})
+		t.Setenv("HOME", t.TempDir())
+
+		cfg, err := New(nil)
+		if err != nil {
+			t.Fatalf("New(nil) returned error: %v", err)
+		}
+		want := []string{"127.0.0.1:8018", "10.0.0.2:8018"}
+		if !slices.Equal(cfg.Api.Listens, want) {
+			t.Errorf("Api.Listens = %v, want %v", cfg.Api.Listens, want)
+		}
+		if !cfg.Api.UI.Enabled {
+			t.Error("UI disabled although 127.0.0.1:api.port is listed")
+		}
+	})
+
+	t.Run("port follows the loopback listen", func(t *testing.T) {
+		viper.Reset()
+		viper.Set("api.port", 8018)
+		viper.Set("api.listens", []string{"10.0.0.2:9001", "127.0.0.1:9000"})
+		t.Setenv("HOME", t.TempDir())
+
+		cfg, err := New(nil)
+		if err != nil {
+			t.Fatalf("New(nil) returned error: %v", err)
+		}
+		if cfg.Api.Port != 9000 || cfg.Zeroconf.Port != 9000 {
+			t.Errorf("Api.Port = %d, Zeroconf.Port = %d, want 9000 from the loopback listen", cfg.Api.Port, cfg.Zeroconf.Port)
+		}
+		if !cfg.Api.UI.Enabled {
+			t.Error("UI disabled although 127.0.0.1 is listed")
+		}
+	})
+
+	t.Run("UI needs a loopback listen", func(t *testing.T) {
+		viper.Reset()
+		viper.Set("api.listens", []string{"10.0.0.2:9000"})
+		t.Setenv("HOME", t.TempDir())
+
+		cfg, err := New(nil)
+		if err != nil {
+			t.Fatalf("New(nil) returned error: %v", err)
+		}
+		if cfg.Api.Port != 9000 {
+			t.Errorf("Api.Port = %d, want 9000 from the only listen", cfg.Api.Port)
+		}
+		if cfg.Api.UI.Enabled {
+			t.Error("UI enabled without a loopback listen")
+		}
+	})
+
+	t.Run("invalid entry", func(t *testing.T) {
+		viper.Reset()
+		viper.Set("api.listens", []string{"nope"})
+		t.Setenv("HOME", t.TempDir())
+
+		if _, err := New(nil); err == nil {
+			t.Error("New(nil) error = nil, want invalid api.listens")
+		}
+	})
+}
+
+func TestNew_APISocket(t *testing.T) {
+	t.Run("absolute path", func(t *testing.T) {
+		viper.Reset()
+		viper.Set("api.socket", " /run/odio-api/api.sock ")
+		t.Setenv("HOME", t.TempDir())
+
+		cfg, err := New(nil)
+		if err != nil {
+			t.Fatalf("New(nil) returned error: %v", err)
+		}
+		if cfg.Api.Socket != "/run/odio-api/api.sock" {
+			t.Errorf("Api.Socket = %q, want /run/odio-api/api.sock", cfg.Api.Socket)
+		}
+	})
+
+	t.Run("relative path", func(t *testing.T) {
+		viper.Reset()
+		viper.Set("api.socket", "api.sock")
+		t.Setenv("HOME", t.TempDir())
+
+		if _, err := New(nil); err == nil {
+			t.Error("New(nil) error = nil, want invalid api.socket")
+		}
+	})
+}
+
+func TestNew_PulseaudioSocket(t *testing.T) {
+	viper.Reset()
+	viper.Set("pulseaudio.socket", " /run/pulse/native ")
+	t.Setenv("HOME", t.TempDir())
+
+	cfg, err := New(nil)
+	if err != nil {
+		t.Fatalf("New(nil) returned error: %v", err)
+	}
+	if cfg.Pulseaudio.Socket != "/run/pulse/native" {
+		t.Errorf("Pulseaudio.Socket = %q, want /run/pulse/native", cfg.Pulseaudio.Socket)
+	}
+
+	viper.Reset()
+	viper.Set("pulseaudio.socket", "pulse/native")
+	if _, err := New(nil); err == nil {
+		t.Error("New(nil) error = nil, want invalid pulseaudio.socket")
+	}
+}
+
+func TestNew_PulseaudioNormalizeTarget(t *testing.T) {
+	for _, target := range []float64{0, -0.5, 1.5} {
+		viper.Reset()
+		viper.Set("pulseaudio.normalize_target", target)
+		t.Setenv("HOME", t.TempDir())
+
+		if _, err := New(nil); err == nil {
+			t.Errorf("New(nil) with normalize_target %v: error = nil, want invalid", target)
+		}
+	}
+}
+
+func TestNew_PulseaudioStartupVolume(t *testing.T) {
+	viper.Reset()
+	t.Setenv("HOME", t.TempDir())
+	cfg, err := New(nil)
+	if err != nil {
+		t.Fatalf("New(nil) returned error: %v", err)
+	}
+	if cfg.Pulseaudio.StartupVolume != nil {
+		t.Errorf("StartupVolume = %v, want nil when unset", *cfg.Pulseaudio.StartupVolume)
+	}
+
+	viper.Set("pulseaudio.startupvolume", 0)
+	cfg, err = New(nil)
+	if err != nil {
+		t.Fatalf("New(nil) returned error: %v", err)
+	}
+	if cfg.Pulseaudio.StartupVolume == nil || *cfg.Pulseaudio.StartupVolume != 0 {
+		t.Errorf("StartupVolume = %v, want 0 (muted level is a valid choice)", cfg.Pulseaudio.StartupVolume)
+	}
+
+	for _, volume := range []float64{-0.1, 1.2} {
+		viper.Set("pulseaudio.startupvolume", volume)
+		if _, err := New(nil); err == nil {
+			t.Errorf("New(nil) with startupvolume %v: error = nil, want invalid", volume)
+		}
+	}
+}
+
+func TestNew_Plugins(t *testing.T) {
+	viper.Reset()
+	viper.Set("plugins", []string{" /usr/lib/odio/squeezebox.so ", ""})
+	t.Setenv("HOME", t.TempDir())
+
+	cfg, err := New(nil)
+	if err != nil {
+		t.Fatalf("New(nil) returned error: %v", err)
+	}
+	if want := []string{"/usr/lib/odio/squeezebox.so"}; !slices.Equal(cfg.Plugins.Paths, want) {
+		t.Errorf("Plugins.Paths = %v, want %v", cfg.Plugins.Paths, want)
+	}
+
+	viper.Reset()
+	viper.Set("plugins", []string{"/usr/lib/odio/squeezebox"})
+	if _, err := New(nil); err == nil {
+		t.Error("New(nil) error = nil, want invalid plugins entry")
+	}
+}
+
+func TestNew_OverridePriority(t *testing.T) {
+	dir := t.TempDir()
+	cfgFile := dir + "/config.yaml"
+	if err := os.WriteFile(cfgFile, []byte("api:\n  port: 8100\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		env       string
+		overrides []string
+		want      int
+	}{
+		{"config file", "", nil, 8100},
+		{"env over config file", "9090", nil, 9090},
+		{"--set over env", "9090", []string{"api.port=9191"}, 9191},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			t.Setenv("HOME", t.TempDir())
+			if tt.env != "" {
+				t.Setenv("ODIO_API_PORT", tt.env)
+			}
+
+			cfg, err := New(&cfgFile, tt.overrides...)
+			if err != nil {
+				t.Fatalf("New() returned error: %v", err)
+			}
+			if cfg.Api.Port != tt.want {
+				t.Errorf("Api.Port = %d, want %d", cfg.Api.Port, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew_InvalidOverride(t *testing.T) {
+	for _, o := range []string{"api.port", "=9090"} {
+		viper.Reset()
+		t.Setenv("HOME", t.TempDir())
+		if _, err := New(nil, o); err == nil {
+			t.Errorf("New(nil, %q) error = nil, want invalid --set", o)
+		}
+	}
+}
+
 func BenchmarkParseLogLevel(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		parseLogLevel("DEBUG")
@@ -163,6 +742,9 @@ func TestNew_Defaults(t *testing.T) {
 	if !cfg.Pulseaudio.Enabled {
 		t.Error("Pulseaudio.Enabled should be true by default")
 	}
+	if cfg.Pulseaudio.AutoNormalize || cfg.Pulseaudio.NormalizeTarget != 0.3 {
+		t.Errorf("Pulseaudio normalize = %v/%v, want off/0.3 by default", cfg.Pulseaudio.AutoNormalize, cfg.Pulseaudio.NormalizeTarget)
+	}
 	if !cfg.MPRIS.Enabled {
 		t.Error("MPRIS.Enabled should be true by default")
 	}
@@ -212,23 +794,74 @@ func TestNew_InvalidPort(t *testing.T) {
 			// Reset viper to ensure clean state
 			viper.Reset()
 
-			// Set invalid port
-			viper.Set("api.port", tt.port)
+			// Set invalid port
+			viper.Set("api.port", tt.port)
+
+			// Isolate from user's config files by using a temp directory
+			t.Setenv("HOME", t.TempDir())
+
+			// Set XDG_SESSION_DESKTOP to avoid headless mode detection
+			t.Setenv("XDG_SESSION_DESKTOP", "test-desktop")
+
+			cfg, err := New(nil)
+			if err == nil {
+				t.Errorf("New(nil) with port %d should return error, got config: %+v", tt.port, cfg)
+			}
+			if cfg != nil {
+				t.Errorf("New(nil) with invalid port should return nil config, got: %+v", cfg)
+			}
+		})
+	}
+}
+
+func TestNew_ShutdownTimeout(t *testing.T) {
+	viper.Reset()
+	t.Setenv("HOME", t.TempDir())
+
+	cfg, err := New(nil)
+	if err != nil {
+		t.Fatalf("New(nil) returned error: %v", err)
+	}
+	if cfg.Api.ShutdownTimeout != 5*time.Second {
+		t.Errorf("Api.ShutdownTimeout = %v, want 5s", cfg.Api.ShutdownTimeout)
+	}
+
+	viper.Set("api.shutdown_timeout", "20s")
+	cfg, err = New(nil)
+	if err != nil {
+		t.Fatalf("New(nil) returned error: %v", err)
+	}
+	if cfg.Api.ShutdownTimeout != 20*time.Second {
+		t.Errorf("Api.ShutdownTimeout = %v, want 20s", cfg.Api.ShutdownTimeout)
+	}
+}
+
+func TestNew_MaxBodyBytes(t *testing.T) {
+	viper.Reset()
+	t.Setenv("HOME", t.TempDir())
 
-			// Isolate from user's config files by using a temp directory
-			t.Setenv("HOME", t.TempDir())
+	cfg, err := New(nil)
+	if err != nil {
+		t.Fatalf("New(nil) returned error: %v", err)
+	}
+	if cfg.Api.MaxBodyBytes != 64<<10 {
+		t.Errorf("Api.MaxBodyBytes = %d, want %d", cfg.Api.MaxBodyBytes, 64<<10)
+	}
 
-			// Set XDG_SESSION_DESKTOP to avoid headless mode detection
-			t.Setenv("XDG_SESSION_DESKTOP", "test-desktop")
+	viper.Reset()
+	viper.Set("api.maxbodybytes", 4096)
+	cfg, err = New(nil)
+	if err != nil {
+		t.Fatalf("New(nil) returned error: %v", err)
+	}
+	if cfg.Api.MaxBodyBytes != 4096 {
+		t.Errorf("Api.MaxBodyBytes = %d, want 4096", cfg.Api.MaxBodyBytes)
+	}
 
-			cfg, err := New(nil)
-			if err == nil {
-				t.Errorf("New(nil) with port %d should return error, got config: %+v", tt.port, cfg)
-			}
-			if cfg != nil {
-				t.Errorf("New(nil) with invalid port should return nil config, got: %+v", cfg)
-			}
-		})
+	viper.Reset()
+	viper.Set("api.maxbodybytes", 0)
+	if _, err := New(nil); err == nil {
+		t.Error("New(nil) with api.maxbodybytes=0 should return error")
 	}
 }
 
@@ -508,6 +1141,9 @@ func TestNew_Login1CapabilitiesDisabledByDefault(t *testing.T) {
 	if cfg.Login1.Capabilities.CanPoweroff {
 		t.Error("Login1.Capabilities.CanPoweroff should be false by default")
 	}
+	if !cfg.Login1.AutoDetect {
+		t.Error("Login1.AutoDetect should be true by default")
+	}
 }
 
 func TestNew_Login1ExplicitlyEnabled(t *testing.T) {
@@ -765,6 +1401,30 @@ func TestNew_ZeroconfExplicitlyDisabled(t *testing.T) {
 	}
 }
 
+func TestNew_ZeroconfBackendTxtRecords(t *testing.T) {
+	viper.Reset()
+	viper.Set("bluetooth.enabled", true)
+	viper.Set("mpris.enabled", false)
+
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_SESSION_DESKTOP", "test-desktop")
+
+	cfg, err := New(nil)
+	if err != nil {
+		t.Fatalf("New(nil) returned error: %v", err)
+	}
+
+	records := cfg.Zeroconf.TxtRecords
+	if len(records) == 0 || records[0] != "version="+AppVersion {
+		t.Fatalf("TxtRecords should start with the version, got %v", records)
+	}
+	for _, want := range []string{"bt=1", "mpris=0"} {
+		if !slices.Contains(records, want) {
+			t.Errorf("TxtRecords = %v, want %q", records, want)
+		}
+	}
+}
+
 func TestNew_SystemdDisabledByDefault(t *testing.T) {
 	viper.Reset()
 
@@ -1179,6 +1839,47 @@ func TestParseSystemdServices_EmptyName(t *testing.T) {
 	}
 }
 
+func TestValidateUnitName(t *testing.T) {
+	tests := []struct {
+		name    string
+		unit    string
+		wantErr string
+	}{
+		{"service", "mpd.service", ""},
+		{"template instance", "snapclient@living.service", ""},
+		{"timer", "odio-check-upgrade.timer", ""},
+		{"socket", "pipewire.socket", ""},
+		{"path", "odio-upgrade.path", ""},
+		{"empty", "", "empty unit name"},
+		{"missing suffix", "mympd", `did you mean "mympd.service"`},
+		{"unsupported suffix", "default.target", "must end in"},
+		{"path separator", "../etc/passwd.service", "path separators"},
+		{"dot dot", "a..service", "path separators"},
+		{"too long", strings.Repeat("a", 250) + ".service", "longer than 255 bytes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateUnitName(tt.unit)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateUnitName(%q) = %v, want nil", tt.unit, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateUnitName(%q) = %v, want error containing %q", tt.unit, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseSystemdServices_InvalidUnitName(t *testing.T) {
+	_, err := parseSystemdServices([]any{"mpd.service", "mympd"})
+	if err == nil || !strings.Contains(err.Error(), "entry 1") {
+		t.Errorf("parseSystemdServices() error = %v, want entry 1 rejected", err)
+	}
+}
+
 func TestParseSystemdServices_InvalidEntryType(t *testing.T) {
 	// Numbers / lists / bools should be rejected.
 	tests := []any{42, true, []any{"nested"}, 3.14}
@@ -1406,6 +2107,40 @@ func TestMergeConfDir_OverridesExistingValues(t *testing.T) {
 	}
 }
 
+// The user conf.d applies without --config, over the user config.yaml, and
+// even when there is no config.yaml at all.
+func TestNew_UserConfDirOverridesPort(t *testing.T) {
+	for _, withMain := range []bool{true, false} {
+		viper.Reset()
+		home := t.TempDir()
+		t.Setenv("HOME", home)
+
+		appDir := filepath.Join(home, ".config", AppName)
+		if err := os.MkdirAll(filepath.Join(appDir, "conf.d"), 0755); err != nil {
+			t.Fatalf("Failed to create conf.d: %v", err)
+		}
+		if withMain {
+			if err := os.WriteFile(filepath.Join(appDir, "config.yaml"), []byte("api:\n  port: 8100\n"), 0644); err != nil {
+				t.Fatalf("Failed to write main config: %v", err)
+			}
+		}
+		if err := os.WriteFile(filepath.Join(appDir, "conf.d", "10-port.yaml"), []byte("api:\n  port: 9100\n"), 0644); err != nil {
+			t.Fatalf("Failed to write snippet: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(appDir, "conf.d", "20-port.yaml"), []byte("api:\n  port: 9200\n"), 0644); err != nil {
+			t.Fatalf("Failed to write snippet: %v", err)
+		}
+
+		cfg, err := New(nil)
+		if err != nil {
+			t.Fatalf("New(nil) returned error: %v", err)
+		}
+		if cfg.Api.Port != 9200 {
+			t.Errorf("main config %v: Api.Port = %d, want 9200 from the last snippet", withMain, cfg.Api.Port)
+		}
+	}
+}
+
 func TestMergeConfDir_IgnoresNonYAMLAndHidden(t *testing.T) {
 	viper.Reset()
 	viper.SetConfigType("yaml")
@@ -1842,3 +2577,309 @@ func TestNew_BluetoothPowerOnStartExplicitlyEnabled(t *testing.T) {
 		t.Error("Bluetooth.PowerOnStart should be true when explicitly enabled")
 	}
 }
+
+func TestNew_GPIODisabledByDefault(t *testing.T) {
+	viper.Reset()
+	t.Setenv("HOME", t.TempDir())
+
+	cfg, err := New(nil)
+	if err != nil {
+		t.Fatalf("New(nil) returned error: %v", err)
+	}
+	if cfg.GPIO == nil {
+		t.Fatal("GPIO should not be nil")
+	}
+	if cfg.GPIO.Enabled {
+		t.Error("GPIO.Enabled should be false by default")
+	}
+	if cfg.GPIO.Chip != "gpiochip0" {
+		t.Errorf("GPIO.Chip = %q, want gpiochip0", cfg.GPIO.Chip)
+	}
+	if cfg.GPIO.Debounce != 50*time.Millisecond {
+		t.Errorf("GPIO.Debounce = %s, want 50ms", cfg.GPIO.Debounce)
+	}
+}
+
+func TestNew_GPIOMappingsFromConfigFile(t *testing.T) {
+	viper.Reset()
+
+	tmpDir := t.TempDir()
+	configFile := tmpDir + "/config.yaml"
+	configContent := `
+gpio:
+  enabled: true
+  debounce: 80ms
+  mappings:
+    - pin: 17
+      action: mpris.play_pause
+    - pin: 27
+      action: audio.mute
+`
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+	t.Setenv("HOME", t.TempDir())
+
+	cfg, err := New(&configFile)
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+
+	if !cfg.GPIO.Enabled {
+		t.Error("GPIO.Enabled should be true from config file")
+	}
+	if cfg.GPIO.Debounce != 80*time.Millisecond {
+		t.Errorf("GPIO.Debounce = %s, want 80ms", cfg.GPIO.Debounce)
+	}
+	want := []GPIOMapping{{Pin: 17, Action: "mpris.play_pause"}, {Pin: 27, Action: "audio.mute"}}
+	if len(cfg.GPIO.Mappings) != len(want) {
+		t.Fatalf("GPIO.Mappings = %+v, want %+v", cfg.GPIO.Mappings, want)
+	}
+	for i := range want {
+		if cfg.GPIO.Mappings[i] != want[i] {
+			t.Errorf("GPIO.Mappings[%d] = %+v, want %+v", i, cfg.GPIO.Mappings[i], want[i])
+		}
+	}
+}
+
+func TestNew_SnapcastDefaults(t *testing.T) {
+	viper.Reset()
+	t.Setenv("HOME", t.TempDir())
+
+	cfg, err := New(nil)
+	if err != nil {
+		t.Fatalf("New(nil) returned error: %v", err)
+	}
+	if cfg.Snapcast == nil {
+		t.Fatal("Snapcast should not be nil")
+	}
+	if cfg.Snapcast.Enabled {
+		t.Error("Snapcast.Enabled should be false by default")
+	}
+	if cfg.Snapcast.Host != "127.0.0.1" || cfg.Snapcast.Port != 1705 {
+		t.Errorf("Snapcast address = %s:%d, want 127.0.0.1:1705", cfg.Snapcast.Host, cfg.Snapcast.Port)
+	}
+	if cfg.Snapcast.Timeout != 5*time.Second {
+		t.Errorf("Snapcast.Timeout = %s, want 5s", cfg.Snapcast.Timeout)
+	}
+}
+
+func TestNew_SnapcastInvalidPort(t *testing.T) {
+	viper.Reset()
+	t.Setenv("HOME", t.TempDir())
+
+	viper.Set("snapcast.port", 70000)
+	if _, err := New(nil); err == nil {
+		t.Error("New() should reject snapcast.port out of range")
+	}
+}
+
+func TestNew_BluetoothAutoConnectDefaults(t *testing.T) {
+	viper.Reset()
+	t.Setenv("HOME", t.TempDir())
+
+	cfg, err := New(nil)
+	if err != nil {
+		t.Fatalf("New(nil) returned error: %v", err)
+	}
+	if cfg.Bluetooth.AutoConnect {
+		t.Error("Bluetooth.AutoConnect should be false by default")
+	}
+	if cfg.Bluetooth.AutoConnectTimeout != 15*time.Second {
+		t.Errorf("Bluetooth.AutoConnectTimeout = %s, want 15s", cfg.Bluetooth.AutoConnectTimeout)
+	}
+	if cfg.Bluetooth.AutoConnectConcurrency != 2 {
+		t.Errorf("Bluetooth.AutoConnectConcurrency = %d, want 2", cfg.Bluetooth.AutoConnectConcurrency)
+	}
+	if len(cfg.Bluetooth.AllowList) != 0 || len(cfg.Bluetooth.BlockList) != 0 {
+		t.Errorf("Bluetooth device policy = %v / %v, want empty by default", cfg.Bluetooth.AllowList, cfg.Bluetooth.BlockList)
+	}
+}
+
+func TestNew_BluetoothIdlePowerOff(t *testing.T) {
+	viper.Reset()
+	t.Setenv("HOME", t.TempDir())
+
+	cfg, err := New(nil)
+	if err != nil {
+		t.Fatalf("New(nil) returned error: %v", err)
+	}
+	if !cfg.Bluetooth.IdlePowerOff {
+		t.Error("Bluetooth.IdlePowerOff should be true by default")
+	}
+
+	viper.Reset()
+	viper.Set("bluetooth.idle.poweroff", false)
+	cfg, err = New(nil)
+	if err != nil {
+		t.Fatalf("New(nil) returned error: %v", err)
+	}
+	if cfg.Bluetooth.IdlePowerOff {
+		t.Error("Bluetooth.IdlePowerOff should follow bluetooth.idle.poweroff=false")
+	}
+}
+
+func TestNew_ALSADisabledByDefault(t *testing.T) {
+	viper.Reset()
+	t.Setenv("HOME", t.TempDir())
+
+	cfg, err := New(nil)
+	if err != nil {
+		t.Fatalf("New(nil) returned error: %v", err)
+	}
+	if cfg.ALSA == nil {
+		t.Fatal("ALSA should not be nil")
+	}
+	if cfg.ALSA.Enabled {
+		t.Error("ALSA.Enabled should be false by default")
+	}
+}
+
+func TestNew_MPRISTimeouts(t *testing.T) {
+	tests := []struct {
+		name string
+		set  map[string]any
+		want MPRISTimeoutConfig
+	}{
+		{"default", nil, MPRISTimeoutConfig{Default: 5 * time.Second, List: 5 * time.Second, Control: 5 * time.Second}},
+		{"shorthand", map[string]any{"mpris.timeout": "2s"}, MPRISTimeoutConfig{Default: 2 * time.Second, List: 2 * time.Second, Control: 2 * time.Second}},
+		{"per-operation", map[string]any{
+			"mpris.timeout":          "2s",
+			"mpris.timeouts.list":    "10s",
+			"mpris.timeouts.control": "500ms",
+		}, MPRISTimeoutConfig{Default: 2 * time.Second, List: 10 * time.Second, Control: 500 * time.Millisecond}},
+		{"read alias", map[string]any{
+			"mpris.timeout":          "2s",
+			"mpris.timeouts.read":    "8s",
+			"mpris.timeouts.control": "1s",
+		}, MPRISTimeoutConfig{Default: 2 * time.Second, List: 8 * time.Second, Control: time.Second}},
+		{"list wins over read", map[string]any{
+			"mpris.timeouts.read": "8s",
+			"mpris.timeouts.list": "10s",
+		}, MPRISTimeoutConfig{Default: 5 * time.Second, List: 10 * time.Second, Control: 5 * time.Second}},
+		{"default inherited", map[string]any{"mpris.timeouts.default": "3s"}, MPRISTimeoutConfig{Default: 3 * time.Second, List: 3 * time.Second, Control: 3 * time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			for k, v := range tt.set {
+				viper.Set(k, v)
+			}
+			t.Setenv("HOME", t.TempDir())
+
+			cfg, err := New(nil)
+			if err != nil {
+				t.Fatalf("New(nil) returned error: %v", err)
+			}
+			if cfg.MPRIS.Timeouts != tt.want {
+				t.Errorf("MPRIS.Timeouts = %+v, want %+v", cfg.MPRIS.Timeouts, tt.want)
+			}
+		})
+	}
+}
+
+func TestEffective(t *testing.T) {
+	viper.Reset()
+	t.Setenv("HOME", t.TempDir())
+	viper.Set("systemd.enabled", true)
+	viper.Set("systemd.user", []string{"mpd.service", "snapclient.service"})
+	viper.Set("api.listens", []string{"127.0.0.1:9000"})
+
+	cfg, err := New(nil)
+	if err != nil {
+		t.Fatalf("New(nil) returned error: %v", err)
+	}
+	got := cfg.Effective()
+	for _, want := range []string{
+		"log=INFO",
+		"api=127.0.0.1:9000",
+		"systemd(units=0 system/2 user, timeout=1m30s)",
+		"mpris(timeout=5s list=5s control=5s)",
+		"bluetooth(timeout=5s pairing=1m0s idle=30m0s scan=1m0s)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Effective() = %q, want it to contain %q", got, want)
+		}
+	}
+	if !strings.Contains(got, "backends=[bt,mpris,pulse,systemd,zeroconf]") {
+		t.Errorf("Effective() = %q, want the enabled backends listed", got)
+	}
+	if strings.Contains(got, "\n") {
+		t.Errorf("Effective() = %q, want a single line", got)
+	}
+}
+
+func TestNew_StartupTimeouts(t *testing.T) {
+	viper.Reset()
+	t.Setenv("HOME", t.TempDir())
+
+	tmpDir := t.TempDir()
+	configFile := tmpDir + "/config.yaml"
+	configContent := `
+bluetooth:
+  startup_timeout: 5s
+`
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	cfg, err := New(&configFile)
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+	if cfg.Bluetooth.StartupTimeout != 5*time.Second {
+		t.Errorf("Bluetooth.StartupTimeout = %s, want 5s", cfg.Bluetooth.StartupTimeout)
+	}
+	for name, got := range map[string]time.Duration{
+		"mpris":      cfg.MPRIS.StartupTimeout,
+		"pulseaudio": cfg.Pulseaudio.StartupTimeout,
+		"systemd":    cfg.Systemd.StartupTimeout,
+	} {
+		if got != 10*time.Second {
+			t.Errorf("%s startup timeout = %s, want 10s default", name, got)
+		}
+	}
+}
+
+func TestNew_EventsDedupWindow(t *testing.T) {
+	viper.Reset()
+	t.Setenv("HOME", t.TempDir())
+
+	cfg, err := New(nil)
+	if err != nil {
+		t.Fatalf("New(nil) returned error: %v", err)
+	}
+	if cfg.Events.DedupWindow != 500*time.Millisecond {
+		t.Errorf("Events.DedupWindow = %s, want 500ms", cfg.Events.DedupWindow)
+	}
+}
+
+func TestNew_Login1GracePeriod(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  time.Duration
+	}{
+		{name: "default runs immediately", value: nil, want: 0},
+		{name: "explicit duration", value: "30s", want: 30 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			if tt.value != nil {
+				viper.Set("power.graceperiod", tt.value)
+			}
+			t.Setenv("HOME", t.TempDir())
+			t.Setenv("XDG_SESSION_DESKTOP", "test-desktop")
+
+			cfg, err := New(nil)
+			if err != nil {
+				t.Fatalf("New(nil) returned error: %v", err)
+			}
+			if cfg.Login1.GracePeriod != tt.want {
+				t.Errorf("GracePeriod = %v, want %v", cfg.Login1.GracePeriod, tt.want)
+			}
+		})
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"path"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
 
@@ -32,6 +34,21 @@ func parseLogLevel(levelStr string) logger.Level {
 	}
 }
 
+// applyOverrides sets each "key=value" pair above every other source. Keys are
+// dotted config keys such as api.port; values stay strings, which viper casts
+// on read.
+func applyOverrides(overrides []string) error {
+	for _, o := range overrides {
+		key, value, ok := strings.Cut(o, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return fmt.Errorf("invalid --set %q: want key=value", o)
+		}
+		viper.Set(key, value)
+	}
+	return nil
+}
+
 func getDuration(key string, fallback time.Duration) time.Duration {
 	if d := viper.GetDuration(key); d >= 0 {
 		return d
@@ -39,6 +56,117 @@ func getDuration(key string, fallback time.Duration) time.Duration {
 	return fallback
 }
 
+// getDurationIfSet is getDuration for keys without a default of their own:
+// an unset key inherits fallback, e.g. from a shorthand key.
+func getDurationIfSet(key string, fallback time.Duration) time.Duration {
+	if !viper.IsSet(key) {
+		return fallback
+	}
+	return getDuration(key, fallback)
+}
+
+// normalizeList lowercases and trims every entry of a name list, dropping
+// empty ones.
+func normalizeList(in []string) []string {
+	var out []string
+	for _, v := range in {
+		if v = strings.ToLower(strings.TrimSpace(v)); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// trimList drops blank entries and surrounding spaces, keeping the case.
+func trimList(in []string) []string {
+	var out []string
+	for _, v := range in {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// agentCapabilities are the BlueZ agent IO capabilities odio can act as.
+var agentCapabilities = []string{"NoInputNoOutput", "DisplayYesNo"}
+
+// parseAgentCapability validates bluetooth.agent_capability, matched
+// case-insensitively and returned in BlueZ's spelling.
+func parseAgentCapability(v string) (string, error) {
+	v = strings.TrimSpace(v)
+	for _, c := range agentCapabilities {
+		if strings.EqualFold(v, c) {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("invalid bluetooth.agent_capability %q: must be one of %s", v, strings.Join(agentCapabilities, ", "))
+}
+
+// parseUnitPatterns validates systemd.auto_whitelist_pattern: unit name
+// globs in path.Match syntax, blank entries dropped.
+func parseUnitPatterns(patterns []string) ([]string, error) {
+	patterns = trimList(patterns)
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid systemd.auto_whitelist_pattern %q: %w", p, err)
+		}
+	}
+	return patterns, nil
+}
+
+// parseBasePath validates api.ui.basepath: an absolute URL path below the
+// root, returned cleaned and without its trailing slash ("/odio/ui/" → "/odio/ui").
+func parseBasePath(v string) (string, error) {
+	v = strings.TrimSpace(v)
+	if !strings.HasPrefix(v, "/") || strings.ContainsAny(v, "?#") {
+		return "", fmt.Errorf("invalid api.ui.basepath %q: must be an absolute path like /ui", v)
+	}
+	v = path.Clean(v)
+	if v == "/" {
+		return "", fmt.Errorf("invalid api.ui.basepath %q: the UI cannot be mounted at the root", v)
+	}
+	return v, nil
+}
+
+// sensitiveKeyParts mark settings whose string values are redacted from the
+// debug dump.
+var sensitiveKeyParts = []string{"password", "secret", "token", "apikey", "credential"}
+
+// redacted replaces the value of a sensitive setting.
+const redacted = "<redacted>"
+
+// redactSettings returns a copy of viper's nested settings with the string
+// values of sensitive keys replaced, safe to paste into a bug report.
+func redactSettings(in map[string]any) map[string]any {
+	out := make(map[string]any, len(in))
+	for k, v := range in {
+		switch val := v.(type) {
+		case map[string]any:
+			out[k] = redactSettings(val)
+		case string:
+			if isSensitiveKey(k) && val != "" {
+				out[k] = redacted
+			} else {
+				out[k] = val
+			}
+		default:
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
 // parseSystemdServices accepts viper's raw value for a service list and
 // supports two YAML shapes interchangeably within the same list:
 //   - bare string  →  SystemdService{Name: s}
@@ -66,10 +194,40 @@ func parseSystemdServices(raw any) ([]SystemdService, error) {
 		if s.Name == "" {
 			return nil, fmt.Errorf("entry %d: missing or empty 'name' field", i)
 		}
+		if err := ValidateUnitName(s.Name); err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i, err)
+		}
 	}
 	return services, nil
 }
 
+// unitSuffixes are the unit types odio-api may manage.
+var unitSuffixes = []string{".service", ".timer", ".socket", ".path"}
+
+// maxUnitNameLen is systemd's own limit on unit names.
+const maxUnitNameLen = 255
+
+// ValidateUnitName checks a systemd unit name before it is watched or acted
+// on: non-empty, at most 255 bytes, no path separator or "..", and a
+// .service, .timer, .socket or .path suffix. A bare name gets a hint, so a
+// config listing "mympd" says to write "mympd.service".
+func ValidateUnitName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("empty unit name")
+	case len(name) > maxUnitNameLen:
+		return fmt.Errorf("unit name %.32q... is longer than %d bytes", name, maxUnitNameLen)
+	case strings.ContainsAny(name, `/\`) || strings.Contains(name, ".."):
+		return fmt.Errorf("unit name %q must not contain path separators or \"..\"", name)
+	}
+	for _, suffix := range unitSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return nil
+		}
+	}
+	return fmt.Errorf("unit name %q must end in .service, .timer, .socket or .path (did you mean %q?)", name, name+".service")
+}
+
 var systemdServiceType = reflect.TypeOf(SystemdService{})
 
 // stringToSystemdServiceHook lets a YAML scalar stand in for a {name, url}
@@ -132,12 +290,58 @@ func resolveBindsToListens(binds []string, port string) ([]string, error) {
 	return addrs, nil
 }
 
-// hasLoopback returns true if listens contains 127.0.0.1:port or 0.0.0.0:port.
+// parseListens validates explicit host:port listen addresses from
+// api.listens. Hosts must be IP literals, an empty host listening on all
+// interfaces; duplicates are dropped.
+func parseListens(entries []string) ([]string, error) {
+	seen := map[string]bool{}
+	var addrs []string
+	for _, entry := range entries {
+		host, portStr, err := net.SplitHostPort(strings.TrimSpace(entry))
+		if err != nil {
+			return nil, fmt.Errorf("invalid api.listens entry %q: %w", entry, err)
+		}
+		if host != "" && net.ParseIP(host) == nil {
+			return nil, fmt.Errorf("invalid api.listens entry %q: host must be an IP address", entry)
+		}
+		if port, err := strconv.Atoi(portStr); err != nil || port <= 0 || port > 65535 {
+			return nil, fmt.Errorf("invalid api.listens entry %q: bad port", entry)
+		}
+		addr := net.JoinHostPort(host, portStr)
+		if !seen[addr] {
+			seen[addr] = true
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs, nil
+}
+
+// listensPort returns the API port of explicit api.listens entries, as
+// parsed by parseListens: that of the first entry reachable over loopback,
+// which the UI calls, otherwise that of the first entry.
+func listensPort(listens []string) int {
+	first := 0
+	for i, l := range listens {
+		_, portStr, _ := net.SplitHostPort(l)
+		port, _ := strconv.Atoi(portStr)
+		if hasLoopback([]string{l}, portStr) {
+			return port
+		}
+		if i == 0 {
+			first = port
+		}
+	}
+	return first
+}
+
+// hasLoopback returns true if listens contains 127.0.0.1:port, 0.0.0.0:port
+// or :port.
 func hasLoopback(listens []string, port string) bool {
 	loopback := net.JoinHostPort("127.0.0.1", port)
 	wildcard := net.JoinHostPort("0.0.0.0", port)
+	anyHost := net.JoinHostPort("", port)
 	for _, l := range listens {
-		if l == loopback || l == wildcard {
+		if l == loopback || l == wildcard || l == anyHost {
 			return true
 		}
 	}
@@ -190,3 +394,33 @@ func systemdHasUTMP() bool {
 	_, err := os.Stat("/run/utmp")
 	return err == nil
 }
+
+// backendTxtRecords advertises which backends are enabled (e.g. "bt=1",
+// "power=0") so zeroconf clients can pick a box without connecting to it.
+func backendTxtRecords(cfg *Config) []string {
+	power := cfg.Login1.Enabled && cfg.Login1.Capabilities != nil &&
+		(cfg.Login1.Capabilities.CanPoweroff || cfg.Login1.Capabilities.CanReboot)
+	flags := []struct {
+		key     string
+		enabled bool
+	}{
+		{"alsa", cfg.ALSA.Enabled},
+		{"bt", cfg.Bluetooth.Enabled},
+		{"gpio", cfg.GPIO.Enabled},
+		{"mpris", cfg.MPRIS.Enabled},
+		{"power", power},
+		{"pulse", cfg.Pulseaudio.Enabled},
+		{"snapcast", cfg.Snapcast.Enabled},
+		{"systemd", cfg.Systemd.Enabled},
+		{"upgrade", cfg.Upgrade.Enabled},
+	}
+	records := make([]string, 0, len(flags))
+	for _, f := range flags {
+		v := "0"
+		if f.enabled {
+			v = "1"
+		}
+		records = append(records, f.key+"="+v)
+	}
+	return records
+}
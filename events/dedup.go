@@ -0,0 +1,69 @@
+package events
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// Deduper drops an event repeating the previous event of the same Type with an
+// identical payload within a short window. BlueZ, for one, fires several
+// PropertiesChanged signals for a single connection, each producing the same
+// bluetooth.updated state.
+//
+// Only the last payload per Type is remembered: a state going A → B → A within
+// the window must still deliver the final A, so an older identical payload
+// never suppresses anything. This also bounds the map to the number of event
+// types, so no cleanup is needed.
+type Deduper struct {
+	window time.Duration
+	last   sync.Map // Type → dedupEntry
+	now    func() time.Time
+}
+
+type dedupEntry struct {
+	hash uint64
+	seen time.Time
+}
+
+// NewDeduper returns a Deduper for the given window; nil when window <= 0,
+// which disables deduplication.
+func NewDeduper(window time.Duration) *Deduper {
+	if window <= 0 {
+		return nil
+	}
+	return &Deduper{window: window, now: time.Now}
+}
+
+// Allow reports whether e should be delivered, recording it when it is. A nil
+// Deduper allows everything.
+func (d *Deduper) Allow(e Event) bool {
+	if d == nil {
+		return true
+	}
+	hash, ok := payloadHash(e)
+	if !ok {
+		return true // unhashable payload: never a duplicate
+	}
+
+	now := d.now()
+	if prev, ok := d.last.Load(e.Type); ok {
+		entry := prev.(dedupEntry)
+		if entry.hash == hash && now.Sub(entry.seen) < d.window {
+			return false
+		}
+	}
+	d.last.Store(e.Type, dedupEntry{hash: hash, seen: now})
+	return true
+}
+
+func payloadHash(e Event) (uint64, bool) {
+	data, err := json.Marshal(e.Data)
+	if err != nil {
+		return 0, false
+	}
+	h := fnv.New64a()
+	_, _ = h.Write(data)
+	return h.Sum64(), true
+}
@@ -0,0 +1,51 @@
+package events
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewDeduper_Disabled(t *testing.T) {
+	if NewDeduper(0) != nil {
+		t.Error("NewDeduper(0) should return nil")
+	}
+	var d *Deduper
+	if !d.Allow(Event{Type: TypeBluetoothUpdated}) {
+		t.Error("nil Deduper should allow every event")
+	}
+}
+
+func TestDeduper_Allow(t *testing.T) {
+	now := time.Unix(0, 0)
+	d := NewDeduper(500 * time.Millisecond)
+	d.now = func() time.Time { return now }
+
+	connected := Event{Type: TypeBluetoothUpdated, Data: map[string]bool{"connected": true}}
+	idle := Event{Type: TypeBluetoothUpdated, Data: map[string]bool{"connected": false}}
+
+	if !d.Allow(connected) {
+		t.Fatal("first event should pass")
+	}
+	now = now.Add(100 * time.Millisecond)
+	if d.Allow(connected) {
+		t.Error("identical event within the window should be dropped")
+	}
+
+	// Same payload, other type: independent
+	if !d.Allow(Event{Type: TypeServiceUpdated, Data: map[string]bool{"connected": true}}) {
+		t.Error("identical payload of another type should pass")
+	}
+
+	// A → B → A: the final A is a real state change
+	if !d.Allow(idle) {
+		t.Error("different payload should pass")
+	}
+	if !d.Allow(connected) {
+		t.Error("A -> B -> A should deliver the final A")
+	}
+
+	now = now.Add(600 * time.Millisecond)
+	if !d.Allow(connected) {
+		t.Error("identical event after the window should pass")
+	}
+}
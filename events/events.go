@@ -14,6 +14,7 @@ const (
 	TypeServiceUpdated      = "service.updated"
 	TypeBluetoothUpdated    = "bluetooth.updated"
 	TypeBluetoothDiscovered = "bluetooth.discovered"
+	TypeBluetoothConnection = "bluetooth.connection"
 	TypePowerAction         = "power.action"
 	TypeUpgradeInfo         = "upgrade.info"
 	TypeUpgradeProgress     = "upgrade.progress"
@@ -40,7 +41,7 @@ var BackendTypes = map[string][]string{
 	"mpris":     {TypePlayerUpdated, TypePlayerAdded, TypePlayerRemoved, TypePlayerPosition, TypePlayerTracklist},
 	"audio":     {TypeAudioUpdated, TypeAudioRemoved, TypeAudioOutputUpdated, TypeAudioOutputRemoved},
 	"systemd":   {TypeServiceUpdated},
-	"bluetooth": {TypeBluetoothUpdated, TypeBluetoothDiscovered},
+	"bluetooth": {TypeBluetoothUpdated, TypeBluetoothDiscovered, TypeBluetoothConnection},
 	"power":     {TypePowerAction},
 	"upgrade":   {TypeUpgradeInfo, TypeUpgradeProgress},
 }
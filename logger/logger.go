@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 )
 
 type Level int
@@ -24,6 +25,14 @@ var levelNames = map[Level]string{
 	FATAL: "FATAL",
 }
 
+// String returns the level name, e.g. "INFO".
+func (l Level) String() string {
+	if name, ok := levelNames[l]; ok {
+		return strings.TrimSpace(name)
+	}
+	return fmt.Sprintf("Level(%d)", int(l))
+}
+
 type Logger struct {
 	level  Level
 	logger *log.Logger
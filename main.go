@@ -7,7 +7,9 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/b0bbywan/go-odio-api/api"
 	"github.com/b0bbywan/go-odio-api/backend"
@@ -22,7 +24,10 @@ func main() {
 
 	flag.Usage = usage
 	configFile := flag.String("config", "", "path to configuration file")
+	var overrides setFlags
+	flag.Var(&overrides, "set", "override a config key (key=value), repeatable")
 	versionFlag := flag.Bool("version", false, "Print version")
+	dumpConfig := flag.Bool("dump-config", false, "print the effective configuration as YAML and exit")
 
 	flag.Parse()
 
@@ -31,13 +36,23 @@ func main() {
 		return
 	}
 
-	cfg, err := config.New(configFile)
+	cfg, err := config.New(configFile, overrides...)
 	if err != nil {
 		logger.Fatal("[%s] Failed to load config: %v", config.AppName, err)
 	}
 
+	if *dumpConfig {
+		out, err := config.Dump()
+		if err != nil {
+			logger.Fatal("[%s] Failed to dump config: %v", config.AppName, err)
+		}
+		fmt.Print(string(out))
+		return
+	}
+
 	// Set log level from config
 	logger.SetLevel(cfg.LogLevel)
+	logger.Info("[%s] effective config: %s", config.AppName, cfg.Effective())
 
 	// Global context for the entire application
 	ctx, cancel := context.WithCancel(context.Background())
@@ -46,10 +61,15 @@ func main() {
 	// Initialize backends
 	b, err := backend.New(
 		ctx,
+		cfg.ALSA,
 		cfg.Bluetooth,
+		cfg.Events,
+		cfg.GPIO,
 		cfg.Login1,
 		cfg.MPRIS,
+		cfg.Plugins,
 		cfg.Pulseaudio,
+		cfg.Snapcast,
 		cfg.Systemd,
 		cfg.Upgrade,
 		cfg.Zeroconf,
@@ -75,14 +95,14 @@ func main() {
 		<-sigChan
 
 		logger.Info("[%s] Shutdown signal received, stopping server...", config.AppName)
-		clear(b, cancel, shutdownDone)
+		clear(b, server, cfg.Api.ShutdownTimeout, cancel, shutdownDone)
 	}()
 
 	logger.Info("[%s] started", config.AppName)
 	if server != nil {
 		if err := server.Run(ctx); err != nil && err != http.ErrServerClosed {
 			logger.Error("[%s] http server error: %v", config.AppName, err)
-			clear(b, cancel, shutdownDone)
+			clear(b, server, cfg.Api.ShutdownTimeout, cancel, shutdownDone)
 		}
 	}
 
@@ -90,7 +110,16 @@ func main() {
 	logger.Info("[%s] stopped", config.AppName)
 }
 
-func clear(b *backend.Backend, cancel context.CancelFunc, shutdown chan struct{}) {
+func clear(b *backend.Backend, server *api.Server, timeout time.Duration, cancel context.CancelFunc, shutdown chan struct{}) {
+	// Let in-flight requests complete while the backends can still serve them
+	if server != nil {
+		ctx, cancelShutdown := context.WithTimeout(context.Background(), timeout)
+		if err := server.Shutdown(ctx); err != nil {
+			logger.Warn("[%s] http server did not drain in %s: %v", config.AppName, timeout, err)
+		}
+		cancelShutdown()
+	}
+
 	// Cancel the global context - stops all listeners
 	cancel()
 
@@ -101,12 +130,30 @@ func clear(b *backend.Backend, cancel context.CancelFunc, shutdown chan struct{}
 	close(shutdown)
 }
 
+// setFlags collects repeated --set key=value flags.
+type setFlags []string
+
+func (s *setFlags) String() string { return strings.Join(*s, ",") }
+
+func (s *setFlags) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
 func usage() {
 	fmt.Println("Usage:")
 	fmt.Println("  odio-api [options]")
 	fmt.Println("")
 	fmt.Println("Options:")
-	fmt.Println("  --config <path>  configuration file to use")
-	fmt.Println("  --version        Display version")
-	fmt.Println("  -h, --help       this help message")
+	fmt.Println("  --config <path>       configuration file to use")
+	fmt.Println("  --set <key>=<value>   override a config key, e.g. --set api.port=9090 (repeatable)")
+	fmt.Println("  --dump-config         print the effective configuration as YAML and exit")
+	fmt.Println("  --version             Display version")
+	fmt.Println("  -h, --help            this help message")
+	fmt.Println("")
+	fmt.Println("Configuration priority, highest first:")
+	fmt.Println("  1. --set flags")
+	fmt.Println("  2. environment variables: ODIO_ + key, dots as underscores (ODIO_API_PORT=9090)")
+	fmt.Println("  3. config file (--config or the default locations) and its conf.d/ snippets")
+	fmt.Println("  4. built-in defaults")
 }
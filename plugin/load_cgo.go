@@ -0,0 +1,35 @@
+//go:build cgo
+
+package plugin
+
+import (
+	"fmt"
+	goplugin "plugin"
+)
+
+// Supported reports whether this build can load Go plugins.
+const Supported = true
+
+// Load opens the plugin at soPath and builds its backend.
+func Load(soPath string) (Backend, error) {
+	p, err := goplugin.Open(soPath)
+	if err != nil {
+		return nil, fmt.Errorf("open plugin %s: %w", soPath, err)
+	}
+	sym, err := p.Lookup(NewSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", soPath, err)
+	}
+	newBackend, ok := sym.(func() (Backend, error))
+	if !ok {
+		return nil, fmt.Errorf("plugin %s: %s has type %T, want func() (plugin.Backend, error)", soPath, NewSymbol, sym)
+	}
+	b, err := newBackend()
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", soPath, err)
+	}
+	if b == nil {
+		return nil, fmt.Errorf("plugin %s: %s returned no backend", soPath, NewSymbol)
+	}
+	return b, nil
+}
@@ -0,0 +1,16 @@
+//go:build !cgo
+
+package plugin
+
+import "errors"
+
+// Supported reports whether this build can load Go plugins.
+const Supported = false
+
+// ErrUnsupported is returned by Load in builds without cgo.
+var ErrUnsupported = errors.New("plugins unsupported in this build (built without cgo)")
+
+// Load always fails: the Go plugin loader needs cgo.
+func Load(soPath string) (Backend, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,14 @@
+//go:build !cgo
+
+package plugin
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLoadUnsupportedWithoutCgo(t *testing.T) {
+	if _, err := Load("/usr/lib/odio-api/plugins/any.so"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Load() error = %v, want ErrUnsupported", err)
+	}
+}
@@ -0,0 +1,31 @@
+// Package plugin lets external Go programs add backends without forking:
+// a plugin is a Go plugin (.so, built with -buildmode=plugin against the same
+// module version) exporting a NewBackend function. Go plugins need cgo: in
+// a CGO_ENABLED=0 build, Supported is false and Load always fails.
+package plugin
+
+import (
+	"net/http"
+
+	"github.com/b0bbywan/go-odio-api/events"
+)
+
+// NewSymbol is the function a plugin must export, of type
+// func() (plugin.Backend, error).
+const NewSymbol = "NewBackend"
+
+// Route is an HTTP handler a plugin mounts on the API server. Pattern follows
+// http.ServeMux syntax, e.g. "GET /squeezebox/players".
+type Route struct {
+	Pattern string
+	Handler http.HandlerFunc
+}
+
+// Backend is what a plugin provides. It is started after the built-in
+// backends and closed with them; its events join the SSE stream.
+type Backend interface {
+	Start() error
+	Close()
+	Routes() []Route
+	Events() <-chan events.Event
+}
@@ -0,0 +1,12 @@
+package plugin
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadMissingFile(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.so")); err == nil {
+		t.Fatal("Load() error = nil, want an error for a missing plugin")
+	}
+}
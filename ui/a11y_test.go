@@ -0,0 +1,140 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+// renderNodes renders a template and parses the result as an HTML fragment.
+func renderNodes(t *testing.T, name string, data any) *html.Node {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := LoadTemplates("en", DefaultBasePath).ExecuteTemplate(&buf, name, data); err != nil {
+		t.Fatalf("render %s: %v", name, err)
+	}
+	doc, err := html.Parse(&buf)
+	if err != nil {
+		t.Fatalf("parse %s: %v", name, err)
+	}
+	return doc
+}
+
+// findAll returns the element nodes of the tree matching pred, in document order.
+func findAll(n *html.Node, pred func(*html.Node) bool) []*html.Node {
+	var out []*html.Node
+	for c := range n.Descendants() {
+		if c.Type == html.ElementNode && pred(c) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func attr(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// visibleText is the node's text content outside of inline SVG icons.
+func visibleText(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "svg" {
+			return
+		}
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return strings.TrimSpace(sb.String())
+}
+
+func TestAccessibilityAttributes(t *testing.T) {
+	vol := 0.4
+	player := PlayerView{
+		Name: "p", State: "Playing", Title: "Song", Volume: &vol,
+		CanPlay: true, CanPause: true, CanNext: true, CanPrev: true, CanStop: true,
+		CanShuffle: true, CanLoop: true, LoopStatus: "None",
+		Tracks: []TrackView{{Ref: "1", Label: "One"}, {Ref: "2", Label: "Two"}},
+	}
+	renders := map[string]*html.Node{
+		"mpris-player":    renderNodes(t, "mpris-player", player),
+		"pulseaudio-sink": renderNodes(t, "pulseaudio-sink", AudioClient{Name: "firefox", Volume: 0.5}),
+		"section-bluetooth": renderNodes(t, "section-bluetooth", &BluetoothView{
+			Powered: true,
+		}),
+	}
+
+	for name, doc := range renders {
+		t.Run(name+" icon-only buttons are labelled", func(t *testing.T) {
+			for _, b := range findAll(doc, func(n *html.Node) bool { return n.Data == "button" }) {
+				if visibleText(b) != "" {
+					continue
+				}
+				if label, _ := attr(b, "aria-label"); label == "" {
+					t.Errorf("icon-only button without aria-label: %v", b.Attr)
+				}
+			}
+		})
+
+		t.Run(name+" sliders expose their range", func(t *testing.T) {
+			sliders := findAll(doc, func(n *html.Node) bool {
+				typ, _ := attr(n, "type")
+				return n.Data == "input" && typ == "range"
+			})
+			for _, s := range sliders {
+				for _, key := range []string{"aria-valuemin", "aria-valuemax", "aria-valuenow", "aria-label"} {
+					if _, ok := attr(s, key); !ok {
+						t.Errorf("slider missing %s: %v", key, s.Attr)
+					}
+				}
+			}
+		})
+	}
+
+	t.Run("player status is a polite live region", func(t *testing.T) {
+		live := findAll(renders["mpris-player"], func(n *html.Node) bool {
+			role, _ := attr(n, "role")
+			polite, _ := attr(n, "aria-live")
+			return role == "status" && polite == "polite"
+		})
+		if len(live) == 0 {
+			t.Error(`expected an element with role="status" aria-live="polite"`)
+		}
+	})
+
+	t.Run("volume slider reports its value", func(t *testing.T) {
+		sliders := findAll(renders["pulseaudio-sink"], func(n *html.Node) bool { return n.Data == "input" })
+		if len(sliders) != 1 {
+			t.Fatalf("got %d sliders, want 1", len(sliders))
+		}
+		if now, _ := attr(sliders[0], "aria-valuenow"); now != "50" {
+			t.Errorf("aria-valuenow = %q, want 50", now)
+		}
+	})
+
+	t.Run("bluetooth power button is a toggle", func(t *testing.T) {
+		power := findAll(renders["section-bluetooth"], func(n *html.Node) bool {
+			post, _ := attr(n, "hx-post")
+			return n.Data == "button" && strings.HasPrefix(post, "/bluetooth/power_")
+		})
+		if len(power) != 1 {
+			t.Fatalf("got %d power buttons, want 1", len(power))
+		}
+		if pressed, _ := attr(power[0], "aria-pressed"); pressed != "true" {
+			t.Errorf(`aria-pressed = %q, want "true" while powered`, pressed)
+		}
+	})
+}
@@ -20,17 +20,35 @@ type APIClient struct {
 	client  *http.Client
 }
 
+// DefaultClientTimeout bounds each internal API call unless configured.
+const DefaultClientTimeout = 3 * time.Second
+
 // NewAPIClient creates a new internal API client.
 // It always connects to 127.0.0.1, which is guaranteed to be in the server's listen list.
-func NewAPIClient(port int) *APIClient {
+// timeout bounds each call, so a stalled handler fails its section instead of
+// hanging the whole dashboard render.
+func NewAPIClient(port int, timeout time.Duration) *APIClient {
 	return &APIClient{
 		baseURL: fmt.Sprintf("http://127.0.0.1:%d", port),
 		client: &http.Client{
-			Timeout: 5 * time.Second,
+			Timeout:   timeout,
+			Transport: bareJSON{http.DefaultTransport},
 		},
 	}
 }
 
+// bareJSON asks the API for bare payloads: the views decode them directly,
+// whether or not api.envelope wraps responses for other clients.
+type bareJSON struct {
+	next http.RoundTripper
+}
+
+func (t bareJSON) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("X-Odio-Envelope", "off")
+	return t.next.RoundTrip(req)
+}
+
 func (c *APIClient) GetServerInfo() (*ServerInfo, error) {
 	var v ServerInfo
 	if err := c.get("/server", &v); err != nil {
@@ -39,6 +57,11 @@ func (c *APIClient) GetServerInfo() (*ServerInfo, error) {
 	if v.Backends.Power {
 		var power PowerCapabilities
 		if err := c.get("/power", &power); err == nil {
+			// hide the actions polkit currently refuses
+			if power.Live != nil {
+				power.Reboot = power.Reboot && power.Live.Reboot
+				power.PowerOff = power.PowerOff && power.Live.PowerOff
+			}
 			v.Power = &power
 		}
 	}
@@ -118,6 +141,7 @@ func convertTracks(tracks []Track, currentID string) []TrackView {
 
 func convertPlayers(raw []Player) []PlayerView {
 	views := make([]PlayerView, 0, len(raw))
+	featured := false
 	for _, p := range raw {
 		if p.Status != "Playing" && p.Status != "Paused" {
 			continue
@@ -163,7 +187,9 @@ func convertPlayers(raw []Player) []PlayerView {
 			Rate:              p.Rate,
 			CanSeek:           p.Capabilities.CanSeek,
 			PositionUpdatedAt: p.PositionUpdatedAt.Format(time.RFC3339Nano),
+			Featured:          !featured && p.Status == "Playing",
 		})
+		featured = featured || p.Status == "Playing"
 	}
 	return views
 }
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"html/template"
 	"net/http"
+	"path"
 	"strings"
 	"time"
 
@@ -20,8 +21,21 @@ var templatesFS embed.FS
 //go:embed static
 var staticFS embed.FS
 
-func LoadTemplates() *template.Template {
+// DefaultBasePath is the path the UI is served at, and its public URL prefix
+// unless a reverse proxy mounts it elsewhere.
+const DefaultBasePath = "/ui"
+
+// LoadTemplates parses the embedded templates with their user-visible strings
+// translated to locale (see translator). basePath is the UI's public URL prefix:
+// UI links (assets, sections, events) start with uiBase, API links with
+// apiBase, its parent ("" for the default /ui).
+func LoadTemplates(locale, basePath string) *template.Template {
+	apiBase := strings.TrimSuffix(path.Dir(basePath), "/")
 	funcMap := template.FuncMap{
+		"t":       translator(locale),
+		"locale":  func() string { return locale },
+		"uiBase":  func() string { return basePath },
+		"apiBase": func() string { return apiBase },
 		"mul": func(a, b float64) float64 {
 			return a * b
 		},
@@ -56,17 +70,28 @@ func LoadTemplates() *template.Template {
 
 // Handler manages UI routes and rendering
 type Handler struct {
-	tmpl        *template.Template
-	client      *APIClient
-	broadcaster *backend.Broadcaster
+	tmpl            *template.Template
+	client          *APIClient
+	broadcaster     *backend.Broadcaster
+	refreshInterval time.Duration
 }
 
-// NewHandler creates a new UI handler with API client and event broadcaster
-func NewHandler(apiPort int, broadcaster *backend.Broadcaster) *Handler {
+// NewHandler creates a new UI handler with API client and event broadcaster.
+// refreshInterval is the section polling cadence used while SSE is down,
+// clientTimeout bounds each internal API call, locale selects the translation
+// the templates are rendered in and basePath the URL prefix generated links
+// use (see LoadTemplates).
+func NewHandler(
+	apiPort int,
+	refreshInterval, clientTimeout time.Duration,
+	locale, basePath string,
+	broadcaster *backend.Broadcaster,
+) *Handler {
 	return &Handler{
-		tmpl:        LoadTemplates(),
-		client:      NewAPIClient(apiPort),
-		broadcaster: broadcaster,
+		tmpl:            LoadTemplates(locale, basePath),
+		client:          NewAPIClient(apiPort, clientTimeout),
+		broadcaster:     broadcaster,
+		refreshInterval: refreshInterval,
 	}
 }
 
@@ -89,8 +114,9 @@ func (h *Handler) Dashboard(w http.ResponseWriter, r *http.Request) {
 
 	// Build view data
 	data := DashboardView{
-		Title:      "Odio",
-		ServerInfo: serverInfo,
+		Title:          "Odio",
+		ServerInfo:     serverInfo,
+		RefreshSeconds: max(int(h.refreshInterval/time.Second), 1),
 	}
 
 	// Conditionally fetch data based on enabled backends
@@ -310,10 +336,11 @@ var (
 		events.TypeAudioOutputUpdated: audioSection,
 		events.TypeAudioOutputRemoved: audioSection,
 
-		events.TypeServiceUpdated:   systemdSection,
-		events.TypeBluetoothUpdated: bluetoothSection,
-		events.TypeUpgradeInfo:      upgradeSection,
-		events.TypeUpgradeProgress:  upgradeRingSection,
+		events.TypeServiceUpdated:      systemdSection,
+		events.TypeBluetoothUpdated:    bluetoothSection,
+		events.TypeBluetoothConnection: bluetoothSection,
+		events.TypeUpgradeInfo:         upgradeSection,
+		events.TypeUpgradeProgress:     upgradeRingSection,
 	}
 )
 
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"bytes"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -20,7 +21,7 @@ func TestLoadTemplates(t *testing.T) {
 		}
 	}()
 
-	tmpl := LoadTemplates()
+	tmpl := LoadTemplates("en", DefaultBasePath)
 	if tmpl == nil {
 		t.Fatal("LoadTemplates returned nil")
 	}
@@ -37,6 +38,7 @@ func TestLoadTemplates(t *testing.T) {
 		"section-upgrade",
 		"upgrade-ring",
 		"mpris-player",
+		"mpris-player-featured",
 		"pulseaudio-sink",
 		"systemd-unit",
 	}
@@ -50,7 +52,7 @@ func TestLoadTemplates(t *testing.T) {
 
 // TestSectionTemplates verifies all section templates can be executed without panic
 func TestSectionTemplates(t *testing.T) {
-	tmpl := LoadTemplates()
+	tmpl := LoadTemplates("en", DefaultBasePath)
 
 	tests := []struct {
 		name     string
@@ -151,7 +153,7 @@ func TestSectionTemplates(t *testing.T) {
 // TestUpgradeBadgeTemplate asserts the badge label per state and that the
 // last-check time is surfaced in the tooltip; every state is a re-check button.
 func TestUpgradeBadgeTemplate(t *testing.T) {
-	tmpl := LoadTemplates()
+	tmpl := LoadTemplates("en", DefaultBasePath)
 	checked := time.Date(2026, 6, 15, 20, 46, 34, 0, time.UTC)
 
 	// Badge is icon-only; state is asserted via the tooltip and a distinguishing
@@ -212,7 +214,7 @@ func TestUpgradeBadgeTemplate(t *testing.T) {
 }
 
 func TestUpgradeBadgeRunning(t *testing.T) {
-	tmpl := LoadTemplates()
+	tmpl := LoadTemplates("en", DefaultBasePath)
 	pct := 42
 
 	render := func(status *UpgradeStatus) string {
@@ -246,7 +248,7 @@ func TestUpgradeBadgeRunning(t *testing.T) {
 }
 
 func TestUpgradeBadgeFailed(t *testing.T) {
-	tmpl := LoadTemplates()
+	tmpl := LoadTemplates("en", DefaultBasePath)
 
 	render := func(status *UpgradeStatus) string {
 		t.Helper()
@@ -295,7 +297,7 @@ func TestUpgradeBadgeFailed(t *testing.T) {
 // TestUpgradeBadgeGatedActions verifies the badge renders a static icon (no
 // hx-post) when the matching trigger is unavailable, e.g. result-file-only mode.
 func TestUpgradeBadgeGatedActions(t *testing.T) {
-	tmpl := LoadTemplates()
+	tmpl := LoadTemplates("en", DefaultBasePath)
 
 	render := func(status *UpgradeStatus) string {
 		t.Helper()
@@ -330,7 +332,7 @@ func TestUpgradeBadgeGatedActions(t *testing.T) {
 
 // TestComponentTemplates verifies all component templates can be executed without panic
 func TestComponentTemplates(t *testing.T) {
-	tmpl := LoadTemplates()
+	tmpl := LoadTemplates("en", DefaultBasePath)
 
 	tests := []struct {
 		name     string
@@ -625,7 +627,7 @@ func TestGetAudio_FilterCorked(t *testing.T) {
 
 	u, _ := url.Parse(server.URL)
 	port, _ := strconv.Atoi(u.Port())
-	client := NewAPIClient(port)
+	client := NewAPIClient(port, DefaultClientTimeout)
 
 	data, err := client.GetAudio()
 	if err != nil {
@@ -718,7 +720,7 @@ func TestConvertBluetooth(t *testing.T) {
 // must not mangle the embedded JSON), and that a nameless device falls back to
 // its address.
 func TestBluetoothDevicesTemplate(t *testing.T) {
-	tmpl := LoadTemplates()
+	tmpl := LoadTemplates("en", DefaultBasePath)
 	view := &BluetoothView{
 		Powered: true,
 		Devices: []BluetoothDevice{
@@ -752,7 +754,7 @@ func TestBluetoothDevicesTemplate(t *testing.T) {
 // description is rendered as a clickable <a> wired to openServiceUrl, and
 // without one the description stays plain text.
 func TestSystemdUnitTemplate_URLLink(t *testing.T) {
-	tmpl := LoadTemplates()
+	tmpl := LoadTemplates("en", DefaultBasePath)
 
 	tests := []struct {
 		name    string
@@ -968,7 +970,7 @@ func TestGetPlayersAttachesTracklists(t *testing.T) {
 	server := httptest.NewServer(mux)
 	defer server.Close()
 
-	views, err := NewAPIClient(testAPIPort(t, server)).GetPlayers()
+	views, err := NewAPIClient(testAPIPort(t, server), DefaultClientTimeout).GetPlayers()
 	if err != nil {
 		t.Fatalf("GetPlayers failed: %v", err)
 	}
@@ -997,7 +999,7 @@ func TestGetPlayersAttachesTracklists(t *testing.T) {
 // TestMprisPlayerTracklistRendering verifies the toggle and tracklist only
 // render with at least 2 tracks, and remove buttons only when editable.
 func TestMprisPlayerTracklistRendering(t *testing.T) {
-	tmpl := LoadTemplates()
+	tmpl := LoadTemplates("en", DefaultBasePath)
 	twoTracks := []TrackView{
 		{Ref: "%2Fa%2F1", Label: "One", Current: true},
 		{Ref: "%2Fa%2F2", Label: "Two"},
@@ -1046,3 +1048,141 @@ func TestMprisPlayerTracklistRendering(t *testing.T) {
 		}
 	})
 }
+
+func TestConvertPlayersFeatured(t *testing.T) {
+	views := convertPlayers([]Player{
+		{Name: "paused", Status: "Paused"},
+		{Name: "first", Status: "Playing"},
+		{Name: "second", Status: "Playing"},
+	})
+
+	want := map[string]bool{"paused": false, "first": true, "second": false}
+	for _, v := range views {
+		if v.Featured != want[v.Name] {
+			t.Errorf("%s: Featured = %v, want %v", v.Name, v.Featured, want[v.Name])
+		}
+	}
+}
+
+func TestMprisPlayerFeaturedRendering(t *testing.T) {
+	tmpl := LoadTemplates("en", DefaultBasePath)
+
+	var buf bytes.Buffer
+	players := []PlayerView{
+		{Name: "p", State: "Playing", Title: "Song", Artist: "Band", Album: "Record",
+			ArtUrl: "/players/p/cover?t=1", Position: 30_000_000, Duration: 120_000_000, Featured: true},
+		{Name: "q", State: "Paused"},
+	}
+	if err := tmpl.ExecuteTemplate(&buf, "section-mpris", players); err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	html := buf.String()
+
+	for _, want := range []string{
+		"player-featured-art",
+		"Song", "Band", "Record",
+		`hx-get="/players/p/position"`,
+		"width: 25.00%",
+		`id="player-q"`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected rendered section to contain %q", want)
+		}
+	}
+	if strings.Count(html, "player-featured-art") != 1 {
+		t.Error("expected a single featured card")
+	}
+}
+
+// TestDashboardRefreshTrigger verifies that sections poll at the configured
+// cadence, gated on the SSE stream being down
+func TestDashboardRefreshTrigger(t *testing.T) {
+	tmpl := LoadTemplates("en", DefaultBasePath)
+
+	var buf bytes.Buffer
+	data := DashboardView{
+		Title:          "Odio",
+		ServerInfo:     &ServerInfo{Backends: Backends{MPRIS: true}},
+		RefreshSeconds: 7,
+	}
+	if err := tmpl.ExecuteTemplate(&buf, "dashboard", data); err != nil {
+		t.Fatalf("ExecuteTemplate: %v", err)
+	}
+	want := `hx-trigger="every 7s [sseDown()]"`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("dashboard missing %s", want)
+	}
+}
+
+// TestDashboardBasePath verifies that a custom base path prefixes every UI
+// link, and its parent every API link
+func TestDashboardBasePath(t *testing.T) {
+	tmpl := LoadTemplates("en", "/odio/ui")
+
+	var buf bytes.Buffer
+	data := DashboardView{
+		Title:      "Odio",
+		ServerInfo: &ServerInfo{Backends: Backends{MPRIS: true}},
+		Players: []PlayerView{
+			{Name: "org.mpris.MediaPlayer2.spotify", State: "Playing", CanPlay: true, Featured: true,
+				ArtUrl: "/players/org.mpris.MediaPlayer2.spotify/cover?t=1"},
+			{Name: "org.mpris.MediaPlayer2.mpd", State: "Paused", CanPlay: true,
+				ArtUrl: "/players/org.mpris.MediaPlayer2.mpd/cover?t=2"},
+		},
+		RefreshSeconds: 5,
+	}
+	if err := tmpl.ExecuteTemplate(&buf, "dashboard", data); err != nil {
+		t.Fatalf("ExecuteTemplate: %v", err)
+	}
+	html := buf.String()
+
+	for _, want := range []string{
+		`src="/odio/players/org.mpris.MediaPlayer2.spotify/cover?t=1"`,
+		`src="/odio/players/org.mpris.MediaPlayer2.mpd/cover?t=2"`,
+		`href="/odio/ui/static/output.css"`,
+		`src="/odio/ui/static/odio.js"`,
+		`sse-connect="/odio/ui/events"`,
+		`hx-get="/odio/ui/sections/mpris"`,
+		`data-api-base="/odio"`,
+		`hx-post="/odio/players/org.mpris.MediaPlayer2.spotify/play_pause"`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("dashboard missing %s", want)
+		}
+	}
+	for _, stale := range []string{`"/ui/`, `hx-post="/players`, `src="/players`} {
+		if strings.Contains(html, stale) {
+			t.Errorf("dashboard still has unprefixed %s", stale)
+		}
+	}
+}
+
+// TestAPIClientTimeout verifies that a stalled API handler fails the call
+// within the client timeout instead of hanging the render
+func TestAPIClientTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	const timeout = 100 * time.Millisecond
+	start := time.Now()
+	_, err := NewAPIClient(testAPIPort(t, server), timeout).GetPlayers()
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Fatal("GetPlayers returned no error from a stalled server")
+	}
+	var netErr interface{ Timeout() bool }
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Errorf("error = %v, want a timeout", err)
+	}
+	if elapsed > 10*timeout {
+		t.Errorf("GetPlayers took %v, want about %v", elapsed, timeout)
+	}
+}
@@ -0,0 +1,62 @@
+package ui
+
+import (
+	"embed"
+	"fmt"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/b0bbywan/go-odio-api/logger"
+)
+
+// defaultLocale is the reference translation: every key exists in it, and
+// lookups missing from another locale fall back to it.
+const defaultLocale = "en"
+
+//go:embed locales
+var localesFS embed.FS
+
+// loadLocale reads the flat key → message map of an embedded locale file.
+func loadLocale(locale string) (map[string]string, error) {
+	data, err := localesFS.ReadFile("locales/" + locale + ".yaml")
+	if err != nil {
+		return nil, fmt.Errorf("unknown locale %q", locale)
+	}
+	var messages map[string]string
+	if err := yaml.Unmarshal(data, &messages); err != nil {
+		return nil, fmt.Errorf("locale %q: %w", locale, err)
+	}
+	return messages, nil
+}
+
+// translator returns the "t" template function for locale: it looks key up in
+// locale, then in defaultLocale, then returns the key itself so a missing
+// translation shows up in the page rather than as an empty string. Extra
+// arguments are formatted into the message with fmt.Sprintf.
+// An unknown locale logs a warning and falls back to defaultLocale.
+func translator(locale string) func(key string, args ...any) string {
+	fallback, err := loadLocale(defaultLocale)
+	if err != nil {
+		panic(err) // embedded reference locale, broken only by a bad build
+	}
+	messages := fallback
+	if locale != defaultLocale {
+		if messages, err = loadLocale(locale); err != nil {
+			logger.Warn("[ui] %v, falling back to %s", err, defaultLocale)
+			messages = fallback
+		}
+	}
+
+	return func(key string, args ...any) string {
+		msg, ok := messages[key]
+		if !ok {
+			if msg, ok = fallback[key]; !ok {
+				return key
+			}
+		}
+		if len(args) > 0 {
+			return fmt.Sprintf(msg, args...)
+		}
+		return msg
+	}
+}
@@ -0,0 +1,75 @@
+package ui
+
+import (
+	"bytes"
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+// TestLocalesHaveSameKeys keeps every embedded translation in step with the
+// reference locale, so a string added to a template is translated everywhere.
+func TestLocalesHaveSameKeys(t *testing.T) {
+	ref, err := loadLocale(defaultLocale)
+	if err != nil {
+		t.Fatalf("loadLocale(%q): %v", defaultLocale, err)
+	}
+
+	files, err := fs.Glob(localesFS, "locales/*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		locale := strings.TrimSuffix(strings.TrimPrefix(f, "locales/"), ".yaml")
+		messages, err := loadLocale(locale)
+		if err != nil {
+			t.Fatalf("loadLocale(%q): %v", locale, err)
+		}
+		for key := range ref {
+			if _, ok := messages[key]; !ok {
+				t.Errorf("%s: missing key %q", locale, key)
+			}
+		}
+		for key := range messages {
+			if _, ok := ref[key]; !ok {
+				t.Errorf("%s: key %q not in %s", locale, key, defaultLocale)
+			}
+		}
+	}
+}
+
+func TestTranslator(t *testing.T) {
+	fr := translator("fr")
+	if got := fr("mpris.title"); got != "Lecteurs multimédia" {
+		t.Errorf(`fr("mpris.title") = %q`, got)
+	}
+	if got := fr("mpris.active", 2); got != "2 actif(s)" {
+		t.Errorf(`fr("mpris.active", 2) = %q`, got)
+	}
+	if got := fr("no.such.key"); got != "no.such.key" {
+		t.Errorf("missing key = %q, want the key itself", got)
+	}
+
+	unknown := translator("xx")
+	if got := unknown("mpris.title"); got != "Media Players" {
+		t.Errorf("unknown locale = %q, want the English fallback", got)
+	}
+}
+
+func TestLoadTemplatesLocale(t *testing.T) {
+	tmpl := LoadTemplates("fr", DefaultBasePath)
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "section-mpris", []PlayerView{}); err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	html := buf.String()
+	for _, want := range []string{"Lecteurs multimédia", "Aucun lecteur actif"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected French section to contain %q", want)
+		}
+	}
+	if strings.Contains(html, "Media Players") {
+		t.Error("expected no English title in the French section")
+	}
+}
@@ -39,7 +39,8 @@ func etagHandler(next http.Handler) http.Handler {
 		if etag, ok := etags[path]; ok {
 			w.Header().Set("ETag", etag)
 			w.Header().Set("Cache-Control", "no-cache")
-			if match := r.Header.Get("If-None-Match"); match == etag {
+			if (r.Method == http.MethodGet || r.Method == http.MethodHead) &&
+				etagMatch(r.Header.Get("If-None-Match"), etag) {
 				w.WriteHeader(http.StatusNotModified)
 				return
 			}
@@ -48,6 +49,19 @@ func etagHandler(next http.Handler) http.Handler {
 	})
 }
 
+// etagMatch reports whether an If-None-Match header matches etag. Browsers
+// and proxies may send a list, "*" or a weak (W/) validator; If-None-Match
+// uses the weak comparison, so the W/ prefix is ignored.
+func etagMatch(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
 // buildETagMap computes SHA-256 ETags for all files in the embedded static FS.
 func buildETagMap() map[string]string {
 	etags := make(map[string]string)
@@ -30,8 +30,8 @@ func testAPIPort(t *testing.T, server *httptest.Server) int {
 
 func newTestHandler(b *backend.Broadcaster) *Handler {
 	return &Handler{
-		tmpl:        LoadTemplates(),
-		client:      NewAPIClient(0), // port 0 — API calls will fail, but that's expected in tests
+		tmpl:        LoadTemplates("en", DefaultBasePath),
+		client:      NewAPIClient(0, DefaultClientTimeout), // port 0 — API calls will fail, but that's expected in tests
 		broadcaster: b,
 	}
 }
@@ -83,8 +83,8 @@ func TestSSEEvents_PlayerPositionDoesNotTriggerMPRIS(t *testing.T) {
 	defer apiServer.Close()
 
 	h := &Handler{
-		tmpl:        LoadTemplates(),
-		client:      NewAPIClient(testAPIPort(t, apiServer)),
+		tmpl:        LoadTemplates("en", DefaultBasePath),
+		client:      NewAPIClient(testAPIPort(t, apiServer), DefaultClientTimeout),
 		broadcaster: b,
 	}
 
@@ -128,8 +128,8 @@ func TestSSEEvents_DebounceCoalesces(t *testing.T) {
 	apiPort := testAPIPort(t, apiServer)
 
 	h := &Handler{
-		tmpl:        LoadTemplates(),
-		client:      NewAPIClient(apiPort),
+		tmpl:        LoadTemplates("en", DefaultBasePath),
+		client:      NewAPIClient(apiPort, DefaultClientTimeout),
 		broadcaster: b,
 	}
 
@@ -182,8 +182,8 @@ func TestSSEEvents_UpgradeFailedRendersFailBadge(t *testing.T) {
 	defer apiServer.Close()
 
 	h := &Handler{
-		tmpl:        LoadTemplates(),
-		client:      NewAPIClient(testAPIPort(t, apiServer)),
+		tmpl:        LoadTemplates("en", DefaultBasePath),
+		client:      NewAPIClient(testAPIPort(t, apiServer), DefaultClientTimeout),
 		broadcaster: b,
 	}
 
@@ -275,8 +275,8 @@ func TestSSEEvents_EventMapping(t *testing.T) {
 			apiPort := testAPIPort(t, apiServer)
 
 			h := &Handler{
-				tmpl:        LoadTemplates(),
-				client:      NewAPIClient(apiPort),
+				tmpl:        LoadTemplates("en", DefaultBasePath),
+				client:      NewAPIClient(apiPort, DefaultClientTimeout),
 				broadcaster: b,
 			}
 
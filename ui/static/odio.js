@@ -6,7 +6,7 @@ function showToast(message, kind = 'error') {
 	const promo = document.getElementById('pwa-promo');
 	const toast = document.createElement('div');
 	// Class names spelled out so Tailwind's content scanner picks them up.
-	const variant = kind === 'success' ? 'toast-success' : 'toast-error';
+	const variant = { success: 'toast-success', info: 'toast-info' }[kind] || 'toast-error';
 	toast.className = `toast ${variant}`;
 	toast.textContent = message;
 	container.appendChild(toast);
@@ -27,6 +27,51 @@ function showToast(message, kind = 'error') {
 	}, 4000);
 }
 
+// Backend state-change toasts, fed by the public /events stream narrowed to
+// the event types worth announcing. Only transitions are toasted: the previous
+// state is tracked per player (and for the adapter) so metadata-only updates
+// stay silent. Nothing happens when the API's SSE endpoint is disabled — the
+// EventSource fails once and stays closed.
+const toastPlayers = new Map(); // bus_name → last playback_status
+let toastBtPowered = null;
+
+function playerToast(player) {
+	const prev = toastPlayers.get(player.bus_name);
+	const status = player.playback_status;
+	toastPlayers.set(player.bus_name, status);
+	if (prev === status) return null;
+	const name = player.identity || player.bus_name;
+	const title = (player.metadata || {})['xesam:title'];
+	if (status === 'Playing') return title ? `${name} is now playing: ${title}` : `${name} is now playing`;
+	if (prev === 'Playing') return `${name} ${status === 'Paused' ? 'paused' : 'stopped'}`;
+	return null;
+}
+
+function bluetoothToast(status) {
+	const prev = toastBtPowered;
+	toastBtPowered = status.powered;
+	if (prev === null || prev === status.powered) return null;
+	return `Bluetooth powered ${status.powered ? 'on' : 'off'}`;
+}
+
+function connectStateToasts() {
+	const source = new EventSource(document.body.dataset.apiBase + '/events?types=player.added,player.updated,player.removed,bluetooth.updated');
+	const onPlayer = e => {
+		const msg = playerToast(JSON.parse(e.data).data);
+		if (msg) showToast(msg, 'info');
+	};
+	source.addEventListener('player.added', onPlayer);
+	source.addEventListener('player.updated', onPlayer);
+	source.addEventListener('player.removed', e => {
+		toastPlayers.delete(JSON.parse(e.data).bus_name);
+	});
+	source.addEventListener('bluetooth.updated', e => {
+		const msg = bluetoothToast(JSON.parse(e.data));
+		if (msg) showToast(msg, 'info');
+	});
+}
+document.addEventListener('DOMContentLoaded', connectStateToasts);
+
 // ── PWA promo ───────────────────────────────────────────────────────────────
 
 const PWA_PROMO_DISMISSED = 'odio.pwa-promo.dismissed';
@@ -139,8 +184,15 @@ document.addEventListener('htmx:afterSwap', function() {
 
 // ── Transport buttons ───────────────────────────────────────────────────────
 
+// Icon-only buttons carry their label twice: title for the tooltip, aria-label
+// for screen readers. Keep them in step when JS changes the button's state.
+function setLabel(el, text) {
+	el.title = text;
+	el.setAttribute('aria-label', text);
+}
+
 // Both icons live in the button as <span>; group-data-[playing=true]: variants
-// flip which one is visible. JS flips data-playing, the title and the matching
+// flip which one is visible. JS flips data-playing, the label and the matching
 // seeker so its interpolation stops/starts without waiting for the next HTMX
 // poll. Position is also snapshotted to the visible slider value: data-position
 // only refreshes on player.updated swaps (not heartbeat ticks), so without
@@ -149,7 +201,7 @@ document.addEventListener('htmx:afterSwap', function() {
 function optimisticTogglePlayPause(button, playerName) {
 	const next = button.dataset.playing !== 'true';
 	button.dataset.playing = next;
-	button.title = next ? 'Pause' : 'Play';
+	setLabel(button, next ? 'Pause' : 'Play');
 	const slider = document.querySelector(`.seek-slider[data-player="${CSS.escape(playerName)}"]`);
 	if (slider) {
 		const frozen = slider.value;
@@ -179,14 +231,14 @@ function optimisticToggleShuffle(btn) {
 	const next = !prev;
 	btn.dataset.shuffle = next;
 	btn.classList.toggle('btn-active', next);
-	btn.title = `Shuffle ${next ? 'on' : 'off'}`;
+	setLabel(btn, `Shuffle ${next ? 'on' : 'off'}`);
 }
 
 function revertShuffle(btn) {
 	const prev = btn.dataset.shufflePrev === 'true';
 	btn.dataset.shuffle = prev;
 	btn.classList.toggle('btn-active', prev);
-	btn.title = `Shuffle ${prev ? 'on' : 'off'}`;
+	setLabel(btn, `Shuffle ${prev ? 'on' : 'off'}`);
 }
 
 // data-loop drives both the icon swap (via group-data-[loop=Track]: variants)
@@ -199,14 +251,14 @@ function optimisticCycleLoop(btn) {
 	btn.dataset.loopPrev = prev;
 	btn.dataset.loop = next;
 	btn.classList.toggle('btn-active', next !== 'None');
-	btn.title = `Repeat: ${next}`;
+	setLabel(btn, `Repeat: ${next}`);
 }
 
 function revertLoop(btn) {
 	const prev = btn.dataset.loopPrev || 'None';
 	btn.dataset.loop = prev;
 	btn.classList.toggle('btn-active', prev !== 'None');
-	btn.title = `Repeat: ${prev}`;
+	setLabel(btn, `Repeat: ${prev}`);
 }
 
 // ── Position seeker ──────────────────────────────────────────────────────────
@@ -252,6 +304,22 @@ function updatePositions() {
 document.addEventListener('DOMContentLoaded', () => setInterval(updatePositions, 500));
 document.addEventListener('htmx:afterSwap', updatePositions);
 
+// Featured now-playing card: its progress bar polls GET /players/{player}/position
+// (hx-swap="none") and is resized from the JSON response here.
+function updateFeaturedProgress(el, event) {
+	if (!event.detail.successful) return;
+	let info;
+	try {
+		info = JSON.parse(event.detail.xhr.responseText);
+	} catch {
+		return;
+	}
+	const pct = info.length > 0 ? Math.min(info.position / info.length * 100, 100) : 0;
+	el.querySelector('.featured-progress-fill').style.width = `${pct}%`;
+	el.querySelector('.featured-progress-pos').textContent = fmtMicros(info.position);
+	el.querySelector('.featured-progress-len').textContent = fmtMicros(info.length);
+}
+
 // ── Countdowns ───────────────────────────────────────────────────────────────
 
 // Sections only re-render on SSE events, so a server-rendered deadline (e.g. the
@@ -398,3 +466,14 @@ function optimisticToggleMuteMpris(button) {
 	button.dataset.muted = isMuted ? 'false' : 'true';
 }
 const revertMuteMpris = optimisticToggleMuteMpris;
+
+// Sections poll their /ui/sections fragment every RefreshSeconds, but only
+// while the SSE stream is down (hx-trigger filter): live events otherwise
+// keep them current. EventSource reconnects on its own; sseOpen flips back.
+var sseConnected = false;
+document.addEventListener('htmx:sseOpen', function() { sseConnected = true; });
+document.addEventListener('htmx:sseError', function() { sseConnected = false; });
+
+function sseDown() {
+	return !sseConnected;
+}
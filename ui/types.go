@@ -10,6 +10,11 @@ import "time"
 type PowerCapabilities struct {
 	Reboot   bool `json:"reboot"`
 	PowerOff bool `json:"power_off"`
+	// Live is logind's current answer; nil when it could not be queried.
+	Live *struct {
+		Reboot   bool `json:"reboot"`
+		PowerOff bool `json:"power_off"`
+	} `json:"live,omitempty"`
 }
 
 // ServerInfo represents the response from /server
@@ -25,10 +30,13 @@ type ServerInfo struct {
 
 // Backends indicates which backends are enabled
 type Backends struct {
+	ALSA       bool `json:"alsa"`
 	Bluetooth  bool `json:"bluetooth"`
+	GPIO       bool `json:"gpio"`
 	MPRIS      bool `json:"mpris"`
 	Power      bool `json:"power"`
 	PulseAudio bool `json:"pulseaudio"`
+	Snapcast   bool `json:"snapcast"`
 	Systemd    bool `json:"systemd"`
 	Upgrade    bool `json:"upgrade"`
 	Zeroconf   bool `json:"zeroconf"`
@@ -185,15 +193,20 @@ type Service struct {
 type BluetoothDevice struct {
 	Address   string `json:"address"`
 	Name      string `json:"name"`
+	Alias     string `json:"alias,omitempty"`
 	Paired    bool   `json:"paired"`
 	Bonded    bool   `json:"bonded"`
 	Trusted   bool   `json:"trusted"`
 	Connected bool   `json:"connected"`
 }
 
-// Label is the display label: the name, falling back to the address. Used as
-// both the sort key and the rendered label so the two never diverge.
+// Label is the display label: the alias, then the name, falling back to the
+// address. Used as both the sort key and the rendered label so the two never
+// diverge.
 func (d BluetoothDevice) Label() string {
+	if d.Alias != "" {
+		return d.Alias
+	}
 	if d.Name != "" {
 		return d.Name
 	}
@@ -217,13 +230,14 @@ type BluetoothStatus struct {
 
 // DashboardView is the main view model for the dashboard page
 type DashboardView struct {
-	Title      string
-	ServerInfo *ServerInfo
-	Players    []PlayerView
-	AudioData  *AudioData
-	Services   []ServiceView
-	Bluetooth  *BluetoothView
-	Upgrade    *UpgradeStatus
+	Title          string
+	ServerInfo     *ServerInfo
+	Players        []PlayerView
+	AudioData      *AudioData
+	Services       []ServiceView
+	Bluetooth      *BluetoothView
+	Upgrade        *UpgradeStatus
+	RefreshSeconds int // section polling cadence while SSE is down
 }
 
 // PlayerView is a view-optimized version of Player for templates
@@ -233,7 +247,7 @@ type PlayerView struct {
 	Artist      string
 	Title       string
 	Album       string
-	ArtUrl      string   // Cover art proxy path (/players/{name}/cover), empty if unavailable
+	ArtUrl      string   // Cover art proxy path (/players/{name}/cover) below apiBase, empty if unavailable
 	State       string   // "playing", "paused", "stopped"
 	Volume      *float64 // Volume level 0.0-1.0
 	CanPlay     bool
@@ -257,12 +271,23 @@ type PlayerView struct {
 	// Tracklist — empty when the player doesn't expose one
 	Tracks        []TrackView
 	CanEditTracks bool
+	// Featured marks the first Playing player, rendered as the now-playing card
+	Featured bool
 }
 
 // ShowTracklist reports whether the tracklist view is worth a toggle:
 // a list of 0 or 1 track offers nothing over the cover.
 func (v PlayerView) ShowTracklist() bool { return len(v.Tracks) >= 2 }
 
+// ProgressPercent is the server-rendered position as a percentage of Duration,
+// the starting width of the featured card's progress bar.
+func (v PlayerView) ProgressPercent() float64 {
+	if v.Duration <= 0 {
+		return 0
+	}
+	return min(float64(v.Position)*100/float64(v.Duration), 100)
+}
+
 // TrackView is a view-optimized tracklist entry for templates
 type TrackView struct {
 	Ref     string // %2F-encoded full track ID, used as the API path reference